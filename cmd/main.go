@@ -1,14 +1,39 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"go-transfer/internal/config"
+	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
-	config.Setup()
-	err := http.ListenAndServe(":8080", nil)
-	if err != nil {
-		return
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	handler, runner := config.Setup()
+	runner.Start(ctx)
+
+	server := &http.Server{Addr: ":8080", Handler: handler}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("failed to shutdown server: %v", err)
+		}
+	}()
+
+	err := server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("server error: %v", err)
 	}
+
+	stop()
+	runner.Wait()
 }
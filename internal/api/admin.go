@@ -0,0 +1,25 @@
+package api
+
+import (
+	"crypto/subtle"
+	"net/http"
+)
+
+const adminTokenHeader = "X-Admin-Token"
+
+// authorizeAdmin guards admin endpoints with the configured token. Without a
+// token the endpoints are disabled and answer as if they did not exist.
+func authorizeAdmin(w http.ResponseWriter, r *http.Request, adminToken string) bool {
+	if adminToken == "" {
+		http.NotFound(w, r)
+		return false
+	}
+	token := r.Header.Get(adminTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		http.Error(w, ErrInvalidAdminToken.Error(), http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+var ErrInvalidAdminToken = NewError("X-Admin-Token header is missing or invalid")
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"net/http"
+	"strconv"
+)
+
+// serveIdempotent runs handle for a request carrying an Idempotency-Key only
+// once, replaying the stored response when the payer uses the key again on
+// the same endpoint. Requests without a key are handled every time.
+func serveIdempotent(w http.ResponseWriter, r *http.Request, idempotency *usecase.Idempotency, body []byte, handle func(w http.ResponseWriter, r *http.Request, body []byte)) {
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		handle(w, r, body)
+		return
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		http.Error(w, ErrInvalidIdempotencyKey.Error(), http.StatusBadRequest)
+		return
+	}
+
+	scope := idempotencyScope(r, body)
+	record, err := idempotency.Begin(r.Context(), scope, key, hashRequest(body))
+	switch {
+	case errors.Is(err, usecase.ErrIdempotencyKeyReused):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, usecase.ErrIdempotencyRequestInFlight):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	if record != nil {
+		w.Header().Set(idempotentReplayedHeader, strconv.FormatBool(true))
+		if record.ResponseContentType != "" {
+			w.Header().Set("Content-Type", record.ResponseContentType)
+		}
+		if record.ResponseLocation != "" {
+			w.Header().Set("Location", record.ResponseLocation)
+		}
+		w.WriteHeader(record.ResponseCode)
+		_, _ = w.Write(record.ResponseBody)
+		return
+	}
+
+	recorder := newResponseRecorder(w)
+	handle(recorder, r, body)
+
+	// Recording must outlive the client, or a key whose request went through
+	// would stay IN_PROGRESS and block retries until it expires.
+	ctx := context.WithoutCancel(r.Context())
+	if recorder.status >= http.StatusInternalServerError {
+		if err := idempotency.Release(ctx, scope, key); err != nil {
+			fmt.Printf("failed to release idempotency key %q: %v\n", key, err)
+		}
+		return
+	}
+	if err := idempotency.Complete(ctx, scope, key, recorder.status, recorder.Header().Get("Content-Type"), recorder.Header().Get("Location"), recorder.body.Bytes()); err != nil {
+		fmt.Printf("failed to complete idempotency key %q: %v\n", key, err)
+	}
+}
+
+// idempotencyScope ties a key to the payer of the request and its endpoint. A
+// body without a payer is rejected by the handler, under a scope of its own.
+func idempotencyScope(r *http.Request, body []byte) usecase.IdempotencyScope {
+	var req struct {
+		Payer int64 `json:"payer"`
+	}
+	_ = json.Unmarshal(body, &req)
+	return usecase.IdempotencyScope{PayerID: req.Payer, Route: r.Method + " " + r.URL.Path}
+}
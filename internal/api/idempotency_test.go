@@ -0,0 +1,114 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+	"go-transfer/internal/domain/usecase"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type idempotencyRepoInMemory struct {
+	mu      sync.Mutex
+	records map[string]entities.IdempotencyRecord
+}
+
+func newIdempotencyRepoInMemory() *idempotencyRepoInMemory {
+	return &idempotencyRepoInMemory{records: make(map[string]entities.IdempotencyRecord)}
+}
+
+func (r *idempotencyRepoInMemory) Create(ctx context.Context, record *entities.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.records[record.Key]; ok {
+		return port.ErrIdempotencyKeyExists
+	}
+	r.records[record.Key] = *record
+	return nil
+}
+
+func (r *idempotencyRepoInMemory) GetByKey(ctx context.Context, key string) (*entities.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record, ok := r.records[key]
+	if !ok {
+		return nil, port.ErrIdempotencyRecordNotFound
+	}
+	return &record, nil
+}
+
+func (r *idempotencyRepoInMemory) Replace(ctx context.Context, record *entities.IdempotencyRecord, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.records[record.Key]
+	if !ok || !(existing.IsExpired(now) || existing.IsAbandoned(now) && existing.RequestHash == record.RequestHash) {
+		return port.ErrIdempotencyKeyExists
+	}
+	r.records[record.Key] = *record
+	return nil
+}
+
+func (r *idempotencyRepoInMemory) Complete(ctx context.Context, key string, code int, contentType, location string, body []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	record := r.records[key]
+	record.Status = entities.IdempotencyStatusCompleted
+	record.ResponseCode = code
+	record.ResponseContentType = contentType
+	record.ResponseLocation = location
+	record.ResponseBody = body
+	r.records[key] = record
+	return nil
+}
+
+func (r *idempotencyRepoInMemory) Delete(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.records, key)
+	return nil
+}
+
+func (r *idempotencyRepoInMemory) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestServeIdempotent_ScopesKeysByPayerAndEndpoint(t *testing.T) {
+	idempotency := usecase.NewIdempotency(newIdempotencyRepoInMemory(), time.Hour, time.Minute)
+	handled := 0
+	handle := func(w http.ResponseWriter, r *http.Request, body []byte) {
+		handled++
+		w.WriteHeader(http.StatusCreated)
+	}
+	serve := func(path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		r.Header.Set(idempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		serveIdempotent(w, r, idempotency, []byte(body), handle)
+		return w
+	}
+
+	transfer := `{"payer":1,"payee":2,"value":"10.00"}`
+	assert.Equal(t, http.StatusCreated, serve("/transfers", transfer).Code)
+
+	// The same key on the batch endpoint is a new request, not a reuse.
+	batch := serve("/transfers/batch", `{"payer":1,"mode":"best_effort","items":[]}`)
+	assert.Equal(t, http.StatusCreated, batch.Code)
+	assert.Empty(t, batch.Header().Get(idempotentReplayedHeader))
+
+	// So is the same key sent by another payer.
+	assert.Equal(t, http.StatusCreated, serve("/transfers", `{"payer":3,"payee":2,"value":"10.00"}`).Code)
+	assert.Equal(t, 3, handled)
+
+	replayed := serve("/transfers", transfer)
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, "true", replayed.Header().Get(idempotentReplayedHeader))
+	assert.Equal(t, 3, handled)
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+	"go-transfer/internal/domain/usecase"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type TransferLimitsRequest struct {
+	SingleMax     *entities.Money `json:"single_max"`
+	DailyAmount   *entities.Money `json:"daily_amount"`
+	MonthlyAmount *entities.Money `json:"monthly_amount"`
+	HourlyCount   *int            `json:"hourly_count"`
+}
+
+type TransferLimitsResponse struct {
+	SingleMax     *entities.Money `json:"single_max"`
+	DailyAmount   *entities.Money `json:"daily_amount"`
+	MonthlyAmount *entities.Money `json:"monthly_amount"`
+	HourlyCount   *int            `json:"hourly_count"`
+}
+
+func NewTransferLimitsResponse(limits entities.TransferLimits) *TransferLimitsResponse {
+	return &TransferLimitsResponse{
+		SingleMax:     limits.SingleMax,
+		DailyAmount:   limits.DailyAmount,
+		MonthlyAmount: limits.MonthlyAmount,
+		HourlyCount:   limits.HourlyCount,
+	}
+}
+
+type UserLimitsResponse struct {
+	UserID     int64                   `json:"user_id"`
+	WalletType entities.WalletType     `json:"wallet_type"`
+	Override   *TransferLimitsResponse `json:"override"`
+	Effective  *TransferLimitsResponse `json:"effective"`
+}
+
+func NewUserLimitsResponse(limits *usecase.UserLimits) UserLimitsResponse {
+	response := UserLimitsResponse{
+		UserID:     limits.UserID,
+		WalletType: limits.WalletType,
+		Effective:  NewTransferLimitsResponse(limits.Effective),
+	}
+	if limits.Override != nil {
+		response.Override = NewTransferLimitsResponse(limits.Override.TransferLimits)
+	}
+	return response
+}
+
+type LimitExceededResponse struct {
+	Error           string            `json:"error"`
+	Limit           usecase.LimitKind `json:"limit"`
+	RemainingAmount *entities.Money   `json:"remaining_amount,omitempty"`
+	RemainingCount  *int              `json:"remaining_count,omitempty"`
+	ResetsAt        *time.Time        `json:"resets_at,omitempty"`
+}
+
+func NewLimitExceededResponse(exceeded *usecase.LimitExceededError) LimitExceededResponse {
+	return LimitExceededResponse{
+		Error:           usecase.ErrLimitExceeded.Error(),
+		Limit:           exceeded.Limit,
+		RemainingAmount: exceeded.RemainingAmount,
+		RemainingCount:  exceeded.RemainingCount,
+		ResetsAt:        exceeded.ResetsAt,
+	}
+}
+
+type LimitHandler struct {
+	LimitsUseCase *usecase.Limits
+	adminToken    string
+}
+
+func NewLimitHandler(LimitsUseCase *usecase.Limits, adminToken string) *LimitHandler {
+	return &LimitHandler{
+		LimitsUseCase: LimitsUseCase,
+		adminToken:    adminToken,
+	}
+}
+
+func (h *LimitHandler) GetUserLimits(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r, h.adminToken) {
+		return
+	}
+
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidUserID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limits, err := h.LimitsUseCase.GetUserLimits(r.Context(), userID)
+	switch {
+	case errors.Is(err, usecase.ErrLimitUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewUserLimitsResponse(limits))
+}
+
+func (h *LimitHandler) SetUserLimits(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r, h.adminToken) {
+		return
+	}
+
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidUserID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req TransferLimitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	limits, err := h.LimitsUseCase.SetUserLimits(r.Context(), userID, entities.TransferLimits{
+		SingleMax:     req.SingleMax,
+		DailyAmount:   req.DailyAmount,
+		MonthlyAmount: req.MonthlyAmount,
+		HourlyCount:   req.HourlyCount,
+	})
+	switch {
+	case errors.Is(err, usecase.ErrInvalidLimit):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrLimitUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewUserLimitsResponse(limits))
+}
+
+func (h *LimitHandler) ResetUserLimits(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r, h.adminToken) {
+		return
+	}
+
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidUserID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	err = h.LimitsUseCase.ResetUserLimits(r.Context(), userID)
+	switch {
+	case errors.Is(err, port.ErrTransferLimitNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *LimitHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"expvar"
+	"net/http"
+)
+
+// MetricsHandler shows admins the metrics published through expvar, circuit
+// breaker state among them.
+type MetricsHandler struct {
+	adminToken string
+}
+
+func NewMetricsHandler(adminToken string) *MetricsHandler {
+	return &MetricsHandler{
+		adminToken: adminToken,
+	}
+}
+
+func (h *MetricsHandler) Metrics(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r, h.adminToken) {
+		return
+	}
+	expvar.Handler().ServeHTTP(w, r)
+}
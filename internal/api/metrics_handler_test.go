@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsHandler_RequiresAdminToken(t *testing.T) {
+	h := NewMetricsHandler("secret")
+	serve := func(token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/admin/metrics", nil)
+		if token != "" {
+			r.Header.Set(adminTokenHeader, token)
+		}
+		w := httptest.NewRecorder()
+		h.Metrics(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve("").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("wrong").Code)
+	w := serve("secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "memstats")
+}
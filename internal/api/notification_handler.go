@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+	"go-transfer/internal/domain/usecase"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultDeadNotificationLimit = 50
+	maxDeadNotificationLimit     = 500
+)
+
+type NotificationResponse struct {
+	ID                  int64                       `json:"id"`
+	ReceiverID          int64                       `json:"receiver_id"`
+	Kind                entities.NotificationKind   `json:"kind"`
+	TransferID          *int64                      `json:"transfer_id,omitempty"`
+	ScheduledTransferID *int64                      `json:"scheduled_transfer_id,omitempty"`
+	TransferBatchID     *int64                      `json:"transfer_batch_id,omitempty"`
+	Value               entities.Money              `json:"value"`
+	Status              entities.NotificationStatus `json:"status"`
+	Attempts            int                         `json:"attempts"`
+	LastError           string                      `json:"last_error,omitempty"`
+	NextAttemptAt       *time.Time                  `json:"next_attempt_at,omitempty"`
+	CreatedAt           time.Time                   `json:"created_at"`
+	UpdatedAt           time.Time                   `json:"updated_at"`
+}
+
+func NewNotificationResponse(notification *entities.Notification) NotificationResponse {
+	return NotificationResponse{
+		ID:                  notification.ID,
+		ReceiverID:          notification.ReceiverID,
+		Kind:                notification.Kind,
+		TransferID:          notification.TransactionID,
+		ScheduledTransferID: notification.ScheduledTransferID,
+		TransferBatchID:     notification.TransferBatchID,
+		Value:               notification.Amount,
+		Status:              notification.Status,
+		Attempts:            notification.Attempts,
+		LastError:           notification.LastError,
+		NextAttemptAt:       notification.NextAttemptAt,
+		CreatedAt:           notification.CreatedAt,
+		UpdatedAt:           notification.UpdatedAt,
+	}
+}
+
+type NotificationHandler struct {
+	NotificationUseCase *usecase.NotificationUseCase
+	adminToken          string
+}
+
+func NewNotificationHandler(NotificationUseCase *usecase.NotificationUseCase, adminToken string) *NotificationHandler {
+	return &NotificationHandler{
+		NotificationUseCase: NotificationUseCase,
+		adminToken:          adminToken,
+	}
+}
+
+func (h *NotificationHandler) ListDead(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r, h.adminToken) {
+		return
+	}
+
+	query := r.URL.Query()
+	afterID := int64(0)
+	if value := query.Get("after_id"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, ErrInvalidNotificationID.Error(), http.StatusBadRequest)
+			return
+		}
+		afterID = parsed
+	}
+	limit := defaultDeadNotificationLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxDeadNotificationLimit {
+			http.Error(w, ErrInvalidNotificationLimit.Error(), http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	notifications, err := h.NotificationUseCase.ListDead(r.Context(), afterID, limit)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]NotificationResponse, 0, len(notifications))
+	for i := range notifications {
+		response = append(response, NewNotificationResponse(&notifications[i]))
+	}
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+func (h *NotificationHandler) Redrive(w http.ResponseWriter, r *http.Request) {
+	if !authorizeAdmin(w, r, h.adminToken) {
+		return
+	}
+
+	notificationID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidNotificationID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	notification, err := h.NotificationUseCase.Redrive(r.Context(), notificationID)
+	switch {
+	case errors.Is(err, port.ErrNotificationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrNotificationNotDead):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewNotificationResponse(notification))
+}
+
+func (h *NotificationHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+var (
+	ErrInvalidNotificationID    = NewError("Notification id must be a non-negative number")
+	ErrInvalidNotificationLimit = NewError("limit must be between 1 and 500")
+)
@@ -0,0 +1,345 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/usecase"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultScheduledTransferLimit = 50
+	maxScheduledTransferLimit     = 100
+)
+
+type ScheduledTransferRecurrence struct {
+	Frequency      entities.RecurrenceFrequency `json:"frequency"`
+	DayOfMonth     int                          `json:"day_of_month,omitempty"`
+	EndsAt         *time.Time                   `json:"ends_at,omitempty"`
+	MaxOccurrences *int                         `json:"max_occurrences,omitempty"`
+}
+
+type ScheduledTransferRequest struct {
+	Value                 entities.Money                     `json:"value"`
+	Payer                 int64                              `json:"payer"`
+	Payee                 int64                              `json:"payee"`
+	ExecuteAt             time.Time                          `json:"execute_at"`
+	Recurrence            *ScheduledTransferRecurrence       `json:"recurrence"`
+	OnInsufficientBalance entities.InsufficientBalancePolicy `json:"on_insufficient_balance"`
+}
+
+type ScheduledTransferResponse struct {
+	ID                    int64                              `json:"id"`
+	Payer                 int64                              `json:"payer"`
+	Payee                 int64                              `json:"payee"`
+	Value                 entities.Money                     `json:"value"`
+	Currency              string                             `json:"currency"`
+	ExecuteAt             time.Time                          `json:"execute_at"`
+	Status                entities.ScheduledTransferStatus   `json:"status"`
+	Recurrence            *ScheduledTransferRecurrence       `json:"recurrence,omitempty"`
+	OnInsufficientBalance entities.InsufficientBalancePolicy `json:"on_insufficient_balance"`
+	Occurrences           int                                `json:"occurrences"`
+	Attempts              int                                `json:"attempts"`
+	NextAttemptAt         *time.Time                         `json:"next_attempt_at,omitempty"`
+	TransferID            *int64                             `json:"transfer_id,omitempty"`
+	FailureReason         string                             `json:"failure_reason,omitempty"`
+	CreatedAt             time.Time                          `json:"created_at"`
+	UpdatedAt             time.Time                          `json:"updated_at"`
+}
+
+func NewScheduledTransferResponse(scheduled *entities.ScheduledTransfer) ScheduledTransferResponse {
+	response := ScheduledTransferResponse{
+		ID:                    scheduled.ID,
+		Payer:                 scheduled.PayerID,
+		Payee:                 scheduled.PayeeID,
+		Value:                 scheduled.Amount,
+		Currency:              scheduled.Currency,
+		ExecuteAt:             scheduled.ExecuteAt,
+		Status:                scheduled.Status,
+		OnInsufficientBalance: scheduled.OnInsufficientBalance,
+		Occurrences:           scheduled.Occurrences,
+		Attempts:              scheduled.Attempts,
+		NextAttemptAt:         scheduled.NextAttemptAt,
+		TransferID:            scheduled.TransactionID,
+		FailureReason:         scheduled.FailureReason,
+		CreatedAt:             scheduled.CreatedAt,
+		UpdatedAt:             scheduled.UpdatedAt,
+	}
+	if scheduled.IsRecurring() {
+		response.Recurrence = &ScheduledTransferRecurrence{
+			Frequency:      scheduled.Recurrence.Frequency,
+			DayOfMonth:     scheduled.Recurrence.DayOfMonth,
+			EndsAt:         scheduled.Recurrence.EndsAt,
+			MaxOccurrences: scheduled.Recurrence.MaxOccurrences,
+		}
+	}
+	return response
+}
+
+type ScheduledTransferRunResponse struct {
+	ID           int64                                `json:"id"`
+	ScheduledFor time.Time                            `json:"scheduled_for"`
+	Attempt      int                                  `json:"attempt"`
+	Status       entities.ScheduledTransferRunStatus  `json:"status"`
+	TransferID   *int64                               `json:"transfer_id,omitempty"`
+	Error        string                               `json:"error,omitempty"`
+	FailureCode  entities.ScheduledTransferRunFailure `json:"failure_code,omitempty"`
+	StartedAt    time.Time                            `json:"started_at"`
+	FinishedAt   *time.Time                           `json:"finished_at,omitempty"`
+}
+
+func NewScheduledTransferRunResponse(run *entities.ScheduledTransferRun) ScheduledTransferRunResponse {
+	return ScheduledTransferRunResponse{
+		ID:           run.ID,
+		ScheduledFor: run.ScheduledFor,
+		Attempt:      run.Attempt,
+		Status:       run.Status,
+		TransferID:   run.TransactionID,
+		Error:        run.Error,
+		FailureCode:  run.FailureCode,
+		StartedAt:    run.StartedAt,
+		FinishedAt:   run.FinishedAt,
+	}
+}
+
+type ScheduledTransferHandler struct {
+	ScheduledUseCase *usecase.ScheduledTransfers
+}
+
+func NewScheduledTransferHandler(ScheduledUseCase *usecase.ScheduledTransfers) *ScheduledTransferHandler {
+	return &ScheduledTransferHandler{
+		ScheduledUseCase: ScheduledUseCase,
+	}
+}
+
+// Schedule records a transfer to be made at execute_at, or a standing order
+// when recurrence is given.
+func (h *ScheduledTransferHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	var req ScheduledTransferRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxTransactionRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validateScheduledTransferRequest(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	input := usecase.ScheduleInput{
+		PayerID:               req.Payer,
+		PayeeID:               req.Payee,
+		Amount:                req.Value,
+		ExecuteAt:             req.ExecuteAt,
+		OnInsufficientBalance: req.OnInsufficientBalance,
+	}
+	if req.Recurrence != nil {
+		input.Recurrence = entities.Recurrence{
+			Frequency:      req.Recurrence.Frequency,
+			DayOfMonth:     req.Recurrence.DayOfMonth,
+			EndsAt:         req.Recurrence.EndsAt,
+			MaxOccurrences: req.Recurrence.MaxOccurrences,
+		}
+	}
+	scheduled, err := h.ScheduledUseCase.Schedule(r.Context(), input)
+	switch {
+	case errors.Is(err, usecase.ErrInvalidScheduledAmount), errors.Is(err, usecase.ErrExecuteAtNotInFuture),
+		errors.Is(err, entities.ErrInvalidRecurrence), errors.Is(err, usecase.ErrInvalidInsufficientBalancePolicy):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrSenderNotFound), errors.Is(err, usecase.ErrReceiverNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrMerchantCannotTransfer):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", scheduledTransferLocation(scheduled.ID))
+	h.writeJSON(w, http.StatusCreated, NewScheduledTransferResponse(scheduled))
+}
+
+// GetScheduledTransfer returns a scheduled transfer to its payer or payee.
+func (h *ScheduledTransferHandler) GetScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	requesterID, id, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	scheduled, err := h.ScheduledUseCase.Get(r.Context(), id, requesterID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewScheduledTransferResponse(scheduled))
+}
+
+// ListScheduledTransfers returns the scheduled transfers the requester
+// created, after the after_id cursor.
+func (h *ScheduledTransferHandler) ListScheduledTransfers(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
+	if err != nil {
+		http.Error(w, ErrMissingUserID.Error(), http.StatusUnauthorized)
+		return
+	}
+	afterID := int64(0)
+	if value := r.URL.Query().Get("after_id"); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || parsed < 0 {
+			http.Error(w, ErrInvalidScheduledTransferID.Error(), http.StatusBadRequest)
+			return
+		}
+		afterID = parsed
+	}
+	limit, ok := h.parseLimit(w, r)
+	if !ok {
+		return
+	}
+
+	scheduled, err := h.ScheduledUseCase.List(r.Context(), requesterID, afterID, limit)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]ScheduledTransferResponse, 0, len(scheduled))
+	for i := range scheduled {
+		response = append(response, NewScheduledTransferResponse(&scheduled[i]))
+	}
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// ListRuns returns the latest executions of a scheduled transfer.
+func (h *ScheduledTransferHandler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	requesterID, id, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+	limit, ok := h.parseLimit(w, r)
+	if !ok {
+		return
+	}
+
+	runs, err := h.ScheduledUseCase.Runs(r.Context(), id, requesterID, limit)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	response := make([]ScheduledTransferRunResponse, 0, len(runs))
+	for i := range runs {
+		response = append(response, NewScheduledTransferRunResponse(&runs[i]))
+	}
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// Cancel stops a scheduled transfer, or every remaining occurrence of a
+// standing order.
+func (h *ScheduledTransferHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.ScheduledUseCase.Cancel)
+}
+
+// Pause suspends a standing order until it is resumed.
+func (h *ScheduledTransferHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.ScheduledUseCase.Pause)
+}
+
+// Resume reactivates a paused standing order from its next occurrence.
+func (h *ScheduledTransferHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.change(w, r, h.ScheduledUseCase.Resume)
+}
+
+func (h *ScheduledTransferHandler) change(w http.ResponseWriter, r *http.Request, apply func(ctx context.Context, id, requesterID int64) (*entities.ScheduledTransfer, error)) {
+	requesterID, id, ok := h.parseRequest(w, r)
+	if !ok {
+		return
+	}
+
+	scheduled, err := apply(r.Context(), id, requesterID)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewScheduledTransferResponse(scheduled))
+}
+
+func (h *ScheduledTransferHandler) parseRequest(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	requesterID, err := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
+	if err != nil {
+		http.Error(w, ErrMissingUserID.Error(), http.StatusUnauthorized)
+		return 0, 0, false
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidScheduledTransferID.Error(), http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return requesterID, id, true
+}
+
+func (h *ScheduledTransferHandler) parseLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultScheduledTransferLimit, true
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxScheduledTransferLimit {
+		http.Error(w, ErrInvalidScheduledTransferLimit.Error(), http.StatusBadRequest)
+		return 0, false
+	}
+	return limit, true
+}
+
+func (h *ScheduledTransferHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrScheduledTransferNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecase.ErrScheduledTransferAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, usecase.ErrScheduledTransferNotCancellable), errors.Is(err, usecase.ErrScheduledTransferNotRecurring),
+		errors.Is(err, usecase.ErrScheduledTransferNotPausable), errors.Is(err, usecase.ErrScheduledTransferNotPaused):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func (h *ScheduledTransferHandler) validateScheduledTransferRequest(req ScheduledTransferRequest) error {
+	if !req.Value.IsPositive() {
+		return ErrInvalidTransactionValue
+	}
+	if req.Payer == req.Payee {
+		return ErrSamePayerPayee
+	}
+	if req.ExecuteAt.IsZero() {
+		return ErrMissingExecuteAt
+	}
+	return nil
+}
+
+func (h *ScheduledTransferHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func scheduledTransferLocation(id int64) string {
+	return "/scheduled-transfers/" + strconv.FormatInt(id, 10)
+}
+
+var (
+	ErrMissingExecuteAt              = NewError("execute_at must be an RFC 3339 date")
+	ErrInvalidScheduledTransferID    = NewError("Scheduled transfer id must be a number")
+	ErrInvalidScheduledTransferLimit = NewError("limit must be between 1 and 100")
+)
@@ -1,24 +1,237 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
 	"go-transfer/internal/domain/usecase"
+	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	userIDHeader              = "X-User-ID"
+	deviceIDHeader            = "X-Device-ID"
+	maxIdempotencyKeyLength   = 255
+	maxTransactionRequestSize = 1 << 20
+	maxCancelReasonLength     = 255
 )
 
 type TransactionRequest struct {
-	Value float64 `json:"value"`
-	Payer int64   `json:"payer"`
-	Payee int64   `json:"payee"`
+	Value entities.Money `json:"value"`
+	Payer int64          `json:"payer"`
+	Payee int64          `json:"payee"`
+	// QuoteID uses the exchange rate locked by POST /transfers/quote.
+	QuoteID *int64 `json:"quote_id,omitempty"`
+	// Capture false only holds the payer's funds until the transfer is
+	// captured or voided.
+	Capture *bool `json:"capture,omitempty"`
+	// Splits pays value to several payees, each given a value or a
+	// percentage, instead of to payee.
+	Splits []SplitRequest `json:"splits,omitempty"`
+	// Description, ExternalReference and Metadata are kept for the payer's
+	// reconciliation; ExternalReference, such as an order number, must be
+	// unique among the payer's transfers.
+	Description       string            `json:"description,omitempty"`
+	ExternalReference string            `json:"external_reference,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+}
+
+type SplitRequest struct {
+	Payee      int64           `json:"payee"`
+	Value      *entities.Money `json:"value,omitempty"`
+	Percentage string          `json:"percentage,omitempty"`
+}
+
+type RefundRequest struct {
+	Value *entities.Money `json:"value,omitempty"`
+}
+
+type CaptureRequest struct {
+	Value *entities.Money `json:"value,omitempty"`
+}
+
+type CancelRequest struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type TransferResponse struct {
+	ID                 int64                      `json:"id"`
+	Type               entities.TransactionType   `json:"type"`
+	Payer              int64                      `json:"payer"`
+	Payee              int64                      `json:"payee"`
+	Value              entities.Money             `json:"value"`
+	Currency           string                     `json:"currency"`
+	ExchangeRate       *entities.ExchangeRate     `json:"exchange_rate,omitempty"`
+	ReceivedValue      *entities.Money            `json:"received_value,omitempty"`
+	ReceivedCurrency   *string                    `json:"received_currency,omitempty"`
+	AuthorizedValue    *entities.Money            `json:"authorized_value,omitempty"`
+	HoldExpiresAt      *time.Time                 `json:"hold_expires_at,omitempty"`
+	RefundedValue      entities.Money             `json:"refunded_value"`
+	Fee                entities.Money             `json:"fee"`
+	FeeBreakdown       *FeeBreakdownResponse      `json:"fee_breakdown,omitempty"`
+	Status             entities.TransactionStatus `json:"status"`
+	OriginalTransferID *int64                     `json:"original_transfer_id,omitempty"`
+	Authorization      *AuthorizationResponse     `json:"authorization,omitempty"`
+	Splits             []SplitResponse            `json:"splits,omitempty"`
+	Description        string                     `json:"description,omitempty"`
+	ExternalReference  *string                    `json:"external_reference,omitempty"`
+	Metadata           map[string]string          `json:"metadata,omitempty"`
+	CreatedAt          time.Time                  `json:"created_at"`
+	UpdatedAt          time.Time                  `json:"updated_at"`
+}
+
+type SplitResponse struct {
+	Payee int64          `json:"payee"`
+	Value entities.Money `json:"value"`
+}
+
+type FeeLineResponse struct {
+	Code   entities.FeeLineCode `json:"code"`
+	Amount entities.Money       `json:"amount"`
+}
+
+type FeeBreakdownResponse struct {
+	Schedule string            `json:"schedule"`
+	Lines    []FeeLineResponse `json:"lines"`
+}
+
+func NewFeeBreakdownResponse(fees entities.FeeBreakdown) *FeeBreakdownResponse {
+	if len(fees.Lines) == 0 {
+		return nil
+	}
+	response := &FeeBreakdownResponse{Schedule: fees.Schedule}
+	for _, line := range fees.Lines {
+		response.Lines = append(response.Lines, FeeLineResponse{Code: line.Code, Amount: line.Amount})
+	}
+	return response
+}
+
+type QuoteResponse struct {
+	Payer        int64                 `json:"payer"`
+	Payee        int64                 `json:"payee"`
+	Value        entities.Money        `json:"value"`
+	Currency     string                `json:"currency"`
+	Fee          entities.Money        `json:"fee"`
+	Total        entities.Money        `json:"total"`
+	FeeBreakdown *FeeBreakdownResponse `json:"fee_breakdown,omitempty"`
+	Conversion   *ConversionResponse   `json:"conversion,omitempty"`
+}
+
+type ConversionResponse struct {
+	Rate          entities.ExchangeRate `json:"rate"`
+	ReceivedValue entities.Money        `json:"received_value"`
+	Currency      string                `json:"currency"`
+	QuoteID       int64                 `json:"quote_id"`
+	ExpiresAt     time.Time             `json:"expires_at"`
+}
+
+func NewQuoteResponse(quote *usecase.TransferQuote) QuoteResponse {
+	response := QuoteResponse{
+		Payer:        quote.PayerID,
+		Payee:        quote.PayeeID,
+		Value:        quote.Amount,
+		Currency:     quote.Amount.Currency,
+		Fee:          quote.Fee,
+		Total:        quote.Total,
+		FeeBreakdown: NewFeeBreakdownResponse(quote.Fees),
+	}
+	if conversion := quote.Conversion; conversion != nil && conversion.Quote != nil {
+		response.Conversion = &ConversionResponse{
+			Rate:          conversion.Rate,
+			ReceivedValue: conversion.Amount,
+			Currency:      conversion.Amount.Currency,
+			QuoteID:       conversion.Quote.ID,
+			ExpiresAt:     conversion.Quote.ExpiresAt,
+		}
+	}
+	return response
+}
+
+type AuthorizationResponse struct {
+	Outcome    entities.AuthorizationOutcome `json:"outcome"`
+	ReasonCode string                        `json:"reason_code,omitempty"`
+	Reference  string                        `json:"reference,omitempty"`
+}
+
+func NewAuthorizationResponse(decision entities.AuthorizationDecision) *AuthorizationResponse {
+	if decision.Outcome == "" {
+		return nil
+	}
+	return &AuthorizationResponse{
+		Outcome:    decision.Outcome,
+		ReasonCode: decision.ReasonCode,
+		Reference:  decision.Reference,
+	}
+}
+
+type AuthorizationDeniedResponse struct {
+	Error         string                 `json:"error"`
+	TransferID    int64                  `json:"transfer_id"`
+	Authorization *AuthorizationResponse `json:"authorization"`
+}
+
+func NewTransferResponse(transaction *entities.Transaction) TransferResponse {
+	response := TransferResponse{
+		ID:                 transaction.ID,
+		Type:               transaction.Type,
+		Payer:              transaction.SenderID,
+		Payee:              transaction.ReceiverID,
+		Value:              transaction.Amount,
+		Currency:           transaction.Amount.Currency,
+		ExchangeRate:       transaction.ExchangeRate,
+		ReceivedValue:      transaction.ReceivedAmount,
+		ReceivedCurrency:   transaction.ReceivedCurrency,
+		AuthorizedValue:    transaction.AuthorizedAmount,
+		HoldExpiresAt:      transaction.HoldExpiresAt,
+		RefundedValue:      transaction.RefundedAmount,
+		Fee:                transaction.Fee,
+		FeeBreakdown:       NewFeeBreakdownResponse(transaction.FeeBreakdown),
+		Status:             transaction.Status,
+		OriginalTransferID: transaction.OriginalTransactionID,
+		Authorization:      NewAuthorizationResponse(transaction.Authorization),
+		Description:        transaction.Details.Description,
+		ExternalReference:  transaction.Details.ExternalReference,
+		Metadata:           transaction.Details.Metadata,
+		CreatedAt:          transaction.CreatedAt,
+		UpdatedAt:          transaction.UpdatedAt,
+	}
+	for _, leg := range transaction.Legs {
+		response.Splits = append(response.Splits, SplitResponse{Payee: leg.ReceiverID, Value: leg.Amount})
+	}
+	return response
+}
+
+type StatusChangeResponse struct {
+	From      entities.TransactionStatus `json:"from,omitempty"`
+	To        entities.TransactionStatus `json:"to"`
+	Reason    string                     `json:"reason"`
+	CreatedAt time.Time                  `json:"created_at"`
+}
+
+type TransferPageResponse struct {
+	Data       []TransferResponse `json:"data"`
+	NextCursor string             `json:"next_cursor,omitempty"`
 }
 
 type TransactionHandler struct {
 	TransactionUseCase *usecase.Transaction
+	IdempotencyUseCase *usecase.Idempotency
 }
 
-func NewTransactionHandler(TransactionUseCase *usecase.Transaction) *TransactionHandler {
+func NewTransactionHandler(TransactionUseCase *usecase.Transaction, IdempotencyUseCase *usecase.Idempotency) *TransactionHandler {
 	return &TransactionHandler{
 		TransactionUseCase: TransactionUseCase,
+		IdempotencyUseCase: IdempotencyUseCase,
 	}
 }
 
@@ -28,8 +241,18 @@ func (h *TransactionHandler) Transaction(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTransactionRequestSize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	serveIdempotent(w, r, h.IdempotencyUseCase, body, h.transfer)
+}
+
+func (h *TransactionHandler) transfer(w http.ResponseWriter, r *http.Request, body []byte) {
 	var req TransactionRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := json.Unmarshal(body, &req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
@@ -38,19 +261,532 @@ func (h *TransactionHandler) Transaction(w http.ResponseWriter, r *http.Request)
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	err = h.TransactionUseCase.Execute(r.Context(), req.Payer, req.Payee, req.Value)
+	splits, err := splitInputs(req.Splits)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	transaction, err := h.TransactionUseCase.Execute(r.Context(), usecase.TransferInput{
+		PayerID: req.Payer,
+		PayeeID: req.Payee,
+		Amount:  req.Value,
+		QuoteID: req.QuoteID,
+		Hold:    req.Capture != nil && !*req.Capture,
+		Splits:  splits,
+		Client:  clientMetadata(r),
+		Details: transferDetails(req),
+	})
+	var denied *usecase.AuthorizationDeniedError
+	var exceeded *usecase.LimitExceededError
+	switch {
+	case errors.As(err, &exceeded):
+		h.writeJSON(w, http.StatusUnprocessableEntity, NewLimitExceededResponse(exceeded))
+		return
+	case isSplitError(err), errors.Is(err, entities.ErrInvalidTransferDetails):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrSenderNotFound), errors.Is(err, usecase.ErrReceiverNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrDuplicateExternalReference),
+		errors.Is(err, entities.ErrInvalidTransition), errors.Is(err, port.ErrTransactionStatusConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, usecase.ErrMerchantCannotTransfer), errors.Is(err, usecase.ErrInsufficientBalance),
+		errors.Is(err, usecase.ErrSplitCurrencyMismatch), isExchangeError(err):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case errors.As(err, &denied):
+		h.writeJSON(w, http.StatusForbidden, AuthorizationDeniedResponse{
+			Error:         usecase.ErrTransferNotAuthorized.Error(),
+			TransferID:    denied.Transaction.ID,
+			Authorization: NewAuthorizationResponse(denied.Transaction.Authorization),
+		})
+		return
+	case errors.Is(err, port.ErrServiceUnavailable):
+		http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusCreated
+	if transaction.Status == entities.TransactionStatusPending {
+		// Held for review: accepted, but no money has moved yet.
+		status = http.StatusAccepted
+	}
+	w.Header().Set("Location", transferLocation(transaction.ID))
+	h.writeJSON(w, status, NewTransferResponse(transaction))
+}
+
+// Quote prices a transfer, fees included, without moving any money.
+func (h *TransactionHandler) Quote(w http.ResponseWriter, r *http.Request) {
+	var req TransactionRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxTransactionRequestSize)).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validateTransactionRequest(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(req.Splits) > 0 {
+		http.Error(w, ErrSplitQuote.Error(), http.StatusBadRequest)
+		return
+	}
+
+	quote, err := h.TransactionUseCase.Quote(r.Context(), req.Payer, req.Payee, req.Value)
+	switch {
+	case errors.Is(err, usecase.ErrConversionUnavailable):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewQuoteResponse(quote))
+}
+
+func isExchangeError(err error) bool {
+	return errors.Is(err, usecase.ErrConversionUnavailable) ||
+		errors.Is(err, usecase.ErrExchangeQuoteNotFound) ||
+		errors.Is(err, usecase.ErrExchangeQuoteExpired) ||
+		errors.Is(err, usecase.ErrExchangeQuoteMismatch) ||
+		errors.Is(err, usecase.ErrExchangeQuoteUsed)
+}
+
+func isSplitError(err error) bool {
+	return errors.Is(err, usecase.ErrSplitTooFewPayees) ||
+		errors.Is(err, usecase.ErrSplitDuplicatePayee) ||
+		errors.Is(err, usecase.ErrSplitMixedShares) ||
+		errors.Is(err, usecase.ErrInvalidSplitShare) ||
+		errors.Is(err, usecase.ErrSplitSumMismatch) ||
+		errors.Is(err, usecase.ErrSplitNotSupported) ||
+		errors.Is(err, entities.ErrInvalidSplit)
+}
+
+// splitInputs reads the payees of a split transfer, each given either a
+// value or a percentage such as "33.33".
+func splitInputs(splits []SplitRequest) ([]usecase.SplitInput, error) {
+	inputs := make([]usecase.SplitInput, 0, len(splits))
+	for _, split := range splits {
+		input := usecase.SplitInput{PayeeID: split.Payee, Amount: split.Value}
+		if split.Percentage != "" {
+			share, err := entities.ParsePercentage(split.Percentage)
+			if err != nil {
+				return nil, ErrInvalidSplitPercentage
+			}
+			input.Percentage = &share
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// transferDetails reads what the payer says about a transfer. An empty
+// external reference is the same as none.
+func transferDetails(req TransactionRequest) entities.TransferDetails {
+	details := entities.TransferDetails{Description: req.Description, Metadata: req.Metadata}
+	if req.ExternalReference != "" {
+		reference := req.ExternalReference
+		details.ExternalReference = &reference
+	}
+	return details
+}
+
+// clientMetadata describes the caller to the authorizer.
+func clientMetadata(r *http.Request) port.ClientMetadata {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return port.ClientMetadata{
+		IPAddress: ip,
+		UserAgent: r.UserAgent(),
+		DeviceID:  r.Header.Get(deviceIDHeader),
+	}
+}
+
+func (h *TransactionHandler) GetTransfer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requesterID, err := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
 	if err != nil {
+		http.Error(w, ErrMissingUserID.Error(), http.StatusUnauthorized)
+		return
+	}
+	transactionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidTransferID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.TransactionUseCase.GetTransfer(r.Context(), transactionID, requesterID)
+	switch {
+	case errors.Is(err, usecase.ErrTransferNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrTransferAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, map[string]string{"message": "Transaction successful"})
+	h.writeJSON(w, http.StatusOK, NewTransferResponse(transaction))
+}
+
+func (h *TransactionHandler) GetTransferHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requesterID, err := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
+	if err != nil {
+		http.Error(w, ErrMissingUserID.Error(), http.StatusUnauthorized)
+		return
+	}
+	transactionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidTransferID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.TransactionUseCase.GetStatusHistory(r.Context(), transactionID, requesterID)
+	switch {
+	case errors.Is(err, usecase.ErrTransferNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrTransferAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]StatusChangeResponse, 0, len(history))
+	for _, change := range history {
+		response = append(response, StatusChangeResponse{
+			From:      change.FromStatus,
+			To:        change.ToStatus,
+			Reason:    change.Reason,
+			CreatedAt: change.CreatedAt,
+		})
+	}
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+func (h *TransactionHandler) Refund(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requesterID, err := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
+	if err != nil {
+		http.Error(w, ErrMissingUserID.Error(), http.StatusUnauthorized)
+		return
+	}
+	transactionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidTransferID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req RefundRequest
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTransactionRequestSize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	refund, err := h.TransactionUseCase.Refund(r.Context(), transactionID, requesterID, req.Value)
+	switch {
+	case errors.Is(err, usecase.ErrTransferNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrTransferAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, usecase.ErrInvalidRefundAmount):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrTransferNotRefundable):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, usecase.ErrRefundExceedsAmount), errors.Is(err, usecase.ErrInsufficientBalance):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", transferLocation(refund.ID))
+	h.writeJSON(w, http.StatusCreated, NewTransferResponse(refund))
+}
+
+// Capture settles a held transfer, for the value sent or for everything
+// authorized.
+func (h *TransactionHandler) Capture(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
+	if err != nil {
+		http.Error(w, ErrMissingUserID.Error(), http.StatusUnauthorized)
+		return
+	}
+	transactionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidTransferID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req CaptureRequest
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTransactionRequestSize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	transaction, err := h.TransactionUseCase.Capture(r.Context(), transactionID, requesterID, req.Value)
+	var exceeded *usecase.LimitExceededError
+	switch {
+	case errors.As(err, &exceeded):
+		h.writeJSON(w, http.StatusUnprocessableEntity, NewLimitExceededResponse(exceeded))
+		return
+	case errors.Is(err, usecase.ErrInvalidCaptureAmount):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrCaptureExceedsAmount), errors.Is(err, usecase.ErrInsufficientBalance):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		h.writeHoldError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewTransferResponse(transaction))
+}
+
+// Void releases a held transfer without moving any money.
+func (h *TransactionHandler) Void(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
+	if err != nil {
+		http.Error(w, ErrMissingUserID.Error(), http.StatusUnauthorized)
+		return
+	}
+	transactionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidTransferID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.TransactionUseCase.Void(r.Context(), transactionID, requesterID)
+	if err != nil {
+		h.writeHoldError(w, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewTransferResponse(transaction))
+}
+
+// Cancel stops a transfer that has not moved money yet, releasing any funds
+// it holds. Only the payer may cancel.
+func (h *TransactionHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
+	if err != nil {
+		http.Error(w, ErrMissingUserID.Error(), http.StatusUnauthorized)
+		return
+	}
+	transactionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidTransferID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req CancelRequest
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTransactionRequestSize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	if len(req.Reason) > maxCancelReasonLength {
+		http.Error(w, ErrCancelReasonTooLong.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := h.TransactionUseCase.Cancel(r.Context(), transactionID, requesterID, req.Reason)
+	switch {
+	case errors.Is(err, usecase.ErrTransferNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrTransferAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, usecase.ErrTransferNotCancellable):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewTransferResponse(transaction))
+}
+
+func (h *TransactionHandler) writeHoldError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrTransferNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, usecase.ErrTransferAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, usecase.ErrTransferNotHeld), errors.Is(err, usecase.ErrHoldExpired):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func (h *TransactionHandler) ListUserTransfers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	requesterID, err := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
+	if err != nil {
+		http.Error(w, ErrMissingUserID.Error(), http.StatusUnauthorized)
+		return
+	}
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidUserID.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, err := parseTransferFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.UserID = userID
+
+	page, err := h.TransactionUseCase.ListTransfers(r.Context(), requesterID, filter, r.URL.Query().Get("cursor"))
+	switch {
+	case errors.Is(err, usecase.ErrTransferAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, usecase.ErrInvalidTransferFilter), errors.Is(err, usecase.ErrInvalidCursor):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	response := TransferPageResponse{Data: make([]TransferResponse, 0, len(page.Transfers)), NextCursor: page.NextCursor}
+	for i := range page.Transfers {
+		response.Data = append(response.Data, NewTransferResponse(&page.Transfers[i]))
+	}
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+func parseTransferFilter(query url.Values) (port.TransactionFilter, error) {
+	filter := port.TransactionFilter{
+		Direction: port.TransferDirection(query.Get("direction")),
+		Status:    entities.TransactionStatus(query.Get("status")),
+		Order:     port.SortOrder(query.Get("sort")),
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return filter, NewError("limit must be a number")
+		}
+		filter.Limit = limit
+	}
+	var err error
+	if filter.MinAmount, err = parseMoneyParam(query, "min_amount"); err != nil {
+		return filter, err
+	}
+	if filter.MaxAmount, err = parseMoneyParam(query, "max_amount"); err != nil {
+		return filter, err
+	}
+	if filter.From, err = parseTimeParam(query, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseTimeParam(query, "to"); err != nil {
+		return filter, err
+	}
+	if value := query.Get("external_reference"); value != "" {
+		filter.ExternalReference = &value
+	}
+	return filter, nil
+}
+
+func parseMoneyParam(query url.Values, name string) (*entities.Money, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := entities.ParseMoney(value, entities.DefaultCurrency)
+	if err != nil {
+		return nil, NewError(name + " must be a decimal amount")
+	}
+	return &amount, nil
+}
+
+func parseTimeParam(query url.Values, name string) (*time.Time, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, NewError(name + " must be an RFC 3339 timestamp")
+	}
+	return &parsed, nil
 }
 
 func (h *TransactionHandler) validateTransactionRequest(req TransactionRequest) error {
-	if req.Value <= 0 {
+	if !req.Value.IsPositive() {
 		return ErrInvalidTransactionValue
 	}
+	if len(req.Splits) > 0 {
+		if req.Payee != 0 {
+			return ErrSplitWithPayee
+		}
+		for _, split := range req.Splits {
+			if split.Payee == req.Payer {
+				return ErrSamePayerPayee
+			}
+		}
+		return nil
+	}
 	if req.Payer == req.Payee {
 		return ErrSamePayerPayee
 	}
@@ -65,9 +801,26 @@ func (h *TransactionHandler) writeJSON(w http.ResponseWriter, status int, data i
 	}
 }
 
+func transferLocation(id int64) string {
+	return "/transfers/" + strconv.FormatInt(id, 10)
+}
+
+func hashRequest(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
 var (
 	ErrInvalidTransactionValue = NewError("Transaction value must be greater than zero")
 	ErrSamePayerPayee          = NewError("Payer and payee cannot be the same")
+	ErrInvalidIdempotencyKey   = NewError("Idempotency-Key must have at most 255 characters")
+	ErrMissingUserID           = NewError("X-User-ID header must identify the requesting user")
+	ErrInvalidTransferID       = NewError("Transfer id must be a number")
+	ErrInvalidUserID           = NewError("User id must be a number")
+	ErrCancelReasonTooLong     = NewError("Cancel reason must have at most 255 characters")
+	ErrSplitWithPayee          = NewError("Payee must be left out of transfers with splits")
+	ErrSplitQuote              = NewError("Transfers with splits cannot be quoted")
+	ErrInvalidSplitPercentage  = NewError("Split percentage must be a number above 0 and up to 100 with at most two decimals")
 )
 
 type Error struct {
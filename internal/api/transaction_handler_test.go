@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+	"go-transfer/internal/domain/usecase"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The fakes embed their port so only what a transfer reaches before it fails
+// needs implementing.
+
+type userRepoFake struct {
+	port.UserRepository
+	users map[int64]*entities.User
+}
+
+func (r *userRepoFake) GetByID(ctx context.Context, id int64) (*entities.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+type walletRepoFake struct {
+	port.WalletRepository
+	wallets map[int64]*entities.Wallet
+}
+
+func (r *walletRepoFake) GetByOwnerID(ctx context.Context, ownerID int64) (*entities.Wallet, error) {
+	return r.wallets[ownerID], nil
+}
+
+type transactionRepoFake struct {
+	port.TransactionRepository
+	statusErr error
+}
+
+func (r *transactionRepoFake) Create(ctx context.Context, transaction *entities.Transaction) (int64, error) {
+	return 99, nil
+}
+
+func (r *transactionRepoFake) UpdateStatus(ctx context.Context, id int64, from, to entities.TransactionStatus, reason string) error {
+	return r.statusErr
+}
+
+func TestTransactionHandler_Transfer_MapsDomainErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		payer     *entities.Wallet
+		statusErr error
+		want      int
+	}{
+		{name: "payer not found", body: `{"payer":3,"payee":2,"value":"10.00"}`, want: http.StatusNotFound},
+		{name: "payee not found", body: `{"payer":1,"payee":3,"value":"10.00"}`, want: http.StatusNotFound},
+		{
+			name:  "merchant payer",
+			body:  `{"payer":1,"payee":2,"value":"10.00"}`,
+			payer: &entities.Wallet{ID: 10, OwnerID: 1, Type: entities.MerchantWallet, Balance: entities.MoneyFromCents(10000)},
+			want:  http.StatusUnprocessableEntity,
+		},
+		{name: "insufficient balance", body: `{"payer":1,"payee":2,"value":"200.00"}`, want: http.StatusUnprocessableEntity},
+		{
+			name:      "status changed concurrently",
+			body:      `{"payer":1,"payee":2,"value":"10.00"}`,
+			statusErr: port.ErrTransactionStatusConflict,
+			want:      http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payer := tt.payer
+			if payer == nil {
+				payer = &entities.Wallet{ID: 10, OwnerID: 1, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(10000)}
+			}
+			users := &userRepoFake{users: map[int64]*entities.User{1: {ID: 1}, 2: {ID: 2}}}
+			wallets := &walletRepoFake{wallets: map[int64]*entities.Wallet{1: payer, 2: {ID: 20, OwnerID: 2, Type: entities.CommonWallet}}}
+			transactions := &transactionRepoFake{statusErr: tt.statusErr}
+			h := NewTransactionHandler(usecase.NewTransaction(users, wallets, transactions, nil, nil, nil, nil, nil, 0), nil)
+
+			w := httptest.NewRecorder()
+			h.transfer(w, httptest.NewRequest(http.MethodPost, "/transfers", strings.NewReader(tt.body)), []byte(tt.body))
+			assert.Equal(t, tt.want, w.Code, w.Body.String())
+		})
+	}
+}
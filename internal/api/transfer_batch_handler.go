@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/usecase"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type TransferBatchItemRequest struct {
+	Payee int64          `json:"payee"`
+	Value entities.Money `json:"value"`
+}
+
+type TransferBatchRequest struct {
+	Payer int64                      `json:"payer"`
+	Mode  entities.TransferBatchMode `json:"mode"`
+	Items []TransferBatchItemRequest `json:"items"`
+}
+
+type TransferBatchItemResponse struct {
+	Position      int                              `json:"position"`
+	Payee         int64                            `json:"payee"`
+	Value         entities.Money                   `json:"value"`
+	Status        entities.TransferBatchItemStatus `json:"status"`
+	TransferID    *int64                           `json:"transfer_id,omitempty"`
+	FailureReason string                           `json:"failure_reason,omitempty"`
+}
+
+type TransferBatchResponse struct {
+	ID            int64                        `json:"id"`
+	Payer         int64                        `json:"payer"`
+	Mode          entities.TransferBatchMode   `json:"mode"`
+	Status        entities.TransferBatchStatus `json:"status"`
+	Value         entities.Money               `json:"value"`
+	SettledValue  entities.Money               `json:"settled_value"`
+	Currency      string                       `json:"currency"`
+	FailureReason string                       `json:"failure_reason,omitempty"`
+	Items         []TransferBatchItemResponse  `json:"items"`
+	CreatedAt     time.Time                    `json:"created_at"`
+	UpdatedAt     time.Time                    `json:"updated_at"`
+}
+
+func NewTransferBatchResponse(batch *entities.TransferBatch) TransferBatchResponse {
+	response := TransferBatchResponse{
+		ID:            batch.ID,
+		Payer:         batch.PayerID,
+		Mode:          batch.Mode,
+		Status:        batch.Status,
+		Value:         batch.Total,
+		SettledValue:  batch.Settled(),
+		Currency:      batch.Currency,
+		FailureReason: batch.FailureReason,
+		Items:         make([]TransferBatchItemResponse, 0, len(batch.Items)),
+		CreatedAt:     batch.CreatedAt,
+		UpdatedAt:     batch.UpdatedAt,
+	}
+	for _, item := range batch.Items {
+		response.Items = append(response.Items, TransferBatchItemResponse{
+			Position:      item.Position,
+			Payee:         item.PayeeID,
+			Value:         item.Amount,
+			Status:        item.Status,
+			TransferID:    item.TransactionID,
+			FailureReason: item.FailureReason,
+		})
+	}
+	return response
+}
+
+type TransferBatchHandler struct {
+	BatchUseCase       *usecase.TransferBatches
+	IdempotencyUseCase *usecase.Idempotency
+}
+
+func NewTransferBatchHandler(BatchUseCase *usecase.TransferBatches, IdempotencyUseCase *usecase.Idempotency) *TransferBatchHandler {
+	return &TransferBatchHandler{
+		BatchUseCase:       BatchUseCase,
+		IdempotencyUseCase: IdempotencyUseCase,
+	}
+}
+
+// Submit queues a batch of transfers from one payer. They are made in the
+// background; the batch is followed through its Location.
+func (h *TransferBatchHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxTransactionRequestSize))
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	serveIdempotent(w, r, h.IdempotencyUseCase, body, h.submit)
+}
+
+func (h *TransferBatchHandler) submit(w http.ResponseWriter, r *http.Request, body []byte) {
+	var req TransferBatchRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := h.validateTransferBatchRequest(req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	input := usecase.BatchInput{PayerID: req.Payer, Mode: req.Mode}
+	for _, item := range req.Items {
+		input.Items = append(input.Items, usecase.BatchItemInput{PayeeID: item.Payee, Amount: item.Value})
+	}
+	batch, err := h.BatchUseCase.Submit(r.Context(), input)
+	var exceeded *usecase.LimitExceededError
+	switch {
+	case errors.As(err, &exceeded):
+		h.writeJSON(w, http.StatusUnprocessableEntity, NewLimitExceededResponse(exceeded))
+		return
+	case errors.Is(err, usecase.ErrInvalidTransferBatchMode), errors.Is(err, usecase.ErrEmptyTransferBatch),
+		errors.Is(err, usecase.ErrTransferBatchTooLarge), errors.Is(err, usecase.ErrInvalidBatchItemAmount):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, usecase.ErrSenderNotFound), errors.Is(err, usecase.ErrReceiverNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrMerchantCannotTransfer), errors.Is(err, usecase.ErrInsufficientBalance), isExchangeError(err):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Location", transferBatchLocation(batch.ID))
+	h.writeJSON(w, http.StatusAccepted, NewTransferBatchResponse(batch))
+}
+
+// GetTransferBatch returns a batch and the outcome of each item to its payer.
+func (h *TransferBatchHandler) GetTransferBatch(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
+	if err != nil {
+		http.Error(w, ErrMissingUserID.Error(), http.StatusUnauthorized)
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidTransferBatchID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	batch, err := h.BatchUseCase.Get(r.Context(), id, requesterID)
+	switch {
+	case errors.Is(err, usecase.ErrTransferBatchNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrTransferBatchAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewTransferBatchResponse(batch))
+}
+
+func (h *TransferBatchHandler) validateTransferBatchRequest(req TransferBatchRequest) error {
+	for _, item := range req.Items {
+		if !item.Value.IsPositive() {
+			return ErrInvalidTransactionValue
+		}
+		if item.Payee == req.Payer {
+			return ErrSamePayerPayee
+		}
+	}
+	return nil
+}
+
+func (h *TransferBatchHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
+
+func transferBatchLocation(id int64) string {
+	return "/transfer-batches/" + strconv.FormatInt(id, 10)
+}
+
+var ErrInvalidTransferBatchID = NewError("Transfer batch id must be a number")
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/usecase"
+	"net/http"
+	"strconv"
+)
+
+// TransferReviewHandler lets admins decide transfers the authorizer held for
+// review.
+type TransferReviewHandler struct {
+	TransactionUseCase *usecase.Transaction
+	adminToken         string
+}
+
+func NewTransferReviewHandler(TransactionUseCase *usecase.Transaction, adminToken string) *TransferReviewHandler {
+	return &TransferReviewHandler{
+		TransactionUseCase: TransactionUseCase,
+		adminToken:         adminToken,
+	}
+}
+
+func (h *TransferReviewHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.TransactionUseCase.Approve)
+}
+
+func (h *TransferReviewHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.TransactionUseCase.Reject)
+}
+
+func (h *TransferReviewHandler) decide(w http.ResponseWriter, r *http.Request, decide func(context.Context, int64) (*entities.Transaction, error)) {
+	if !authorizeAdmin(w, r, h.adminToken) {
+		return
+	}
+
+	transactionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, ErrInvalidTransferID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	transaction, err := decide(r.Context(), transactionID)
+	var exceeded *usecase.LimitExceededError
+	switch {
+	case errors.As(err, &exceeded):
+		h.writeJSON(w, http.StatusUnprocessableEntity, NewLimitExceededResponse(exceeded))
+		return
+	case errors.Is(err, usecase.ErrTransferNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, usecase.ErrTransferNotUnderReview):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, usecase.ErrInsufficientBalance), isExchangeError(err):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, NewTransferResponse(transaction))
+}
+
+func (h *TransferReviewHandler) writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	}
+}
@@ -32,6 +32,12 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	currency, err := h.walletUseCase.ParseCurrency(input.Currency)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	user, err := h.userUseCase.CreateUser(r.Context(), input)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -39,9 +45,10 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	walletInput := usecase.WalletInput{
-		OwnerID: user.ID,
-		Type:    input.Type,
-		Balance: input.Balance,
+		OwnerID:  user.ID,
+		Type:     input.Type,
+		Balance:  input.Balance,
+		Currency: currency,
 	}
 	err = h.walletUseCase.CreateWallet(r.Context(), walletInput)
 	w.Header().Set("Content-Type", "application/json")
@@ -3,15 +3,18 @@ package config
 import (
 	"fmt"
 	"go-transfer/internal/config/handlers"
+	"go-transfer/internal/config/setup_jobs"
 	"go-transfer/internal/config/setup_repositories"
 	"go-transfer/internal/config/setup_routes"
 	"go-transfer/internal/config/setup_usecases"
 	"go-transfer/internal/env"
 	"go-transfer/internal/infra/database"
+	"go-transfer/internal/jobs"
 	"log"
+	"net/http"
 )
 
-func Setup() {
+func Setup() (http.Handler, *jobs.Runner) {
 	fmt.Println("Init Setup ...")
 
 	AppConfig := env.LoadEnv()
@@ -21,16 +24,13 @@ func Setup() {
 		log.Fatalf("Erro ao conectar no banco de dados: %v", err)
 	}
 
-	userRepository, walletRepository, transactionRepository, notificationRepository := setup_repositories.SetupRepositories(db)
+	repositories := setup_repositories.SetupRepositories(db)
 
-	userUseCase, walletUseCase, transactionUseCase := setup_usecases.SetupUseCases(
-		userRepository,
-		walletRepository,
-		transactionRepository,
-		notificationRepository,
-	)
+	useCases := setup_usecases.SetupUseCases(repositories)
 
-	userHandler, transactionHandler := handlers.SetupHandlers(userUseCase, walletUseCase, transactionUseCase)
+	apiHandlers := handlers.SetupHandlers(useCases)
 
-	setup_routes.SetupRoutes(userHandler, transactionHandler)
+	routes := setup_routes.SetupRoutes(apiHandlers)
+
+	return routes, setup_jobs.SetupJobs(useCases.Idempotency, useCases.Outbox, useCases.Notification, useCases.Transaction, useCases.Scheduled, useCases.TransferBatch, useCases.AuthorizationRules)
 }
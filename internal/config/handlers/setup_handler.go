@@ -3,16 +3,30 @@ package handlers
 import (
 	"fmt"
 	"go-transfer/internal/api"
-	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/config/setup_usecases"
 )
 
-func SetupHandlers(
-	userUseCase *usecase.User,
-	walletUseCase *usecase.Wallet,
-	transactionUseCase *usecase.Transaction,
-) (*api.UserHandler, *api.TransactionHandler) {
+type Handlers struct {
+	User          *api.UserHandler
+	Transaction   *api.TransactionHandler
+	Notification  *api.NotificationHandler
+	Limit         *api.LimitHandler
+	Scheduled     *api.ScheduledTransferHandler
+	TransferBatch *api.TransferBatchHandler
+	Review        *api.TransferReviewHandler
+	Metrics       *api.MetricsHandler
+}
+
+func SetupHandlers(useCases *setup_usecases.UseCases) *Handlers {
 	fmt.Println("Configuring handlers...")
-	userHandler := SetupUserHandlers(userUseCase, walletUseCase)
-	transactionHandler := SetupTransactionHandlers(transactionUseCase)
-	return userHandler, transactionHandler
+	return &Handlers{
+		User:          SetupUserHandlers(useCases.User, useCases.Wallet),
+		Transaction:   SetupTransactionHandlers(useCases.Transaction, useCases.Idempotency),
+		Notification:  SetupNotificationHandlers(useCases.Notification),
+		Limit:         SetupLimitHandlers(useCases.Limits),
+		Scheduled:     SetupScheduledTransferHandlers(useCases.Scheduled),
+		TransferBatch: SetupTransferBatchHandlers(useCases.TransferBatch, useCases.Idempotency),
+		Review:        SetupTransferReviewHandlers(useCases.Transaction),
+		Metrics:       SetupMetricsHandlers(),
+	}
 }
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"fmt"
+	"go-transfer/internal/api"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+)
+
+func SetupLimitHandlers(
+	limitsUseCase *usecase.Limits,
+) *api.LimitHandler {
+	fmt.Println("Configuring Limit handler...")
+	AppConfig := env.LoadEnv()
+
+	return api.NewLimitHandler(limitsUseCase, AppConfig.AdminToken)
+}
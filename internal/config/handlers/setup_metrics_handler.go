@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"fmt"
+	"go-transfer/internal/api"
+	"go-transfer/internal/env"
+)
+
+func SetupMetricsHandlers() *api.MetricsHandler {
+	fmt.Println("Configuring Metrics handler...")
+	AppConfig := env.LoadEnv()
+
+	return api.NewMetricsHandler(AppConfig.AdminToken)
+}
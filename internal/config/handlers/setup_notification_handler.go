@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"fmt"
+	"go-transfer/internal/api"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+)
+
+func SetupNotificationHandlers(
+	notificationUseCase *usecase.NotificationUseCase,
+) *api.NotificationHandler {
+	fmt.Println("Configuring Notification handler...")
+	AppConfig := env.LoadEnv()
+
+	return api.NewNotificationHandler(notificationUseCase, AppConfig.AdminToken)
+}
@@ -0,0 +1,14 @@
+package handlers
+
+import (
+	"fmt"
+	"go-transfer/internal/api"
+	"go-transfer/internal/domain/usecase"
+)
+
+func SetupScheduledTransferHandlers(
+	scheduledUseCase *usecase.ScheduledTransfers,
+) *api.ScheduledTransferHandler {
+	fmt.Println("Configuring Scheduled Transfer handler...")
+	return api.NewScheduledTransferHandler(scheduledUseCase)
+}
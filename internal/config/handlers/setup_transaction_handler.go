@@ -8,7 +8,8 @@ import (
 
 func SetupTransactionHandlers(
 	transactionUseCase *usecase.Transaction,
+	idempotencyUseCase *usecase.Idempotency,
 ) *api.TransactionHandler {
 	fmt.Println("Configuring Transaction handler...")
-	return api.NewTransactionHandler(transactionUseCase)
+	return api.NewTransactionHandler(transactionUseCase, idempotencyUseCase)
 }
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"fmt"
+	"go-transfer/internal/api"
+	"go-transfer/internal/domain/usecase"
+)
+
+func SetupTransferBatchHandlers(
+	batchUseCase *usecase.TransferBatches,
+	idempotencyUseCase *usecase.Idempotency,
+) *api.TransferBatchHandler {
+	fmt.Println("Configuring Transfer Batch handler...")
+	return api.NewTransferBatchHandler(batchUseCase, idempotencyUseCase)
+}
@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"fmt"
+	"go-transfer/internal/api"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+)
+
+func SetupTransferReviewHandlers(
+	transactionUseCase *usecase.Transaction,
+) *api.TransferReviewHandler {
+	fmt.Println("Configuring Transfer Review handler...")
+	AppConfig := env.LoadEnv()
+
+	return api.NewTransferReviewHandler(transactionUseCase, AppConfig.AdminToken)
+}
@@ -0,0 +1,27 @@
+package setup_jobs
+
+import (
+	"context"
+	"fmt"
+	"go-transfer/internal/env"
+	"go-transfer/internal/infra/authorizers"
+	"go-transfer/internal/jobs"
+	"log"
+)
+
+func NewAuthorizationRulesReloadJob(authorizationRules *authorizers.RulesAuthorizer) jobs.Job {
+	fmt.Println("Configuring authorization rules reload job...")
+	AppConfig := env.LoadEnv()
+
+	return jobs.Job{
+		Name:     "authorization-rules-reload",
+		Interval: AppConfig.AuthorizationRulesReload,
+		Run: func(ctx context.Context) error {
+			changed, err := authorizationRules.Reload(ctx)
+			if changed {
+				log.Printf("authorization rules reloaded from %s", AppConfig.AuthorizationRulesFile)
+			}
+			return err
+		},
+	}
+}
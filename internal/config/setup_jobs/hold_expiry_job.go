@@ -0,0 +1,23 @@
+package setup_jobs
+
+import (
+	"context"
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/jobs"
+)
+
+func NewHoldExpiryJob(transactionUseCase *usecase.Transaction) jobs.Job {
+	fmt.Println("Configuring hold expiry job...")
+	AppConfig := env.LoadEnv()
+
+	return jobs.Job{
+		Name:     "hold-expiry",
+		Interval: AppConfig.HoldExpiryInterval,
+		Run: func(ctx context.Context) error {
+			_, err := transactionUseCase.ExpireHolds(ctx, AppConfig.HoldExpiryBatchSize)
+			return err
+		},
+	}
+}
@@ -0,0 +1,23 @@
+package setup_jobs
+
+import (
+	"context"
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/jobs"
+)
+
+func NewIdempotencyCleanupJob(idempotencyUseCase *usecase.Idempotency) jobs.Job {
+	fmt.Println("Configuring idempotency cleanup job...")
+	AppConfig := env.LoadEnv()
+
+	return jobs.Job{
+		Name:     "idempotency-cleanup",
+		Interval: AppConfig.IdempotencyCleanupInterval,
+		Run: func(ctx context.Context) error {
+			_, err := idempotencyUseCase.PurgeExpired(ctx)
+			return err
+		},
+	}
+}
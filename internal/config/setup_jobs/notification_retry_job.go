@@ -0,0 +1,23 @@
+package setup_jobs
+
+import (
+	"context"
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/jobs"
+)
+
+func NewNotificationRetryJob(notificationUseCase *usecase.NotificationUseCase) jobs.Job {
+	fmt.Println("Configuring notification retry job...")
+	AppConfig := env.LoadEnv()
+
+	return jobs.Job{
+		Name:     "notification-retry",
+		Interval: AppConfig.NotificationRetryInterval,
+		Run: func(ctx context.Context) error {
+			_, err := notificationUseCase.RetryDue(ctx, AppConfig.NotificationRetryLease, AppConfig.NotificationRetryBatchSize)
+			return err
+		},
+	}
+}
@@ -0,0 +1,23 @@
+package setup_jobs
+
+import (
+	"context"
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/jobs"
+)
+
+func NewOutboxDispatchJob(outboxUseCase *usecase.Outbox) jobs.Job {
+	fmt.Println("Configuring outbox dispatch job...")
+	AppConfig := env.LoadEnv()
+
+	return jobs.Job{
+		Name:     "outbox-dispatch",
+		Interval: AppConfig.OutboxDispatchInterval,
+		Run: func(ctx context.Context) error {
+			_, err := outboxUseCase.Dispatch(ctx)
+			return err
+		},
+	}
+}
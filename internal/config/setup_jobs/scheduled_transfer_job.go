@@ -0,0 +1,23 @@
+package setup_jobs
+
+import (
+	"context"
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/jobs"
+)
+
+func NewScheduledTransferJob(scheduledUseCase *usecase.ScheduledTransfers) jobs.Job {
+	fmt.Println("Configuring scheduled transfer job...")
+	AppConfig := env.LoadEnv()
+
+	return jobs.Job{
+		Name:     "scheduled-transfers",
+		Interval: AppConfig.ScheduledTransferInterval,
+		Run: func(ctx context.Context) error {
+			_, err := scheduledUseCase.RunDue(ctx, AppConfig.ScheduledTransferBatchSize)
+			return err
+		},
+	}
+}
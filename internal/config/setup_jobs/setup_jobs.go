@@ -0,0 +1,31 @@
+package setup_jobs
+
+import (
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/infra/authorizers"
+	"go-transfer/internal/jobs"
+)
+
+func SetupJobs(
+	idempotencyUseCase *usecase.Idempotency,
+	outboxUseCase *usecase.Outbox,
+	notificationUseCase *usecase.NotificationUseCase,
+	transactionUseCase *usecase.Transaction,
+	scheduledUseCase *usecase.ScheduledTransfers,
+	batchUseCase *usecase.TransferBatches,
+	authorizationRules *authorizers.RulesAuthorizer,
+) *jobs.Runner {
+	fmt.Println("Configuring jobs...")
+	runner := jobs.NewRunner()
+	runner.Add(NewIdempotencyCleanupJob(idempotencyUseCase))
+	runner.Add(NewOutboxDispatchJob(outboxUseCase))
+	runner.Add(NewNotificationRetryJob(notificationUseCase))
+	runner.Add(NewHoldExpiryJob(transactionUseCase))
+	runner.Add(NewScheduledTransferJob(scheduledUseCase))
+	runner.Add(NewTransferBatchJob(batchUseCase))
+	if authorizationRules != nil {
+		runner.Add(NewAuthorizationRulesReloadJob(authorizationRules))
+	}
+	return runner
+}
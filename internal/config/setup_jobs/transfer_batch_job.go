@@ -0,0 +1,23 @@
+package setup_jobs
+
+import (
+	"context"
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/jobs"
+)
+
+func NewTransferBatchJob(batchUseCase *usecase.TransferBatches) jobs.Job {
+	fmt.Println("Configuring transfer batch job...")
+	AppConfig := env.LoadEnv()
+
+	return jobs.Job{
+		Name:     "transfer-batches",
+		Interval: AppConfig.TransferBatchInterval,
+		Run: func(ctx context.Context) error {
+			_, err := batchUseCase.RunQueued(ctx, AppConfig.TransferBatchClaimSize)
+			return err
+		},
+	}
+}
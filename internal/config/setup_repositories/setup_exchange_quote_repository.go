@@ -0,0 +1,12 @@
+package setup_repositories
+
+import (
+	"fmt"
+	"go-transfer/internal/infra/repositories"
+	"gorm.io/gorm"
+)
+
+func NewExchangeQuoteRepository(db *gorm.DB) *repositories.ExchangeQuoteRepository {
+	fmt.Println("Configuring exchange quote repository...")
+	return repositories.NewExchangeQuoteRepository(db)
+}
@@ -0,0 +1,12 @@
+package setup_repositories
+
+import (
+	"fmt"
+	"go-transfer/internal/infra/repositories"
+	"gorm.io/gorm"
+)
+
+func NewIdempotencyRepository(db *gorm.DB) *repositories.IdempotencyRepository {
+	fmt.Println("Configuring idempotency repository...")
+	return repositories.NewIdempotencyRepository(db)
+}
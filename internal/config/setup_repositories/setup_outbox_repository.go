@@ -0,0 +1,12 @@
+package setup_repositories
+
+import (
+	"fmt"
+	"go-transfer/internal/infra/repositories"
+	"gorm.io/gorm"
+)
+
+func NewOutboxRepository(db *gorm.DB) *repositories.OutboxRepository {
+	fmt.Println("Configuring outbox repository...")
+	return repositories.NewOutboxRepository(db)
+}
@@ -6,16 +6,35 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupRepositories(db *gorm.DB) (
-	*repositories.UserRepository,
-	*repositories.WalletRepository,
-	*repositories.TransactionRepository,
-	*repositories.NotificationRepository,
-) {
+type Repositories struct {
+	User            *repositories.UserRepository
+	Wallet          *repositories.WalletRepository
+	Transaction     *repositories.TransactionRepository
+	Notification    *repositories.NotificationRepository
+	Idempotency     *repositories.IdempotencyRepository
+	Outbox          *repositories.OutboxRepository
+	TransferLimit   *repositories.TransferLimitRepository
+	TransferCounter *repositories.TransferCounterRepository
+	ExchangeQuote   *repositories.ExchangeQuoteRepository
+	Scheduled       *repositories.ScheduledTransferRepository
+	TransferBatch   *repositories.TransferBatchRepository
+	UnitOfWork      *repositories.UnitOfWork
+}
+
+func SetupRepositories(db *gorm.DB) *Repositories {
 	fmt.Println("Configuring repositories...")
-	userRepository := NewUserRepository(db)
-	walletRepository := NewWalletRepository(db)
-	transactionRepository := NewTransactionRepository(db)
-	notificationRepository := NewNotificationRepository(db)
-	return userRepository, walletRepository, transactionRepository, notificationRepository
+	return &Repositories{
+		User:            NewUserRepository(db),
+		Wallet:          NewWalletRepository(db),
+		Transaction:     NewTransactionRepository(db),
+		Notification:    NewNotificationRepository(db),
+		Idempotency:     NewIdempotencyRepository(db),
+		Outbox:          NewOutboxRepository(db),
+		TransferLimit:   NewTransferLimitRepository(db),
+		TransferCounter: NewTransferCounterRepository(db),
+		ExchangeQuote:   NewExchangeQuoteRepository(db),
+		Scheduled:       NewScheduledTransferRepository(db),
+		TransferBatch:   NewTransferBatchRepository(db),
+		UnitOfWork:      NewUnitOfWork(db),
+	}
 }
@@ -0,0 +1,12 @@
+package setup_repositories
+
+import (
+	"fmt"
+	"go-transfer/internal/infra/repositories"
+	"gorm.io/gorm"
+)
+
+func NewScheduledTransferRepository(db *gorm.DB) *repositories.ScheduledTransferRepository {
+	fmt.Println("Configuring scheduled transfer repository...")
+	return repositories.NewScheduledTransferRepository(db)
+}
@@ -0,0 +1,12 @@
+package setup_repositories
+
+import (
+	"fmt"
+	"go-transfer/internal/infra/repositories"
+	"gorm.io/gorm"
+)
+
+func NewTransferBatchRepository(db *gorm.DB) *repositories.TransferBatchRepository {
+	fmt.Println("Configuring transfer batch repository...")
+	return repositories.NewTransferBatchRepository(db)
+}
@@ -0,0 +1,12 @@
+package setup_repositories
+
+import (
+	"fmt"
+	"go-transfer/internal/infra/repositories"
+	"gorm.io/gorm"
+)
+
+func NewTransferCounterRepository(db *gorm.DB) *repositories.TransferCounterRepository {
+	fmt.Println("Configuring transfer counter repository...")
+	return repositories.NewTransferCounterRepository(db)
+}
@@ -0,0 +1,12 @@
+package setup_repositories
+
+import (
+	"fmt"
+	"go-transfer/internal/infra/repositories"
+	"gorm.io/gorm"
+)
+
+func NewTransferLimitRepository(db *gorm.DB) *repositories.TransferLimitRepository {
+	fmt.Println("Configuring transfer limit repository...")
+	return repositories.NewTransferLimitRepository(db)
+}
@@ -0,0 +1,15 @@
+package setup_repositories
+
+import (
+	"fmt"
+	"go-transfer/internal/env"
+	"go-transfer/internal/infra/repositories"
+	"gorm.io/gorm"
+)
+
+func NewUnitOfWork(db *gorm.DB) *repositories.UnitOfWork {
+	fmt.Println("Configuring unit of work...")
+	AppConfig := env.LoadEnv()
+
+	return repositories.NewUnitOfWork(db, NewWalletLocker(AppConfig.WalletLocker))
+}
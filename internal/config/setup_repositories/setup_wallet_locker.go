@@ -0,0 +1,24 @@
+package setup_repositories
+
+import (
+	"fmt"
+	"go-transfer/internal/domain/port"
+	"go-transfer/internal/env"
+	"go-transfer/internal/infra/locks"
+	"go-transfer/internal/infra/repositories"
+	"gorm.io/gorm"
+)
+
+func NewWalletLocker(kind string) repositories.WalletLockerFactory {
+	fmt.Printf("Configuring %s wallet locker...\n", kind)
+	if kind == env.WalletLockerPostgres {
+		return func(tx *gorm.DB) port.WalletLocker {
+			return locks.NewAdvisoryWalletLocker(tx)
+		}
+	}
+
+	memoryLocker := locks.NewMemoryWalletLocker()
+	return func(*gorm.DB) port.WalletLocker {
+		return memoryLocker
+	}
+}
@@ -0,0 +1,19 @@
+package setup_routes
+
+import (
+	"fmt"
+	"go-transfer/internal/api"
+	"net/http"
+)
+
+func SetupAdminRoutes(mux *http.ServeMux, notificationHandler *api.NotificationHandler, limitHandler *api.LimitHandler, reviewHandler *api.TransferReviewHandler, metricsHandler *api.MetricsHandler) {
+	fmt.Println("Configuring admin routes...")
+	mux.HandleFunc("GET /admin/notifications/dead", notificationHandler.ListDead)
+	mux.HandleFunc("POST /admin/notifications/{id}/redrive", notificationHandler.Redrive)
+	mux.HandleFunc("GET /admin/users/{id}/limits", limitHandler.GetUserLimits)
+	mux.HandleFunc("PUT /admin/users/{id}/limits", limitHandler.SetUserLimits)
+	mux.HandleFunc("DELETE /admin/users/{id}/limits", limitHandler.ResetUserLimits)
+	mux.HandleFunc("POST /admin/transfers/{id}/approve", reviewHandler.Approve)
+	mux.HandleFunc("POST /admin/transfers/{id}/reject", reviewHandler.Reject)
+	mux.HandleFunc("GET /admin/metrics", metricsHandler.Metrics)
+}
@@ -0,0 +1,18 @@
+package setup_routes
+
+import (
+	"fmt"
+	"go-transfer/internal/api"
+	"net/http"
+)
+
+func SetupScheduledTransferRoutes(mux *http.ServeMux, scheduledHandler *api.ScheduledTransferHandler) {
+	fmt.Println("Configuring scheduled transfer routes...")
+	mux.HandleFunc("POST /scheduled-transfers", scheduledHandler.Schedule)
+	mux.HandleFunc("GET /scheduled-transfers", scheduledHandler.ListScheduledTransfers)
+	mux.HandleFunc("GET /scheduled-transfers/{id}", scheduledHandler.GetScheduledTransfer)
+	mux.HandleFunc("GET /scheduled-transfers/{id}/runs", scheduledHandler.ListRuns)
+	mux.HandleFunc("POST /scheduled-transfers/{id}/cancel", scheduledHandler.Cancel)
+	mux.HandleFunc("POST /scheduled-transfers/{id}/pause", scheduledHandler.Pause)
+	mux.HandleFunc("POST /scheduled-transfers/{id}/resume", scheduledHandler.Resume)
+}
@@ -2,11 +2,20 @@ package setup_routes
 
 import (
 	"fmt"
-	"go-transfer/internal/api"
+	"go-transfer/internal/config/handlers"
+	"net/http"
 )
 
-func SetupRoutes(userHandler *api.UserHandler, transactionHandler *api.TransactionHandler) {
+// SetupRoutes registers the API on its own mux rather than on
+// http.DefaultServeMux, where imported packages such as expvar add
+// unauthenticated debug endpoints.
+func SetupRoutes(h *handlers.Handlers) *http.ServeMux {
 	fmt.Println("Configuring routes...")
-	SetupUserRoutes(userHandler)
-	SetupTransferRoutes(transactionHandler)
+	mux := http.NewServeMux()
+	SetupUserRoutes(mux, h.User)
+	SetupTransferRoutes(mux, h.Transaction)
+	SetupScheduledTransferRoutes(mux, h.Scheduled)
+	SetupTransferBatchRoutes(mux, h.TransferBatch)
+	SetupAdminRoutes(mux, h.Notification, h.Limit, h.Review, h.Metrics)
+	return mux
 }
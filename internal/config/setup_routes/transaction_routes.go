@@ -6,7 +6,15 @@ import (
 	"net/http"
 )
 
-func SetupTransferRoutes(transactionHandler *api.TransactionHandler) {
+func SetupTransferRoutes(mux *http.ServeMux, transactionHandler *api.TransactionHandler) {
 	fmt.Println("Configuring routes...")
-	http.HandleFunc("/transfers", transactionHandler.Transaction)
+	mux.HandleFunc("/transfers", transactionHandler.Transaction)
+	mux.HandleFunc("POST /transfers/quote", transactionHandler.Quote)
+	mux.HandleFunc("GET /transfers/{id}", transactionHandler.GetTransfer)
+	mux.HandleFunc("GET /transfers/{id}/history", transactionHandler.GetTransferHistory)
+	mux.HandleFunc("POST /transfers/{id}/refund", transactionHandler.Refund)
+	mux.HandleFunc("POST /transfers/{id}/capture", transactionHandler.Capture)
+	mux.HandleFunc("POST /transfers/{id}/void", transactionHandler.Void)
+	mux.HandleFunc("POST /transfers/{id}/cancel", transactionHandler.Cancel)
+	mux.HandleFunc("GET /users/{id}/transfers", transactionHandler.ListUserTransfers)
 }
@@ -0,0 +1,13 @@
+package setup_routes
+
+import (
+	"fmt"
+	"go-transfer/internal/api"
+	"net/http"
+)
+
+func SetupTransferBatchRoutes(mux *http.ServeMux, batchHandler *api.TransferBatchHandler) {
+	fmt.Println("Configuring transfer batch routes...")
+	mux.HandleFunc("POST /transfers/batch", batchHandler.Submit)
+	mux.HandleFunc("GET /transfer-batches/{id}", batchHandler.GetTransferBatch)
+}
@@ -6,7 +6,7 @@ import (
 	"net/http"
 )
 
-func SetupUserRoutes(userHandler *api.UserHandler) {
+func SetupUserRoutes(mux *http.ServeMux, userHandler *api.UserHandler) {
 	fmt.Println("Configuring user routes...")
-	http.HandleFunc("/users", userHandler.CreateUser)
+	mux.HandleFunc("/users", userHandler.CreateUser)
 }
@@ -2,20 +2,42 @@ package setup_usecases
 
 import (
 	"fmt"
+	"go-transfer/internal/config/setup_repositories"
 	"go-transfer/internal/domain/usecase"
-	"go-transfer/internal/infra/repositories"
+	"go-transfer/internal/infra/authorizers"
 )
 
-func SetupUseCases(
-	userRepo *repositories.UserRepository,
-	walletRepo *repositories.WalletRepository,
-	transactionRepo *repositories.TransactionRepository,
-	notificationRepo *repositories.NotificationRepository,
-) (*usecase.User, *usecase.Wallet, *usecase.Transaction) {
+type UseCases struct {
+	User          *usecase.User
+	Wallet        *usecase.Wallet
+	Transaction   *usecase.Transaction
+	Idempotency   *usecase.Idempotency
+	Outbox        *usecase.Outbox
+	Notification  *usecase.NotificationUseCase
+	Limits        *usecase.Limits
+	Scheduled     *usecase.ScheduledTransfers
+	TransferBatch *usecase.TransferBatches
+	// AuthorizationRules is nil unless AUTHORIZER uses local rules.
+	AuthorizationRules *authorizers.RulesAuthorizer
+}
+
+func SetupUseCases(repos *setup_repositories.Repositories) *UseCases {
 	fmt.Println("Configuring usecases...")
-	userUseCase := SetupUserUseCase(userRepo)
-	walletUseCase := SetupWalletUseCase(walletRepo)
-	notificationUseCase := SetupNotificationUseCase(notificationRepo)
-	transactionRepository := SetupTransactionUseCase(userRepo, walletRepo, transactionRepo, notificationUseCase)
-	return userUseCase, walletUseCase, transactionRepository
+	notificationUseCase := SetupNotificationUseCase(repos.Notification)
+	exchangeRates := SetupExchangeRates()
+	authorizationService, authorizationRules := SetupAuthorizationService(repos.Transaction, exchangeRates)
+	limits := SetupLimitsUseCase(repos.TransferLimit, repos.TransferCounter, repos.Wallet, exchangeRates)
+	transactionUseCase := SetupTransactionUseCase(repos.User, repos.Wallet, repos.Transaction, repos.UnitOfWork, authorizationService, limits, repos.ExchangeQuote, exchangeRates)
+	return &UseCases{
+		User:               SetupUserUseCase(repos.User),
+		Wallet:             SetupWalletUseCase(repos.Wallet, repos.UnitOfWork),
+		Transaction:        transactionUseCase,
+		Idempotency:        SetupIdempotencyUseCase(repos.Idempotency),
+		Outbox:             SetupOutboxUseCase(repos.Outbox, notificationUseCase),
+		Notification:       notificationUseCase,
+		Limits:             limits,
+		Scheduled:          SetupScheduledTransferUseCase(repos.Scheduled, transactionUseCase, repos.UnitOfWork),
+		TransferBatch:      SetupTransferBatchUseCase(repos.TransferBatch, transactionUseCase, repos.UnitOfWork),
+		AuthorizationRules: authorizationRules,
+	}
 }
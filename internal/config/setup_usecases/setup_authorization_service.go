@@ -0,0 +1,46 @@
+package setup_usecases
+
+import (
+	"fmt"
+	"go-transfer/internal/domain/port"
+	"go-transfer/internal/env"
+	"go-transfer/internal/infra/authorizers"
+	"go-transfer/internal/infra/externals"
+	"go-transfer/internal/infra/repositories"
+	"log"
+)
+
+// SetupAuthorizationService returns the authorizer selected by AUTHORIZER and,
+// when it uses local rules, the rules authorizer so they can be reloaded.
+func SetupAuthorizationService(
+	transactionRepo *repositories.TransactionRepository,
+	exchangeRates port.ExchangeRateProvider,
+) (port.AuthorizationService, *authorizers.RulesAuthorizer) {
+	fmt.Println("Configuring Authorization service...")
+	AppConfig := env.LoadEnv()
+
+	remote := externals.NewAuthorizationService(
+		AppConfig.AuthorizationURL,
+		externals.NewHTTPClient(AppConfig.AuthorizationTimeout),
+		externals.NewCircuitBreaker("authorization", externals.BreakerSettings{
+			FailureThreshold: AppConfig.AuthorizationBreaker.FailureThreshold,
+			OpenTimeout:      AppConfig.AuthorizationBreaker.OpenTimeout,
+		}),
+	)
+	if AppConfig.Authorizer == env.AuthorizerRemote {
+		return remote, nil
+	}
+
+	rules, err := authorizers.NewRulesAuthorizer(AppConfig.AuthorizationRulesFile, transactionRepo, exchangeRates)
+	if err != nil {
+		log.Fatalf("Erro ao carregar regras de autorização: %v", err)
+	}
+	if AppConfig.Authorizer == env.AuthorizerRules {
+		return rules, rules
+	}
+	composite, err := authorizers.NewCompositeAuthorizer(rules, remote)
+	if err != nil {
+		log.Fatalf("Erro ao configurar autorizador composto: %v", err)
+	}
+	return composite, rules
+}
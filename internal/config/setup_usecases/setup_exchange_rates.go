@@ -0,0 +1,25 @@
+package setup_usecases
+
+import (
+	"fmt"
+	"go-transfer/internal/domain/port"
+	"go-transfer/internal/env"
+	"go-transfer/internal/infra/rates"
+	"log"
+)
+
+// SetupExchangeRates returns the rates of EXCHANGE_RATES_FILE, or nil when
+// no file is configured.
+func SetupExchangeRates() port.ExchangeRateProvider {
+	fmt.Println("Configuring exchange rates...")
+	AppConfig := env.LoadEnv()
+
+	if AppConfig.ExchangeRatesFile == "" {
+		return nil
+	}
+	provider, err := rates.LoadStaticProvider(AppConfig.ExchangeRatesFile)
+	if err != nil {
+		log.Fatalf("Erro ao carregar taxas de câmbio: %v", err)
+	}
+	return provider
+}
@@ -0,0 +1,17 @@
+package setup_usecases
+
+import (
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/infra/repositories"
+)
+
+func SetupIdempotencyUseCase(
+	idempotencyRepo *repositories.IdempotencyRepository,
+) *usecase.Idempotency {
+	fmt.Println("Configuring Idempotency usecases...")
+	AppConfig := env.LoadEnv()
+
+	return usecase.NewIdempotency(idempotencyRepo, AppConfig.IdempotencyTTL, AppConfig.IdempotencyLease)
+}
@@ -0,0 +1,55 @@
+package setup_usecases
+
+import (
+	"fmt"
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/infra/repositories"
+	"log"
+	"time"
+)
+
+func SetupLimitsUseCase(
+	limitRepo *repositories.TransferLimitRepository,
+	counterRepo *repositories.TransferCounterRepository,
+	walletRepo *repositories.WalletRepository,
+	exchangeRates port.ExchangeRateProvider,
+) *usecase.Limits {
+	fmt.Println("Configuring Limits usecases...")
+	AppConfig := env.LoadEnv()
+
+	location, err := time.LoadLocation(AppConfig.LimitsTimezone)
+	if err != nil {
+		log.Fatalf("LIMITS_TIMEZONE inválido: %v", err)
+	}
+	defaults := map[entities.WalletType]entities.TransferLimits{
+		entities.CommonWallet:   parseLimits("COMMON", AppConfig.CommonLimits),
+		entities.MerchantWallet: parseLimits("MERCHANT", AppConfig.MerchantLimits),
+	}
+	return usecase.NewLimits(limitRepo, counterRepo, walletRepo, defaults, location, exchangeRates)
+}
+
+func parseLimits(walletType string, config env.LimitConfig) entities.TransferLimits {
+	limits := entities.TransferLimits{
+		SingleMax:     parseLimitAmount("LIMIT_"+walletType+"_SINGLE_MAX", config.SingleMax),
+		DailyAmount:   parseLimitAmount("LIMIT_"+walletType+"_DAILY_AMOUNT", config.DailyAmount),
+		MonthlyAmount: parseLimitAmount("LIMIT_"+walletType+"_MONTHLY_AMOUNT", config.MonthlyAmount),
+	}
+	if config.HourlyCount > 0 {
+		limits.HourlyCount = &config.HourlyCount
+	}
+	return limits
+}
+
+func parseLimitAmount(key, value string) *entities.Money {
+	if value == "" {
+		return nil
+	}
+	amount, err := entities.ParseMoney(value, entities.DefaultCurrency)
+	if err != nil || !amount.IsPositive() {
+		log.Fatalf("Valor inválido para %s: %q.", key, value)
+	}
+	return &amount
+}
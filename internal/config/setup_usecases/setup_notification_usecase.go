@@ -14,8 +14,20 @@ func SetupNotificationUseCase(
 	fmt.Println("Configuring Notification usecases...")
 	AppConfig := env.LoadEnv()
 
-	notificationService := externals.NewNotificationService(AppConfig.NotificationURL)
-	notificationUseCase := usecase.NewNotification(notificationRepo, notificationService)
+	notificationService := externals.NewNotificationService(
+		AppConfig.NotificationURL,
+		externals.NewHTTPClient(AppConfig.NotificationTimeout),
+		externals.NewCircuitBreaker("notification", externals.BreakerSettings{
+			FailureThreshold: AppConfig.NotificationBreaker.FailureThreshold,
+			OpenTimeout:      AppConfig.NotificationBreaker.OpenTimeout,
+		}),
+	)
+	retryPolicy := usecase.RetryPolicy{
+		MaxAttempts: AppConfig.NotificationMaxAttempts,
+		BaseDelay:   AppConfig.NotificationRetryBaseDelay,
+		MaxDelay:    AppConfig.NotificationRetryMaxDelay,
+	}
+	notificationUseCase := usecase.NewNotification(notificationRepo, notificationService, retryPolicy)
 
 	return notificationUseCase
 }
@@ -0,0 +1,18 @@
+package setup_usecases
+
+import (
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/infra/repositories"
+)
+
+func SetupOutboxUseCase(
+	outboxRepo *repositories.OutboxRepository,
+	notificationUseCase *usecase.NotificationUseCase,
+) *usecase.Outbox {
+	fmt.Println("Configuring Outbox usecases...")
+	AppConfig := env.LoadEnv()
+
+	return usecase.NewOutbox(outboxRepo, notificationUseCase, AppConfig.OutboxBatchSize, AppConfig.OutboxMaxAttempts, AppConfig.OutboxLease, AppConfig.OutboxRetryDelay)
+}
@@ -0,0 +1,33 @@
+package setup_usecases
+
+import (
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/infra/repositories"
+	"log"
+	"time"
+)
+
+func SetupScheduledTransferUseCase(
+	scheduledRepo *repositories.ScheduledTransferRepository,
+	transactionUseCase *usecase.Transaction,
+	unitOfWork *repositories.UnitOfWork,
+) *usecase.ScheduledTransfers {
+	fmt.Println("Configuring Scheduled Transfer usecases...")
+	AppConfig := env.LoadEnv()
+
+	location, err := time.LoadLocation(AppConfig.ScheduledTransferTimezone)
+	if err != nil {
+		log.Fatalf("SCHEDULED_TRANSFER_TIMEZONE inválido: %v", err)
+	}
+	return usecase.NewScheduledTransfers(
+		scheduledRepo,
+		transactionUseCase,
+		unitOfWork,
+		AppConfig.ScheduledTransferLease,
+		location,
+		AppConfig.ScheduledTransferRetry,
+		AppConfig.ScheduledTransferRetries,
+	)
+}
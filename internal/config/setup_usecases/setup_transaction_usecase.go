@@ -2,21 +2,40 @@ package setup_usecases
 
 import (
 	"fmt"
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
 	"go-transfer/internal/domain/usecase"
 	"go-transfer/internal/env"
-	"go-transfer/internal/infra/externals"
+	"go-transfer/internal/infra/fees"
 	"go-transfer/internal/infra/repositories"
+	"log"
 )
 
 func SetupTransactionUseCase(
 	userRepo *repositories.UserRepository,
 	walletRepo *repositories.WalletRepository,
 	transactionRepo *repositories.TransactionRepository,
-	notificationUseCase *usecase.NotificationUseCase,
+	unitOfWork *repositories.UnitOfWork,
+	authorizationService port.AuthorizationService,
+	limits *usecase.Limits,
+	exchangeQuoteRepo *repositories.ExchangeQuoteRepository,
+	exchangeRates port.ExchangeRateProvider,
 ) *usecase.Transaction {
 	fmt.Println("Configuring Transaction usecases...")
 	AppConfig := env.LoadEnv()
 
-	authorizationService := externals.NewAuthorizationService(AppConfig.AuthorizationURL)
-	return usecase.NewTransaction(userRepo, walletRepo, transactionRepo, notificationUseCase, authorizationService)
+	var feeTable *entities.FeeTable
+	if AppConfig.FeeSchedulesFile != "" {
+		var err error
+		feeTable, err = fees.LoadFeeTable(AppConfig.FeeSchedulesFile)
+		if err != nil {
+			log.Fatalf("Erro ao carregar tabela de tarifas: %v", err)
+		}
+	}
+
+	var exchange *usecase.Exchange
+	if exchangeRates != nil {
+		exchange = usecase.NewExchange(exchangeRates, exchangeQuoteRepo, AppConfig.ExchangeQuoteTTL)
+	}
+	return usecase.NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authorizationService, limits, feeTable, exchange, AppConfig.HoldTTL)
 }
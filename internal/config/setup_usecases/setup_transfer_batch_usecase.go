@@ -0,0 +1,25 @@
+package setup_usecases
+
+import (
+	"fmt"
+	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
+	"go-transfer/internal/infra/repositories"
+)
+
+func SetupTransferBatchUseCase(
+	batchRepo *repositories.TransferBatchRepository,
+	transactionUseCase *usecase.Transaction,
+	unitOfWork *repositories.UnitOfWork,
+) *usecase.TransferBatches {
+	fmt.Println("Configuring Transfer Batch usecases...")
+	AppConfig := env.LoadEnv()
+
+	return usecase.NewTransferBatches(
+		batchRepo,
+		transactionUseCase,
+		unitOfWork,
+		AppConfig.TransferBatchMaxItems,
+		AppConfig.TransferBatchLease,
+	)
+}
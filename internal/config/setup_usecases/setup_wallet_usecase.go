@@ -3,14 +3,17 @@ package setup_usecases
 import (
 	"fmt"
 	"go-transfer/internal/domain/usecase"
+	"go-transfer/internal/env"
 	"go-transfer/internal/infra/repositories"
 )
 
 func SetupWalletUseCase(
 	walletRepo *repositories.WalletRepository,
+	unitOfWork *repositories.UnitOfWork,
 ) *usecase.Wallet {
 	fmt.Println("Configuring Wallet usecases...")
-	walletUseCase := usecase.NewWallet(walletRepo)
+	AppConfig := env.LoadEnv()
+	walletUseCase := usecase.NewWallet(walletRepo, unitOfWork, AppConfig.Currencies)
 
 	return walletUseCase
 }
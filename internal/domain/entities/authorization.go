@@ -0,0 +1,17 @@
+package entities
+
+type AuthorizationOutcome string
+
+const (
+	AuthorizationApproved AuthorizationOutcome = "APPROVED"
+	AuthorizationDenied   AuthorizationOutcome = "DENIED"
+	AuthorizationReview   AuthorizationOutcome = "REVIEW"
+)
+
+// AuthorizationDecision is the authorizer's verdict on a transfer. Reference
+// identifies the decision on the authorizer's side.
+type AuthorizationDecision struct {
+	Outcome    AuthorizationOutcome `gorm:"type:text;not null;default:''"`
+	ReasonCode string               `gorm:"type:text;not null;default:''"`
+	Reference  string               `gorm:"type:text;not null;default:''"`
+}
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ExchangeQuote locks the rate of a transfer between wallets of different
+// currencies until ExpiresAt. It is spent by the first transfer that uses it.
+type ExchangeQuote struct {
+	ID                int64        `gorm:"primaryKey"`
+	PayerID           int64        `gorm:"not null;index"`
+	PayeeID           int64        `gorm:"not null"`
+	Amount            Money        `gorm:"not null"`
+	Currency          string       `gorm:"type:char(3);not null"`
+	Rate              ExchangeRate `gorm:"not null"`
+	ConvertedAmount   Money        `gorm:"not null"`
+	ConvertedCurrency string       `gorm:"type:char(3);not null"`
+	ExpiresAt         time.Time    `gorm:"not null"`
+	UsedAt            *time.Time
+	CreatedAt         time.Time `gorm:"autoCreateTime"`
+}
+
+func (q *ExchangeQuote) AfterFind(*gorm.DB) error {
+	q.Amount = NewMoney(q.Amount.Cents, q.Currency)
+	q.ConvertedAmount = NewMoney(q.ConvertedAmount.Cents, q.ConvertedCurrency)
+	return nil
+}
+
+func (q *ExchangeQuote) IsExpired(now time.Time) bool {
+	return !now.Before(q.ExpiresAt)
+}
@@ -0,0 +1,108 @@
+package entities
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	exchangeRateDecimals = 8
+	exchangeRateScale    = 100_000_000
+)
+
+var ErrInvalidExchangeRate = errors.New("invalid exchange rate")
+
+// ExchangeRate is how much one unit of a currency is worth in another, with
+// eight decimal places: 543210000 is 5.4321.
+type ExchangeRate int64
+
+// ParseExchangeRate reads a positive rate such as "5.4321".
+func ParseExchangeRate(value string) (ExchangeRate, error) {
+	units, fraction, hasPoint := strings.Cut(strings.TrimSpace(value), ".")
+	if units == "" || hasPoint && fraction == "" || len(fraction) > exchangeRateDecimals {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidExchangeRate, value)
+	}
+	fraction += strings.Repeat("0", exchangeRateDecimals-len(fraction))
+	if !isDigits(units) || !isDigits(fraction) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidExchangeRate, value)
+	}
+
+	whole, err := strconv.ParseInt(units, 10, 64)
+	if err != nil || whole > math.MaxInt64/exchangeRateScale-1 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidExchangeRate, value)
+	}
+	decimals, _ := strconv.ParseInt(fraction, 10, 64)
+	rate := ExchangeRate(whole*exchangeRateScale + decimals)
+	if rate <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidExchangeRate, value)
+	}
+	return rate, nil
+}
+
+func (r ExchangeRate) String() string {
+	fraction := strings.TrimRight(fmt.Sprintf("%08d", int64(r)%exchangeRateScale), "0")
+	if fraction == "" {
+		return strconv.FormatInt(int64(r)/exchangeRateScale, 10)
+	}
+	return fmt.Sprintf("%d.%s", int64(r)/exchangeRateScale, fraction)
+}
+
+// Convert returns amount in currency at rate r, rounding half a cent up.
+func (r ExchangeRate) Convert(amount Money, currency string) (Money, error) {
+	converted := new(big.Int).Mul(big.NewInt(amount.Cents), big.NewInt(int64(r)))
+	converted.Add(converted, big.NewInt(exchangeRateScale/2))
+	converted.Div(converted, big.NewInt(exchangeRateScale))
+	if !converted.IsInt64() {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(converted.Int64(), currency), nil
+}
+
+// Inverse is the rate of the opposite conversion, rounded to eight places.
+func (r ExchangeRate) Inverse() ExchangeRate {
+	if r <= 0 {
+		return 0
+	}
+	inverse := new(big.Int).Mul(big.NewInt(exchangeRateScale), big.NewInt(exchangeRateScale))
+	inverse.Add(inverse, big.NewInt(int64(r)/2))
+	inverse.Div(inverse, big.NewInt(int64(r)))
+	if !inverse.IsInt64() {
+		return 0
+	}
+	return ExchangeRate(inverse.Int64())
+}
+
+func (r ExchangeRate) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(r.String())), nil
+}
+
+func (r ExchangeRate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+func (r *ExchangeRate) Scan(src any) error {
+	var text string
+	switch value := src.(type) {
+	case []byte:
+		text = string(value)
+	case string:
+		text = value
+	default:
+		return fmt.Errorf("cannot scan %T into ExchangeRate", src)
+	}
+	parsed, err := ParseExchangeRate(text)
+	if err != nil {
+		return err
+	}
+	*r = parsed
+	return nil
+}
+
+func (ExchangeRate) GormDataType() string {
+	return "numeric(20,8)"
+}
@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExchangeRate(t *testing.T) {
+	rate, err := ParseExchangeRate("5.4321")
+	assert.NoError(t, err)
+	assert.Equal(t, ExchangeRate(543210000), rate)
+	assert.Equal(t, "5.4321", rate.String())
+
+	rate, err = ParseExchangeRate("2")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", rate.String())
+
+	for _, invalid := range []string{"", "0", "-1", "1.", "abc", "0.123456789"} {
+		_, err := ParseExchangeRate(invalid)
+		assert.ErrorIs(t, err, ErrInvalidExchangeRate, invalid)
+	}
+}
+
+func TestExchangeRate_Convert(t *testing.T) {
+	rate, _ := ParseExchangeRate("5.4321")
+
+	converted, err := rate.Convert(NewMoney(10000, "USD"), "BRL")
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(54321, "BRL"), converted)
+
+	// 0.01 USD is 0.054321 BRL, rounded to 0.05.
+	converted, err = rate.Convert(NewMoney(1, "USD"), "BRL")
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(5, "BRL"), converted)
+
+	half, _ := ParseExchangeRate("0.5")
+	converted, err = half.Convert(NewMoney(1, "BRL"), "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, NewMoney(1, "USD"), converted)
+}
+
+func TestExchangeRate_Inverse(t *testing.T) {
+	rate, _ := ParseExchangeRate("4")
+	assert.Equal(t, "0.25", rate.Inverse().String())
+
+	rate, _ = ParseExchangeRate("3")
+	assert.Equal(t, "0.33333333", rate.Inverse().String())
+}
+
+func TestExchangeRate_Scan(t *testing.T) {
+	var rate ExchangeRate
+	assert.NoError(t, rate.Scan([]byte("5.43210000")))
+	assert.Equal(t, ExchangeRate(543210000), rate)
+
+	value, err := rate.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "5.4321", value)
+}
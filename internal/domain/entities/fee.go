@@ -0,0 +1,267 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var ErrInvalidFeeSchedule = errors.New("invalid fee schedule")
+
+type FeeScheduleType string
+
+const (
+	// FeeFlat charges Flat whatever the amount.
+	FeeFlat FeeScheduleType = "FLAT"
+	// FeePercentage charges Rate of the amount.
+	FeePercentage FeeScheduleType = "PERCENTAGE"
+	// FeeTiered charges the Flat and Rate of the first tier the amount fits in.
+	FeeTiered FeeScheduleType = "TIERED"
+	// FeeCapped charges Flat plus Rate of the amount, kept within Min and Max.
+	FeeCapped FeeScheduleType = "CAPPED"
+)
+
+type FeeLineCode string
+
+const (
+	FeeLineFlat       FeeLineCode = "FLAT"
+	FeeLinePercentage FeeLineCode = "PERCENTAGE"
+	FeeLineMinimum    FeeLineCode = "MINIMUM"
+	FeeLineCap        FeeLineCode = "CAP"
+)
+
+// FeeRate is a percentage in hundredths of a percent: 250 is 2.5%.
+type FeeRate int64
+
+// ParseFeeRate reads a percentage such as "2.5" with at most two decimals.
+func ParseFeeRate(value string) (FeeRate, error) {
+	parsed, err := ParseMoney(value, DefaultCurrency)
+	if err != nil || parsed.IsNegative() || parsed.Cents > 100*centsPerUnit {
+		return 0, fmt.Errorf("%w: rate %q", ErrInvalidFeeSchedule, value)
+	}
+	return FeeRate(parsed.Cents), nil
+}
+
+func (r FeeRate) String() string {
+	return MoneyFromCents(int64(r)).String() + "%"
+}
+
+// Apply returns r of amount, rounding half a cent up.
+func (r FeeRate) Apply(amount Money) (Money, error) {
+	fee := new(big.Int).Mul(big.NewInt(amount.Cents), big.NewInt(int64(r)))
+	fee.Add(fee, big.NewInt(5000))
+	fee.Quo(fee, big.NewInt(10000))
+	if !fee.IsInt64() {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(fee.Int64(), amount.Currency), nil
+}
+
+// FeeTier applies to amounts up to UpTo, inclusive. A nil UpTo has no bound.
+type FeeTier struct {
+	UpTo *Money
+	Flat Money
+	Rate FeeRate
+}
+
+type FeeSchedule struct {
+	Name  string
+	Type  FeeScheduleType
+	Flat  Money
+	Rate  FeeRate
+	Tiers []FeeTier
+	Min   *Money
+	Max   *Money
+}
+
+func (s FeeSchedule) Validate() error {
+	if s.Flat.IsNegative() || s.Rate < 0 {
+		return fmt.Errorf("%w: %s: negative flat or rate", ErrInvalidFeeSchedule, s.Name)
+	}
+	switch s.Type {
+	case FeeFlat, FeePercentage:
+	case FeeTiered:
+		if len(s.Tiers) == 0 {
+			return fmt.Errorf("%w: %s: tiered schedule without tiers", ErrInvalidFeeSchedule, s.Name)
+		}
+		for i, tier := range s.Tiers {
+			if tier.Flat.IsNegative() || tier.Rate < 0 {
+				return fmt.Errorf("%w: %s: negative flat or rate", ErrInvalidFeeSchedule, s.Name)
+			}
+			if tier.UpTo == nil && i != len(s.Tiers)-1 {
+				return fmt.Errorf("%w: %s: only the last tier may be unbounded", ErrInvalidFeeSchedule, s.Name)
+			}
+			if i > 0 && tier.UpTo != nil {
+				if cmp, err := tier.UpTo.Cmp(*s.Tiers[i-1].UpTo); err != nil || cmp <= 0 {
+					return fmt.Errorf("%w: %s: tiers must be in increasing order", ErrInvalidFeeSchedule, s.Name)
+				}
+			}
+		}
+	case FeeCapped:
+		if s.Max == nil {
+			return fmt.Errorf("%w: %s: capped schedule without max", ErrInvalidFeeSchedule, s.Name)
+		}
+		if s.Min != nil {
+			if below, err := s.Max.LessThan(*s.Min); err != nil || below {
+				return fmt.Errorf("%w: %s: max below min", ErrInvalidFeeSchedule, s.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("%w: %s: unknown type %q", ErrInvalidFeeSchedule, s.Name, s.Type)
+	}
+	return nil
+}
+
+// Calculate returns the fee lines charged on a transfer of amount. The
+// amounts of the schedule apply in the currency of amount.
+func (s FeeSchedule) Calculate(amount Money) (FeeBreakdown, error) {
+	breakdown := FeeBreakdown{Schedule: s.Name}
+	in := func(scheduled Money) Money { return NewMoney(scheduled.Cents, amount.Currency) }
+	flat, rate := s.Flat, s.Rate
+	switch s.Type {
+	case FeeFlat:
+		rate = 0
+	case FeePercentage:
+		flat = Money{}
+	case FeeTiered:
+		tier := s.Tiers[len(s.Tiers)-1]
+		for _, candidate := range s.Tiers {
+			if candidate.UpTo == nil {
+				tier = candidate
+				break
+			}
+			cmp, err := amount.Cmp(in(*candidate.UpTo))
+			if err != nil {
+				return FeeBreakdown{}, err
+			}
+			if cmp <= 0 {
+				tier = candidate
+				break
+			}
+		}
+		flat, rate = tier.Flat, tier.Rate
+	}
+
+	percentage, err := rate.Apply(amount)
+	if err != nil {
+		return FeeBreakdown{}, err
+	}
+	breakdown.add(FeeLineFlat, in(flat))
+	breakdown.add(FeeLinePercentage, percentage)
+
+	if s.Type != FeeCapped {
+		return breakdown, nil
+	}
+	total, err := breakdown.Total(amount.Currency)
+	if err != nil {
+		return FeeBreakdown{}, err
+	}
+	if s.Min != nil {
+		below, err := total.LessThan(in(*s.Min))
+		if err != nil {
+			return FeeBreakdown{}, err
+		}
+		if below {
+			breakdown.Lines = nil
+			breakdown.add(FeeLineMinimum, in(*s.Min))
+			return breakdown, nil
+		}
+	}
+	above, err := in(*s.Max).LessThan(total)
+	if err != nil {
+		return FeeBreakdown{}, err
+	}
+	if above {
+		breakdown.Lines = nil
+		breakdown.add(FeeLineCap, in(*s.Max))
+	}
+	return breakdown, nil
+}
+
+type FeeLine struct {
+	Code   FeeLineCode `json:"code"`
+	Amount Money       `json:"amount"`
+}
+
+// FeeBreakdown lists the fees charged on a transfer and the schedule they
+// came from. A transfer without fees has no lines.
+type FeeBreakdown struct {
+	Schedule string    `json:"schedule,omitempty"`
+	Lines    []FeeLine `json:"lines,omitempty"`
+}
+
+func (b *FeeBreakdown) add(code FeeLineCode, amount Money) {
+	if amount.IsPositive() {
+		b.Lines = append(b.Lines, FeeLine{Code: code, Amount: amount})
+	}
+}
+
+// Total adds up the fee lines, which are all in currency.
+func (b FeeBreakdown) Total(currency string) (Money, error) {
+	total := NewMoney(0, currency)
+	for _, line := range b.Lines {
+		var err error
+		total, err = total.Add(line.Amount)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// FeeTable selects the fee schedule of a transfer by the wallet types of its
+// payer and payee. Pairs without a schedule, and a nil table, charge no fee.
+type FeeTable struct {
+	schedules map[[2]WalletType]FeeSchedule
+}
+
+func NewFeeTable() *FeeTable {
+	return &FeeTable{schedules: make(map[[2]WalletType]FeeSchedule)}
+}
+
+func (t *FeeTable) Set(payer, payee WalletType, schedule FeeSchedule) error {
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	t.schedules[[2]WalletType{payer, payee}] = schedule
+	return nil
+}
+
+func (t *FeeTable) Quote(payer, payee WalletType, amount Money) (FeeBreakdown, error) {
+	if t == nil {
+		return FeeBreakdown{}, nil
+	}
+	schedule, ok := t.schedules[[2]WalletType{payer, payee}]
+	if !ok {
+		return FeeBreakdown{}, nil
+	}
+	return schedule.Calculate(amount)
+}
+
+// CombineFees adds up the lines of several breakdowns by code, in the order
+// they first appear. The schedule is kept only if they all share it.
+func CombineFees(breakdowns ...FeeBreakdown) (FeeBreakdown, error) {
+	var combined FeeBreakdown
+	for i, breakdown := range breakdowns {
+		if i == 0 || combined.Schedule == breakdown.Schedule {
+			combined.Schedule = breakdown.Schedule
+		} else {
+			combined.Schedule = ""
+		}
+	lines:
+		for _, line := range breakdown.Lines {
+			for j := range combined.Lines {
+				if combined.Lines[j].Code == line.Code {
+					total, err := combined.Lines[j].Amount.Add(line.Amount)
+					if err != nil {
+						return FeeBreakdown{}, err
+					}
+					combined.Lines[j].Amount = total
+					continue lines
+				}
+			}
+			combined.Lines = append(combined.Lines, line)
+		}
+	}
+	return combined, nil
+}
@@ -0,0 +1,131 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func moneyRef(cents int64) *Money {
+	amount := MoneyFromCents(cents)
+	return &amount
+}
+
+func TestFeeRate_ApplyRoundsHalfUp(t *testing.T) {
+	rate, err := ParseFeeRate("2.5")
+	assert.NoError(t, err)
+	assert.Equal(t, FeeRate(250), rate)
+	assert.Equal(t, "2.50%", rate.String())
+
+	fee, err := rate.Apply(MoneyFromCents(1010)) // 25.25 cents
+	assert.NoError(t, err)
+	assert.Equal(t, MoneyFromCents(25), fee)
+
+	fee, err = rate.Apply(MoneyFromCents(1020)) // 25.5 cents
+	assert.NoError(t, err)
+	assert.Equal(t, MoneyFromCents(26), fee)
+
+	_, err = ParseFeeRate("100.01")
+	assert.ErrorIs(t, err, ErrInvalidFeeSchedule)
+}
+
+func TestFeeSchedule_Calculate(t *testing.T) {
+	amount := MoneyFromCents(100000)
+	tests := []struct {
+		name     string
+		schedule FeeSchedule
+		lines    []FeeLine
+	}{
+		{
+			name:     "flat",
+			schedule: FeeSchedule{Type: FeeFlat, Flat: MoneyFromCents(150), Rate: 100},
+			lines:    []FeeLine{{Code: FeeLineFlat, Amount: MoneyFromCents(150)}},
+		},
+		{
+			name:     "percentage",
+			schedule: FeeSchedule{Type: FeePercentage, Flat: MoneyFromCents(150), Rate: 199},
+			lines:    []FeeLine{{Code: FeeLinePercentage, Amount: MoneyFromCents(1990)}},
+		},
+		{
+			name: "tiered",
+			schedule: FeeSchedule{Type: FeeTiered, Tiers: []FeeTier{
+				{UpTo: moneyRef(50000), Flat: MoneyFromCents(100)},
+				{UpTo: moneyRef(500000), Flat: MoneyFromCents(50), Rate: 50},
+				{Rate: 25},
+			}},
+			lines: []FeeLine{{Code: FeeLineFlat, Amount: MoneyFromCents(50)}, {Code: FeeLinePercentage, Amount: MoneyFromCents(500)}},
+		},
+		{
+			name:     "capped at max",
+			schedule: FeeSchedule{Type: FeeCapped, Flat: MoneyFromCents(30), Rate: 300, Max: moneyRef(1500)},
+			lines:    []FeeLine{{Code: FeeLineCap, Amount: MoneyFromCents(1500)}},
+		},
+		{
+			name:     "capped within bounds",
+			schedule: FeeSchedule{Type: FeeCapped, Flat: MoneyFromCents(30), Rate: 100, Min: moneyRef(50), Max: moneyRef(1500)},
+			lines:    []FeeLine{{Code: FeeLineFlat, Amount: MoneyFromCents(30)}, {Code: FeeLinePercentage, Amount: MoneyFromCents(1000)}},
+		},
+		{
+			name:     "capped at min",
+			schedule: FeeSchedule{Type: FeeCapped, Rate: 1, Min: moneyRef(50), Max: moneyRef(1500)},
+			lines:    []FeeLine{{Code: FeeLineMinimum, Amount: MoneyFromCents(50)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, tt.schedule.Validate())
+			breakdown, err := tt.schedule.Calculate(amount)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.lines, breakdown.Lines)
+		})
+	}
+}
+
+func TestFeeSchedule_ValidateRejectsInconsistentSchedules(t *testing.T) {
+	invalid := []FeeSchedule{
+		{Type: "WEEKLY"},
+		{Type: FeeFlat, Flat: MoneyFromCents(-1)},
+		{Type: FeeTiered},
+		{Type: FeeTiered, Tiers: []FeeTier{{}, {UpTo: moneyRef(100)}}},
+		{Type: FeeTiered, Tiers: []FeeTier{{UpTo: moneyRef(100)}, {UpTo: moneyRef(100)}}},
+		{Type: FeeCapped, Rate: 100},
+		{Type: FeeCapped, Min: moneyRef(200), Max: moneyRef(100)},
+	}
+	for _, schedule := range invalid {
+		assert.ErrorIs(t, schedule.Validate(), ErrInvalidFeeSchedule, "%+v", schedule)
+	}
+}
+
+func TestFeeTable_Quote(t *testing.T) {
+	table := NewFeeTable()
+	assert.NoError(t, table.Set(CommonWallet, MerchantWallet, FeeSchedule{Name: "merchant", Type: FeeFlat, Flat: MoneyFromCents(100)}))
+
+	breakdown, err := table.Quote(CommonWallet, MerchantWallet, MoneyFromCents(5000))
+	assert.NoError(t, err)
+	assert.Equal(t, "merchant", breakdown.Schedule)
+	total, err := breakdown.Total(DefaultCurrency)
+	assert.NoError(t, err)
+	assert.Equal(t, MoneyFromCents(100), total)
+
+	breakdown, err = table.Quote(CommonWallet, CommonWallet, MoneyFromCents(5000))
+	assert.NoError(t, err)
+	assert.Empty(t, breakdown.Lines)
+
+	var none *FeeTable
+	breakdown, err = none.Quote(CommonWallet, MerchantWallet, MoneyFromCents(5000))
+	assert.NoError(t, err)
+	assert.Empty(t, breakdown.Lines)
+}
+
+func TestCombineFees_AddsLinesByCode(t *testing.T) {
+	combined, err := CombineFees(
+		FeeBreakdown{Schedule: "merchant", Lines: []FeeLine{{Code: FeeLineFlat, Amount: MoneyFromCents(100)}, {Code: FeeLinePercentage, Amount: MoneyFromCents(50)}}},
+		FeeBreakdown{Schedule: "merchant", Lines: []FeeLine{{Code: FeeLineFlat, Amount: MoneyFromCents(100)}}},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, FeeBreakdown{Schedule: "merchant", Lines: []FeeLine{{Code: FeeLineFlat, Amount: MoneyFromCents(200)}, {Code: FeeLinePercentage, Amount: MoneyFromCents(50)}}}, combined)
+
+	combined, err = CombineFees(FeeBreakdown{Schedule: "merchant", Lines: []FeeLine{{Code: FeeLineFlat, Amount: MoneyFromCents(100)}}}, FeeBreakdown{})
+	assert.NoError(t, err)
+	assert.Empty(t, combined.Schedule)
+}
@@ -0,0 +1,34 @@
+package entities
+
+import "time"
+
+type IdempotencyStatus string
+
+const (
+	IdempotencyStatusInProgress IdempotencyStatus = "IN_PROGRESS"
+	IdempotencyStatusCompleted  IdempotencyStatus = "COMPLETED"
+)
+
+type IdempotencyRecord struct {
+	Key                 string            `gorm:"primaryKey"`
+	RequestHash         string            `gorm:"not null"`
+	Status              IdempotencyStatus `gorm:"not null;default:'IN_PROGRESS'"`
+	ResponseCode        int
+	ResponseContentType string
+	ResponseLocation    string
+	ResponseBody        []byte
+	ExpiresAt           time.Time `gorm:"not null;index"`
+	CreatedAt           time.Time `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime"`
+	// LeasedUntil is when an IN_PROGRESS request is presumed dead, e.g. its
+	// process crashed, and a retry with the same payload may take the key over.
+	LeasedUntil time.Time `gorm:"not null;default:now()"`
+}
+
+func (r *IdempotencyRecord) IsExpired(now time.Time) bool {
+	return !r.ExpiresAt.After(now)
+}
+
+func (r *IdempotencyRecord) IsAbandoned(now time.Time) bool {
+	return r.Status == IdempotencyStatusInProgress && !r.LeasedUntil.After(now)
+}
@@ -0,0 +1,132 @@
+package entities
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LedgerDirection string
+
+const (
+	LedgerDebit  LedgerDirection = "DEBIT"
+	LedgerCredit LedgerDirection = "CREDIT"
+)
+
+var (
+	ErrUnbalancedPosting = errors.New("ledger posting does not sum to zero")
+	ErrInvalidPosting    = errors.New("invalid ledger posting")
+)
+
+type LedgerEntry struct {
+	ID            int64           `gorm:"primaryKey"`
+	TransactionID int64           `gorm:"not null;index"`
+	WalletID      int64           `gorm:"not null;index"`
+	Direction     LedgerDirection `gorm:"type:text;not null"`
+	Amount        Money           `gorm:"not null"`
+	Currency      string          `gorm:"type:char(3);not null;default:'BRL'"`
+	CreatedAt     time.Time       `gorm:"autoCreateTime"`
+	Transaction   Transaction     `gorm:"foreignKey:TransactionID"`
+	Wallet        Wallet          `gorm:"foreignKey:WalletID"`
+}
+
+func (e *LedgerEntry) BeforeCreate(*gorm.DB) error {
+	e.Currency = e.Amount.Currency
+	return nil
+}
+
+func (e *LedgerEntry) AfterFind(*gorm.DB) error {
+	e.Amount = NewMoney(e.Amount.Cents, e.Currency)
+	return nil
+}
+
+// SignedAmount is the effect of the entry on the wallet balance: credits
+// add to it and debits subtract from it.
+func (e LedgerEntry) SignedAmount() Money {
+	if e.Direction == LedgerDebit {
+		return NewMoney(-e.Amount.Cents, e.Amount.Currency)
+	}
+	return e.Amount
+}
+
+// NewTransferPosting moves amount from one wallet to another as a balanced
+// pair of entries.
+func NewTransferPosting(transactionID, fromWalletID, toWalletID int64, amount Money) []LedgerEntry {
+	return []LedgerEntry{
+		{TransactionID: transactionID, WalletID: fromWalletID, Direction: LedgerDebit, Amount: amount},
+		{TransactionID: transactionID, WalletID: toWalletID, Direction: LedgerCredit, Amount: amount},
+	}
+}
+
+// NewFeePosting moves each fee line from the payer's wallet to the revenue
+// wallet, one pair of entries per line.
+func NewFeePosting(transactionID, payerWalletID, revenueWalletID int64, fees FeeBreakdown) []LedgerEntry {
+	entries := make([]LedgerEntry, 0, 2*len(fees.Lines))
+	for _, line := range fees.Lines {
+		entries = append(entries, NewTransferPosting(transactionID, payerWalletID, revenueWalletID, line.Amount)...)
+	}
+	return entries
+}
+
+// NewConversionPosting pays amount from one wallet into the exchange wallet of
+// its currency and converted out of the exchange wallet of the other currency
+// into the receiving wallet. Each currency balances on its own.
+func NewConversionPosting(transactionID, fromWalletID, fromExchangeWalletID, toExchangeWalletID, toWalletID int64, amount, converted Money) []LedgerEntry {
+	return append(
+		NewTransferPosting(transactionID, fromWalletID, fromExchangeWalletID, amount),
+		NewTransferPosting(transactionID, toExchangeWalletID, toWalletID, converted)...,
+	)
+}
+
+// ReversePosting mirrors entries under a new transaction, undoing their effect.
+func ReversePosting(transactionID int64, entries []LedgerEntry) []LedgerEntry {
+	reversed := make([]LedgerEntry, 0, len(entries))
+	for _, entry := range entries {
+		direction := LedgerCredit
+		if entry.Direction == LedgerCredit {
+			direction = LedgerDebit
+		}
+		reversed = append(reversed, LedgerEntry{
+			TransactionID: transactionID,
+			WalletID:      entry.WalletID,
+			Direction:     direction,
+			Amount:        entry.Amount,
+		})
+	}
+	return reversed
+}
+
+// ValidatePosting checks that entries belong to one transaction, carry
+// positive amounts and that debits and credits cancel out in each currency.
+func ValidatePosting(entries []LedgerEntry) error {
+	if len(entries) < 2 {
+		return ErrInvalidPosting
+	}
+
+	totals := make(map[string]Money)
+	for _, entry := range entries {
+		if entry.TransactionID != entries[0].TransactionID || !entry.Amount.IsPositive() {
+			return ErrInvalidPosting
+		}
+		if entry.Direction != LedgerDebit && entry.Direction != LedgerCredit {
+			return ErrInvalidPosting
+		}
+		currency := normalizeCurrency(entry.Amount.Currency)
+		total, ok := totals[currency]
+		if !ok {
+			total = NewMoney(0, currency)
+		}
+		total, err := total.Add(entry.SignedAmount())
+		if err != nil {
+			return err
+		}
+		totals[currency] = total
+	}
+	for _, total := range totals {
+		if !total.IsZero() {
+			return ErrUnbalancedPosting
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatePosting_Transfer(t *testing.T) {
+	entries := NewTransferPosting(1, 10, 20, MoneyFromCents(500))
+	assert.NoError(t, ValidatePosting(entries))
+	assert.Equal(t, MoneyFromCents(-500), entries[0].SignedAmount())
+	assert.Equal(t, MoneyFromCents(500), entries[1].SignedAmount())
+}
+
+func TestValidatePosting_Unbalanced(t *testing.T) {
+	entries := []LedgerEntry{
+		{TransactionID: 1, WalletID: 10, Direction: LedgerDebit, Amount: MoneyFromCents(500)},
+		{TransactionID: 1, WalletID: 20, Direction: LedgerCredit, Amount: MoneyFromCents(450)},
+	}
+	assert.ErrorIs(t, ValidatePosting(entries), ErrUnbalancedPosting)
+}
+
+func TestValidatePosting_Conversion(t *testing.T) {
+	entries := NewConversionPosting(1, 10, 30, 31, 20, NewMoney(10000, "USD"), NewMoney(54321, "BRL"))
+	assert.NoError(t, ValidatePosting(entries))
+
+	// Balanced overall but not within each currency.
+	crossed := []LedgerEntry{
+		{TransactionID: 1, WalletID: 10, Direction: LedgerDebit, Amount: NewMoney(500, "USD")},
+		{TransactionID: 1, WalletID: 20, Direction: LedgerCredit, Amount: NewMoney(500, "BRL")},
+	}
+	assert.ErrorIs(t, ValidatePosting(crossed), ErrUnbalancedPosting)
+}
+
+func TestValidatePosting_Invalid(t *testing.T) {
+	assert.ErrorIs(t, ValidatePosting(nil), ErrInvalidPosting)
+
+	mixedTransactions := []LedgerEntry{
+		{TransactionID: 1, WalletID: 10, Direction: LedgerDebit, Amount: MoneyFromCents(500)},
+		{TransactionID: 2, WalletID: 20, Direction: LedgerCredit, Amount: MoneyFromCents(500)},
+	}
+	assert.ErrorIs(t, ValidatePosting(mixedTransactions), ErrInvalidPosting)
+
+	zeroAmount := []LedgerEntry{
+		{TransactionID: 1, WalletID: 10, Direction: LedgerDebit, Amount: MoneyFromCents(0)},
+		{TransactionID: 1, WalletID: 20, Direction: LedgerCredit, Amount: MoneyFromCents(0)},
+	}
+	assert.ErrorIs(t, ValidatePosting(zeroAmount), ErrInvalidPosting)
+}
+
+func TestReversePosting(t *testing.T) {
+	original := NewTransferPosting(1, 10, 20, MoneyFromCents(500))
+	reversed := ReversePosting(2, original)
+
+	assert.NoError(t, ValidatePosting(reversed))
+	assert.Equal(t, LedgerCredit, reversed[0].Direction)
+	assert.Equal(t, int64(10), reversed[0].WalletID)
+	assert.Equal(t, LedgerDebit, reversed[1].Direction)
+	assert.Equal(t, int64(2), reversed[1].TransactionID)
+}
@@ -0,0 +1,245 @@
+package entities
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultCurrency = "BRL"
+	centsPerUnit    = 100
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrMoneyOverflow    = errors.New("money amount overflow")
+	ErrInvalidMoney     = errors.New("invalid money amount")
+	ErrInvalidCurrency  = errors.New("invalid currency code")
+)
+
+// Money is an exact monetary amount stored as integer cents of a currency.
+type Money struct {
+	Cents    int64
+	Currency string
+}
+
+func NewMoney(cents int64, currency string) Money {
+	return Money{Cents: cents, Currency: normalizeCurrency(currency)}
+}
+
+func MoneyFromCents(cents int64) Money {
+	return NewMoney(cents, DefaultCurrency)
+}
+
+// ParseMoney reads a decimal string such as "10", "10.5" or "-0.01".
+// More than two fractional digits are rejected instead of rounded.
+func ParseMoney(value string, currency string) (Money, error) {
+	value = strings.TrimSpace(value)
+	negative := false
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		negative = value[0] == '-'
+		value = value[1:]
+	}
+
+	units, fraction, hasPoint := strings.Cut(value, ".")
+	if units == "" && fraction == "" || hasPoint && fraction == "" {
+		return Money{}, ErrInvalidMoney
+	}
+	if units == "" {
+		units = "0"
+	}
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > 2 {
+		return Money{}, ErrInvalidMoney
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	if !isDigits(units) || !isDigits(fraction) {
+		return Money{}, ErrInvalidMoney
+	}
+
+	whole, err := strconv.ParseInt(units, 10, 64)
+	if err != nil {
+		return Money{}, ErrMoneyOverflow
+	}
+	cents, _ := strconv.ParseInt(fraction, 10, 64)
+	if whole > (math.MaxInt64-cents)/centsPerUnit {
+		return Money{}, ErrMoneyOverflow
+	}
+	total := whole*centsPerUnit + cents
+	if negative {
+		total = -total
+	}
+	return NewMoney(total, currency), nil
+}
+
+func (m Money) String() string {
+	sign := ""
+	cents := m.Cents
+	if cents < 0 {
+		sign = "-"
+	}
+	units := cents / centsPerUnit
+	fraction := cents % centsPerUnit
+	if units < 0 {
+		units = -units
+	}
+	if fraction < 0 {
+		fraction = -fraction
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, units, fraction)
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := m.sameCurrency(other)
+	if err != nil {
+		return Money{}, err
+	}
+	if (other.Cents > 0 && m.Cents > math.MaxInt64-other.Cents) ||
+		(other.Cents < 0 && m.Cents < math.MinInt64-other.Cents) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{Cents: m.Cents + other.Cents, Currency: currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Cents == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return m.Add(Money{Cents: -other.Cents, Currency: other.Currency})
+}
+
+// Cmp returns -1, 0 or 1 as m is less than, equal to or greater than other.
+// Amounts of different currencies cannot be compared.
+func (m Money) Cmp(other Money) (int, error) {
+	if _, err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.Cents < other.Cents:
+		return -1, nil
+	case m.Cents > other.Cents:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+func (m Money) LessThan(other Money) (bool, error) {
+	cmp, err := m.Cmp(other)
+	return cmp < 0, err
+}
+
+func (m Money) IsZero() bool {
+	return m.Cents == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Cents > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Cents < 0
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(m.String())), nil
+}
+
+// UnmarshalJSON accepts both "10.50" and 10.50; numbers are parsed from
+// their literal text so no float rounding is involved.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	text := string(data)
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	parsed, err := ParseMoney(text, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+func (m *Money) Scan(src any) error {
+	var parsed Money
+	var err error
+	switch value := src.(type) {
+	case nil:
+		parsed = NewMoney(0, m.Currency)
+	case []byte:
+		parsed, err = ParseMoney(string(value), m.Currency)
+	case string:
+		parsed, err = ParseMoney(value, m.Currency)
+	case int64:
+		if value > math.MaxInt64/centsPerUnit || value < math.MinInt64/centsPerUnit {
+			return ErrMoneyOverflow
+		}
+		parsed = NewMoney(value*centsPerUnit, m.Currency)
+	case float64:
+		parsed = NewMoney(int64(math.Round(value*centsPerUnit)), m.Currency)
+	default:
+		return fmt.Errorf("cannot scan %T into Money", src)
+	}
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (Money) GormDataType() string {
+	return "numeric(20,2)"
+}
+
+func (m Money) sameCurrency(other Money) (string, error) {
+	currency := normalizeCurrency(m.Currency)
+	if currency != normalizeCurrency(other.Currency) {
+		return "", ErrCurrencyMismatch
+	}
+	return currency, nil
+}
+
+// ParseCurrency reads an ISO 4217 code such as "usd"; an empty code is the
+// default currency.
+func ParseCurrency(code string) (string, error) {
+	currency := normalizeCurrency(strings.TrimSpace(code))
+	if len(currency) != 3 {
+		return "", ErrInvalidCurrency
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return "", ErrInvalidCurrency
+		}
+	}
+	return currency, nil
+}
+
+func normalizeCurrency(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return strings.ToUpper(currency)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package entities
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMoney(t *testing.T) {
+	cases := map[string]int64{
+		"10":     1000,
+		"10.5":   1050,
+		"10.50":  1050,
+		"0.01":   1,
+		".99":    99,
+		"-3.25":  -325,
+		"7.100":  710,
+		" 1.00 ": 100,
+	}
+	for input, expected := range cases {
+		money, err := ParseMoney(input, "")
+		assert.NoError(t, err, input)
+		assert.Equal(t, NewMoney(expected, DefaultCurrency), money, input)
+	}
+
+	for _, input := range []string{"", "-", "1.", "1.234", "abc", "1,00", "1e3"} {
+		_, err := ParseMoney(input, "")
+		assert.ErrorIs(t, err, ErrInvalidMoney, input)
+	}
+
+	_, err := ParseMoney("99999999999999999999", "")
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "0.00", MoneyFromCents(0).String())
+	assert.Equal(t, "0.05", MoneyFromCents(5).String())
+	assert.Equal(t, "-0.05", MoneyFromCents(-5).String())
+	assert.Equal(t, "1234.56", MoneyFromCents(123456).String())
+}
+
+func TestMoney_Arithmetic(t *testing.T) {
+	sum, err := MoneyFromCents(10).Add(MoneyFromCents(20))
+	assert.NoError(t, err)
+	assert.Equal(t, MoneyFromCents(30), sum)
+
+	diff, err := MoneyFromCents(10).Sub(MoneyFromCents(20))
+	assert.NoError(t, err)
+	assert.Equal(t, MoneyFromCents(-10), diff)
+
+	_, err = MoneyFromCents(math.MaxInt64).Add(MoneyFromCents(1))
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = MoneyFromCents(math.MinInt64).Sub(MoneyFromCents(1))
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = NewMoney(10, "BRL").Add(NewMoney(10, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	less, err := MoneyFromCents(10).LessThan(MoneyFromCents(20))
+	assert.NoError(t, err)
+	assert.True(t, less)
+	_, err = NewMoney(10, "BRL").Cmp(NewMoney(20, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	total := MoneyFromCents(0)
+	for i := 0; i < 1000; i++ {
+		total, _ = total.Add(MoneyFromCents(10))
+	}
+	assert.Equal(t, MoneyFromCents(10000), total)
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(MoneyFromCents(1050))
+	assert.NoError(t, err)
+	assert.Equal(t, `"10.50"`, string(data))
+
+	var fromString, fromNumber Money
+	assert.NoError(t, json.Unmarshal([]byte(`"10.50"`), &fromString))
+	assert.NoError(t, json.Unmarshal([]byte(`10.5`), &fromNumber))
+	assert.Equal(t, MoneyFromCents(1050), fromString)
+	assert.Equal(t, MoneyFromCents(1050), fromNumber)
+
+	assert.Error(t, json.Unmarshal([]byte(`"10.555"`), &fromString))
+}
+
+func TestMoney_Scan(t *testing.T) {
+	var money Money
+	assert.NoError(t, money.Scan([]byte("12.34")))
+	assert.Equal(t, MoneyFromCents(1234), money)
+
+	assert.NoError(t, money.Scan(int64(5)))
+	assert.Equal(t, MoneyFromCents(500), money)
+
+	assert.NoError(t, money.Scan(0.1+0.2))
+	assert.Equal(t, MoneyFromCents(30), money)
+
+	value, err := MoneyFromCents(1234).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "12.34", value)
+}
+
+func TestParseCurrency(t *testing.T) {
+	currency, err := ParseCurrency(" usd ")
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", currency)
+
+	currency, err = ParseCurrency("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultCurrency, currency)
+
+	for _, invalid := range []string{"US", "USDT", "U$D", "12A"} {
+		_, err := ParseCurrency(invalid)
+		assert.ErrorIs(t, err, ErrInvalidCurrency, invalid)
+	}
+}
@@ -12,17 +12,45 @@ const (
 	NotificationStatusPending NotificationStatus = "PENDING"
 	NotificationStatusSent    NotificationStatus = "SENT"
 	NotificationStatusFailed  NotificationStatus = "FAILED"
+	NotificationStatusDead    NotificationStatus = "DEAD"
 )
 
+type NotificationKind string
+
+const (
+	NotificationKindTransferReceived        NotificationKind = "TRANSFER_RECEIVED"
+	NotificationKindScheduledTransferFailed NotificationKind = "SCHEDULED_TRANSFER_FAILED"
+	NotificationKindTransferBatchFinished   NotificationKind = "TRANSFER_BATCH_FINISHED"
+	NotificationKindTransferCancelled       NotificationKind = "TRANSFER_CANCELLED"
+	NotificationKindRefundReceived          NotificationKind = "REFUND_RECEIVED"
+	NotificationKindRefundSent              NotificationKind = "REFUND_SENT"
+)
+
+// Notification tells ReceiverID about a transfer. TransactionID is set for
+// received and cancelled transfers and for refunds, ScheduledTransferID for
+// failed scheduled ones and TransferBatchID for finished batches. Details are
+// those of the transfer.
 type Notification struct {
-	ID            int64              `gorm:"primaryKey"`
-	ReceiverID    int64              `gorm:"not null;index"`
-	TransactionID int64              `gorm:"not null;index"`
-	Amount        float64            `gorm:"not null"`
-	Status        NotificationStatus `gorm:"not null default 'PENDING'"`
-	CreatedAt     time.Time          `gorm:"autoCreateTime"`
-	UpdatedAt     time.Time          `gorm:"autoUpdateTime"`
-	DeletedAt     gorm.DeletedAt     `gorm:"index"`
-	Receiver      User               `gorm:"foreignKey:ReceiverID"`
-	Transaction   Transaction        `gorm:"foreignKey:TransactionID"`
+	ID                  int64              `gorm:"primaryKey"`
+	ReceiverID          int64              `gorm:"not null;index"`
+	Kind                NotificationKind   `gorm:"type:text;not null;default:'TRANSFER_RECEIVED'"`
+	TransactionID       *int64             `gorm:"index"`
+	ScheduledTransferID *int64             `gorm:"index"`
+	TransferBatchID     *int64             `gorm:"index"`
+	Amount              Money              `gorm:"not null"`
+	Status              NotificationStatus `gorm:"not null default 'PENDING';index:idx_notifications_retry,priority:1"`
+	Attempts            int                `gorm:"not null;default:0"`
+	LastError           string             `gorm:"type:text"`
+	NextAttemptAt       *time.Time         `gorm:"index:idx_notifications_retry,priority:2"`
+	CreatedAt           time.Time          `gorm:"autoCreateTime"`
+	UpdatedAt           time.Time          `gorm:"autoUpdateTime"`
+	DeletedAt           gorm.DeletedAt     `gorm:"index"`
+	Receiver            User               `gorm:"foreignKey:ReceiverID"`
+	Transaction         *Transaction       `gorm:"foreignKey:TransactionID"`
+	ScheduledTransfer   *ScheduledTransfer `gorm:"foreignKey:ScheduledTransferID"`
+	TransferBatch       *TransferBatch     `gorm:"foreignKey:TransferBatchID"`
+	Details             TransferDetails    `gorm:"type:jsonb;serializer:json"`
+	// OutboxMessageID is the outbox message the notification was created
+	// for, so delivering that message again does not notify twice.
+	OutboxMessageID *int64 `gorm:"uniqueIndex"`
 }
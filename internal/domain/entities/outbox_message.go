@@ -0,0 +1,123 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "PENDING"
+	OutboxStatusDone    OutboxStatus = "DONE"
+	OutboxStatusDead    OutboxStatus = "DEAD"
+)
+
+const (
+	OutboxEventTransferNotification    = "transfer.notification"
+	OutboxEventScheduledTransferFailed = "scheduled_transfer.failed"
+	OutboxEventTransferBatchFinished   = "transfer_batch.finished"
+	OutboxEventTransferCancelled       = "transfer.cancelled"
+	OutboxEventRefundNotification      = "refund.notification"
+)
+
+// OutboxMessage is an event written in the same database transaction as the
+// change that caused it and delivered afterwards by a background dispatcher.
+type OutboxMessage struct {
+	ID          int64        `gorm:"primaryKey"`
+	EventType   string       `gorm:"type:text;not null"`
+	AggregateID int64        `gorm:"not null;index"`
+	Payload     []byte       `gorm:"type:jsonb;not null"`
+	Status      OutboxStatus `gorm:"type:text;not null;default:'PENDING';index:idx_outbox_messages_pending,priority:1"`
+	Attempts    int          `gorm:"not null;default:0"`
+	LastError   string       `gorm:"type:text"`
+	AvailableAt time.Time    `gorm:"not null;index:idx_outbox_messages_pending,priority:2"`
+	ProcessedAt *time.Time
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// OutboxPayload is the body of an outbox message; each payload knows the
+// event it is delivered as and the record it is about.
+type OutboxPayload interface {
+	outboxEvent() (eventType string, aggregateID int64)
+}
+
+// NewOutboxMessage wraps payload in a message available for delivery at now.
+func NewOutboxMessage(payload OutboxPayload, now time.Time) (*OutboxMessage, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	eventType, aggregateID := payload.outboxEvent()
+	return &OutboxMessage{
+		EventType:   eventType,
+		AggregateID: aggregateID,
+		Payload:     body,
+		Status:      OutboxStatusPending,
+		AvailableAt: now,
+	}, nil
+}
+
+type TransferNotificationPayload struct {
+	ReceiverID    int64           `json:"receiver_id"`
+	TransactionID int64           `json:"transaction_id"`
+	Amount        Money           `json:"amount"`
+	Details       TransferDetails `json:"details,omitzero"`
+}
+
+func (p TransferNotificationPayload) outboxEvent() (string, int64) {
+	return OutboxEventTransferNotification, p.TransactionID
+}
+
+type ScheduledTransferFailedPayload struct {
+	PayerID             int64  `json:"payer_id"`
+	ScheduledTransferID int64  `json:"scheduled_transfer_id"`
+	Amount              Money  `json:"amount"`
+	Reason              string `json:"reason"`
+}
+
+func (p ScheduledTransferFailedPayload) outboxEvent() (string, int64) {
+	return OutboxEventScheduledTransferFailed, p.ScheduledTransferID
+}
+
+// TransferBatchFinishedPayload summarises a batch for its payer. Amount is
+// what the batch actually settled.
+type TransferBatchFinishedPayload struct {
+	PayerID int64               `json:"payer_id"`
+	BatchID int64               `json:"batch_id"`
+	Status  TransferBatchStatus `json:"status"`
+	Amount  Money               `json:"amount"`
+}
+
+func (p TransferBatchFinishedPayload) outboxEvent() (string, int64) {
+	return OutboxEventTransferBatchFinished, p.BatchID
+}
+
+// TransferCancelledPayload tells the payee of a held transfer that its payer
+// cancelled it. Amount is what had been authorized.
+type TransferCancelledPayload struct {
+	ReceiverID    int64           `json:"receiver_id"`
+	TransactionID int64           `json:"transaction_id"`
+	Amount        Money           `json:"amount"`
+	Details       TransferDetails `json:"details,omitzero"`
+}
+
+func (p TransferCancelledPayload) outboxEvent() (string, int64) {
+	return OutboxEventTransferCancelled, p.TransactionID
+}
+
+// RefundNotificationPayload tells one side of a refund about it: Kind is
+// REFUND_RECEIVED for the payer getting money back and REFUND_SENT for the
+// payee paying it. Details are those of the refunded transfer.
+type RefundNotificationPayload struct {
+	ReceiverID    int64            `json:"receiver_id"`
+	TransactionID int64            `json:"transaction_id"`
+	Kind          NotificationKind `json:"kind"`
+	Amount        Money            `json:"amount"`
+	Details       TransferDetails  `json:"details,omitzero"`
+}
+
+func (p RefundNotificationPayload) outboxEvent() (string, int64) {
+	return OutboxEventRefundNotification, p.TransactionID
+}
@@ -0,0 +1,109 @@
+package entities
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+type RecurrenceFrequency string
+
+const (
+	RecurrenceWeekly RecurrenceFrequency = "WEEKLY"
+	// RecurrenceMonthly runs on DayOfMonth, or on the last day of months
+	// that are shorter.
+	RecurrenceMonthly RecurrenceFrequency = "MONTHLY"
+	// RecurrenceLastBusinessDay runs on the last weekday of each month.
+	// Holidays are not taken into account.
+	RecurrenceLastBusinessDay RecurrenceFrequency = "LAST_BUSINESS_DAY"
+)
+
+// Recurrence is the calendar rule of a standing order. A zero Recurrence
+// means the transfer runs only once. The order ends after EndsAt or after
+// MaxOccurrences occurrences, whichever comes first.
+type Recurrence struct {
+	Frequency      RecurrenceFrequency `gorm:"type:text"`
+	DayOfMonth     int
+	EndsAt         *time.Time
+	MaxOccurrences *int
+}
+
+func (r Recurrence) IsZero() bool {
+	return r.Frequency == ""
+}
+
+func (r Recurrence) Validate() error {
+	if r.IsZero() && (r.EndsAt != nil || r.MaxOccurrences != nil) {
+		return ErrInvalidRecurrence
+	}
+	switch r.Frequency {
+	case "", RecurrenceWeekly, RecurrenceLastBusinessDay:
+		if r.DayOfMonth != 0 {
+			return ErrInvalidRecurrence
+		}
+	case RecurrenceMonthly:
+		if r.DayOfMonth < 1 || r.DayOfMonth > 31 {
+			return ErrInvalidRecurrence
+		}
+	default:
+		return ErrInvalidRecurrence
+	}
+	if r.MaxOccurrences != nil && *r.MaxOccurrences < 1 {
+		return ErrInvalidRecurrence
+	}
+	return nil
+}
+
+// First returns the first occurrence at or after start. Occurrences keep the
+// clock time of start in loc.
+func (r Recurrence) First(start time.Time, loc *time.Location) time.Time {
+	start = start.In(loc)
+	switch r.Frequency {
+	case RecurrenceMonthly, RecurrenceLastBusinessDay:
+		if first := r.inMonth(start, 0); !first.Before(start) {
+			return first
+		}
+		return r.inMonth(start, 1)
+	default:
+		return start
+	}
+}
+
+// Next returns the occurrence that follows occurrence.
+func (r Recurrence) Next(occurrence time.Time, loc *time.Location) time.Time {
+	occurrence = occurrence.In(loc)
+	switch r.Frequency {
+	case RecurrenceWeekly:
+		return occurrence.AddDate(0, 0, 7)
+	case RecurrenceMonthly, RecurrenceLastBusinessDay:
+		return r.inMonth(occurrence, 1)
+	default:
+		return occurrence
+	}
+}
+
+// IsOver reports whether occurrence falls outside the order, given how many
+// occurrences already happened.
+func (r Recurrence) IsOver(occurrence time.Time, occurrences int) bool {
+	if r.MaxOccurrences != nil && occurrences >= *r.MaxOccurrences {
+		return true
+	}
+	return r.EndsAt != nil && occurrence.After(*r.EndsAt)
+}
+
+// inMonth returns the occurrence in the month months after the month of t,
+// at the clock time of t.
+func (r Recurrence) inMonth(t time.Time, months int) time.Time {
+	year, month, _ := t.Date()
+	firstOfMonth := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1)
+
+	if r.Frequency == RecurrenceMonthly {
+		return firstOfMonth.AddDate(0, 0, min(r.DayOfMonth, lastDay.Day())-1)
+	}
+	for lastDay.Weekday() == time.Saturday || lastDay.Weekday() == time.Sunday {
+		lastDay = lastDay.AddDate(0, 0, -1)
+	}
+	return lastDay
+}
@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecurrence_Validate(t *testing.T) {
+	zero, endsAt := 0, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, Recurrence{}.Validate())
+	assert.NoError(t, Recurrence{Frequency: RecurrenceWeekly, EndsAt: &endsAt}.Validate())
+	assert.NoError(t, Recurrence{Frequency: RecurrenceMonthly, DayOfMonth: 31}.Validate())
+	assert.ErrorIs(t, Recurrence{EndsAt: &endsAt}.Validate(), ErrInvalidRecurrence)
+	assert.ErrorIs(t, Recurrence{Frequency: RecurrenceMonthly}.Validate(), ErrInvalidRecurrence)
+	assert.ErrorIs(t, Recurrence{Frequency: RecurrenceWeekly, DayOfMonth: 3}.Validate(), ErrInvalidRecurrence)
+	assert.ErrorIs(t, Recurrence{Frequency: RecurrenceWeekly, MaxOccurrences: &zero}.Validate(), ErrInvalidRecurrence)
+	assert.ErrorIs(t, Recurrence{Frequency: "DAILY"}.Validate(), ErrInvalidRecurrence)
+}
+
+func TestRecurrence_MonthlyClampsToShortMonths(t *testing.T) {
+	monthly := Recurrence{Frequency: RecurrenceMonthly, DayOfMonth: 31}
+
+	first := monthly.First(time.Date(2025, 1, 31, 9, 30, 0, 0, time.UTC), time.UTC)
+	assert.Equal(t, time.Date(2025, 1, 31, 9, 30, 0, 0, time.UTC), first)
+
+	second := monthly.Next(first, time.UTC)
+	assert.Equal(t, time.Date(2025, 2, 28, 9, 30, 0, 0, time.UTC), second)
+	assert.Equal(t, time.Date(2025, 3, 31, 9, 30, 0, 0, time.UTC), monthly.Next(second, time.UTC))
+
+	// A start after day N in its month begins in the following month.
+	monthly.DayOfMonth = 10
+	assert.Equal(t, time.Date(2025, 2, 10, 9, 30, 0, 0, time.UTC), monthly.First(first, time.UTC))
+}
+
+func TestRecurrence_LastBusinessDaySkipsWeekends(t *testing.T) {
+	lastBusinessDay := Recurrence{Frequency: RecurrenceLastBusinessDay}
+
+	// May 31st 2025 is a Saturday.
+	first := lastBusinessDay.First(time.Date(2025, 5, 2, 8, 0, 0, 0, time.UTC), time.UTC)
+	assert.Equal(t, time.Date(2025, 5, 30, 8, 0, 0, 0, time.UTC), first)
+	assert.Equal(t, time.Date(2025, 6, 30, 8, 0, 0, 0, time.UTC), lastBusinessDay.Next(first, time.UTC))
+}
+
+func TestRecurrence_KeepsLocalClockTime(t *testing.T) {
+	location, err := time.LoadLocation("America/Sao_Paulo")
+	assert.NoError(t, err)
+	weekly := Recurrence{Frequency: RecurrenceWeekly}
+
+	next := weekly.Next(time.Date(2025, 3, 3, 9, 0, 0, 0, location), location)
+	assert.Equal(t, 9, next.Hour())
+	assert.Equal(t, time.Monday, next.Weekday())
+}
+
+func TestRecurrence_IsOver(t *testing.T) {
+	maxOccurrences := 3
+	endsAt := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	recurrence := Recurrence{Frequency: RecurrenceWeekly, EndsAt: &endsAt, MaxOccurrences: &maxOccurrences}
+
+	assert.False(t, recurrence.IsOver(endsAt, 2))
+	assert.True(t, recurrence.IsOver(endsAt.Add(time.Second), 2))
+	assert.True(t, recurrence.IsOver(endsAt, 3))
+}
@@ -0,0 +1,165 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ScheduledTransferStatus string
+
+const (
+	ScheduledTransferStatusScheduled ScheduledTransferStatus = "SCHEDULED"
+	ScheduledTransferStatusPaused    ScheduledTransferStatus = "PAUSED"
+	ScheduledTransferStatusExecuted  ScheduledTransferStatus = "EXECUTED"
+	ScheduledTransferStatusFailed    ScheduledTransferStatus = "FAILED"
+	ScheduledTransferStatusCompleted ScheduledTransferStatus = "COMPLETED"
+	ScheduledTransferStatusCancelled ScheduledTransferStatus = "CANCELLED"
+)
+
+// InsufficientBalancePolicy says what happens to an occurrence the payer
+// cannot afford.
+type InsufficientBalancePolicy string
+
+const (
+	InsufficientBalanceSkip  InsufficientBalancePolicy = "SKIP"
+	InsufficientBalanceRetry InsufficientBalancePolicy = "RETRY"
+)
+
+// ScheduledTransfer is a transfer the payer asked to be made at ExecuteAt,
+// or a standing order repeated by Recurrence, in which case ExecuteAt is its
+// next occurrence. The scheduler leases due items until LeasedUntil while it
+// executes them.
+type ScheduledTransfer struct {
+	ID                    int64                     `gorm:"primaryKey"`
+	PayerID               int64                     `gorm:"not null;index"`
+	PayeeID               int64                     `gorm:"not null"`
+	Amount                Money                     `gorm:"not null"`
+	Currency              string                    `gorm:"type:char(3);not null"`
+	ExecuteAt             time.Time                 `gorm:"not null;index:idx_scheduled_transfers_due,priority:2"`
+	Status                ScheduledTransferStatus   `gorm:"type:text;not null;default:'SCHEDULED';index:idx_scheduled_transfers_due,priority:1"`
+	Recurrence            Recurrence                `gorm:"embedded;embeddedPrefix:recurrence_"`
+	OnInsufficientBalance InsufficientBalancePolicy `gorm:"type:text;not null;default:'SKIP'"`
+	// Occurrences counts the occurrences of a standing order already
+	// executed or skipped.
+	Occurrences int `gorm:"not null;default:0"`
+	// Attempts counts the failed attempts of the current occurrence, retried
+	// at NextAttemptAt.
+	Attempts      int `gorm:"not null;default:0"`
+	NextAttemptAt *time.Time
+	LeasedUntil   *time.Time
+	// TransactionID and FailureReason describe the latest attempt.
+	TransactionID *int64
+	FailureReason string    `gorm:"type:text"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+func (s *ScheduledTransfer) AfterFind(*gorm.DB) error {
+	s.Amount = NewMoney(s.Amount.Cents, s.Currency)
+	return nil
+}
+
+func (s *ScheduledTransfer) IsRecurring() bool {
+	return !s.Recurrence.IsZero()
+}
+
+func (s *ScheduledTransfer) IsLeased(now time.Time) bool {
+	return s.LeasedUntil != nil && now.Before(*s.LeasedUntil)
+}
+
+// IsCancellable reports whether the scheduler has not started on the
+// transfer yet.
+func (s *ScheduledTransfer) IsCancellable(now time.Time) bool {
+	active := s.Status == ScheduledTransferStatusScheduled || s.Status == ScheduledTransferStatusPaused
+	return active && !s.IsLeased(now)
+}
+
+// DueAt is when the current occurrence is next attempted.
+func (s *ScheduledTransfer) DueAt() time.Time {
+	if s.NextAttemptAt != nil {
+		return *s.NextAttemptAt
+	}
+	return s.ExecuteAt
+}
+
+// CloseOccurrence records that the current occurrence is over. A one-off
+// transfer ends in outcome; a standing order moves on to its next
+// occurrence, or is COMPLETED once there is none.
+func (s *ScheduledTransfer) CloseOccurrence(outcome ScheduledTransferStatus, loc *time.Location) {
+	s.Attempts = 0
+	s.NextAttemptAt = nil
+	s.LeasedUntil = nil
+	if !s.IsRecurring() {
+		s.Status = outcome
+		return
+	}
+	s.Occurrences++
+	next := s.Recurrence.Next(s.ExecuteAt, loc)
+	if s.Recurrence.IsOver(next, s.Occurrences) {
+		s.Status = ScheduledTransferStatusCompleted
+		return
+	}
+	s.ExecuteAt = next
+}
+
+// RetryOccurrence attempts the current occurrence again at at.
+func (s *ScheduledTransfer) RetryOccurrence(at time.Time) {
+	s.Attempts++
+	s.NextAttemptAt = &at
+	s.LeasedUntil = nil
+}
+
+// SkipMissed moves a standing order to its first occurrence at or after
+// now without counting the occurrences it passes over.
+func (s *ScheduledTransfer) SkipMissed(now time.Time, loc *time.Location) {
+	if !s.IsRecurring() {
+		return
+	}
+	s.Attempts = 0
+	s.NextAttemptAt = nil
+	for s.ExecuteAt.Before(now) {
+		next := s.Recurrence.Next(s.ExecuteAt, loc)
+		if s.Recurrence.IsOver(next, s.Occurrences) {
+			s.Status = ScheduledTransferStatusCompleted
+			return
+		}
+		s.ExecuteAt = next
+	}
+}
+
+type ScheduledTransferRunStatus string
+
+const (
+	ScheduledTransferRunStatusRunning   ScheduledTransferRunStatus = "RUNNING"
+	ScheduledTransferRunStatusSucceeded ScheduledTransferRunStatus = "SUCCEEDED"
+	ScheduledTransferRunStatusFailed    ScheduledTransferRunStatus = "FAILED"
+)
+
+// ScheduledTransferRunFailure classifies why a run failed, so whether it is
+// retried does not depend on the wording of Error.
+type ScheduledTransferRunFailure string
+
+const (
+	ScheduledTransferRunFailureInsufficientBalance ScheduledTransferRunFailure = "INSUFFICIENT_BALANCE"
+	ScheduledTransferRunFailureInterrupted         ScheduledTransferRunFailure = "INTERRUPTED"
+	ScheduledTransferRunFailureTransferFailed      ScheduledTransferRunFailure = "TRANSFER_FAILED"
+)
+
+// ScheduledTransferRun records one attempt to execute an occurrence of a
+// scheduled transfer. There is at most one run per occurrence and attempt,
+// so an occurrence is never paid twice.
+type ScheduledTransferRun struct {
+	ID                  int64                      `gorm:"primaryKey"`
+	ScheduledTransferID int64                      `gorm:"not null;uniqueIndex:idx_scheduled_transfer_runs_attempt,priority:1"`
+	ScheduledFor        time.Time                  `gorm:"not null;uniqueIndex:idx_scheduled_transfer_runs_attempt,priority:2"`
+	Attempt             int                        `gorm:"not null;default:1;uniqueIndex:idx_scheduled_transfer_runs_attempt,priority:3"`
+	Status              ScheduledTransferRunStatus `gorm:"type:text;not null"`
+	TransactionID       *int64
+	Error               string    `gorm:"type:text"`
+	StartedAt           time.Time `gorm:"not null"`
+	FinishedAt          *time.Time
+	ScheduledTransfer   ScheduledTransfer `gorm:"foreignKey:ScheduledTransferID"`
+	// FailureCode is only set on failed runs.
+	FailureCode ScheduledTransferRunFailure `gorm:"type:text;not null;default:''"`
+}
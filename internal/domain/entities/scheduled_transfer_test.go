@@ -0,0 +1,57 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledTransfer_IsCancellable(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	leased := now.Add(time.Minute)
+	expired := now.Add(-time.Minute)
+
+	cases := []struct {
+		status      ScheduledTransferStatus
+		leasedUntil *time.Time
+		cancellable bool
+	}{
+		{ScheduledTransferStatusScheduled, nil, true},
+		{ScheduledTransferStatusScheduled, &expired, true},
+		{ScheduledTransferStatusScheduled, &leased, false},
+		{ScheduledTransferStatusPaused, nil, true},
+		{ScheduledTransferStatusExecuted, nil, false},
+		{ScheduledTransferStatusCompleted, nil, false},
+		{ScheduledTransferStatusFailed, nil, false},
+		{ScheduledTransferStatusCancelled, nil, false},
+	}
+	for _, c := range cases {
+		scheduled := ScheduledTransfer{Status: c.status, LeasedUntil: c.leasedUntil}
+		assert.Equal(t, c.cancellable, scheduled.IsCancellable(now), c.status)
+	}
+}
+
+func TestScheduledTransfer_CloseOccurrence(t *testing.T) {
+	maxOccurrences := 2
+	order := ScheduledTransfer{
+		ExecuteAt:  time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC),
+		Status:     ScheduledTransferStatusScheduled,
+		Recurrence: Recurrence{Frequency: RecurrenceWeekly, MaxOccurrences: &maxOccurrences},
+	}
+	retryAt := order.ExecuteAt.Add(time.Hour)
+	order.RetryOccurrence(retryAt)
+	assert.Equal(t, retryAt, order.DueAt())
+
+	order.CloseOccurrence(ScheduledTransferStatusFailed, time.UTC)
+	assert.Equal(t, ScheduledTransferStatusScheduled, order.Status)
+	assert.Equal(t, time.Date(2025, 1, 13, 9, 0, 0, 0, time.UTC), order.DueAt())
+	assert.Equal(t, 0, order.Attempts)
+
+	order.CloseOccurrence(ScheduledTransferStatusExecuted, time.UTC)
+	assert.Equal(t, ScheduledTransferStatusCompleted, order.Status)
+
+	oneOff := ScheduledTransfer{Status: ScheduledTransferStatusScheduled}
+	oneOff.CloseOccurrence(ScheduledTransferStatusExecuted, time.UTC)
+	assert.Equal(t, ScheduledTransferStatusExecuted, oneOff.Status)
+}
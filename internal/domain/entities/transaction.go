@@ -12,17 +12,178 @@ const (
 	TransactionStatusPending   TransactionStatus = "PENDING"
 	TransactionStatusCompleted TransactionStatus = "COMPLETED"
 	TransactionStatusFailed    TransactionStatus = "FAILED"
+
+	TransactionStatusAuthorizing       TransactionStatus = "AUTHORIZING"
+	TransactionStatusRefunded          TransactionStatus = "REFUNDED"
+	TransactionStatusPartiallyRefunded TransactionStatus = "PARTIALLY_REFUNDED"
+	TransactionStatusReversed          TransactionStatus = "REVERSED"
+	TransactionStatusCancelled         TransactionStatus = "CANCELLED"
+
+	// Two-phase transfers hold the payer's funds while AUTHORIZED, until they
+	// are captured (COMPLETED), voided or the hold expires.
+	TransactionStatusAuthorized TransactionStatus = "AUTHORIZED"
+	TransactionStatusVoided     TransactionStatus = "VOIDED"
+	TransactionStatusExpired    TransactionStatus = "EXPIRED"
+)
+
+// SettledStatuses are the statuses of transactions whose money has moved.
+var SettledStatuses = []TransactionStatus{
+	TransactionStatusCompleted,
+	TransactionStatusPartiallyRefunded,
+	TransactionStatusRefunded,
+}
+
+type TransactionType string
+
+const (
+	TransactionTypeTransfer TransactionType = "TRANSFER"
+	TransactionTypeDeposit  TransactionType = "DEPOSIT"
+	TransactionTypeRefund   TransactionType = "REFUND"
 )
 
 type Transaction struct {
-	ID         int64             `gorm:"primaryKey"`
-	SenderID   int64             `gorm:"not null;index"`
-	ReceiverID int64             `gorm:"not null;index"`
-	Amount     float64           `gorm:"not null"`
-	Status     TransactionStatus `gorm:"not null default 'PENDING'"`
-	Sender     User              `gorm:"foreignKey:SenderID"`
-	Receiver   User              `gorm:"foreignKey:ReceiverID"`
-	CreatedAt  time.Time         `gorm:"autoCreateTime"`
-	UpdatedAt  time.Time         `gorm:"autoUpdateTime"`
-	DeletedAt  gorm.DeletedAt    `gorm:"index"`
+	ID                    int64             `gorm:"primaryKey;index:idx_transactions_sender_history,priority:3;index:idx_transactions_receiver_history,priority:3"`
+	SenderID              int64             `gorm:"not null;index;index:idx_transactions_sender_history,priority:1;uniqueIndex:idx_transactions_sender_reference,priority:1"`
+	ReceiverID            int64             `gorm:"not null;index;index:idx_transactions_receiver_history,priority:1"`
+	Amount                Money             `gorm:"not null"`
+	Currency              string            `gorm:"type:char(3);not null;default:'BRL'"`
+	Type                  TransactionType   `gorm:"type:text;not null;default:'TRANSFER'"`
+	Status                TransactionStatus `gorm:"not null default 'PENDING'"`
+	OriginalTransactionID *int64            `gorm:"index"`
+	RefundedAmount        Money             `gorm:"not null;default:0"`
+	Fee                   Money             `gorm:"not null;default:0"`
+	FeeBreakdown          FeeBreakdown      `gorm:"type:jsonb;serializer:json"`
+	// The exchange fields are only set on transfers between wallets of
+	// different currencies: Amount leaves the sender at ExchangeRate and
+	// ReceivedAmount reaches the receiver.
+	ExchangeRate     *ExchangeRate
+	ReceivedAmount   *Money
+	ReceivedCurrency *string `gorm:"type:char(3)"`
+	ExchangeQuoteID  *int64
+	// The hold fields are only set on two-phase transfers. AuthorizedAmount is
+	// what the payer authorized, Amount what is eventually captured, and
+	// HeldAmount, fees included, stays reserved on the payer's wallet until
+	// the transfer is captured, voided or HoldExpiresAt passes.
+	AuthorizedAmount *Money
+	HeldAmount       Money                 `gorm:"not null;default:0"`
+	HoldExpiresAt    *time.Time            `gorm:"index"`
+	Authorization    AuthorizationDecision `gorm:"embedded;embeddedPrefix:authorization_"`
+	Sender           User                  `gorm:"foreignKey:SenderID"`
+	Receiver         User                  `gorm:"foreignKey:ReceiverID"`
+	CreatedAt        time.Time             `gorm:"autoCreateTime;index:idx_transactions_sender_history,priority:2;index:idx_transactions_receiver_history,priority:2"`
+	UpdatedAt        time.Time             `gorm:"autoUpdateTime"`
+	DeletedAt        gorm.DeletedAt        `gorm:"index"`
+	// Legs are only set on split transfers, which pay several payees at once.
+	Legs []TransactionLeg `gorm:"foreignKey:TransactionID"`
+	// Details are optional and only set on transfers.
+	Details TransferDetails `gorm:"embedded"`
+}
+
+// AfterFind tags the amounts with the transaction's currencies, which the
+// numeric columns do not store.
+func (t *Transaction) AfterFind(*gorm.DB) error {
+	t.Amount = NewMoney(t.Amount.Cents, t.Currency)
+	t.RefundedAmount = NewMoney(t.RefundedAmount.Cents, t.Currency)
+	t.Fee = NewMoney(t.Fee.Cents, t.Currency)
+	t.HeldAmount = NewMoney(t.HeldAmount.Cents, t.Currency)
+	if t.AuthorizedAmount != nil {
+		authorized := NewMoney(t.AuthorizedAmount.Cents, t.Currency)
+		t.AuthorizedAmount = &authorized
+	}
+	if t.ReceivedAmount != nil && t.ReceivedCurrency != nil {
+		received := NewMoney(t.ReceivedAmount.Cents, *t.ReceivedCurrency)
+		t.ReceivedAmount = &received
+	}
+	return nil
+}
+
+// IsConverted reports whether the sender and receiver of t use different
+// currencies.
+func (t *Transaction) IsConverted() bool {
+	return t.ExchangeRate != nil && t.ReceivedAmount != nil
+}
+
+// PayeeAmount is what the receiver of t gets, in the receiver's currency.
+func (t *Transaction) PayeeAmount() Money {
+	if t.IsConverted() {
+		return *t.ReceivedAmount
+	}
+	return t.Amount
+}
+
+// PayeeShare is what the receiver gives back when value of t is refunded.
+// Converted transfers use their original rate on the running refunded total,
+// so refunding the whole amount returns exactly what was received.
+func (t *Transaction) PayeeShare(value Money) (Money, error) {
+	if !t.IsConverted() {
+		return value, nil
+	}
+	refunded, err := t.RefundedAmount.Add(value)
+	if err != nil {
+		return Money{}, err
+	}
+	currency := t.ReceivedAmount.Currency
+	after, err := t.ExchangeRate.Convert(refunded, currency)
+	if err != nil {
+		return Money{}, err
+	}
+	before, err := t.ExchangeRate.Convert(t.RefundedAmount, currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return after.Sub(before)
+}
+
+// IsSplit reports whether t pays several payees, one per leg.
+func (t *Transaction) IsSplit() bool {
+	return len(t.Legs) > 0
+}
+
+// PayeeIDs lists who receives money from t: the payee of each leg of a split
+// transfer and ReceiverID otherwise.
+func (t *Transaction) PayeeIDs() []int64 {
+	if !t.IsSplit() {
+		return []int64{t.ReceiverID}
+	}
+	ids := make([]int64, 0, len(t.Legs))
+	for _, leg := range t.Legs {
+		ids = append(ids, leg.ReceiverID)
+	}
+	return ids
+}
+
+// IsHeld reports whether t is a two-phase transfer still waiting to be
+// captured or voided.
+func (t *Transaction) IsHeld() bool {
+	return t.Type == TransactionTypeTransfer && t.Status == TransactionStatusAuthorized
+}
+
+// IsHoldExpired reports whether the hold of t can no longer be captured.
+func (t *Transaction) IsHoldExpired(now time.Time) bool {
+	return t.HoldExpiresAt != nil && !now.Before(*t.HoldExpiresAt)
+}
+
+// IsRefundable reports whether t can still be refunded. Split transfers are
+// not, as no single payee received the whole amount.
+func (t *Transaction) IsRefundable() bool {
+	if t.Type != TransactionTypeTransfer || t.IsSplit() {
+		return false
+	}
+	return t.Status == TransactionStatusCompleted || t.Status == TransactionStatusPartiallyRefunded
+}
+
+func (t *Transaction) RefundableAmount() (Money, error) {
+	return t.Amount.Sub(t.RefundedAmount)
+}
+
+// RefundStatus is the status of a transfer once refunded has been paid back.
+func (t *Transaction) RefundStatus(refunded Money) (TransactionStatus, error) {
+	cmp, err := refunded.Cmp(t.Amount)
+	if err != nil {
+		return "", err
+	}
+	if cmp >= 0 {
+		return TransactionStatusRefunded, nil
+	}
+	return TransactionStatusPartiallyRefunded, nil
 }
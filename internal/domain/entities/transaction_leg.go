@@ -0,0 +1,75 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidSplit = errors.New("invalid split")
+
+// TransactionLeg is what one payee of a split transfer receives. The legs of
+// a transfer add up to its Amount and the first one is its ReceiverID's.
+type TransactionLeg struct {
+	ID            int64     `gorm:"primaryKey"`
+	TransactionID int64     `gorm:"not null;uniqueIndex:idx_transaction_legs_payee,priority:1"`
+	ReceiverID    int64     `gorm:"not null;index;uniqueIndex:idx_transaction_legs_payee,priority:2"`
+	Amount        Money     `gorm:"not null"`
+	Currency      string    `gorm:"type:char(3);not null;default:'BRL'"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+func (l *TransactionLeg) AfterFind(*gorm.DB) error {
+	l.Amount = NewMoney(l.Amount.Cents, l.Currency)
+	return nil
+}
+
+// Percentage is a share in hundredths of a percent: 2500 is 25%.
+type Percentage int64
+
+const wholePercentage Percentage = 100 * centsPerUnit
+
+// ParsePercentage reads a share such as "33.33" with at most two decimals.
+func ParsePercentage(value string) (Percentage, error) {
+	parsed, err := ParseMoney(value, DefaultCurrency)
+	if err != nil || !parsed.IsPositive() || parsed.Cents > int64(wholePercentage) {
+		return 0, fmt.Errorf("%w: percentage %q", ErrInvalidSplit, value)
+	}
+	return Percentage(parsed.Cents), nil
+}
+
+func (p Percentage) String() string {
+	return MoneyFromCents(int64(p)).String() + "%"
+}
+
+// SplitByPercentages divides total in shares that must add up to 100%. Each
+// share is rounded down and the cents left over go one each to the first
+// shares, so the parts always add up to total.
+func SplitByPercentages(total Money, shares []Percentage) ([]Money, error) {
+	var sum Percentage
+	for _, share := range shares {
+		if share <= 0 {
+			return nil, fmt.Errorf("%w: percentage %s", ErrInvalidSplit, share)
+		}
+		sum += share
+	}
+	if sum != wholePercentage {
+		return nil, fmt.Errorf("%w: percentages add up to %s", ErrInvalidSplit, sum)
+	}
+
+	parts := make([]Money, len(shares))
+	left := total.Cents
+	for i, share := range shares {
+		part := new(big.Int).Mul(big.NewInt(total.Cents), big.NewInt(int64(share)))
+		part.Quo(part, big.NewInt(int64(wholePercentage)))
+		parts[i] = NewMoney(part.Int64(), total.Currency)
+		left -= parts[i].Cents
+	}
+	for i := 0; left > 0; i, left = i+1, left-1 {
+		parts[i].Cents++
+	}
+	return parts, nil
+}
@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitByPercentages_GivesLeftoverCentsToFirstShares(t *testing.T) {
+	parts, err := SplitByPercentages(MoneyFromCents(1000), []Percentage{3333, 3333, 3334})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Money{MoneyFromCents(334), MoneyFromCents(333), MoneyFromCents(333)}, parts)
+}
+
+func TestSplitByPercentages_RequiresWhole(t *testing.T) {
+	_, err := SplitByPercentages(MoneyFromCents(1000), []Percentage{5000, 4999})
+	assert.ErrorIs(t, err, ErrInvalidSplit)
+
+	_, err = SplitByPercentages(MoneyFromCents(1000), []Percentage{10001, -1})
+	assert.ErrorIs(t, err, ErrInvalidSplit)
+}
+
+func TestParsePercentage(t *testing.T) {
+	share, err := ParsePercentage("33.33")
+	assert.NoError(t, err)
+	assert.Equal(t, Percentage(3333), share)
+
+	for _, value := range []string{"0", "-5", "100.01", "1.234", "abc"} {
+		_, err := ParsePercentage(value)
+		assert.ErrorIs(t, err, ErrInvalidSplit, value)
+	}
+}
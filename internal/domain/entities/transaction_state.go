@@ -0,0 +1,103 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidTransition        = errors.New("invalid transaction status transition")
+	ErrUnknownTransactionStatus = errors.New("unknown transaction status")
+)
+
+// transactionTransitions lists, for every status, the statuses a transaction
+// may move to next. Statuses without successors are terminal.
+var transactionTransitions = map[TransactionStatus][]TransactionStatus{
+	TransactionStatusPending: {
+		TransactionStatusAuthorizing,
+		TransactionStatusCompleted,
+		TransactionStatusFailed,
+		TransactionStatusCancelled,
+	},
+	TransactionStatusAuthorizing: {
+		TransactionStatusPending,
+		TransactionStatusAuthorized,
+		TransactionStatusCompleted,
+		TransactionStatusFailed,
+		TransactionStatusCancelled,
+	},
+	TransactionStatusAuthorized: {
+		TransactionStatusCompleted,
+		TransactionStatusVoided,
+		TransactionStatusExpired,
+		TransactionStatusCancelled,
+	},
+	TransactionStatusCompleted: {
+		TransactionStatusPartiallyRefunded,
+		TransactionStatusRefunded,
+		TransactionStatusReversed,
+	},
+	TransactionStatusPartiallyRefunded: {
+		TransactionStatusRefunded,
+	},
+	TransactionStatusFailed:    {},
+	TransactionStatusRefunded:  {},
+	TransactionStatusReversed:  {},
+	TransactionStatusCancelled: {},
+	TransactionStatusVoided:    {},
+	TransactionStatusExpired:   {},
+}
+
+type InvalidTransitionError struct {
+	From TransactionStatus
+	To   TransactionStatus
+}
+
+func (e *InvalidTransitionError) Error() string {
+	return fmt.Sprintf("%s: %s -> %s", ErrInvalidTransition, e.From, e.To)
+}
+
+func (e *InvalidTransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+func (s TransactionStatus) IsKnown() bool {
+	_, ok := transactionTransitions[s]
+	return ok
+}
+
+func (s TransactionStatus) IsTerminal() bool {
+	return len(transactionTransitions[s]) == 0
+}
+
+func ValidateTransition(from, to TransactionStatus) error {
+	if !from.IsKnown() {
+		return fmt.Errorf("%w: %q", ErrUnknownTransactionStatus, from)
+	}
+	if !to.IsKnown() {
+		return fmt.Errorf("%w: %q", ErrUnknownTransactionStatus, to)
+	}
+	for _, next := range transactionTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return &InvalidTransitionError{From: from, To: to}
+}
+
+// TransactionStatusChange is one audited row of a transaction's status
+// history. FromStatus is empty for the row written when the transaction is
+// created.
+type TransactionStatusChange struct {
+	ID            int64             `gorm:"primaryKey"`
+	TransactionID int64             `gorm:"not null;index"`
+	FromStatus    TransactionStatus `gorm:"type:text"`
+	ToStatus      TransactionStatus `gorm:"type:text;not null"`
+	Reason        string            `gorm:"type:text"`
+	CreatedAt     time.Time         `gorm:"autoCreateTime"`
+}
+
+func (TransactionStatusChange) TableName() string {
+	return "transaction_status_history"
+}
@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateTransition_Allowed(t *testing.T) {
+	allowed := [][2]TransactionStatus{
+		{TransactionStatusPending, TransactionStatusCompleted},
+		{TransactionStatusPending, TransactionStatusAuthorizing},
+		{TransactionStatusPending, TransactionStatusCancelled},
+		{TransactionStatusAuthorizing, TransactionStatusFailed},
+		{TransactionStatusCompleted, TransactionStatusPartiallyRefunded},
+		{TransactionStatusCompleted, TransactionStatusReversed},
+		{TransactionStatusPartiallyRefunded, TransactionStatusRefunded},
+		{TransactionStatusAuthorizing, TransactionStatusAuthorized},
+		{TransactionStatusAuthorized, TransactionStatusCompleted},
+		{TransactionStatusAuthorized, TransactionStatusVoided},
+		{TransactionStatusAuthorized, TransactionStatusExpired},
+		{TransactionStatusAuthorized, TransactionStatusCancelled},
+	}
+	for _, transition := range allowed {
+		assert.NoError(t, ValidateTransition(transition[0], transition[1]), "%s -> %s", transition[0], transition[1])
+	}
+}
+
+func TestValidateTransition_Rejected(t *testing.T) {
+	rejected := [][2]TransactionStatus{
+		{TransactionStatusFailed, TransactionStatusCompleted},
+		{TransactionStatusCompleted, TransactionStatusPending},
+		{TransactionStatusRefunded, TransactionStatusPartiallyRefunded},
+		{TransactionStatusCancelled, TransactionStatusPending},
+		{TransactionStatusCompleted, TransactionStatusCompleted},
+		{TransactionStatusPending, TransactionStatusAuthorized},
+		{TransactionStatusVoided, TransactionStatusCompleted},
+		{TransactionStatusExpired, TransactionStatusAuthorized},
+	}
+	for _, transition := range rejected {
+		err := ValidateTransition(transition[0], transition[1])
+		assert.ErrorIs(t, err, ErrInvalidTransition)
+
+		var transitionErr *InvalidTransitionError
+		assert.True(t, errors.As(err, &transitionErr))
+		assert.Equal(t, transition[0], transitionErr.From)
+		assert.Equal(t, transition[1], transitionErr.To)
+	}
+}
+
+func TestValidateTransition_UnknownStatus(t *testing.T) {
+	assert.ErrorIs(t, ValidateTransition("BOGUS", TransactionStatusCompleted), ErrUnknownTransactionStatus)
+	assert.ErrorIs(t, ValidateTransition(TransactionStatusPending, "BOGUS"), ErrUnknownTransactionStatus)
+}
+
+func TestTransactionStatus_IsTerminal(t *testing.T) {
+	assert.False(t, TransactionStatusPending.IsTerminal())
+	assert.False(t, TransactionStatusCompleted.IsTerminal())
+	assert.True(t, TransactionStatusFailed.IsTerminal())
+	assert.True(t, TransactionStatusRefunded.IsTerminal())
+	assert.True(t, TransactionStatusCancelled.IsTerminal())
+	assert.False(t, TransactionStatusAuthorized.IsTerminal())
+	assert.True(t, TransactionStatusVoided.IsTerminal())
+	assert.True(t, TransactionStatusExpired.IsTerminal())
+}
@@ -0,0 +1,78 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_IsRefundable(t *testing.T) {
+	cases := []struct {
+		transactionType TransactionType
+		status          TransactionStatus
+		refundable      bool
+	}{
+		{TransactionTypeTransfer, TransactionStatusCompleted, true},
+		{TransactionTypeTransfer, TransactionStatusPartiallyRefunded, true},
+		{TransactionTypeTransfer, TransactionStatusRefunded, false},
+		{TransactionTypeTransfer, TransactionStatusPending, false},
+		{TransactionTypeTransfer, TransactionStatusFailed, false},
+		{TransactionTypeDeposit, TransactionStatusCompleted, false},
+		{TransactionTypeRefund, TransactionStatusCompleted, false},
+	}
+	for _, c := range cases {
+		transaction := &Transaction{Type: c.transactionType, Status: c.status}
+		assert.Equal(t, c.refundable, transaction.IsRefundable(), "%s %s", c.transactionType, c.status)
+	}
+}
+
+func TestTransaction_RefundableAmount(t *testing.T) {
+	transaction := &Transaction{Amount: MoneyFromCents(10000), RefundedAmount: MoneyFromCents(2500)}
+
+	remaining, err := transaction.RefundableAmount()
+	assert.NoError(t, err)
+	assert.Equal(t, MoneyFromCents(7500), remaining)
+}
+
+func TestTransaction_RefundStatus(t *testing.T) {
+	transaction := &Transaction{Amount: MoneyFromCents(10000)}
+
+	status, err := transaction.RefundStatus(MoneyFromCents(9999))
+	assert.NoError(t, err)
+	assert.Equal(t, TransactionStatusPartiallyRefunded, status)
+	status, err = transaction.RefundStatus(MoneyFromCents(10000))
+	assert.NoError(t, err)
+	assert.Equal(t, TransactionStatusRefunded, status)
+	_, err = transaction.RefundStatus(NewMoney(10000, "USD"))
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+func TestTransaction_PayeeShare_ConvertedRefundsAddUpToReceived(t *testing.T) {
+	rate, _ := ParseExchangeRate("5.4321")
+	received := NewMoney(5438, "BRL")
+	transaction := &Transaction{
+		Amount:         NewMoney(1001, "USD"),
+		RefundedAmount: NewMoney(0, "USD"),
+		ExchangeRate:   &rate,
+		ReceivedAmount: &received,
+	}
+
+	total := NewMoney(0, "BRL")
+	for _, cents := range []int64{333, 333, 335} {
+		value := NewMoney(cents, "USD")
+		share, err := transaction.PayeeShare(value)
+		assert.NoError(t, err)
+		total, _ = total.Add(share)
+		transaction.RefundedAmount, _ = transaction.RefundedAmount.Add(value)
+	}
+	assert.Equal(t, received, total)
+}
+
+func TestTransaction_PayeeShare_SameCurrency(t *testing.T) {
+	transaction := &Transaction{Amount: MoneyFromCents(10000)}
+
+	share, err := transaction.PayeeShare(MoneyFromCents(2500))
+	assert.NoError(t, err)
+	assert.Equal(t, MoneyFromCents(2500), share)
+	assert.Equal(t, MoneyFromCents(10000), transaction.PayeeAmount())
+}
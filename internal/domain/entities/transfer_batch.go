@@ -0,0 +1,143 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TransferBatchMode says what happens to a batch when one of its items
+// cannot be made.
+type TransferBatchMode string
+
+const (
+	// TransferBatchAllOrNothing settles every item together or none of them.
+	TransferBatchAllOrNothing TransferBatchMode = "ALL_OR_NOTHING"
+	// TransferBatchBestEffort makes each item on its own.
+	TransferBatchBestEffort TransferBatchMode = "BEST_EFFORT"
+)
+
+type TransferBatchStatus string
+
+const (
+	TransferBatchStatusQueued             TransferBatchStatus = "QUEUED"
+	TransferBatchStatusProcessing         TransferBatchStatus = "PROCESSING"
+	TransferBatchStatusCompleted          TransferBatchStatus = "COMPLETED"
+	TransferBatchStatusPartiallyCompleted TransferBatchStatus = "PARTIALLY_COMPLETED"
+	TransferBatchStatusFailed             TransferBatchStatus = "FAILED"
+)
+
+type TransferBatchItemStatus string
+
+const (
+	TransferBatchItemStatusQueued     TransferBatchItemStatus = "QUEUED"
+	TransferBatchItemStatusProcessing TransferBatchItemStatus = "PROCESSING"
+	TransferBatchItemStatusCompleted  TransferBatchItemStatus = "COMPLETED"
+	// TransferBatchItemStatusPending items made a transfer that is held for
+	// review.
+	TransferBatchItemStatusPending TransferBatchItemStatus = "PENDING"
+	TransferBatchItemStatusFailed  TransferBatchItemStatus = "FAILED"
+	// TransferBatchItemStatusInterrupted items were left in flight by a
+	// worker that stopped; their transfer was failed before it could settle.
+	TransferBatchItemStatusInterrupted TransferBatchItemStatus = "INTERRUPTED"
+)
+
+// TransferBatch is a set of transfers from one payer, made in the
+// background. Total is the sum of the item amounts, in the payer's currency
+// and without fees. The worker processing it leases it until LeasedUntil.
+type TransferBatch struct {
+	ID            int64               `gorm:"primaryKey"`
+	PayerID       int64               `gorm:"not null;index"`
+	Mode          TransferBatchMode   `gorm:"type:text;not null"`
+	Status        TransferBatchStatus `gorm:"type:text;not null;default:'QUEUED';index"`
+	Total         Money               `gorm:"not null"`
+	Currency      string              `gorm:"type:char(3);not null"`
+	FailureReason string              `gorm:"type:text"`
+	LeasedUntil   *time.Time
+	CreatedAt     time.Time           `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time           `gorm:"autoUpdateTime"`
+	Items         []TransferBatchItem `gorm:"foreignKey:BatchID"`
+	// ClaimToken identifies the claim of the worker processing the batch.
+	ClaimToken string `gorm:"type:text;not null;default:''"`
+}
+
+func (b *TransferBatch) AfterFind(*gorm.DB) error {
+	b.Total = NewMoney(b.Total.Cents, b.Currency)
+	return nil
+}
+
+func (b *TransferBatch) IsFinished() bool {
+	switch b.Status {
+	case TransferBatchStatusCompleted, TransferBatchStatusPartiallyCompleted, TransferBatchStatusFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Settled is what the items that moved money add up to.
+func (b *TransferBatch) Settled() Money {
+	settled := NewMoney(0, b.Currency)
+	for _, item := range b.Items {
+		if item.Status == TransferBatchItemStatusCompleted {
+			settled.Cents += item.Amount.Cents
+		}
+	}
+	return settled
+}
+
+// FinishedPayload summarises the finished batch for its payer.
+func (b *TransferBatch) FinishedPayload() TransferBatchFinishedPayload {
+	return TransferBatchFinishedPayload{PayerID: b.PayerID, BatchID: b.ID, Status: b.Status, Amount: b.Settled()}
+}
+
+// Finish sets the final status of the batch from its items: COMPLETED when
+// none failed or was interrupted, FAILED when all did and
+// PARTIALLY_COMPLETED otherwise.
+func (b *TransferBatch) Finish() {
+	failed := 0
+	for _, item := range b.Items {
+		switch item.Status {
+		case TransferBatchItemStatusFailed, TransferBatchItemStatusInterrupted:
+			failed++
+		}
+	}
+	switch failed {
+	case 0:
+		b.Status = TransferBatchStatusCompleted
+	case len(b.Items):
+		b.Status = TransferBatchStatusFailed
+	default:
+		b.Status = TransferBatchStatusPartiallyCompleted
+	}
+	b.LeasedUntil = nil
+}
+
+// TransferBatchItem is one transfer of a batch, numbered by Position from 0.
+type TransferBatchItem struct {
+	ID            int64                   `gorm:"primaryKey"`
+	BatchID       int64                   `gorm:"not null;uniqueIndex:idx_transfer_batch_items_position,priority:1"`
+	Position      int                     `gorm:"not null;uniqueIndex:idx_transfer_batch_items_position,priority:2"`
+	PayeeID       int64                   `gorm:"not null"`
+	Amount        Money                   `gorm:"not null"`
+	Currency      string                  `gorm:"type:char(3);not null"`
+	Status        TransferBatchItemStatus `gorm:"type:text;not null;default:'QUEUED'"`
+	TransactionID *int64
+	FailureReason string    `gorm:"type:text"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+func (i *TransferBatchItem) AfterFind(*gorm.DB) error {
+	i.Amount = NewMoney(i.Amount.Cents, i.Currency)
+	return nil
+}
+
+func (i *TransferBatchItem) Fail(reason string) {
+	i.Status = TransferBatchItemStatusFailed
+	i.FailureReason = reason
+}
+
+func (i *TransferBatchItem) Interrupt(reason string) {
+	i.Status = TransferBatchItemStatusInterrupted
+	i.FailureReason = reason
+}
@@ -0,0 +1,49 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferBatch_Finish(t *testing.T) {
+	completed := TransferBatchItemStatusCompleted
+	pending := TransferBatchItemStatusPending
+	failed := TransferBatchItemStatusFailed
+	interrupted := TransferBatchItemStatusInterrupted
+
+	cases := []struct {
+		items  []TransferBatchItemStatus
+		status TransferBatchStatus
+	}{
+		{[]TransferBatchItemStatus{completed, completed}, TransferBatchStatusCompleted},
+		{[]TransferBatchItemStatus{completed, pending}, TransferBatchStatusCompleted},
+		{[]TransferBatchItemStatus{completed, failed}, TransferBatchStatusPartiallyCompleted},
+		{[]TransferBatchItemStatus{failed, failed}, TransferBatchStatusFailed},
+		{[]TransferBatchItemStatus{completed, interrupted}, TransferBatchStatusPartiallyCompleted},
+		{[]TransferBatchItemStatus{interrupted, failed}, TransferBatchStatusFailed},
+	}
+	for _, c := range cases {
+		leasedUntil := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		batch := TransferBatch{Status: TransferBatchStatusProcessing, LeasedUntil: &leasedUntil}
+		for _, status := range c.items {
+			batch.Items = append(batch.Items, TransferBatchItem{Status: status})
+		}
+		batch.Finish()
+		assert.Equal(t, c.status, batch.Status, c.items)
+		assert.Nil(t, batch.LeasedUntil)
+		assert.True(t, batch.IsFinished())
+	}
+}
+
+func TestTransferBatch_SettledCountsCompletedItems(t *testing.T) {
+	batch := TransferBatch{Currency: DefaultCurrency, Items: []TransferBatchItem{
+		{Amount: MoneyFromCents(1000), Status: TransferBatchItemStatusCompleted},
+		{Amount: MoneyFromCents(2000), Status: TransferBatchItemStatusPending},
+		{Amount: MoneyFromCents(3000), Status: TransferBatchItemStatusFailed},
+		{Amount: MoneyFromCents(4000), Status: TransferBatchItemStatusCompleted},
+	}}
+
+	assert.Equal(t, MoneyFromCents(5000), batch.Settled())
+}
@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+var ErrInvalidTransferDetails = errors.New("invalid transfer details")
+
+const (
+	MaxDescriptionLength       = 255
+	MaxExternalReferenceLength = 64
+	MaxMetadataKeys            = 20
+	MaxMetadataKeyLength       = 40
+	MaxMetadataValueLength     = 500
+)
+
+// TransferDetails is what the payer says about a transfer to reconcile it
+// with their own records. ExternalReference, such as an order number, is
+// unique among the payer's transfers that did not fail, so a failed one can
+// be retried under the same reference.
+type TransferDetails struct {
+	Description       string            `json:"description,omitempty" gorm:"type:text;not null;default:''"`
+	ExternalReference *string           `json:"external_reference,omitempty" gorm:"type:text;uniqueIndex:idx_transactions_sender_reference,priority:2,where:status <> 'FAILED'"`
+	Metadata          map[string]string `json:"metadata,omitempty" gorm:"type:jsonb;serializer:json"`
+}
+
+func (d TransferDetails) Validate() error {
+	if utf8.RuneCountInString(d.Description) > MaxDescriptionLength {
+		return fmt.Errorf("%w: description must have at most %d characters", ErrInvalidTransferDetails, MaxDescriptionLength)
+	}
+	if d.ExternalReference != nil {
+		if length := utf8.RuneCountInString(*d.ExternalReference); length == 0 || length > MaxExternalReferenceLength {
+			return fmt.Errorf("%w: external reference must have 1 to %d characters", ErrInvalidTransferDetails, MaxExternalReferenceLength)
+		}
+	}
+	if len(d.Metadata) > MaxMetadataKeys {
+		return fmt.Errorf("%w: metadata must have at most %d keys", ErrInvalidTransferDetails, MaxMetadataKeys)
+	}
+	for key, value := range d.Metadata {
+		if length := utf8.RuneCountInString(key); length == 0 || length > MaxMetadataKeyLength {
+			return fmt.Errorf("%w: metadata keys must have 1 to %d characters", ErrInvalidTransferDetails, MaxMetadataKeyLength)
+		}
+		if utf8.RuneCountInString(value) > MaxMetadataValueLength {
+			return fmt.Errorf("%w: metadata value of %q must have at most %d characters", ErrInvalidTransferDetails, key, MaxMetadataValueLength)
+		}
+	}
+	return nil
+}
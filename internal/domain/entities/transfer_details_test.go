@@ -0,0 +1,33 @@
+package entities
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferDetails_Validate(t *testing.T) {
+	reference := "order-1234"
+	assert.NoError(t, TransferDetails{}.Validate())
+	assert.NoError(t, TransferDetails{Description: "Order 1234", ExternalReference: &reference, Metadata: map[string]string{"channel": "web"}}.Validate())
+
+	empty := ""
+	long := strings.Repeat("a", MaxExternalReferenceLength+1)
+	tooManyKeys := make(map[string]string, MaxMetadataKeys+1)
+	for i := 0; i <= MaxMetadataKeys; i++ {
+		tooManyKeys[strings.Repeat("k", i+1)] = "v"
+	}
+	invalid := map[string]TransferDetails{
+		"long description":    {Description: strings.Repeat("a", MaxDescriptionLength+1)},
+		"empty reference":     {ExternalReference: &empty},
+		"long reference":      {ExternalReference: &long},
+		"too many keys":       {Metadata: tooManyKeys},
+		"empty key":           {Metadata: map[string]string{"": "v"}},
+		"long key":            {Metadata: map[string]string{strings.Repeat("k", MaxMetadataKeyLength+1): "v"}},
+		"long metadata value": {Metadata: map[string]string{"note": strings.Repeat("v", MaxMetadataValueLength+1)}},
+	}
+	for name, details := range invalid {
+		assert.ErrorIs(t, details.Validate(), ErrInvalidTransferDetails, name)
+	}
+}
@@ -0,0 +1,73 @@
+package entities
+
+import "time"
+
+type LimitPeriod string
+
+const (
+	LimitPeriodHour  LimitPeriod = "HOUR"
+	LimitPeriodDay   LimitPeriod = "DAY"
+	LimitPeriodMonth LimitPeriod = "MONTH"
+)
+
+// LimitPeriods are the periods every settled transfer is counted in.
+var LimitPeriods = []LimitPeriod{LimitPeriodHour, LimitPeriodDay, LimitPeriodMonth}
+
+// Bounds returns the calendar hour, day or month of loc containing t.
+func (p LimitPeriod) Bounds(t time.Time, loc *time.Location) (start, end time.Time) {
+	t = t.In(loc)
+	switch p {
+	case LimitPeriodHour:
+		start = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+		return start, start.Add(time.Hour)
+	case LimitPeriodMonth:
+		start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0)
+	default:
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 0, 1)
+	}
+}
+
+// TransferLimits caps what a user may send. A nil limit does not apply.
+type TransferLimits struct {
+	SingleMax     *Money
+	DailyAmount   *Money
+	MonthlyAmount *Money
+	HourlyCount   *int
+}
+
+// Merge returns l with every limit set in override replacing its own.
+func (l TransferLimits) Merge(override TransferLimits) TransferLimits {
+	if override.SingleMax != nil {
+		l.SingleMax = override.SingleMax
+	}
+	if override.DailyAmount != nil {
+		l.DailyAmount = override.DailyAmount
+	}
+	if override.MonthlyAmount != nil {
+		l.MonthlyAmount = override.MonthlyAmount
+	}
+	if override.HourlyCount != nil {
+		l.HourlyCount = override.HourlyCount
+	}
+	return l
+}
+
+// UserTransferLimit overrides the limits of a user's wallet type.
+type UserTransferLimit struct {
+	UserID         int64 `gorm:"primaryKey;autoIncrement:false"`
+	TransferLimits `gorm:"embedded"`
+	CreatedAt      time.Time `gorm:"autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+}
+
+// TransferCounter totals the transfers a user settled in one period.
+type TransferCounter struct {
+	UserID      int64       `gorm:"primaryKey;autoIncrement:false"`
+	Period      LimitPeriod `gorm:"primaryKey;type:text"`
+	PeriodStart time.Time   `gorm:"primaryKey"`
+	Amount      Money       `gorm:"not null;default:0"`
+	Count       int         `gorm:"not null;default:0"`
+	UpdatedAt   time.Time   `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,41 @@
+package entities
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitPeriod_Bounds(t *testing.T) {
+	loc := time.FixedZone("BRT", -3*60*60)
+	now := time.Date(2024, time.January, 31, 2, 30, 0, 0, time.UTC) // 23:30 on Jan 30 in BRT
+
+	start, end := LimitPeriodHour.Bounds(now, loc)
+	assert.True(t, start.Equal(time.Date(2024, time.January, 30, 23, 0, 0, 0, loc)))
+	assert.True(t, end.Equal(time.Date(2024, time.January, 31, 0, 0, 0, 0, loc)))
+
+	start, end = LimitPeriodDay.Bounds(now, loc)
+	assert.True(t, start.Equal(time.Date(2024, time.January, 30, 0, 0, 0, 0, loc)))
+	assert.True(t, end.Equal(time.Date(2024, time.January, 31, 0, 0, 0, 0, loc)))
+
+	start, end = LimitPeriodMonth.Bounds(now, loc)
+	assert.True(t, start.Equal(time.Date(2024, time.January, 1, 0, 0, 0, 0, loc)))
+	assert.True(t, end.Equal(time.Date(2024, time.February, 1, 0, 0, 0, 0, loc)))
+}
+
+func TestTransferLimits_Merge(t *testing.T) {
+	single := MoneyFromCents(100000)
+	daily := MoneyFromCents(500000)
+	overrideDaily := MoneyFromCents(2000000)
+	hourly := 10
+
+	defaults := TransferLimits{SingleMax: &single, DailyAmount: &daily, HourlyCount: &hourly}
+	merged := defaults.Merge(TransferLimits{DailyAmount: &overrideDaily})
+
+	assert.Equal(t, &single, merged.SingleMax)
+	assert.Equal(t, &overrideDaily, merged.DailyAmount)
+	assert.Nil(t, merged.MonthlyAmount)
+	assert.Equal(t, &hourly, merged.HourlyCount)
+	assert.Equal(t, &daily, defaults.DailyAmount)
+}
@@ -11,14 +11,48 @@ type WalletType string
 const (
 	CommonWallet   WalletType = "COMMON"
 	MerchantWallet WalletType = "MERCHANT"
+	SystemWallet   WalletType = "SYSTEM"
+)
+
+type SystemAccount string
+
+const (
+	SystemAccountFunding SystemAccount = "FUNDING"
+	SystemAccountRevenue SystemAccount = "REVENUE"
+	// SystemAccountExchange takes one currency in and pays another out on
+	// transfers between wallets of different currencies.
+	SystemAccountExchange SystemAccount = "EXCHANGE"
 )
 
 type Wallet struct {
-	ID        int64          `gorm:"primaryKey"`
-	OwnerID   int64          `gorm:"not null;index"`
-	Balance   float64        `gorm:"default:0.00"`
-	Type      WalletType     `gorm:"type:text;default:'COMMON'"`
-	CreatedAt time.Time      `gorm:"autoCreateTime"`
-	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
-	DeletedAt gorm.DeletedAt `gorm:"index"`
+	ID            int64          `gorm:"primaryKey"`
+	OwnerID       int64          `gorm:"not null;index"`
+	Balance       Money          `gorm:"default:0.00"`
+	HeldBalance   Money          `gorm:"not null;default:0"`
+	Currency      string         `gorm:"type:char(3);not null;default:'BRL';uniqueIndex:idx_wallets_system_account_currency,priority:2"`
+	Type          WalletType     `gorm:"type:text;default:'COMMON'"`
+	Version       int64          `gorm:"not null;default:0"`
+	SystemAccount *SystemAccount `gorm:"type:text;uniqueIndex:idx_wallets_system_account_currency,priority:1"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt     gorm.DeletedAt `gorm:"index"`
+}
+
+// CurrencyCode is the currency of the wallet, the default one if unset.
+func (w *Wallet) CurrencyCode() string {
+	return normalizeCurrency(w.Currency)
+}
+
+// AvailableBalance is what the wallet can still spend: its balance minus
+// HeldBalance, reserved by authorized transfers waiting to be captured.
+func (w *Wallet) AvailableBalance() Money {
+	return NewMoney(w.Balance.Cents-w.HeldBalance.Cents, w.CurrencyCode())
+}
+
+// AfterFind tags the balances with the wallet's currency, which the numeric
+// columns do not store.
+func (w *Wallet) AfterFind(*gorm.DB) error {
+	w.Balance = NewMoney(w.Balance.Cents, w.Currency)
+	w.HeldBalance = NewMoney(w.HeldBalance.Cents, w.Currency)
+	return nil
 }
@@ -1,7 +1,38 @@
 package port
 
-import "context"
+import (
+	"context"
+
+	"go-transfer/internal/domain/entities"
+)
+
+// ClientMetadata describes the client that requested a transfer.
+type ClientMetadata struct {
+	IPAddress string
+	UserAgent string
+	DeviceID  string
+}
+
+type AuthorizationRequest struct {
+	TransferID      int64
+	PayerID         int64
+	PayeeID         int64
+	Amount          entities.Money
+	PayerWalletType entities.WalletType
+	PayeeWalletType entities.WalletType
+	Client          ClientMetadata
+	// Splits is only set on split transfers and lists every payee, the first
+	// one being PayeeID.
+	Splits []AuthorizationSplit
+}
+
+// AuthorizationSplit is the share of one payee of a split transfer.
+type AuthorizationSplit struct {
+	PayeeID         int64
+	Amount          entities.Money
+	PayeeWalletType entities.WalletType
+}
 
 type AuthorizationService interface {
-	Authorize(ctx context.Context) (bool, error)
+	Authorize(ctx context.Context, request AuthorizationRequest) (entities.AuthorizationDecision, error)
 }
@@ -0,0 +1,22 @@
+package port
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+)
+
+var (
+	ErrExchangeQuoteNotFound = errors.New("exchange quote not found")
+	ErrExchangeQuoteUsed     = errors.New("exchange quote already used")
+)
+
+type ExchangeQuoteRepository interface {
+	Create(ctx context.Context, quote *entities.ExchangeQuote) error
+	GetByID(ctx context.Context, id int64) (*entities.ExchangeQuote, error)
+	// MarkUsed spends the quote, failing with ErrExchangeQuoteUsed if another
+	// transfer already did.
+	MarkUsed(ctx context.Context, id int64, at time.Time) error
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+	"errors"
+
+	"go-transfer/internal/domain/entities"
+)
+
+var ErrExchangeRateNotFound = errors.New("exchange rate not found")
+
+type ExchangeRateProvider interface {
+	// Rate returns how much one unit of from is worth in to.
+	Rate(ctx context.Context, from, to string) (entities.ExchangeRate, error)
+}
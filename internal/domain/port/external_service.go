@@ -0,0 +1,7 @@
+package port
+
+import "errors"
+
+// ErrServiceUnavailable marks failures of an external dependency, such as a
+// timeout, a 5xx response or an open circuit breaker.
+var ErrServiceUnavailable = errors.New("external service unavailable")
@@ -0,0 +1,26 @@
+package port
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+)
+
+var (
+	ErrIdempotencyKeyExists      = errors.New("idempotency key already exists")
+	ErrIdempotencyRecordNotFound = errors.New("idempotency record not found")
+)
+
+type IdempotencyRepository interface {
+	Create(ctx context.Context, record *entities.IdempotencyRecord) error
+	GetByKey(ctx context.Context, key string) (*entities.IdempotencyRecord, error)
+	// Replace resets record.Key to record when the stored record expired or was
+	// abandoned by a request with the same hash. It returns
+	// ErrIdempotencyKeyExists when another request holds the key.
+	Replace(ctx context.Context, record *entities.IdempotencyRecord, now time.Time) error
+	Complete(ctx context.Context, key string, code int, contentType, location string, body []byte) error
+	Delete(ctx context.Context, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
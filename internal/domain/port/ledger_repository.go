@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"go-transfer/internal/domain/entities"
+)
+
+type LedgerRepository interface {
+	CreateEntries(ctx context.Context, entries []entities.LedgerEntry) error
+	ListByTransaction(ctx context.Context, transactionID int64) ([]entities.LedgerEntry, error)
+}
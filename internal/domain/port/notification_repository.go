@@ -2,12 +2,30 @@ package port
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"go-transfer/internal/domain/entities"
 )
 
+var (
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrNotificationExists   = errors.New("notification already created for this outbox message")
+)
+
 type NotificationRepository interface {
+	// Create fails with ErrNotificationExists if a notification was already
+	// created for the same outbox message.
 	Create(ctx context.Context, notification *entities.Notification) (int64, error)
 	UpdateStatus(ctx context.Context, id int64, status entities.NotificationStatus) error
 	GetByID(ctx context.Context, id int64) (*entities.Notification, error)
+	GetByOutboxMessageID(ctx context.Context, outboxMessageID int64) (*entities.Notification, error)
+	// UpdateDelivery stores the outcome of a delivery attempt: status,
+	// attempts, last error and next attempt time.
+	UpdateDelivery(ctx context.Context, notification *entities.Notification) error
+	// ClaimDue leases up to limit FAILED notifications whose next attempt is
+	// due, pushing their next attempt to now+lease so that other schedulers
+	// skip them.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.Notification, error)
+	ListByStatus(ctx context.Context, status entities.NotificationStatus, afterID int64, limit int) ([]entities.Notification, error)
 }
@@ -1,7 +1,11 @@
 package port
 
-import "context"
+import (
+	"context"
+
+	"go-transfer/internal/domain/entities"
+)
 
 type NotificationService interface {
-	Notify(ctx context.Context, receiverID int64, amount float64) error
+	Notify(ctx context.Context, receiverID int64, kind entities.NotificationKind, amount entities.Money, details entities.TransferDetails) error
 }
@@ -0,0 +1,20 @@
+package port
+
+import (
+	"context"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+)
+
+type OutboxRepository interface {
+	Create(ctx context.Context, message *entities.OutboxMessage) error
+	// ClaimPending leases up to limit pending messages available at now,
+	// hiding them from other dispatchers until now+lease and counting the
+	// attempt. A message whose dispatcher dies is retried once the lease ends.
+	ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.OutboxMessage, error)
+	MarkDone(ctx context.Context, id int64, processedAt time.Time) error
+	MarkFailed(ctx context.Context, id int64, lastError string, retryAt time.Time) error
+	// MarkDead gives up on a message, which is no longer claimed.
+	MarkDead(ctx context.Context, id int64, lastError string) error
+}
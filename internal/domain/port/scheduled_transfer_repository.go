@@ -0,0 +1,40 @@
+package port
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+)
+
+var (
+	ErrScheduledTransferNotFound    = errors.New("scheduled transfer not found")
+	ErrScheduledTransferChanged     = errors.New("scheduled transfer changed concurrently")
+	ErrScheduledTransferRunExists   = errors.New("scheduled transfer already ran for this attempt")
+	ErrScheduledTransferRunNotFound = errors.New("scheduled transfer run not found")
+)
+
+type ScheduledTransferRepository interface {
+	Create(ctx context.Context, scheduled *entities.ScheduledTransfer) error
+	GetByID(ctx context.Context, id int64) (*entities.ScheduledTransfer, error)
+	// ListByPayer returns up to limit scheduled transfers of payerID with an
+	// id greater than afterID, ordered by id.
+	ListByPayer(ctx context.Context, payerID, afterID int64, limit int) ([]entities.ScheduledTransfer, error)
+	// ClaimDue leases up to limit SCHEDULED transfers due at now that no
+	// other scheduler holds, hiding them until now+lease.
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.ScheduledTransfer, error)
+	// Transition stores the status and schedule of scheduled if it is still
+	// in status from and not leased at now, failing with
+	// ErrScheduledTransferChanged otherwise.
+	Transition(ctx context.Context, scheduled *entities.ScheduledTransfer, from entities.ScheduledTransferStatus, now time.Time) error
+	// Release stores the outcome of an execution and ends the lease.
+	Release(ctx context.Context, scheduled *entities.ScheduledTransfer) error
+	// CreateRun fails with ErrScheduledTransferRunExists if the occurrence
+	// already has a run for run.Attempt.
+	CreateRun(ctx context.Context, run *entities.ScheduledTransferRun) error
+	GetRun(ctx context.Context, scheduledTransferID int64, scheduledFor time.Time, attempt int) (*entities.ScheduledTransferRun, error)
+	FinishRun(ctx context.Context, run *entities.ScheduledTransferRun) error
+	// ListRuns returns the runs of a scheduled transfer, latest first.
+	ListRuns(ctx context.Context, scheduledTransferID int64, limit int) ([]entities.ScheduledTransferRun, error)
+}
@@ -2,12 +2,75 @@ package port
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"go-transfer/internal/domain/entities"
 )
 
+var (
+	ErrTransactionNotFound       = errors.New("transaction not found")
+	ErrTransactionStatusConflict = errors.New("transaction status was changed concurrently")
+	ErrExternalReferenceExists   = errors.New("external reference already used by the sender")
+)
+
+type TransferDirection string
+
+const (
+	TransferDirectionAll      TransferDirection = ""
+	TransferDirectionSent     TransferDirection = "sent"
+	TransferDirectionReceived TransferDirection = "received"
+)
+
+type SortOrder string
+
+const (
+	SortDescending SortOrder = "desc"
+	SortAscending  SortOrder = "asc"
+)
+
+// TransactionCursor points at the last row of the previous page; rows are
+// ordered by (CreatedAt, ID) so ties on CreatedAt stay stable.
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+type TransactionFilter struct {
+	UserID    int64
+	Direction TransferDirection
+	Status    entities.TransactionStatus
+	MinAmount *entities.Money
+	MaxAmount *entities.Money
+	From      *time.Time
+	To        *time.Time
+	Order     SortOrder
+	After     *TransactionCursor
+	Limit     int
+	// ExternalReference only matches transfers the user sent.
+	ExternalReference *string
+}
+
 type TransactionRepository interface {
+	// Create returns ErrExternalReferenceExists when the sender already has a
+	// transaction with the same external reference.
 	Create(ctx context.Context, transfer *entities.Transaction) (int64, error)
-	UpdateStatus(ctx context.Context, id int64, status entities.TransactionStatus) error
-	GetByID(ctx context.Context, id int64) (*entities.TransactionStatus, error)
+	// UpdateStatus moves a transaction from one status to another only if it
+	// is still in from, and records the change in its status history.
+	UpdateStatus(ctx context.Context, id int64, from, to entities.TransactionStatus, reason string) error
+	UpdateRefundedAmount(ctx context.Context, id int64, refundedAmount entities.Money) error
+	UpdateAuthorization(ctx context.Context, id int64, decision entities.AuthorizationDecision) error
+	UpdateHold(ctx context.Context, id int64, held entities.Money) error
+	// UpdateCapture stores the amounts a held transfer was captured for:
+	// amount, fee, fee breakdown, received amount and held amount.
+	UpdateCapture(ctx context.Context, transaction *entities.Transaction) error
+	GetByID(ctx context.Context, id int64) (*entities.Transaction, error)
+	ListStatusHistory(ctx context.Context, transactionID int64) ([]entities.TransactionStatusChange, error)
+	ListByUser(ctx context.Context, filter TransactionFilter) ([]entities.Transaction, error)
+	// SumSent totals the settled transfers senderID created in [from, to),
+	// net of refunds.
+	SumSent(ctx context.Context, senderID int64, from, to time.Time) (entities.Money, error)
+	// ListExpiredHolds returns up to limit AUTHORIZED transfers whose hold
+	// expired at or before now, oldest first.
+	ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]entities.Transaction, error)
 }
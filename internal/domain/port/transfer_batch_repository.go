@@ -0,0 +1,35 @@
+package port
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+)
+
+var (
+	ErrTransferBatchNotFound = errors.New("transfer batch not found")
+	// ErrTransferBatchLeaseLost is returned to a worker whose lease ran out
+	// and whose batch was claimed again.
+	ErrTransferBatchLeaseLost = errors.New("transfer batch claimed by another worker")
+)
+
+type TransferBatchRepository interface {
+	// Create stores batch along with its items.
+	Create(ctx context.Context, batch *entities.TransferBatch) error
+	// GetByID returns the batch with its items ordered by position.
+	GetByID(ctx context.Context, id int64) (*entities.TransferBatch, error)
+	// ClaimQueued leases up to limit unfinished batches that no other worker
+	// holds, hiding them until now+lease, and marks them PROCESSING. Each
+	// claim gets a new ClaimToken.
+	ClaimQueued(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.TransferBatch, error)
+	// ExtendLease hides batch until until while its claim still holds it.
+	ExtendLease(ctx context.Context, batch *entities.TransferBatch, until time.Time) error
+	// UpdateItem stores item of batch only while the claim of batch still
+	// holds it.
+	UpdateItem(ctx context.Context, batch *entities.TransferBatch, item *entities.TransferBatchItem) error
+	// Finish stores the final status of batch and ends the lease, only while
+	// its claim still holds it.
+	Finish(ctx context.Context, batch *entities.TransferBatch) error
+}
@@ -0,0 +1,26 @@
+package port
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+)
+
+var ErrTransferLimitNotFound = errors.New("transfer limit not found")
+
+type TransferLimitRepository interface {
+	GetByUserID(ctx context.Context, userID int64) (*entities.UserTransferLimit, error)
+	// Save creates or replaces the override of limit.UserID.
+	Save(ctx context.Context, limit *entities.UserTransferLimit) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+type TransferCounterRepository interface {
+	// Get returns the counter of userID for the period starting at start,
+	// a zero counter if nothing was sent in it yet.
+	Get(ctx context.Context, userID int64, period entities.LimitPeriod, start time.Time) (entities.TransferCounter, error)
+	// Add counts one more transfer of amount in the period starting at start.
+	Add(ctx context.Context, userID int64, period entities.LimitPeriod, start time.Time, amount entities.Money) error
+}
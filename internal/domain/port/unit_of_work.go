@@ -0,0 +1,21 @@
+package port
+
+import "context"
+
+type Repositories struct {
+	Users         UserRepository
+	Wallets       WalletRepository
+	Transactions  TransactionRepository
+	Notifications NotificationRepository
+	Ledger        LedgerRepository
+	Outbox        OutboxRepository
+	Counters      TransferCounterRepository
+	Scheduled     ScheduledTransferRepository
+	Batches       TransferBatchRepository
+	Quotes        ExchangeQuoteRepository
+	Locker        WalletLocker
+}
+
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos Repositories) error) error
+}
@@ -0,0 +1,7 @@
+package port
+
+import "context"
+
+type WalletLocker interface {
+	Lock(ctx context.Context, walletIDs ...int64) (unlock func(), err error)
+}
@@ -2,13 +2,22 @@ package port
 
 import (
 	"context"
+	"errors"
 
 	"go-transfer/internal/domain/entities"
 )
 
+var ErrWalletConflict = errors.New("wallet was modified concurrently or has insufficient balance")
+
 type WalletRepository interface {
 	GetByID(ctx context.Context, id int64) (*entities.Wallet, error)
 	GetByOwnerID(ctx context.Context, ownerID int64) (*entities.Wallet, error)
-	UpdateBalance(ctx context.Context, id int64, balance float64) error
+	GetSystemWallet(ctx context.Context, account entities.SystemAccount, currency string) (*entities.Wallet, error)
+	Debit(ctx context.Context, id int64, amount entities.Money, version int64) error
+	Credit(ctx context.Context, id int64, amount entities.Money, version int64) error
+	// Hold reserves amount of the available balance; ReleaseHold gives it
+	// back. Both bump the version like Debit and Credit.
+	Hold(ctx context.Context, id int64, amount entities.Money, version int64) error
+	ReleaseHold(ctx context.Context, id int64, amount entities.Money, version int64) error
 	Create(ctx context.Context, wallet *entities.Wallet) error
 }
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+var (
+	ErrConversionUnavailable = errors.New("currency conversion unavailable")
+	ErrExchangeQuoteNotFound = errors.New("exchange quote not found")
+	ErrExchangeQuoteExpired  = errors.New("exchange quote expired")
+	ErrExchangeQuoteMismatch = errors.New("exchange quote does not match the transfer")
+	ErrExchangeQuoteUsed     = errors.New("exchange quote already used")
+)
+
+// Conversion is how a transfer is paid out in the payee's currency.
+type Conversion struct {
+	Rate   entities.ExchangeRate
+	Amount entities.Money
+	// Quote is the locked quote the rate came from, if any.
+	Quote *entities.ExchangeQuote
+}
+
+// Exchange converts transfers between wallets of different currencies. Rates
+// handed out in a quote stay locked for lockFor.
+type Exchange struct {
+	rates   port.ExchangeRateProvider
+	quotes  port.ExchangeQuoteRepository
+	lockFor time.Duration
+	now     func() time.Time
+}
+
+func NewExchange(rates port.ExchangeRateProvider, quotes port.ExchangeQuoteRepository, lockFor time.Duration) *Exchange {
+	return &Exchange{
+		rates:   rates,
+		quotes:  quotes,
+		lockFor: lockFor,
+		now:     time.Now,
+	}
+}
+
+// Convert prices amount in currency at the current rate.
+func (e *Exchange) Convert(ctx context.Context, amount entities.Money, currency string) (*Conversion, error) {
+	rate, err := e.rates.Rate(ctx, amount.Currency, currency)
+	if errors.Is(err, port.ErrExchangeRateNotFound) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrConversionUnavailable, amount.Currency, currency)
+	}
+	if err != nil {
+		return nil, err
+	}
+	converted, err := rate.Convert(amount, currency)
+	if err != nil {
+		return nil, err
+	}
+	return &Conversion{Rate: rate, Amount: converted}, nil
+}
+
+// Lock stores conversion as a quote for a transfer of amount from payerID to
+// payeeID, so that a transfer made before it expires gets the same rate.
+func (e *Exchange) Lock(ctx context.Context, payerID, payeeID int64, amount entities.Money, conversion *Conversion) error {
+	quote := &entities.ExchangeQuote{
+		PayerID:           payerID,
+		PayeeID:           payeeID,
+		Amount:            amount,
+		Currency:          amount.Currency,
+		Rate:              conversion.Rate,
+		ConvertedAmount:   conversion.Amount,
+		ConvertedCurrency: conversion.Amount.Currency,
+		ExpiresAt:         e.now().Add(e.lockFor),
+	}
+	if err := e.quotes.Create(ctx, quote); err != nil {
+		return err
+	}
+	conversion.Quote = quote
+	return nil
+}
+
+// Redeem returns the conversion locked by quoteID, provided it was made for
+// this very transfer and is still valid. The quote is not spent yet.
+func (e *Exchange) Redeem(ctx context.Context, quoteID, payerID, payeeID int64, amount entities.Money, currency string) (*Conversion, error) {
+	quote, err := e.quotes.GetByID(ctx, quoteID)
+	if errors.Is(err, port.ErrExchangeQuoteNotFound) {
+		return nil, ErrExchangeQuoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if quote.PayerID != payerID || quote.PayeeID != payeeID || quote.Amount != amount || quote.ConvertedCurrency != currency {
+		return nil, ErrExchangeQuoteMismatch
+	}
+	if quote.UsedAt != nil {
+		return nil, ErrExchangeQuoteUsed
+	}
+	if quote.IsExpired(e.now()) {
+		return nil, ErrExchangeQuoteExpired
+	}
+	return &Conversion{Rate: quote.Rate, Amount: quote.ConvertedAmount, Quote: quote}, nil
+}
+
+// Spend marks quoteID as used, through quotes, so no other transfer can
+// redeem it.
+func (e *Exchange) Spend(ctx context.Context, quotes port.ExchangeQuoteRepository, quoteID int64) error {
+	err := quotes.MarkUsed(ctx, quoteID, e.now())
+	if errors.Is(err, port.ErrExchangeQuoteUsed) {
+		return ErrExchangeQuoteUsed
+	}
+	return err
+}
@@ -0,0 +1,329 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockExchangeRateProvider struct{ mock.Mock }
+
+func (m *mockExchangeRateProvider) Rate(ctx context.Context, from, to string) (entities.ExchangeRate, error) {
+	args := m.Called(ctx, from, to)
+	return args.Get(0).(entities.ExchangeRate), args.Error(1)
+}
+
+type mockExchangeQuoteRepo struct{ mock.Mock }
+
+func (m *mockExchangeQuoteRepo) Create(ctx context.Context, quote *entities.ExchangeQuote) error {
+	args := m.Called(ctx, quote)
+	return args.Error(0)
+}
+
+func (m *mockExchangeQuoteRepo) GetByID(ctx context.Context, id int64) (*entities.ExchangeQuote, error) {
+	args := m.Called(ctx, id)
+	quote, _ := args.Get(0).(*entities.ExchangeQuote)
+	return quote, args.Error(1)
+}
+
+func (m *mockExchangeQuoteRepo) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+var exchangeNow = time.Date(2024, time.March, 10, 14, 20, 0, 0, time.UTC)
+
+func exchangeRate(value string) entities.ExchangeRate {
+	rate, _ := entities.ParseExchangeRate(value)
+	return rate
+}
+
+func newTestExchange(rates port.ExchangeRateProvider, quotes port.ExchangeQuoteRepository) *Exchange {
+	exchange := NewExchange(rates, quotes, 30*time.Second)
+	exchange.now = func() time.Time { return exchangeNow }
+	return exchange
+}
+
+func TestTransaction_Execute_ConvertsBetweenCurrencies(t *testing.T) {
+	ctx := context.Background()
+	senderID := int64(1)
+	receiverID := int64(2)
+	amount := entities.NewMoney(5000, "USD")
+	converted := entities.NewMoney(25000, "BRL")
+
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+	ledgerRepo := new(MockLedgerRepository)
+	authService := new(mockAuthService)
+	outboxRepo := new(MockOutboxRepository)
+	rates := new(mockExchangeRateProvider)
+
+	userRepo.On("GetByID", ctx, senderID).Return(&entities.User{ID: senderID}, nil)
+	userRepo.On("GetByID", ctx, receiverID).Return(&entities.User{ID: receiverID}, nil)
+	rates.On("Rate", ctx, "USD", "BRL").Return(exchangeRate("5"), nil)
+
+	senderWallet := &entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Currency: "USD", Balance: entities.NewMoney(10000, "USD")}
+	receiverWallet := &entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.MerchantWallet, Currency: "BRL"}
+	exchangeUSD := &entities.Wallet{ID: 40, Type: entities.SystemWallet, Currency: "USD"}
+	exchangeBRL := &entities.Wallet{ID: 41, Type: entities.SystemWallet, Currency: "BRL"}
+
+	walletRepo.On("GetByOwnerID", ctx, senderID).Return(senderWallet, nil)
+	walletRepo.On("GetByOwnerID", ctx, receiverID).Return(receiverWallet, nil)
+	for _, wallet := range []*entities.Wallet{senderWallet, receiverWallet, exchangeUSD, exchangeBRL} {
+		walletRepo.On("GetByID", ctx, wallet.ID).Return(wallet, nil)
+	}
+	walletRepo.On("GetSystemWallet", ctx, entities.SystemAccountExchange, "USD").Return(exchangeUSD, nil)
+	walletRepo.On("GetSystemWallet", ctx, entities.SystemAccountExchange, "BRL").Return(exchangeBRL, nil)
+	walletRepo.On("Debit", ctx, senderWallet.ID, amount, senderWallet.Version).Return(nil)
+	walletRepo.On("Credit", ctx, exchangeUSD.ID, amount, exchangeUSD.Version).Return(nil)
+	walletRepo.On("Debit", ctx, exchangeBRL.ID, converted, exchangeBRL.Version).Return(nil)
+	walletRepo.On("Credit", ctx, receiverWallet.ID, converted, receiverWallet.Version).Return(nil)
+	ledgerRepo.On("CreateEntries", ctx, entities.NewConversionPosting(99, senderWallet.ID, exchangeUSD.ID, exchangeBRL.ID, receiverWallet.ID, amount, converted)).Return(nil)
+
+	transactionRepo.On("Create", ctx, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.Amount == amount && transaction.Currency == "USD" &&
+			*transaction.ExchangeRate == exchangeRate("5") &&
+			*transaction.ReceivedAmount == converted && *transaction.ReceivedCurrency == "BRL" &&
+			transaction.ExchangeQuoteID == nil
+	})).Return(int64(99), nil)
+	expectAuthorization(transactionRepo, authService, ctx, 99, approved())
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusCompleted, "transfer settled").Return(nil)
+	outboxRepo.On("Create", ctx, transferNotification(receiverID, 99, converted)).Return(nil)
+
+	locker := &fakeWalletLocker{}
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Ledger: ledgerRepo, Outbox: outboxRepo, Locker: locker}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, nil, nil, newTestExchange(rates, nil), 0)
+
+	// The amount is read in the payer's currency.
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: senderID, PayeeID: receiverID, Amount: entities.MoneyFromCents(5000)})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusCompleted, transaction.Status)
+	assert.Equal(t, converted, transaction.PayeeAmount())
+	assert.Equal(t, [][]int64{{senderWallet.ID, receiverWallet.ID}, {exchangeUSD.ID, exchangeBRL.ID}}, locker.locked)
+
+	walletRepo.AssertExpectations(t)
+	ledgerRepo.AssertExpectations(t)
+}
+
+func TestTransaction_Execute_WithoutExchangeRejectsOtherCurrencies(t *testing.T) {
+	ctx := context.Background()
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+
+	userRepo.On("GetByID", ctx, int64(1)).Return(&entities.User{ID: 1}, nil)
+	userRepo.On("GetByID", ctx, int64(2)).Return(&entities.User{ID: 2}, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(1)).Return(&entities.Wallet{ID: 10, OwnerID: 1, Currency: "USD", Balance: entities.NewMoney(10000, "USD")}, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(2)).Return(&entities.Wallet{ID: 20, OwnerID: 2, Currency: "BRL"}, nil)
+
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, new(mockAuthService), nil, nil, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000)})
+	assert.ErrorIs(t, err, ErrConversionUnavailable)
+	assert.Nil(t, transaction)
+	transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func lockedQuote(id int64) *entities.ExchangeQuote {
+	return &entities.ExchangeQuote{
+		ID: id, PayerID: 1, PayeeID: 2,
+		Amount: entities.NewMoney(5000, "USD"), Currency: "USD",
+		Rate: exchangeRate("5.1"), ConvertedAmount: entities.NewMoney(25500, "BRL"), ConvertedCurrency: "BRL",
+		ExpiresAt: exchangeNow.Add(10 * time.Second),
+	}
+}
+
+func TestTransaction_Execute_RedeemsLockedQuote(t *testing.T) {
+	ctx := context.Background()
+	quoteID := int64(7)
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+	quotes := new(mockExchangeQuoteRepo)
+
+	userRepo.On("GetByID", ctx, int64(1)).Return(&entities.User{ID: 1}, nil)
+	userRepo.On("GetByID", ctx, int64(2)).Return(&entities.User{ID: 2}, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(1)).Return(&entities.Wallet{ID: 10, OwnerID: 1, Currency: "USD", Balance: entities.NewMoney(10000, "USD")}, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(2)).Return(&entities.Wallet{ID: 20, OwnerID: 2, Currency: "BRL"}, nil)
+	quotes.On("GetByID", ctx, quoteID).Return(lockedQuote(quoteID), nil)
+	transactionRepo.On("Create", ctx, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return *transaction.ExchangeRate == exchangeRate("5.1") &&
+			*transaction.ReceivedAmount == entities.NewMoney(25500, "BRL") &&
+			*transaction.ExchangeQuoteID == quoteID
+	})).Return(int64(0), assert.AnError)
+
+	// The rate provider is not asked: the quote's rate is used.
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, new(mockAuthService), nil, nil, newTestExchange(new(mockExchangeRateProvider), quotes), 0)
+
+	_, err := tx.Execute(ctx, TransferInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000), QuoteID: &quoteID})
+	assert.ErrorContains(t, err, assert.AnError.Error())
+	transactionRepo.AssertExpectations(t)
+	// Nothing was settled, so the quote can still be used.
+	quotes.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Execute_SpendsQuoteWhenSettling(t *testing.T) {
+	ctx := context.Background()
+	quoteID := int64(7)
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+	authService := new(mockAuthService)
+	quotes := new(mockExchangeQuoteRepo)
+
+	senderWallet := &entities.Wallet{ID: 10, OwnerID: 1, Currency: "USD", Balance: entities.NewMoney(10000, "USD")}
+	receiverWallet := &entities.Wallet{ID: 20, OwnerID: 2, Currency: "BRL"}
+	userRepo.On("GetByID", ctx, int64(1)).Return(&entities.User{ID: 1}, nil)
+	userRepo.On("GetByID", ctx, int64(2)).Return(&entities.User{ID: 2}, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(1)).Return(senderWallet, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(2)).Return(receiverWallet, nil)
+	walletRepo.On("GetByID", ctx, senderWallet.ID).Return(senderWallet, nil)
+	walletRepo.On("GetByID", ctx, receiverWallet.ID).Return(receiverWallet, nil)
+	quotes.On("GetByID", ctx, quoteID).Return(lockedQuote(quoteID), nil)
+	transactionRepo.On("Create", ctx, mock.Anything).Return(int64(99), nil)
+	expectAuthorization(transactionRepo, authService, ctx, 99, approved())
+	transactionRepo.On("UpdateStatus", mock.Anything, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusFailed, "settlement failed").Return(nil).Once()
+
+	// Another transfer spent the quote first: the settlement that would have
+	// used it is rolled back.
+	settlementQuotes := new(mockExchangeQuoteRepo)
+	settlementQuotes.On("MarkUsed", ctx, quoteID, exchangeNow).Return(port.ErrExchangeQuoteUsed).Once()
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Quotes: settlementQuotes, Locker: &fakeWalletLocker{}}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, nil, nil, newTestExchange(new(mockExchangeRateProvider), quotes), 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000), QuoteID: &quoteID})
+	assert.ErrorIs(t, err, ErrExchangeQuoteUsed)
+	assert.Nil(t, transaction)
+	settlementQuotes.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	quotes.AssertNotCalled(t, "MarkUsed", mock.Anything, mock.Anything, mock.Anything)
+	walletRepo.AssertNotCalled(t, "Debit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExchange_Redeem_RejectsUnusableQuotes(t *testing.T) {
+	ctx := context.Background()
+	used := exchangeNow.Add(-time.Second)
+	valid := entities.ExchangeQuote{
+		ID: 1, PayerID: 1, PayeeID: 2,
+		Amount: entities.NewMoney(5000, "USD"), Currency: "USD",
+		Rate: exchangeRate("5"), ConvertedAmount: entities.NewMoney(25000, "BRL"), ConvertedCurrency: "BRL",
+		ExpiresAt: exchangeNow.Add(time.Second),
+	}
+	expired, usedUp, otherAmount := valid, valid, valid
+	expired.ExpiresAt = exchangeNow
+	usedUp.UsedAt = &used
+	otherAmount.Amount = entities.NewMoney(5001, "USD")
+
+	cases := map[int64]struct {
+		quote *entities.ExchangeQuote
+		err   error
+	}{
+		1: {&valid, nil},
+		2: {&expired, ErrExchangeQuoteExpired},
+		3: {&usedUp, ErrExchangeQuoteUsed},
+		4: {&otherAmount, ErrExchangeQuoteMismatch},
+		5: {nil, ErrExchangeQuoteNotFound},
+	}
+	quotes := new(mockExchangeQuoteRepo)
+	for id, c := range cases {
+		if c.quote == nil {
+			quotes.On("GetByID", ctx, id).Return(nil, port.ErrExchangeQuoteNotFound)
+		} else {
+			quotes.On("GetByID", ctx, id).Return(c.quote, nil)
+		}
+	}
+	exchange := newTestExchange(nil, quotes)
+
+	for id, c := range cases {
+		conversion, err := exchange.Redeem(ctx, id, 1, 2, entities.NewMoney(5000, "USD"), "BRL")
+		if c.err == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, entities.NewMoney(25000, "BRL"), conversion.Amount)
+			continue
+		}
+		assert.ErrorIs(t, err, c.err, "quote %d", id)
+	}
+}
+
+func TestTransaction_Quote_LocksRateBetweenCurrencies(t *testing.T) {
+	ctx := context.Background()
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	rates := new(mockExchangeRateProvider)
+	quotes := new(mockExchangeQuoteRepo)
+
+	userRepo.On("GetByID", ctx, int64(1)).Return(&entities.User{ID: 1}, nil)
+	userRepo.On("GetByID", ctx, int64(2)).Return(&entities.User{ID: 2}, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(1)).Return(&entities.Wallet{ID: 10, OwnerID: 1, Type: entities.CommonWallet, Currency: "BRL"}, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(2)).Return(&entities.Wallet{ID: 20, OwnerID: 2, Type: entities.CommonWallet, Currency: "USD"}, nil)
+	rates.On("Rate", ctx, "BRL", "USD").Return(exchangeRate("0.2"), nil)
+	quotes.On("Create", ctx, mock.MatchedBy(func(quote *entities.ExchangeQuote) bool {
+		return quote.PayerID == 1 && quote.PayeeID == 2 &&
+			quote.Amount == entities.NewMoney(10000, "BRL") &&
+			quote.ConvertedAmount == entities.NewMoney(2000, "USD") &&
+			quote.ExpiresAt.Equal(exchangeNow.Add(30*time.Second))
+	})).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*entities.ExchangeQuote).ID = 7
+	})
+
+	tx := NewTransaction(userRepo, walletRepo, nil, nil, nil, nil, nil, newTestExchange(rates, quotes), 0)
+
+	quote, err := tx.Quote(ctx, 1, 2, entities.MoneyFromCents(10000))
+	assert.NoError(t, err)
+	assert.Equal(t, entities.NewMoney(10000, "BRL"), quote.Total)
+	assert.Equal(t, entities.NewMoney(2000, "USD"), quote.Conversion.Amount)
+	assert.Equal(t, int64(7), quote.Conversion.Quote.ID)
+	quotes.AssertExpectations(t)
+}
+
+func TestTransaction_Refund_ConvertedTransferAtOriginalRate(t *testing.T) {
+	f := newRefundFixture(entities.NewMoney(0, "USD"), entities.NewMoney(50000, "BRL"))
+	rate := exchangeRate("5")
+	received := entities.NewMoney(25000, "BRL")
+	receivedCurrency := "BRL"
+	f.original.Amount = entities.NewMoney(5000, "USD")
+	f.original.Currency = "USD"
+	f.original.ExchangeRate = &rate
+	f.original.ReceivedAmount = &received
+	f.original.ReceivedCurrency = &receivedCurrency
+	f.payerWallet.Currency = "USD"
+	f.payeeWallet.Currency = "BRL"
+
+	value := entities.NewMoney(2000, "USD")
+	share := entities.NewMoney(10000, "BRL")
+	exchangeBRL := &entities.Wallet{ID: 41, Type: entities.SystemWallet, Currency: "BRL"}
+	exchangeUSD := &entities.Wallet{ID: 40, Type: entities.SystemWallet, Currency: "USD"}
+	f.walletRepo.On("GetSystemWallet", f.ctx, entities.SystemAccountExchange, "BRL").Return(exchangeBRL, nil)
+	f.walletRepo.On("GetSystemWallet", f.ctx, entities.SystemAccountExchange, "USD").Return(exchangeUSD, nil)
+	f.walletRepo.On("GetByID", f.ctx, exchangeBRL.ID).Return(exchangeBRL, nil)
+	f.walletRepo.On("GetByID", f.ctx, exchangeUSD.ID).Return(exchangeUSD, nil)
+
+	f.transactionRepo.On("Create", f.ctx, mock.MatchedBy(func(tr *entities.Transaction) bool {
+		return tr.Type == entities.TransactionTypeRefund &&
+			tr.Amount == share && tr.Currency == "BRL" &&
+			*tr.ReceivedAmount == value && *tr.ExchangeRate == exchangeRate("0.2")
+	})).Return(int64(100), nil)
+	f.walletRepo.On("Debit", f.ctx, f.payeeWallet.ID, share, int64(5)).Return(nil)
+	f.walletRepo.On("Credit", f.ctx, exchangeBRL.ID, share, exchangeBRL.Version).Return(nil)
+	f.walletRepo.On("Debit", f.ctx, exchangeUSD.ID, value, exchangeUSD.Version).Return(nil)
+	f.walletRepo.On("Credit", f.ctx, f.payerWallet.ID, value, int64(2)).Return(nil)
+	f.ledgerRepo.On("CreateEntries", f.ctx, entities.NewConversionPosting(100, f.payeeWallet.ID, exchangeBRL.ID, exchangeUSD.ID, f.payerWallet.ID, share, value)).Return(nil)
+	f.transactionRepo.On("UpdateRefundedAmount", f.ctx, int64(99), value).Return(nil)
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusCompleted, entities.TransactionStatusPartiallyRefunded, "refunded by transaction 100").Return(nil)
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(100), entities.TransactionStatusPending, entities.TransactionStatusCompleted, "refund settled").Return(nil)
+	// Notification payloads carry no currency.
+	f.outboxRepo.On("Create", f.ctx, refundNotification(1, 100, entities.NotificationKindRefundReceived, entities.MoneyFromCents(2000))).Return(nil)
+	f.outboxRepo.On("Create", f.ctx, refundNotification(2, 100, entities.NotificationKindRefundSent, entities.MoneyFromCents(10000))).Return(nil)
+
+	refund, err := f.tx.Refund(f.ctx, 99, 2, &value)
+	assert.NoError(t, err)
+	assert.Equal(t, share, refund.Amount)
+	f.assertExpectations(t)
+}
@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+var (
+	ErrIdempotencyKeyReused       = errors.New("idempotency key was already used with a different request")
+	ErrIdempotencyRequestInFlight = errors.New("a request with this idempotency key is still being processed")
+)
+
+// IdempotencyScope is who sent a key and to which endpoint: the same key sent
+// by another payer or to another endpoint belongs to another request.
+type IdempotencyScope struct {
+	PayerID int64
+	// Route is the method and path of the endpoint, e.g. "POST /transfers".
+	Route string
+}
+
+func (s IdempotencyScope) storedKey(key string) string {
+	return fmt.Sprintf("%d:%s:%s", s.PayerID, s.Route, key)
+}
+
+type Idempotency struct {
+	idempotencyRepo port.IdempotencyRepository
+	ttl             time.Duration
+	lease           time.Duration
+	now             func() time.Time
+}
+
+// NewIdempotency builds the usecase. lease must outlast the slowest request:
+// once it passes, a retry with the same payload takes over a key still
+// IN_PROGRESS, assuming the request holding it died.
+func NewIdempotency(idempotencyRepo port.IdempotencyRepository, ttl, lease time.Duration) *Idempotency {
+	return &Idempotency{
+		idempotencyRepo: idempotencyRepo,
+		ttl:             ttl,
+		lease:           lease,
+		now:             time.Now,
+	}
+}
+
+// Begin reserves key within scope for the request identified by requestHash.
+// It returns a nil record when the caller should process the request, or the
+// completed record when the response must be replayed. Losing a race for the
+// key to a concurrent request is reported as ErrIdempotencyRequestInFlight.
+func (i *Idempotency) Begin(ctx context.Context, scope IdempotencyScope, key, requestHash string) (*entities.IdempotencyRecord, error) {
+	key = scope.storedKey(key)
+	err := i.idempotencyRepo.Create(ctx, i.newRecord(key, requestHash))
+	if !errors.Is(err, port.ErrIdempotencyKeyExists) {
+		return nil, err
+	}
+
+	existing, err := i.idempotencyRepo.GetByKey(ctx, key)
+	if errors.Is(err, port.ErrIdempotencyRecordNotFound) {
+		// The record was released or purged since Create: try once more.
+		return nil, i.inFlightOnConflict(i.idempotencyRepo.Create(ctx, i.newRecord(key, requestHash)))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := i.now()
+	if existing.IsExpired(now) {
+		return nil, i.inFlightOnConflict(i.idempotencyRepo.Replace(ctx, i.newRecord(key, requestHash), now))
+	}
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if existing.Status == entities.IdempotencyStatusCompleted {
+		return existing, nil
+	}
+	if !existing.IsAbandoned(now) {
+		return nil, ErrIdempotencyRequestInFlight
+	}
+	fmt.Printf("Retomando chave de idempotência abandonada %s\n", key)
+	return nil, i.inFlightOnConflict(i.idempotencyRepo.Replace(ctx, i.newRecord(key, requestHash), now))
+}
+
+func (i *Idempotency) Complete(ctx context.Context, scope IdempotencyScope, key string, code int, contentType, location string, body []byte) error {
+	return i.idempotencyRepo.Complete(ctx, scope.storedKey(key), code, contentType, location, body)
+}
+
+func (i *Idempotency) Release(ctx context.Context, scope IdempotencyScope, key string) error {
+	return i.idempotencyRepo.Delete(ctx, scope.storedKey(key))
+}
+
+func (i *Idempotency) PurgeExpired(ctx context.Context) (int64, error) {
+	return i.idempotencyRepo.DeleteExpired(ctx, i.now())
+}
+
+func (i *Idempotency) newRecord(key, requestHash string) *entities.IdempotencyRecord {
+	now := i.now()
+	return &entities.IdempotencyRecord{
+		Key:         key,
+		RequestHash: requestHash,
+		Status:      entities.IdempotencyStatusInProgress,
+		ExpiresAt:   now.Add(i.ttl),
+		LeasedUntil: now.Add(i.lease),
+	}
+}
+
+func (i *Idempotency) inFlightOnConflict(err error) error {
+	if errors.Is(err, port.ErrIdempotencyKeyExists) {
+		return ErrIdempotencyRequestInFlight
+	}
+	return err
+}
@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockIdempotencyRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdempotencyRepository) Create(ctx context.Context, record *entities.IdempotencyRecord) error {
+	args := m.Called(ctx, record)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) GetByKey(ctx context.Context, key string) (*entities.IdempotencyRecord, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.IdempotencyRecord), args.Error(1)
+}
+
+func (m *MockIdempotencyRepository) Replace(ctx context.Context, record *entities.IdempotencyRecord, now time.Time) error {
+	args := m.Called(ctx, record, now)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, key string, code int, contentType, location string, body []byte) error {
+	args := m.Called(ctx, key, code, contentType, location, body)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	args := m.Called(ctx, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+var testScope = IdempotencyScope{PayerID: 1, Route: "POST /transfers"}
+
+const testStoredKey = "1:POST /transfers:key-1"
+
+func newIdempotencyAt(repo *MockIdempotencyRepository, now time.Time) *Idempotency {
+	uc := NewIdempotency(repo, time.Hour, time.Minute)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func TestIdempotency_Begin_NewKey(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	repo.On("Create", ctx, mock.MatchedBy(func(r *entities.IdempotencyRecord) bool {
+		return r.Key == testStoredKey && r.RequestHash == "hash" && r.ExpiresAt.Equal(now.Add(time.Hour)) &&
+			r.LeasedUntil.Equal(now.Add(time.Minute))
+	})).Return(nil)
+
+	record, err := uc.Begin(ctx, testScope, "key-1", "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotency_Begin_ReplaysCompletedResponse(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	stored := &entities.IdempotencyRecord{
+		Key:          testStoredKey,
+		RequestHash:  "hash",
+		Status:       entities.IdempotencyStatusCompleted,
+		ResponseCode: 200,
+		ResponseBody: []byte(`{"message":"ok"}`),
+		ExpiresAt:    now.Add(time.Minute),
+	}
+	repo.On("Create", ctx, mock.Anything).Return(port.ErrIdempotencyKeyExists)
+	repo.On("GetByKey", ctx, testStoredKey).Return(stored, nil)
+
+	record, err := uc.Begin(ctx, testScope, "key-1", "hash")
+	assert.NoError(t, err)
+	assert.Equal(t, stored, record)
+}
+
+func TestIdempotency_Begin_DifferentPayload(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	repo.On("Create", ctx, mock.Anything).Return(port.ErrIdempotencyKeyExists)
+	repo.On("GetByKey", ctx, testStoredKey).Return(&entities.IdempotencyRecord{
+		Key:         testStoredKey,
+		RequestHash: "other",
+		Status:      entities.IdempotencyStatusCompleted,
+		ExpiresAt:   now.Add(time.Minute),
+	}, nil)
+
+	record, err := uc.Begin(ctx, testScope, "key-1", "hash")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+	assert.Nil(t, record)
+}
+
+func TestIdempotency_Begin_InFlight(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	repo.On("Create", ctx, mock.Anything).Return(port.ErrIdempotencyKeyExists)
+	repo.On("GetByKey", ctx, testStoredKey).Return(&entities.IdempotencyRecord{
+		Key:         testStoredKey,
+		RequestHash: "hash",
+		Status:      entities.IdempotencyStatusInProgress,
+		ExpiresAt:   now.Add(time.Minute),
+		LeasedUntil: now.Add(time.Second),
+	}, nil)
+
+	_, err := uc.Begin(ctx, testScope, "key-1", "hash")
+	assert.ErrorIs(t, err, ErrIdempotencyRequestInFlight)
+	repo.AssertNotCalled(t, "Replace", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestIdempotency_Begin_TakesOverAbandonedKey(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	repo.On("Create", ctx, mock.Anything).Return(port.ErrIdempotencyKeyExists)
+	repo.On("GetByKey", ctx, testStoredKey).Return(&entities.IdempotencyRecord{
+		Key:         testStoredKey,
+		RequestHash: "hash",
+		Status:      entities.IdempotencyStatusInProgress,
+		ExpiresAt:   now.Add(time.Hour),
+		LeasedUntil: now.Add(-time.Second),
+	}, nil)
+	repo.On("Replace", ctx, mock.MatchedBy(func(r *entities.IdempotencyRecord) bool {
+		return r.Key == testStoredKey && r.LeasedUntil.Equal(now.Add(time.Minute))
+	}), now).Return(nil)
+
+	record, err := uc.Begin(ctx, testScope, "key-1", "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotency_Begin_AbandonedKeyWithDifferentPayload(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	repo.On("Create", ctx, mock.Anything).Return(port.ErrIdempotencyKeyExists)
+	repo.On("GetByKey", ctx, testStoredKey).Return(&entities.IdempotencyRecord{
+		Key:         testStoredKey,
+		RequestHash: "other",
+		Status:      entities.IdempotencyStatusInProgress,
+		ExpiresAt:   now.Add(time.Hour),
+		LeasedUntil: now.Add(-time.Second),
+	}, nil)
+
+	_, err := uc.Begin(ctx, testScope, "key-1", "hash")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestIdempotency_Begin_LostTakeoverIsInFlight(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	repo.On("Create", ctx, mock.Anything).Return(port.ErrIdempotencyKeyExists)
+	repo.On("GetByKey", ctx, testStoredKey).Return(&entities.IdempotencyRecord{
+		Key:         testStoredKey,
+		RequestHash: "hash",
+		Status:      entities.IdempotencyStatusInProgress,
+		ExpiresAt:   now.Add(time.Hour),
+		LeasedUntil: now.Add(-time.Second),
+	}, nil)
+	repo.On("Replace", ctx, mock.Anything, now).Return(port.ErrIdempotencyKeyExists)
+
+	_, err := uc.Begin(ctx, testScope, "key-1", "hash")
+	assert.ErrorIs(t, err, ErrIdempotencyRequestInFlight)
+}
+
+func TestIdempotency_Begin_ReleasedKeyIsReservedAgain(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	repo.On("Create", ctx, mock.Anything).Return(port.ErrIdempotencyKeyExists).Once()
+	repo.On("GetByKey", ctx, testStoredKey).Return(nil, port.ErrIdempotencyRecordNotFound)
+	repo.On("Create", ctx, mock.Anything).Return(nil).Once()
+
+	record, err := uc.Begin(ctx, testScope, "key-1", "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotency_Begin_LostReservationRetryIsInFlight(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	repo.On("Create", ctx, mock.Anything).Return(port.ErrIdempotencyKeyExists).Twice()
+	repo.On("GetByKey", ctx, testStoredKey).Return(nil, port.ErrIdempotencyRecordNotFound)
+
+	_, err := uc.Begin(ctx, testScope, "key-1", "hash")
+	assert.ErrorIs(t, err, ErrIdempotencyRequestInFlight)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotency_Begin_ExpiredKeyIsReused(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	repo.On("Create", ctx, mock.Anything).Return(port.ErrIdempotencyKeyExists)
+	repo.On("GetByKey", ctx, testStoredKey).Return(&entities.IdempotencyRecord{
+		Key:         testStoredKey,
+		RequestHash: "other",
+		Status:      entities.IdempotencyStatusCompleted,
+		ExpiresAt:   now.Add(-time.Minute),
+	}, nil)
+	repo.On("Replace", ctx, mock.MatchedBy(func(r *entities.IdempotencyRecord) bool {
+		return r.Key == testStoredKey && r.RequestHash == "hash"
+	}), now).Return(nil)
+
+	record, err := uc.Begin(ctx, testScope, "key-1", "hash")
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+	repo.AssertExpectations(t)
+}
+
+func TestIdempotency_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	repo := new(MockIdempotencyRepository)
+	uc := newIdempotencyAt(repo, now)
+
+	repo.On("DeleteExpired", ctx, now).Return(int64(3), nil)
+
+	deleted, err := uc.PurgeExpired(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+// postLedger validates a posting, applies its net effect to the cached wallet
+// balances and stores the entries. wallets must hold every wallet referenced by
+// entries, loaded inside the same unit of work.
+func postLedger(ctx context.Context, repos port.Repositories, wallets []*entities.Wallet, entries []entities.LedgerEntry) error {
+	if err := entities.ValidatePosting(entries); err != nil {
+		return err
+	}
+
+	byID := make(map[int64]*entities.Wallet, len(wallets))
+	for _, wallet := range wallets {
+		byID[wallet.ID] = wallet
+	}
+
+	var order []int64
+	deltas := make(map[int64]entities.Money)
+	for _, entry := range entries {
+		if _, ok := byID[entry.WalletID]; !ok {
+			return errors.New("ledger posting references an unknown wallet")
+		}
+		current, seen := deltas[entry.WalletID]
+		if !seen {
+			order = append(order, entry.WalletID)
+			current = entities.NewMoney(0, entry.Amount.Currency)
+		}
+		next, err := current.Add(entry.SignedAmount())
+		if err != nil {
+			return err
+		}
+		deltas[entry.WalletID] = next
+	}
+
+	for _, walletID := range order {
+		wallet := byID[walletID]
+		delta := deltas[walletID]
+		var err error
+		switch {
+		case delta.IsNegative():
+			err = repos.Wallets.Debit(ctx, wallet.ID, entities.NewMoney(-delta.Cents, delta.Currency), wallet.Version)
+		case delta.IsPositive():
+			err = repos.Wallets.Credit(ctx, wallet.ID, delta, wallet.Version)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return repos.Ledger.CreateEntries(ctx, entries)
+}
@@ -2,57 +2,243 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math/rand/v2"
 	"time"
 
 	"go-transfer/internal/domain/entities"
 	"go-transfer/internal/domain/port"
 )
 
+var ErrNotificationNotDead = errors.New("only dead notifications can be re-driven")
+
+// NotificationUseCaseInterface creates and delivers the notification for an
+// outbox message. Each outbox message is notified once, however many times
+// it is delivered.
 type NotificationUseCaseInterface interface {
-	Execute(ctx context.Context, senderID, receiverID int64, amount float64) error
+	Execute(ctx context.Context, outboxMessageID int64, receiverID int64, transferID int64, amount entities.Money, details entities.TransferDetails) error
+	NotifyScheduledTransferFailed(ctx context.Context, outboxMessageID int64, payerID int64, scheduledTransferID int64, amount entities.Money) error
+	NotifyTransferBatchFinished(ctx context.Context, outboxMessageID int64, payerID int64, batchID int64, amount entities.Money) error
+	NotifyTransferCancelled(ctx context.Context, outboxMessageID int64, payeeID int64, transferID int64, amount entities.Money, details entities.TransferDetails) error
+	NotifyRefund(ctx context.Context, outboxMessageID int64, receiverID int64, refundID int64, kind entities.NotificationKind, amount entities.Money, details entities.TransferDetails) error
+}
+
+// RetryPolicy schedules failed notification deliveries with exponential
+// backoff. A notification is marked DEAD once MaxAttempts deliveries failed.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Backoff returns the delay before the attempt following attempt number
+// attempts (1-based), doubling from BaseDelay up to MaxDelay.
+func (p RetryPolicy) Backoff(attempts int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempts && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
 }
 
 type NotificationUseCase struct {
 	notificationRepo    port.NotificationRepository
 	notificationService port.NotificationService
+	retryPolicy         RetryPolicy
+	now                 func() time.Time
+	jitter              func(delay time.Duration) time.Duration
 }
 
-func NewNotification(notificationRepo port.NotificationRepository, notificationService port.NotificationService) *NotificationUseCase {
+func NewNotification(notificationRepo port.NotificationRepository, notificationService port.NotificationService, retryPolicy RetryPolicy) *NotificationUseCase {
 	return &NotificationUseCase{
 		notificationRepo:    notificationRepo,
 		notificationService: notificationService,
+		retryPolicy:         retryPolicy,
+		now:                 time.Now,
+		jitter:              equalJitter,
 	}
 }
 
-func (n *NotificationUseCase) Execute(ctx context.Context, receiverID int64, transferID int64, amount float64) error {
-	notification := &entities.Notification{
-		ReceiverID:    receiverID,
-		TransactionID: transferID,
-		Amount:        amount,
-		Status:        entities.NotificationStatusPending,
-		CreatedAt:     time.Now(),
+// equalJitter keeps half of the delay and randomises the other half, so that
+// notifications failing together do not retry in lockstep.
+func equalJitter(delay time.Duration) time.Duration {
+	half := delay / 2
+	if half <= 0 {
+		return delay
 	}
+	return half + rand.N(half+1)
+}
+
+func (n *NotificationUseCase) Execute(ctx context.Context, outboxMessageID int64, receiverID int64, transferID int64, amount entities.Money, details entities.TransferDetails) error {
+	return n.send(ctx, &entities.Notification{
+		OutboxMessageID: &outboxMessageID,
+		ReceiverID:      receiverID,
+		Kind:            entities.NotificationKindTransferReceived,
+		TransactionID:   &transferID,
+		Amount:          amount,
+		Details:         details,
+	})
+}
+
+// NotifyScheduledTransferFailed tells a payer that a scheduled transfer
+// could not be made.
+func (n *NotificationUseCase) NotifyScheduledTransferFailed(ctx context.Context, outboxMessageID int64, payerID int64, scheduledTransferID int64, amount entities.Money) error {
+	return n.send(ctx, &entities.Notification{
+		OutboxMessageID:     &outboxMessageID,
+		ReceiverID:          payerID,
+		Kind:                entities.NotificationKindScheduledTransferFailed,
+		ScheduledTransferID: &scheduledTransferID,
+		Amount:              amount,
+	})
+}
+
+// NotifyTransferBatchFinished tells a payer that a batch is over, with the
+// amount it settled.
+func (n *NotificationUseCase) NotifyTransferBatchFinished(ctx context.Context, outboxMessageID int64, payerID int64, batchID int64, amount entities.Money) error {
+	return n.send(ctx, &entities.Notification{
+		OutboxMessageID: &outboxMessageID,
+		ReceiverID:      payerID,
+		Kind:            entities.NotificationKindTransferBatchFinished,
+		TransferBatchID: &batchID,
+		Amount:          amount,
+	})
+}
+
+// NotifyTransferCancelled tells the payee of a held transfer that its payer
+// cancelled it.
+func (n *NotificationUseCase) NotifyTransferCancelled(ctx context.Context, outboxMessageID int64, payeeID int64, transferID int64, amount entities.Money, details entities.TransferDetails) error {
+	return n.send(ctx, &entities.Notification{
+		OutboxMessageID: &outboxMessageID,
+		ReceiverID:      payeeID,
+		Kind:            entities.NotificationKindTransferCancelled,
+		TransactionID:   &transferID,
+		Amount:          amount,
+		Details:         details,
+	})
+}
+
+// NotifyRefund tells one side of a refund about it, with kind saying whether
+// they got the money back or paid it.
+func (n *NotificationUseCase) NotifyRefund(ctx context.Context, outboxMessageID int64, receiverID int64, refundID int64, kind entities.NotificationKind, amount entities.Money, details entities.TransferDetails) error {
+	return n.send(ctx, &entities.Notification{
+		OutboxMessageID: &outboxMessageID,
+		ReceiverID:      receiverID,
+		Kind:            kind,
+		TransactionID:   &refundID,
+		Amount:          amount,
+		Details:         details,
+	})
+}
+
+func (n *NotificationUseCase) send(ctx context.Context, notification *entities.Notification) error {
+	notification.Status = entities.NotificationStatusPending
+	notification.CreatedAt = n.now()
 
 	notificationID, err := n.notificationRepo.Create(ctx, notification)
+	if errors.Is(err, port.ErrNotificationExists) {
+		return n.resume(ctx, *notification.OutboxMessageID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create notification record: %w", err)
+	}
+	notification.ID = notificationID
+
+	n.deliver(ctx, notification)
+	return nil
+}
+
+// resume picks up the notification already created for an outbox message
+// delivered again. It is only sent if the first delivery stopped before
+// trying; failed ones are left to RetryDue.
+func (n *NotificationUseCase) resume(ctx context.Context, outboxMessageID int64) error {
+	notification, err := n.notificationRepo.GetByOutboxMessageID(ctx, outboxMessageID)
 	if err != nil {
-		fmt.Printf("failed to create notification record: %v\n", err)
+		return err
+	}
+	if notification.Status == entities.NotificationStatusPending {
+		n.deliver(ctx, notification)
 	}
+	return nil
+}
 
-	err = n.notificationService.Notify(ctx, receiverID, amount)
+// RetryDue re-attempts a batch of failed notifications whose next attempt is
+// due and returns how many were delivered.
+func (n *NotificationUseCase) RetryDue(ctx context.Context, lease time.Duration, limit int) (int, error) {
+	notifications, err := n.notificationRepo.ClaimDue(ctx, n.now(), lease, limit)
 	if err != nil {
-		updateErr := n.notificationRepo.UpdateStatus(ctx, notificationID, entities.NotificationStatusFailed)
-		if updateErr != nil {
-			fmt.Printf("failed to update notification status to 'failed': %v\n", updateErr)
+		return 0, err
+	}
+
+	delivered := 0
+	for i := range notifications {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
 		}
-		fmt.Printf("failed to send notification: %v\n", err)
+		if n.deliver(ctx, &notifications[i]) {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+func (n *NotificationUseCase) ListDead(ctx context.Context, afterID int64, limit int) ([]entities.Notification, error) {
+	return n.notificationRepo.ListByStatus(ctx, entities.NotificationStatusDead, afterID, limit)
+}
+
+// Redrive gives a dead notification a fresh set of attempts, starting now.
+func (n *NotificationUseCase) Redrive(ctx context.Context, id int64) (*entities.Notification, error) {
+	notification, err := n.notificationRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if notification.Status != entities.NotificationStatusDead {
+		return nil, ErrNotificationNotDead
 	}
 
-	if err := n.notificationRepo.UpdateStatus(ctx, notificationID, entities.NotificationStatusSent); err != nil {
-		fmt.Printf("failed to update notification status to 'sent': %v\n", err)
+	now := n.now()
+	notification.Status = entities.NotificationStatusFailed
+	notification.Attempts = 0
+	notification.NextAttemptAt = &now
+	if err := n.notificationRepo.UpdateDelivery(ctx, notification); err != nil {
+		return nil, err
 	}
+	return notification, nil
+}
 
-	return nil
+// deliver makes one delivery attempt and records its outcome, scheduling the
+// next attempt or giving up when the retry policy is exhausted.
+func (n *NotificationUseCase) deliver(ctx context.Context, notification *entities.Notification) bool {
+	notification.Attempts++
+	err := n.notificationService.Notify(ctx, notification.ReceiverID, notification.Kind, notification.Amount, notification.Details)
+	switch {
+	case err == nil:
+		notification.Status = entities.NotificationStatusSent
+		notification.LastError = ""
+		notification.NextAttemptAt = nil
+	case notification.Attempts >= n.retryPolicy.MaxAttempts:
+		notification.Status = entities.NotificationStatusDead
+		notification.LastError = err.Error()
+		notification.NextAttemptAt = nil
+	default:
+		next := n.now().Add(n.jitter(n.retryPolicy.Backoff(notification.Attempts)))
+		notification.Status = entities.NotificationStatusFailed
+		notification.LastError = err.Error()
+		notification.NextAttemptAt = &next
+	}
+
+	// Recording must outlive cancellation, or a delivered notification
+	// would be sent again.
+	if updateErr := n.notificationRepo.UpdateDelivery(context.WithoutCancel(ctx), notification); updateErr != nil {
+		fmt.Printf("failed to record notification %d delivery: %v\n", notification.ID, updateErr)
+	}
+	if err != nil {
+		fmt.Printf("failed to send notification: %v\n", err)
+	}
+	return err == nil
 }
 
 func (n *NotificationUseCase) GetNotificationByID(ctx context.Context, id int64) (*entities.Notification, error) {
@@ -4,10 +4,12 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
 )
 
 type MockNotificationRepository struct {
@@ -26,28 +28,74 @@ func (m *MockNotificationRepository) UpdateStatus(ctx context.Context, id int64,
 
 func (m *MockNotificationRepository) GetByID(ctx context.Context, id int64) (*entities.Notification, error) {
 	args := m.Called(ctx, id)
-	return args.Get(0).(*entities.Notification), args.Error(1)
+	notification, _ := args.Get(0).(*entities.Notification)
+	return notification, args.Error(1)
+}
+
+func (m *MockNotificationRepository) GetByOutboxMessageID(ctx context.Context, outboxMessageID int64) (*entities.Notification, error) {
+	args := m.Called(ctx, outboxMessageID)
+	notification, _ := args.Get(0).(*entities.Notification)
+	return notification, args.Error(1)
+}
+
+func (m *MockNotificationRepository) UpdateDelivery(ctx context.Context, notification *entities.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *MockNotificationRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.Notification, error) {
+	args := m.Called(ctx, now, lease, limit)
+	notifications, _ := args.Get(0).([]entities.Notification)
+	return notifications, args.Error(1)
+}
+
+func (m *MockNotificationRepository) ListByStatus(ctx context.Context, status entities.NotificationStatus, afterID int64, limit int) ([]entities.Notification, error) {
+	args := m.Called(ctx, status, afterID, limit)
+	notifications, _ := args.Get(0).([]entities.Notification)
+	return notifications, args.Error(1)
 }
 
 type MockNotificationService struct {
 	mock.Mock
 }
 
-func (m *MockNotificationService) Notify(ctx context.Context, receiverID int64, amount float64) error {
-	args := m.Called(ctx, receiverID, amount)
+func (m *MockNotificationService) Notify(ctx context.Context, receiverID int64, kind entities.NotificationKind, amount entities.Money, details entities.TransferDetails) error {
+	args := m.Called(ctx, receiverID, kind, amount, details)
 	return args.Error(0)
 }
 
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+
+func newTestNotificationUseCase(repo *MockNotificationRepository, service *MockNotificationService, now time.Time) *NotificationUseCase {
+	uc := NewNotification(repo, service, testRetryPolicy)
+	uc.now = func() time.Time { return now }
+	uc.jitter = func(delay time.Duration) time.Duration { return delay }
+	return uc
+}
+
+func delivery(status entities.NotificationStatus, attempts int, lastError string, nextAttemptAt *time.Time) interface{} {
+	return mock.MatchedBy(func(n *entities.Notification) bool {
+		if n.Status != status || n.Attempts != attempts || n.LastError != lastError {
+			return false
+		}
+		if nextAttemptAt == nil || n.NextAttemptAt == nil {
+			return nextAttemptAt == nil && n.NextAttemptAt == nil
+		}
+		return n.NextAttemptAt.Equal(*nextAttemptAt)
+	})
+}
+
 func TestNotificationUseCase_Execute_Success(t *testing.T) {
 	ctx := context.Background()
 	mockRepo := new(MockNotificationRepository)
 	mockService := new(MockNotificationService)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 
-	uc := NewNotification(mockRepo, mockService)
+	uc := newTestNotificationUseCase(mockRepo, mockService, now)
 
 	receiverID := int64(1)
 	transferID := int64(101)
-	amount := 250.0
+	amount := entities.MoneyFromCents(25000)
 	notificationID := int64(999)
 
 	mockRepo.
@@ -55,14 +103,14 @@ func TestNotificationUseCase_Execute_Success(t *testing.T) {
 		Return(notificationID, nil)
 
 	mockService.
-		On("Notify", mock.Anything, receiverID, amount).
+		On("Notify", mock.Anything, receiverID, entities.NotificationKindTransferReceived, amount, entities.TransferDetails{}).
 		Return(nil)
 
 	mockRepo.
-		On("UpdateStatus", mock.Anything, notificationID, entities.NotificationStatusSent).
+		On("UpdateDelivery", mock.Anything, delivery(entities.NotificationStatusSent, 1, "", nil)).
 		Return(nil)
 
-	err := uc.Execute(ctx, receiverID, transferID, amount)
+	err := uc.Execute(ctx, 1, receiverID, transferID, amount, entities.TransferDetails{})
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -73,33 +121,281 @@ func TestNotificationUseCase_Execute_FailedNotify(t *testing.T) {
 	ctx := context.Background()
 	mockRepo := new(MockNotificationRepository)
 	mockService := new(MockNotificationService)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 
-	uc := NewNotification(mockRepo, mockService)
+	uc := newTestNotificationUseCase(mockRepo, mockService, now)
 
 	receiverID := int64(1)
 	transferID := int64(102)
-	amount := 500.0
+	amount := entities.MoneyFromCents(50000)
 	notificationID := int64(1000)
+	nextAttemptAt := now.Add(time.Minute)
 
 	mockRepo.
 		On("Create", mock.Anything, mock.AnythingOfType("*entities.Notification")).
 		Return(notificationID, nil)
 
 	mockService.
-		On("Notify", mock.Anything, receiverID, amount).
+		On("Notify", mock.Anything, receiverID, entities.NotificationKindTransferReceived, amount, entities.TransferDetails{}).
 		Return(errors.New("notify error"))
 
 	mockRepo.
-		On("UpdateStatus", mock.Anything, notificationID, entities.NotificationStatusFailed).
+		On("UpdateDelivery", mock.Anything, delivery(entities.NotificationStatusFailed, 1, "notify error", &nextAttemptAt)).
+		Return(nil)
+
+	err := uc.Execute(ctx, 1, receiverID, transferID, amount, entities.TransferDetails{})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockService.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_Execute_CreateFails(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockService := new(MockNotificationService)
+
+	uc := newTestNotificationUseCase(mockRepo, mockService, time.Now())
+
+	mockRepo.
+		On("Create", mock.Anything, mock.AnythingOfType("*entities.Notification")).
+		Return(int64(0), errors.New("database down"))
+
+	err := uc.Execute(ctx, 1, 1, 103, entities.MoneyFromCents(100), entities.TransferDetails{})
+
+	assert.ErrorContains(t, err, "database down")
+	mockService.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNotificationUseCase_NotifyScheduledTransferFailed(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockService := new(MockNotificationService)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	uc := newTestNotificationUseCase(mockRepo, mockService, now)
+	amount := entities.MoneyFromCents(7500)
+
+	mockRepo.
+		On("Create", mock.Anything, mock.MatchedBy(func(n *entities.Notification) bool {
+			return n.ReceiverID == 4 && n.Kind == entities.NotificationKindScheduledTransferFailed &&
+				n.TransactionID == nil && n.ScheduledTransferID != nil && *n.ScheduledTransferID == 42
+		})).
+		Return(int64(1), nil)
+	mockService.
+		On("Notify", mock.Anything, int64(4), entities.NotificationKindScheduledTransferFailed, amount, entities.TransferDetails{}).
+		Return(nil)
+	mockRepo.
+		On("UpdateDelivery", mock.Anything, delivery(entities.NotificationStatusSent, 1, "", nil)).
+		Return(nil)
+
+	err := uc.NotifyScheduledTransferFailed(ctx, 1, 4, 42, amount)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockService.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_NotifyTransferBatchFinished(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockService := new(MockNotificationService)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	uc := newTestNotificationUseCase(mockRepo, mockService, now)
+	amount := entities.MoneyFromCents(12000)
+
+	mockRepo.
+		On("Create", mock.Anything, mock.MatchedBy(func(n *entities.Notification) bool {
+			return n.ReceiverID == 4 && n.Kind == entities.NotificationKindTransferBatchFinished &&
+				n.TransactionID == nil && n.TransferBatchID != nil && *n.TransferBatchID == 9
+		})).
+		Return(int64(1), nil)
+	mockService.
+		On("Notify", mock.Anything, int64(4), entities.NotificationKindTransferBatchFinished, amount, entities.TransferDetails{}).
+		Return(nil)
+	mockRepo.
+		On("UpdateDelivery", mock.Anything, delivery(entities.NotificationStatusSent, 1, "", nil)).
+		Return(nil)
+
+	err := uc.NotifyTransferBatchFinished(ctx, 1, 4, 9, amount)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockService.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_NotifyTransferCancelled(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockService := new(MockNotificationService)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	uc := newTestNotificationUseCase(mockRepo, mockService, now)
+	amount := entities.MoneyFromCents(5000)
+	details := entities.TransferDetails{Description: "Order 1234", Metadata: map[string]string{"order": "1234"}}
+
+	mockRepo.
+		On("Create", mock.Anything, mock.MatchedBy(func(n *entities.Notification) bool {
+			return n.ReceiverID == 2 && n.Kind == entities.NotificationKindTransferCancelled &&
+				n.TransactionID != nil && *n.TransactionID == 42 && n.Details.Description == "Order 1234"
+		})).
+		Return(int64(1), nil)
+	mockService.
+		On("Notify", mock.Anything, int64(2), entities.NotificationKindTransferCancelled, amount, details).
+		Return(nil)
+	mockRepo.
+		On("UpdateDelivery", mock.Anything, delivery(entities.NotificationStatusSent, 1, "", nil)).
 		Return(nil)
 
+	err := uc.NotifyTransferCancelled(ctx, 1, 2, 42, amount, details)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockService.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_NotifyRefund(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockService := new(MockNotificationService)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	uc := newTestNotificationUseCase(mockRepo, mockService, now)
+	amount := entities.MoneyFromCents(5000)
+
 	mockRepo.
-		On("UpdateStatus", mock.Anything, notificationID, entities.NotificationStatusSent).
+		On("Create", mock.Anything, mock.MatchedBy(func(n *entities.Notification) bool {
+			return n.ReceiverID == 2 && n.Kind == entities.NotificationKindRefundSent &&
+				n.TransactionID != nil && *n.TransactionID == 100
+		})).
+		Return(int64(1), nil)
+	mockService.
+		On("Notify", mock.Anything, int64(2), entities.NotificationKindRefundSent, amount, entities.TransferDetails{}).
 		Return(nil)
+	mockRepo.
+		On("UpdateDelivery", mock.Anything, delivery(entities.NotificationStatusSent, 1, "", nil)).
+		Return(nil)
+
+	err := uc.NotifyRefund(ctx, 1, 2, 100, entities.NotificationKindRefundSent, amount, entities.TransferDetails{})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockService.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_Execute_OutboxMessageDeliveredAgain(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	amount := entities.MoneyFromCents(5000)
+
+	t.Run("already sent", func(t *testing.T) {
+		mockRepo := new(MockNotificationRepository)
+		mockService := new(MockNotificationService)
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(int64(0), port.ErrNotificationExists)
+		mockRepo.On("GetByOutboxMessageID", ctx, int64(7)).Return(&entities.Notification{ID: 1, Status: entities.NotificationStatusSent}, nil)
+
+		err := newTestNotificationUseCase(mockRepo, mockService, now).Execute(ctx, 7, 2, 99, amount, entities.TransferDetails{})
+
+		assert.NoError(t, err)
+		mockService.AssertNotCalled(t, "Notify", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "UpdateDelivery", mock.Anything, mock.Anything)
+	})
 
-	err := uc.Execute(ctx, receiverID, transferID, amount)
+	t.Run("created but never sent", func(t *testing.T) {
+		mockRepo := new(MockNotificationRepository)
+		mockService := new(MockNotificationService)
+		pending := &entities.Notification{ID: 1, ReceiverID: 2, Kind: entities.NotificationKindTransferReceived, Amount: amount, Status: entities.NotificationStatusPending}
+		mockRepo.On("Create", mock.Anything, mock.Anything).Return(int64(0), port.ErrNotificationExists)
+		mockRepo.On("GetByOutboxMessageID", ctx, int64(7)).Return(pending, nil)
+		mockService.On("Notify", mock.Anything, int64(2), entities.NotificationKindTransferReceived, amount, entities.TransferDetails{}).Return(nil).Once()
+		mockRepo.On("UpdateDelivery", mock.Anything, delivery(entities.NotificationStatusSent, 1, "", nil)).Return(nil).Once()
+
+		err := newTestNotificationUseCase(mockRepo, mockService, now).Execute(ctx, 7, 2, 99, amount, entities.TransferDetails{})
+
+		assert.NoError(t, err)
+		mockService.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestNotificationUseCase_RetryDue(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	mockService := new(MockNotificationService)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	uc := newTestNotificationUseCase(mockRepo, mockService, now)
+
+	due := []entities.Notification{
+		{ID: 1, ReceiverID: 10, Kind: entities.NotificationKindTransferReceived, Amount: entities.MoneyFromCents(100), Status: entities.NotificationStatusFailed, Attempts: 1},
+		{ID: 2, ReceiverID: 20, Kind: entities.NotificationKindTransferReceived, Amount: entities.MoneyFromCents(200), Status: entities.NotificationStatusFailed, Attempts: 1},
+		{ID: 3, ReceiverID: 30, Kind: entities.NotificationKindScheduledTransferFailed, Amount: entities.MoneyFromCents(300), Status: entities.NotificationStatusFailed, Attempts: 2},
+	}
+	nextAttemptAt := now.Add(2 * time.Minute)
+
+	mockRepo.On("ClaimDue", ctx, now, time.Minute, 50).Return(due, nil)
+	mockService.On("Notify", ctx, int64(10), entities.NotificationKindTransferReceived, entities.MoneyFromCents(100), entities.TransferDetails{}).Return(nil)
+	mockService.On("Notify", ctx, int64(20), entities.NotificationKindTransferReceived, entities.MoneyFromCents(200), entities.TransferDetails{}).Return(errors.New("timeout"))
+	mockService.On("Notify", ctx, int64(30), entities.NotificationKindScheduledTransferFailed, entities.MoneyFromCents(300), entities.TransferDetails{}).Return(errors.New("timeout"))
+	mockRepo.On("UpdateDelivery", mock.Anything, delivery(entities.NotificationStatusSent, 2, "", nil)).Return(nil).Once()
+	mockRepo.On("UpdateDelivery", mock.Anything, delivery(entities.NotificationStatusFailed, 2, "timeout", &nextAttemptAt)).Return(nil).Once()
+	mockRepo.On("UpdateDelivery", mock.Anything, delivery(entities.NotificationStatusDead, 3, "timeout", nil)).Return(nil).Once()
+
+	delivered, err := uc.RetryDue(ctx, time.Minute, 50)
 
 	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
 	mockRepo.AssertExpectations(t)
 	mockService.AssertExpectations(t)
 }
+
+func TestNotificationUseCase_Redrive(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	uc := newTestNotificationUseCase(mockRepo, new(MockNotificationService), now)
+
+	dead := &entities.Notification{ID: 7, Status: entities.NotificationStatusDead, Attempts: 3, LastError: "timeout"}
+	mockRepo.On("GetByID", ctx, int64(7)).Return(dead, nil)
+	mockRepo.On("UpdateDelivery", ctx, delivery(entities.NotificationStatusFailed, 0, "timeout", &now)).Return(nil)
+
+	notification, err := uc.Redrive(ctx, 7)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.NotificationStatusFailed, notification.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestNotificationUseCase_Redrive_NotDead(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockNotificationRepository)
+
+	uc := newTestNotificationUseCase(mockRepo, new(MockNotificationService), time.Now())
+
+	mockRepo.On("GetByID", ctx, int64(7)).Return(&entities.Notification{ID: 7, Status: entities.NotificationStatusSent}, nil)
+
+	_, err := uc.Redrive(ctx, 7)
+
+	assert.ErrorIs(t, err, ErrNotificationNotDead)
+	mockRepo.AssertNotCalled(t, "UpdateDelivery", mock.Anything, mock.Anything)
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 16*time.Second, policy.Backoff(5))
+	assert.Equal(t, 30*time.Second, policy.Backoff(6))
+	assert.Equal(t, 30*time.Second, policy.Backoff(60))
+}
+
+func TestEqualJitter_StaysWithinBounds(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		delay := equalJitter(10 * time.Second)
+		assert.GreaterOrEqual(t, delay, 5*time.Second)
+		assert.LessOrEqual(t, delay, 10*time.Second)
+	}
+}
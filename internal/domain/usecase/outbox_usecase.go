@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+// errUndeliverable marks messages that no retry will deliver.
+var errUndeliverable = errors.New("undeliverable outbox message")
+
+type Outbox struct {
+	outboxRepo          port.OutboxRepository
+	notificationUseCase NotificationUseCaseInterface
+	batchSize           int
+	lease               time.Duration
+	retryDelay          time.Duration
+	now                 func() time.Time
+	// maxAttempts is how many deliveries a message gets before it is DEAD.
+	maxAttempts int
+}
+
+func NewOutbox(outboxRepo port.OutboxRepository, notificationUseCase NotificationUseCaseInterface, batchSize, maxAttempts int, lease, retryDelay time.Duration) *Outbox {
+	return &Outbox{
+		outboxRepo:          outboxRepo,
+		notificationUseCase: notificationUseCase,
+		batchSize:           batchSize,
+		lease:               lease,
+		retryDelay:          retryDelay,
+		now:                 time.Now,
+		maxAttempts:         maxAttempts,
+	}
+}
+
+// Dispatch delivers one batch of pending messages and returns how many were
+// delivered. Delivery is at least once: a message is only marked done after
+// its handler succeeded, so a crash in between delivers it again, which the
+// handler ignores. Messages that cannot be read, or still fail after
+// maxAttempts deliveries, are marked DEAD and not claimed again. It stops
+// between messages once ctx is cancelled; claimed messages it did not reach
+// become available again when their lease ends.
+func (o *Outbox) Dispatch(ctx context.Context) (int, error) {
+	messages, err := o.outboxRepo.ClaimPending(ctx, o.now(), o.lease, o.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	// Bookkeeping outlives cancellation so a message handled right before
+	// shutdown is not delivered twice.
+	bookkeeping := context.WithoutCancel(ctx)
+	delivered := 0
+	for _, message := range messages {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		if err := o.handle(ctx, message); err != nil {
+			if markErr := o.markFailed(bookkeeping, message, err); markErr != nil {
+				return delivered, markErr
+			}
+			continue
+		}
+		if err := o.outboxRepo.MarkDone(bookkeeping, message.ID, o.now()); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+func (o *Outbox) markFailed(ctx context.Context, message entities.OutboxMessage, err error) error {
+	if errors.Is(err, errUndeliverable) || message.Attempts >= o.maxAttempts {
+		fmt.Printf("giving up on outbox message %d: %v\n", message.ID, err)
+		return o.outboxRepo.MarkDead(ctx, message.ID, err.Error())
+	}
+	return o.outboxRepo.MarkFailed(ctx, message.ID, err.Error(), o.now().Add(o.retryDelay))
+}
+
+func (o *Outbox) handle(ctx context.Context, message entities.OutboxMessage) error {
+	switch message.EventType {
+	case entities.OutboxEventTransferNotification:
+		var payload entities.TransferNotificationPayload
+		if err := decodePayload(message, &payload); err != nil {
+			return err
+		}
+		return o.notificationUseCase.Execute(ctx, message.ID, payload.ReceiverID, payload.TransactionID, payload.Amount, payload.Details)
+	case entities.OutboxEventScheduledTransferFailed:
+		var payload entities.ScheduledTransferFailedPayload
+		if err := decodePayload(message, &payload); err != nil {
+			return err
+		}
+		return o.notificationUseCase.NotifyScheduledTransferFailed(ctx, message.ID, payload.PayerID, payload.ScheduledTransferID, payload.Amount)
+	case entities.OutboxEventTransferBatchFinished:
+		var payload entities.TransferBatchFinishedPayload
+		if err := decodePayload(message, &payload); err != nil {
+			return err
+		}
+		return o.notificationUseCase.NotifyTransferBatchFinished(ctx, message.ID, payload.PayerID, payload.BatchID, payload.Amount)
+	case entities.OutboxEventTransferCancelled:
+		var payload entities.TransferCancelledPayload
+		if err := decodePayload(message, &payload); err != nil {
+			return err
+		}
+		return o.notificationUseCase.NotifyTransferCancelled(ctx, message.ID, payload.ReceiverID, payload.TransactionID, payload.Amount, payload.Details)
+	case entities.OutboxEventRefundNotification:
+		var payload entities.RefundNotificationPayload
+		if err := decodePayload(message, &payload); err != nil {
+			return err
+		}
+		return o.notificationUseCase.NotifyRefund(ctx, message.ID, payload.ReceiverID, payload.TransactionID, payload.Kind, payload.Amount, payload.Details)
+	default:
+		return fmt.Errorf("%w: unknown event type %q", errUndeliverable, message.EventType)
+	}
+}
+
+func decodePayload(message entities.OutboxMessage, payload any) error {
+	if err := json.Unmarshal(message.Payload, payload); err != nil {
+		return fmt.Errorf("%w: %v", errUndeliverable, err)
+	}
+	return nil
+}
+
+// enqueue writes payload to the outbox in the unit of work of repos, so it is
+// only delivered if that unit of work commits.
+func enqueue(ctx context.Context, repos port.Repositories, payload entities.OutboxPayload) error {
+	message, err := entities.NewOutboxMessage(payload, time.Now())
+	if err != nil {
+		return err
+	}
+	return repos.Outbox.Create(ctx, message)
+}
+
+// enqueuePayeeNotifications tells each payee of a settled transfer what they
+// received, which on split transfers is the amount of their leg.
+func enqueuePayeeNotifications(ctx context.Context, repos port.Repositories, transaction *entities.Transaction) error {
+	if !transaction.IsSplit() {
+		return enqueue(ctx, repos, entities.TransferNotificationPayload{ReceiverID: transaction.ReceiverID, TransactionID: transaction.ID, Amount: transaction.PayeeAmount(), Details: transaction.Details})
+	}
+	for _, leg := range transaction.Legs {
+		if err := enqueue(ctx, repos, entities.TransferNotificationPayload{ReceiverID: leg.ReceiverID, TransactionID: transaction.ID, Amount: leg.Amount, Details: transaction.Details}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
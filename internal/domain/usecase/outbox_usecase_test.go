@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) Create(ctx context.Context, message *entities.OutboxMessage) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.OutboxMessage, error) {
+	args := m.Called(ctx, now, lease, limit)
+	messages, _ := args.Get(0).([]entities.OutboxMessage)
+	return messages, args.Error(1)
+}
+
+func (m *MockOutboxRepository) MarkDone(ctx context.Context, id int64, processedAt time.Time) error {
+	args := m.Called(ctx, id, processedAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkFailed(ctx context.Context, id int64, lastError string, retryAt time.Time) error {
+	args := m.Called(ctx, id, lastError, retryAt)
+	return args.Error(0)
+}
+
+func (m *MockOutboxRepository) MarkDead(ctx context.Context, id int64, lastError string) error {
+	args := m.Called(ctx, id, lastError)
+	return args.Error(0)
+}
+
+// transferNotification matches the outbox message enqueued for one receiver
+// of a transfer notification.
+func transferNotification(receiverID, transactionID int64, amount entities.Money) interface{} {
+	return mock.MatchedBy(func(message *entities.OutboxMessage) bool {
+		var payload entities.TransferNotificationPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return false
+		}
+		return message.EventType == entities.OutboxEventTransferNotification &&
+			message.Status == entities.OutboxStatusPending &&
+			message.AggregateID == transactionID &&
+			payload.ReceiverID == receiverID && payload.TransactionID == transactionID && payload.Amount == amount
+	})
+}
+
+func refundNotification(receiverID, transactionID int64, kind entities.NotificationKind, amount entities.Money) interface{} {
+	return mock.MatchedBy(func(message *entities.OutboxMessage) bool {
+		var payload entities.RefundNotificationPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return false
+		}
+		return message.EventType == entities.OutboxEventRefundNotification &&
+			message.AggregateID == transactionID &&
+			payload.ReceiverID == receiverID && payload.TransactionID == transactionID &&
+			payload.Kind == kind && payload.Amount == amount
+	})
+}
+
+func newTestOutbox(outboxRepo *MockOutboxRepository, notificationUseCase *mockNotificationUseCase, now time.Time) *Outbox {
+	outbox := NewOutbox(outboxRepo, notificationUseCase, 10, 3, 30*time.Second, 5*time.Second)
+	outbox.now = func() time.Time { return now }
+	return outbox
+}
+
+func notificationMessage(t *testing.T, id, receiverID, transactionID int64, amount entities.Money) entities.OutboxMessage {
+	message, err := entities.NewOutboxMessage(entities.TransferNotificationPayload{ReceiverID: receiverID, TransactionID: transactionID, Amount: amount}, time.Time{})
+	assert.NoError(t, err)
+	message.ID = id
+	return *message
+}
+
+func TestOutbox_Dispatch_DeliversAndMarksDone(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := new(MockOutboxRepository)
+	notificationUseCase := new(mockNotificationUseCase)
+	amount := entities.MoneyFromCents(5000)
+
+	messages := []entities.OutboxMessage{
+		notificationMessage(t, 1, 2, 99, amount),
+		notificationMessage(t, 2, 1, 99, amount),
+	}
+	outboxRepo.On("ClaimPending", ctx, now, 30*time.Second, 10).Return(messages, nil)
+	notificationUseCase.On("Execute", ctx, int64(1), int64(2), int64(99), amount, entities.TransferDetails{}).Return(nil)
+	notificationUseCase.On("Execute", ctx, int64(2), int64(1), int64(99), amount, entities.TransferDetails{}).Return(nil)
+	outboxRepo.On("MarkDone", mock.Anything, int64(1), now).Return(nil)
+	outboxRepo.On("MarkDone", mock.Anything, int64(2), now).Return(nil)
+
+	delivered, err := newTestOutbox(outboxRepo, notificationUseCase, now).Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	outboxRepo.AssertExpectations(t)
+	notificationUseCase.AssertExpectations(t)
+}
+
+func TestOutbox_Dispatch_FailureIsRetriedLater(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := new(MockOutboxRepository)
+	notificationUseCase := new(mockNotificationUseCase)
+	amount := entities.MoneyFromCents(5000)
+
+	messages := []entities.OutboxMessage{notificationMessage(t, 1, 2, 99, amount)}
+	outboxRepo.On("ClaimPending", ctx, now, 30*time.Second, 10).Return(messages, nil)
+	notificationUseCase.On("Execute", ctx, int64(1), int64(2), int64(99), amount, entities.TransferDetails{}).Return(errors.New("database down"))
+	outboxRepo.On("MarkFailed", mock.Anything, int64(1), "database down", now.Add(5*time.Second)).Return(nil)
+
+	delivered, err := newTestOutbox(outboxRepo, notificationUseCase, now).Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	outboxRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "MarkDone", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOutbox_Dispatch_ScheduledTransferFailed(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := new(MockOutboxRepository)
+	notificationUseCase := new(mockNotificationUseCase)
+	amount := entities.MoneyFromCents(5000)
+
+	message, err := entities.NewOutboxMessage(entities.ScheduledTransferFailedPayload{PayerID: 1, ScheduledTransferID: 7, Amount: amount, Reason: "insufficient balance"}, now)
+	assert.NoError(t, err)
+	message.ID = 1
+	outboxRepo.On("ClaimPending", ctx, now, 30*time.Second, 10).Return([]entities.OutboxMessage{*message}, nil)
+	notificationUseCase.On("NotifyScheduledTransferFailed", ctx, int64(1), int64(1), int64(7), amount).Return(nil)
+	outboxRepo.On("MarkDone", mock.Anything, int64(1), now).Return(nil)
+
+	delivered, err := newTestOutbox(outboxRepo, notificationUseCase, now).Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	outboxRepo.AssertExpectations(t)
+	notificationUseCase.AssertExpectations(t)
+}
+
+func TestOutbox_Dispatch_TransferBatchFinished(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := new(MockOutboxRepository)
+	notificationUseCase := new(mockNotificationUseCase)
+	batch := &entities.TransferBatch{
+		ID: 3, PayerID: 1, Status: entities.TransferBatchStatusPartiallyCompleted, Currency: entities.DefaultCurrency,
+		Items: []entities.TransferBatchItem{
+			{Amount: entities.MoneyFromCents(5000), Status: entities.TransferBatchItemStatusCompleted},
+			{Amount: entities.MoneyFromCents(2000), Status: entities.TransferBatchItemStatusFailed},
+		},
+	}
+
+	message, err := entities.NewOutboxMessage(batch.FinishedPayload(), now)
+	assert.NoError(t, err)
+	message.ID = 1
+	outboxRepo.On("ClaimPending", ctx, now, 30*time.Second, 10).Return([]entities.OutboxMessage{*message}, nil)
+	notificationUseCase.On("NotifyTransferBatchFinished", ctx, int64(1), int64(1), int64(3), entities.MoneyFromCents(5000)).Return(nil)
+	outboxRepo.On("MarkDone", mock.Anything, int64(1), now).Return(nil)
+
+	delivered, err := newTestOutbox(outboxRepo, notificationUseCase, now).Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	notificationUseCase.AssertExpectations(t)
+}
+
+func TestOutbox_Dispatch_TransferCancelled(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := new(MockOutboxRepository)
+	notificationUseCase := new(mockNotificationUseCase)
+
+	reference := "order-1234"
+	details := entities.TransferDetails{Description: "Order 1234", ExternalReference: &reference}
+	message, err := entities.NewOutboxMessage(entities.TransferCancelledPayload{ReceiverID: 2, TransactionID: 42, Amount: entities.MoneyFromCents(5000), Details: details}, now)
+	assert.NoError(t, err)
+	message.ID = 1
+	outboxRepo.On("ClaimPending", ctx, now, 30*time.Second, 10).Return([]entities.OutboxMessage{*message}, nil)
+	notificationUseCase.On("NotifyTransferCancelled", ctx, int64(1), int64(2), int64(42), entities.MoneyFromCents(5000), details).Return(nil)
+	outboxRepo.On("MarkDone", mock.Anything, int64(1), now).Return(nil)
+
+	delivered, err := newTestOutbox(outboxRepo, notificationUseCase, now).Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	notificationUseCase.AssertExpectations(t)
+}
+
+func TestOutbox_Dispatch_RefundNotification(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := new(MockOutboxRepository)
+	notificationUseCase := new(mockNotificationUseCase)
+
+	message, err := entities.NewOutboxMessage(entities.RefundNotificationPayload{ReceiverID: 2, TransactionID: 100, Kind: entities.NotificationKindRefundSent, Amount: entities.MoneyFromCents(5000)}, now)
+	assert.NoError(t, err)
+	message.ID = 1
+	outboxRepo.On("ClaimPending", ctx, now, 30*time.Second, 10).Return([]entities.OutboxMessage{*message}, nil)
+	notificationUseCase.On("NotifyRefund", ctx, int64(1), int64(2), int64(100), entities.NotificationKindRefundSent, entities.MoneyFromCents(5000), entities.TransferDetails{}).Return(nil)
+	outboxRepo.On("MarkDone", mock.Anything, int64(1), now).Return(nil)
+
+	delivered, err := newTestOutbox(outboxRepo, notificationUseCase, now).Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	notificationUseCase.AssertExpectations(t)
+}
+
+func TestOutbox_Dispatch_UnknownEventType(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := new(MockOutboxRepository)
+
+	messages := []entities.OutboxMessage{{ID: 1, EventType: "wallet.created"}}
+	outboxRepo.On("ClaimPending", ctx, now, 30*time.Second, 10).Return(messages, nil)
+	outboxRepo.On("MarkDead", mock.Anything, int64(1), `undeliverable outbox message: unknown event type "wallet.created"`).Return(nil)
+
+	_, err := newTestOutbox(outboxRepo, new(mockNotificationUseCase), now).Dispatch(ctx)
+	assert.NoError(t, err)
+	outboxRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "MarkFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOutbox_Dispatch_UndecodablePayloadIsDead(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := new(MockOutboxRepository)
+
+	messages := []entities.OutboxMessage{{ID: 1, EventType: entities.OutboxEventTransferNotification, Payload: []byte(`{"receiver_id":"two"}`)}}
+	outboxRepo.On("ClaimPending", ctx, now, 30*time.Second, 10).Return(messages, nil)
+	outboxRepo.On("MarkDead", mock.Anything, int64(1), mock.MatchedBy(func(lastError string) bool {
+		return strings.HasPrefix(lastError, "undeliverable outbox message: ")
+	})).Return(nil)
+
+	_, err := newTestOutbox(outboxRepo, new(mockNotificationUseCase), now).Dispatch(ctx)
+	assert.NoError(t, err)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestOutbox_Dispatch_GivesUpAfterMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := new(MockOutboxRepository)
+	notificationUseCase := new(mockNotificationUseCase)
+	amount := entities.MoneyFromCents(5000)
+
+	message := notificationMessage(t, 1, 2, 99, amount)
+	message.Attempts = 3
+	outboxRepo.On("ClaimPending", ctx, now, 30*time.Second, 10).Return([]entities.OutboxMessage{message}, nil)
+	notificationUseCase.On("Execute", ctx, int64(1), int64(2), int64(99), amount, entities.TransferDetails{}).Return(errors.New("database down"))
+	outboxRepo.On("MarkDead", mock.Anything, int64(1), "database down").Return(nil)
+
+	delivered, err := newTestOutbox(outboxRepo, notificationUseCase, now).Dispatch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, delivered)
+	outboxRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "MarkFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOutbox_Dispatch_StopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	outboxRepo := new(MockOutboxRepository)
+	notificationUseCase := new(mockNotificationUseCase)
+	amount := entities.MoneyFromCents(5000)
+
+	messages := []entities.OutboxMessage{
+		notificationMessage(t, 1, 2, 99, amount),
+		notificationMessage(t, 2, 1, 99, amount),
+	}
+	outboxRepo.On("ClaimPending", ctx, now, 30*time.Second, 10).Return(messages, nil)
+	notificationUseCase.On("Execute", ctx, int64(1), int64(2), int64(99), amount, entities.TransferDetails{}).Run(func(mock.Arguments) { cancel() }).Return(nil)
+	outboxRepo.On("MarkDone", mock.Anything, int64(1), now).Return(nil)
+
+	delivered, err := newTestOutbox(outboxRepo, notificationUseCase, now).Dispatch(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, delivered)
+	notificationUseCase.AssertNumberOfCalls(t, "Execute", 1)
+}
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+var (
+	ErrTransferNotRefundable = errors.New("transfer cannot be refunded")
+	ErrInvalidRefundAmount   = errors.New("refund amount must be greater than zero")
+	ErrRefundExceedsAmount   = errors.New("refund exceeds the amount left to refund")
+)
+
+// Refund pays back part or all of a completed transfer from the payee to the
+// payer. A nil amount refunds whatever has not been refunded yet. Only the
+// payee may issue a refund. amount is in the currency the payer sent, and a
+// converted transfer is refunded at its original rate.
+func (t *Transaction) Refund(ctx context.Context, transactionID, requesterID int64, amount *entities.Money) (*entities.Transaction, error) {
+	if amount != nil && !amount.IsPositive() {
+		return nil, ErrInvalidRefundAmount
+	}
+
+	var refund *entities.Transaction
+	var err error
+	for attempt := 1; attempt <= maxWalletUpdateAttempts; attempt++ {
+		refund, err = t.refund(ctx, transactionID, requesterID, amount)
+		if !errors.Is(err, port.ErrWalletConflict) {
+			break
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (t *Transaction) refund(ctx context.Context, transactionID, requesterID int64, amount *entities.Money) (*entities.Transaction, error) {
+	var refund *entities.Transaction
+	unlock := func() {}
+	err := t.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		original, err := t.refundableTransfer(ctx, repos, transactionID, requesterID)
+		if err != nil {
+			return err
+		}
+
+		payeeWallet, payerWallet, release, err := t.lockWallets(ctx, repos, original.ReceiverID, original.SenderID)
+		if err != nil {
+			return err
+		}
+		unlock = release
+
+		// Re-read under the wallet locks so concurrent refunds see each other.
+		original, err = t.refundableTransfer(ctx, repos, transactionID, requesterID)
+		if err != nil {
+			return err
+		}
+		remaining, err := original.RefundableAmount()
+		if err != nil {
+			return err
+		}
+		value := remaining
+		if amount != nil {
+			value = entities.NewMoney(amount.Cents, remaining.Currency)
+		}
+		exceeds, err := remaining.LessThan(value)
+		if err != nil {
+			return err
+		}
+		if exceeds {
+			return ErrRefundExceedsAmount
+		}
+		share, err := original.PayeeShare(value)
+		if err != nil {
+			return err
+		}
+		short, err := payeeWallet.AvailableBalance().LessThan(share)
+		if err != nil {
+			return err
+		}
+		if short {
+			return ErrInsufficientBalance
+		}
+
+		created, err := t.createRefund(ctx, repos.Transactions, original, share, value)
+		if err != nil {
+			return err
+		}
+
+		wallets := []*entities.Wallet{payeeWallet, payerWallet}
+		var posting []entities.LedgerEntry
+		if created.IsConverted() {
+			exchange, releaseExchange, err := t.lockSystemWallets(ctx, repos,
+				systemWallet{entities.SystemAccountExchange, payeeWallet.CurrencyCode()},
+				systemWallet{entities.SystemAccountExchange, payerWallet.CurrencyCode()},
+			)
+			if err != nil {
+				return err
+			}
+			unlock = func() {
+				releaseExchange()
+				release()
+			}
+			wallets = append(wallets, exchange...)
+			posting = entities.NewConversionPosting(created.ID, payeeWallet.ID, exchange[0].ID, exchange[1].ID, payerWallet.ID, share, value)
+		} else {
+			posting = entities.NewTransferPosting(created.ID, payeeWallet.ID, payerWallet.ID, value)
+		}
+		if err := postLedger(ctx, repos, wallets, posting); err != nil {
+			return err
+		}
+
+		refunded, err := original.RefundedAmount.Add(value)
+		if err != nil {
+			return err
+		}
+		if err := repos.Transactions.UpdateRefundedAmount(ctx, original.ID, refunded); err != nil {
+			return err
+		}
+		status, err := original.RefundStatus(refunded)
+		if err != nil {
+			return err
+		}
+		if status != original.Status {
+			reason := fmt.Sprintf("refunded by transaction %d", created.ID)
+			if err := transitionTransaction(ctx, repos.Transactions, original, status, reason); err != nil {
+				return err
+			}
+		}
+
+		if err := transitionTransaction(ctx, repos.Transactions, created, entities.TransactionStatusCompleted, "refund settled"); err != nil {
+			return err
+		}
+		// Both sides are told the details of the transfer being refunded.
+		received := entities.RefundNotificationPayload{ReceiverID: created.ReceiverID, TransactionID: created.ID, Kind: entities.NotificationKindRefundReceived, Amount: value, Details: original.Details}
+		if err := enqueue(ctx, repos, received); err != nil {
+			return err
+		}
+		sent := entities.RefundNotificationPayload{ReceiverID: created.SenderID, TransactionID: created.ID, Kind: entities.NotificationKindRefundSent, Amount: share, Details: original.Details}
+		if err := enqueue(ctx, repos, sent); err != nil {
+			return err
+		}
+		refund = created
+		return nil
+	})
+	// Released only once the unit of work has committed or rolled back.
+	unlock()
+	if err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+func (t *Transaction) refundableTransfer(ctx context.Context, repos port.Repositories, transactionID, requesterID int64) (*entities.Transaction, error) {
+	original, err := repos.Transactions.GetByID(ctx, transactionID)
+	if errors.Is(err, port.ErrTransactionNotFound) {
+		return nil, ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if original.ReceiverID != requesterID {
+		return nil, ErrTransferAccessDenied
+	}
+	if !original.IsRefundable() {
+		return nil, ErrTransferNotRefundable
+	}
+	return original, nil
+}
+
+// createRefund records a refund in which the payee of original pays back
+// share and the payer gets value; they only differ for converted transfers.
+func (t *Transaction) createRefund(ctx context.Context, transactionRepo port.TransactionRepository, original *entities.Transaction, share, value entities.Money) (*entities.Transaction, error) {
+	originalID := original.ID
+	refund := &entities.Transaction{
+		SenderID:              original.ReceiverID,
+		ReceiverID:            original.SenderID,
+		Amount:                share,
+		Currency:              share.Currency,
+		Type:                  entities.TransactionTypeRefund,
+		Status:                entities.TransactionStatusPending,
+		OriginalTransactionID: &originalID,
+	}
+	if original.IsConverted() {
+		rate := original.ExchangeRate.Inverse()
+		refund.ExchangeRate = &rate
+		refund.ReceivedAmount = &value
+		refund.ReceivedCurrency = &value.Currency
+	}
+	refundID, err := transactionRepo.Create(ctx, refund)
+	if err != nil {
+		return nil, errors.New("failed to create refund record: " + err.Error())
+	}
+	refund.ID = refundID
+	return refund, nil
+}
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type refundFixture struct {
+	*transferFixture
+	original *entities.Transaction
+}
+
+// newRefundFixture has the payee refund a completed transfer 99 of 50.00.
+func newRefundFixture(refunded entities.Money, payeeBalance entities.Money) *refundFixture {
+	f := &refundFixture{
+		transferFixture: newTransferFixture(context.Background()),
+		original: &entities.Transaction{
+			ID: 99, SenderID: 1, ReceiverID: 2,
+			Amount:         entities.MoneyFromCents(5000),
+			RefundedAmount: refunded,
+			Type:           entities.TransactionTypeTransfer,
+			Status:         entities.TransactionStatusCompleted,
+		},
+	}
+	f.payerWallet.Balance, f.payerWallet.Version = entities.MoneyFromCents(1000), 2
+	f.payeeWallet.Balance, f.payeeWallet.Version = payeeBalance, 5
+	f.transactionRepo.On("GetByID", f.ctx, f.original.ID).Return(f.original, nil)
+	return f
+}
+
+func (f *refundFixture) expectRefund(amount entities.Money, refunded entities.Money, status entities.TransactionStatus) {
+	f.transactionRepo.On("Create", f.ctx, mock.MatchedBy(func(tr *entities.Transaction) bool {
+		return tr.Type == entities.TransactionTypeRefund &&
+			tr.SenderID == 2 && tr.ReceiverID == 1 &&
+			tr.Amount == amount &&
+			tr.OriginalTransactionID != nil && *tr.OriginalTransactionID == 99
+	})).Return(int64(100), nil).Once()
+	f.walletRepo.On("Debit", f.ctx, f.payeeWallet.ID, amount, int64(5)).Return(nil).Once()
+	f.walletRepo.On("Credit", f.ctx, f.payerWallet.ID, amount, int64(2)).Return(nil).Once()
+	f.ledgerRepo.On("CreateEntries", f.ctx, entities.NewTransferPosting(100, f.payeeWallet.ID, f.payerWallet.ID, amount)).Return(nil).Once()
+	f.transactionRepo.On("UpdateRefundedAmount", f.ctx, int64(99), refunded).Return(nil).Once()
+	if status != f.original.Status {
+		f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), f.original.Status, status, "refunded by transaction 100").Return(nil).Once()
+	}
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(100), entities.TransactionStatusPending, entities.TransactionStatusCompleted, "refund settled").Return(nil).Once()
+	f.outboxRepo.On("Create", f.ctx, refundNotification(1, 100, entities.NotificationKindRefundReceived, amount)).Return(nil).Once()
+	f.outboxRepo.On("Create", f.ctx, refundNotification(2, 100, entities.NotificationKindRefundSent, amount)).Return(nil).Once()
+}
+
+func (f *refundFixture) assertExpectations(t *testing.T) {
+	f.walletRepo.AssertExpectations(t)
+	f.transactionRepo.AssertExpectations(t)
+	f.ledgerRepo.AssertExpectations(t)
+	f.outboxRepo.AssertExpectations(t)
+}
+
+func TestTransaction_Refund_FullRemaining(t *testing.T) {
+	f := newRefundFixture(entities.MoneyFromCents(1500), entities.MoneyFromCents(8000))
+	f.expectRefund(entities.MoneyFromCents(3500), entities.MoneyFromCents(5000), entities.TransactionStatusRefunded)
+
+	refund, err := f.tx.Refund(f.ctx, 99, 2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(100), refund.ID)
+	assert.Equal(t, entities.MoneyFromCents(3500), refund.Amount)
+	assert.Equal(t, entities.TransactionStatusCompleted, refund.Status)
+	f.assertExpectations(t)
+}
+
+func TestTransaction_Refund_Partial(t *testing.T) {
+	f := newRefundFixture(entities.MoneyFromCents(0), entities.MoneyFromCents(8000))
+	amount := entities.MoneyFromCents(2000)
+	f.expectRefund(amount, entities.MoneyFromCents(2000), entities.TransactionStatusPartiallyRefunded)
+
+	refund, err := f.tx.Refund(f.ctx, 99, 2, &amount)
+	assert.NoError(t, err)
+	assert.Equal(t, amount, refund.Amount)
+	f.assertExpectations(t)
+}
+
+func TestTransaction_Refund_ExceedsRemaining(t *testing.T) {
+	f := newRefundFixture(entities.MoneyFromCents(4000), entities.MoneyFromCents(8000))
+	amount := entities.MoneyFromCents(1001)
+
+	refund, err := f.tx.Refund(f.ctx, 99, 2, &amount)
+	assert.ErrorIs(t, err, ErrRefundExceedsAmount)
+	assert.Nil(t, refund)
+	f.transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Refund_InsufficientPayeeBalance(t *testing.T) {
+	f := newRefundFixture(entities.MoneyFromCents(0), entities.MoneyFromCents(1000))
+
+	_, err := f.tx.Refund(f.ctx, 99, 2, nil)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	f.transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Refund_OnlyPayee(t *testing.T) {
+	f := newRefundFixture(entities.MoneyFromCents(0), entities.MoneyFromCents(8000))
+
+	_, err := f.tx.Refund(f.ctx, 99, 1, nil)
+	assert.ErrorIs(t, err, ErrTransferAccessDenied)
+}
+
+func TestTransaction_Refund_NotRefundable(t *testing.T) {
+	f := newRefundFixture(entities.MoneyFromCents(5000), entities.MoneyFromCents(8000))
+	f.original.Status = entities.TransactionStatusRefunded
+
+	_, err := f.tx.Refund(f.ctx, 99, 2, nil)
+	assert.ErrorIs(t, err, ErrTransferNotRefundable)
+}
+
+func TestTransaction_Refund_InvalidAmount(t *testing.T) {
+	f := newRefundFixture(entities.MoneyFromCents(0), entities.MoneyFromCents(8000))
+	amount := entities.MoneyFromCents(0)
+
+	_, err := f.tx.Refund(f.ctx, 99, 2, &amount)
+	assert.ErrorIs(t, err, ErrInvalidRefundAmount)
+}
+
+func TestTransaction_Refund_PartialAfterPartialKeepsStatus(t *testing.T) {
+	f := newRefundFixture(entities.MoneyFromCents(1000), entities.MoneyFromCents(8000))
+	f.original.Status = entities.TransactionStatusPartiallyRefunded
+	amount := entities.MoneyFromCents(1000)
+	f.expectRefund(amount, entities.MoneyFromCents(2000), entities.TransactionStatusPartiallyRefunded)
+
+	_, err := f.tx.Refund(f.ctx, 99, 2, &amount)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusPartiallyRefunded, f.original.Status)
+	f.assertExpectations(t)
+}
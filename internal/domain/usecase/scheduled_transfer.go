@@ -0,0 +1,392 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+var (
+	ErrScheduledTransferNotFound        = errors.New("scheduled transfer not found")
+	ErrScheduledTransferAccessDenied    = errors.New("scheduled transfer does not belong to the requester")
+	ErrScheduledTransferNotCancellable  = errors.New("scheduled transfer can no longer be cancelled")
+	ErrScheduledTransferNotRecurring    = errors.New("only standing orders can be paused or resumed")
+	ErrScheduledTransferNotPausable     = errors.New("scheduled transfer can no longer be paused")
+	ErrScheduledTransferNotPaused       = errors.New("scheduled transfer is not paused")
+	ErrInvalidScheduledAmount           = errors.New("scheduled amount must be greater than zero")
+	ErrExecuteAtNotInFuture             = errors.New("execution date must be in the future")
+	ErrInvalidInsufficientBalancePolicy = errors.New("insufficient balance policy must be SKIP or RETRY")
+)
+
+// errRunInterrupted is recorded for a run whose scheduler stopped before
+// recording the outcome. Whether money moved is unknown, so it is not retried.
+var errRunInterrupted = errors.New("execution interrupted")
+
+type ScheduleInput struct {
+	PayerID int64
+	PayeeID int64
+	// Amount is in the payer's currency.
+	Amount entities.Money
+	// ExecuteAt is the first execution, or for a standing order the date
+	// from which its occurrences start.
+	ExecuteAt             time.Time
+	Recurrence            entities.Recurrence
+	OnInsufficientBalance entities.InsufficientBalancePolicy
+}
+
+type ScheduledTransfers struct {
+	scheduledRepo port.ScheduledTransferRepository
+	transactions  *Transaction
+	unitOfWork    port.UnitOfWork
+	// lease is how long a scheduler owns the transfers it claimed.
+	lease time.Duration
+	// location is the calendar standing orders follow.
+	location *time.Location
+	// An occurrence the payer cannot afford is retried every retryDelay, up
+	// to maxRetries times, when its policy is RETRY.
+	retryDelay time.Duration
+	maxRetries int
+	now        func() time.Time
+}
+
+func NewScheduledTransfers(
+	scheduledRepo port.ScheduledTransferRepository,
+	transactions *Transaction,
+	unitOfWork port.UnitOfWork,
+	lease time.Duration,
+	location *time.Location,
+	retryDelay time.Duration,
+	maxRetries int,
+) *ScheduledTransfers {
+	return &ScheduledTransfers{
+		scheduledRepo: scheduledRepo,
+		transactions:  transactions,
+		unitOfWork:    unitOfWork,
+		lease:         lease,
+		location:      location,
+		retryDelay:    retryDelay,
+		maxRetries:    maxRetries,
+		now:           time.Now,
+	}
+}
+
+// Schedule records a transfer to be executed at input.ExecuteAt, or a
+// standing order repeated by input.Recurrence. Balance and limits are only
+// checked when it runs.
+func (s *ScheduledTransfers) Schedule(ctx context.Context, input ScheduleInput) (*entities.ScheduledTransfer, error) {
+	if !input.Amount.IsPositive() {
+		return nil, ErrInvalidScheduledAmount
+	}
+	if !input.ExecuteAt.After(s.now()) {
+		return nil, ErrExecuteAtNotInFuture
+	}
+	recurrence := input.Recurrence
+	if recurrence.Frequency == entities.RecurrenceMonthly && recurrence.DayOfMonth == 0 {
+		recurrence.DayOfMonth = input.ExecuteAt.In(s.location).Day()
+	}
+	if err := recurrence.Validate(); err != nil {
+		return nil, err
+	}
+	policy := input.OnInsufficientBalance
+	switch policy {
+	case "":
+		policy = entities.InsufficientBalanceSkip
+	case entities.InsufficientBalanceSkip, entities.InsufficientBalanceRetry:
+	default:
+		return nil, ErrInvalidInsufficientBalancePolicy
+	}
+	executeAt := input.ExecuteAt
+	if !recurrence.IsZero() {
+		executeAt = recurrence.First(executeAt, s.location)
+		if recurrence.IsOver(executeAt, 0) {
+			return nil, entities.ErrInvalidRecurrence
+		}
+	}
+
+	payerWallet, _, err := s.transactions.transferWallets(ctx, input.PayerID, input.PayeeID)
+	if err != nil {
+		return nil, err
+	}
+	if payerWallet.Type == entities.MerchantWallet {
+		return nil, ErrMerchantCannotTransfer
+	}
+
+	currency := payerWallet.CurrencyCode()
+	scheduled := &entities.ScheduledTransfer{
+		PayerID:               input.PayerID,
+		PayeeID:               input.PayeeID,
+		Amount:                entities.NewMoney(input.Amount.Cents, currency),
+		Currency:              currency,
+		ExecuteAt:             executeAt,
+		Status:                entities.ScheduledTransferStatusScheduled,
+		Recurrence:            recurrence,
+		OnInsufficientBalance: policy,
+	}
+	if err := s.scheduledRepo.Create(ctx, scheduled); err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// Get returns a scheduled transfer only to its payer or payee.
+func (s *ScheduledTransfers) Get(ctx context.Context, id, requesterID int64) (*entities.ScheduledTransfer, error) {
+	scheduled, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled.PayerID != requesterID && scheduled.PayeeID != requesterID {
+		return nil, ErrScheduledTransferAccessDenied
+	}
+	return scheduled, nil
+}
+
+// List returns the scheduled transfers payerID created, by id.
+func (s *ScheduledTransfers) List(ctx context.Context, payerID, afterID int64, limit int) ([]entities.ScheduledTransfer, error) {
+	return s.scheduledRepo.ListByPayer(ctx, payerID, afterID, limit)
+}
+
+// Runs returns the latest runs of a scheduled transfer to its payer or
+// payee.
+func (s *ScheduledTransfers) Runs(ctx context.Context, id, requesterID int64, limit int) ([]entities.ScheduledTransferRun, error) {
+	if _, err := s.Get(ctx, id, requesterID); err != nil {
+		return nil, err
+	}
+	return s.scheduledRepo.ListRuns(ctx, id, limit)
+}
+
+// Cancel stops a scheduled transfer that has not started executing. Only the
+// payer may cancel.
+func (s *ScheduledTransfers) Cancel(ctx context.Context, id, requesterID int64) (*entities.ScheduledTransfer, error) {
+	scheduled, err := s.getOwned(ctx, id, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if !scheduled.IsCancellable(now) {
+		return nil, ErrScheduledTransferNotCancellable
+	}
+	from := scheduled.Status
+	scheduled.Status = entities.ScheduledTransferStatusCancelled
+	return s.transition(ctx, scheduled, from, now, ErrScheduledTransferNotCancellable)
+}
+
+// Pause stops a standing order from running until it is resumed. Only the
+// payer may pause.
+func (s *ScheduledTransfers) Pause(ctx context.Context, id, requesterID int64) (*entities.ScheduledTransfer, error) {
+	scheduled, err := s.getOwned(ctx, id, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !scheduled.IsRecurring() {
+		return nil, ErrScheduledTransferNotRecurring
+	}
+	now := s.now()
+	if scheduled.Status != entities.ScheduledTransferStatusScheduled || scheduled.IsLeased(now) {
+		return nil, ErrScheduledTransferNotPausable
+	}
+	scheduled.Status = entities.ScheduledTransferStatusPaused
+	return s.transition(ctx, scheduled, entities.ScheduledTransferStatusScheduled, now, ErrScheduledTransferNotPausable)
+}
+
+// Resume reactivates a paused standing order. Occurrences missed while it
+// was paused are skipped, which completes the order if none is left.
+func (s *ScheduledTransfers) Resume(ctx context.Context, id, requesterID int64) (*entities.ScheduledTransfer, error) {
+	scheduled, err := s.getOwned(ctx, id, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	if !scheduled.IsRecurring() {
+		return nil, ErrScheduledTransferNotRecurring
+	}
+	if scheduled.Status != entities.ScheduledTransferStatusPaused {
+		return nil, ErrScheduledTransferNotPaused
+	}
+	now := s.now()
+	scheduled.Status = entities.ScheduledTransferStatusScheduled
+	scheduled.SkipMissed(now, s.location)
+	return s.transition(ctx, scheduled, entities.ScheduledTransferStatusPaused, now, ErrScheduledTransferNotPaused)
+}
+
+func (s *ScheduledTransfers) transition(ctx context.Context, scheduled *entities.ScheduledTransfer, from entities.ScheduledTransferStatus, now time.Time, changed error) (*entities.ScheduledTransfer, error) {
+	err := s.scheduledRepo.Transition(ctx, scheduled, from, now)
+	if errors.Is(err, port.ErrScheduledTransferChanged) {
+		return nil, changed
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// RunDue executes up to limit occurrences that are due and returns how many
+// of them were executed. An occurrence that cannot be made is retried or
+// skipped according to the transfer's policy, and its payer is notified
+// when it is given up.
+func (s *ScheduledTransfers) RunDue(ctx context.Context, limit int) (int, error) {
+	due, err := s.scheduledRepo.ClaimDue(ctx, s.now(), s.lease, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	executed := 0
+	for i := range due {
+		if ctx.Err() != nil {
+			return executed, ctx.Err()
+		}
+		ok, err := s.run(ctx, &due[i])
+		if err != nil {
+			return executed, err
+		}
+		if ok {
+			executed++
+		}
+	}
+	return executed, nil
+}
+
+// run makes one attempt at the current occurrence of scheduled. The run is
+// recorded before the transfer is made, so a claim that comes back after a
+// crash never pays twice.
+func (s *ScheduledTransfers) run(ctx context.Context, scheduled *entities.ScheduledTransfer) (bool, error) {
+	run := &entities.ScheduledTransferRun{
+		ScheduledTransferID: scheduled.ID,
+		ScheduledFor:        scheduled.ExecuteAt,
+		Attempt:             scheduled.Attempts + 1,
+		Status:              entities.ScheduledTransferRunStatusRunning,
+		StartedAt:           s.now(),
+	}
+	err := s.scheduledRepo.CreateRun(ctx, run)
+	if errors.Is(err, port.ErrScheduledTransferRunExists) {
+		return false, s.resume(ctx, scheduled, run.Attempt)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	transaction, err := s.transactions.Execute(ctx, TransferInput{
+		PayerID: scheduled.PayerID,
+		PayeeID: scheduled.PayeeID,
+		Amount:  scheduled.Amount,
+	})
+	if err != nil && ctx.Err() != nil {
+		// Left RUNNING: the next claim records it as interrupted.
+		return false, ctx.Err()
+	}
+	// Recording the outcome outlives cancellation, or a finished transfer
+	// would be left interrupted.
+	bookkeeping := context.WithoutCancel(ctx)
+	if err != nil {
+		var denied *AuthorizationDeniedError
+		if errors.As(err, &denied) {
+			run.TransactionID = &denied.Transaction.ID
+		}
+		return false, s.fail(bookkeeping, scheduled, run, err, true)
+	}
+	run.TransactionID = &transaction.ID
+	return true, s.succeed(bookkeeping, scheduled, run)
+}
+
+// resume settles an attempt whose run was already recorded by an earlier
+// claim.
+func (s *ScheduledTransfers) resume(ctx context.Context, scheduled *entities.ScheduledTransfer, attempt int) error {
+	run, err := s.scheduledRepo.GetRun(ctx, scheduled.ID, scheduled.ExecuteAt, attempt)
+	if err != nil {
+		return err
+	}
+	if run.Status == entities.ScheduledTransferRunStatusRunning {
+		// The payer is not told it failed, as the transfer may have been made.
+		return s.fail(ctx, scheduled, run, errRunInterrupted, false)
+	}
+	return s.settle(ctx, scheduled, run, false)
+}
+
+func (s *ScheduledTransfers) succeed(ctx context.Context, scheduled *entities.ScheduledTransfer, run *entities.ScheduledTransferRun) error {
+	finishedAt := s.now()
+	run.Status = entities.ScheduledTransferRunStatusSucceeded
+	run.FinishedAt = &finishedAt
+	return s.settle(ctx, scheduled, run, false)
+}
+
+func (s *ScheduledTransfers) fail(ctx context.Context, scheduled *entities.ScheduledTransfer, run *entities.ScheduledTransferRun, cause error, notify bool) error {
+	finishedAt := s.now()
+	run.Status = entities.ScheduledTransferRunStatusFailed
+	run.Error = cause.Error()
+	run.FailureCode = runFailure(cause)
+	run.FinishedAt = &finishedAt
+	return s.settle(ctx, scheduled, run, notify)
+}
+
+func runFailure(cause error) entities.ScheduledTransferRunFailure {
+	switch {
+	case errors.Is(cause, ErrInsufficientBalance):
+		return entities.ScheduledTransferRunFailureInsufficientBalance
+	case errors.Is(cause, errRunInterrupted):
+		return entities.ScheduledTransferRunFailureInterrupted
+	default:
+		return entities.ScheduledTransferRunFailureTransferFailed
+	}
+}
+
+// settle records a finished run and moves scheduled on: to its next
+// occurrence, or to a retry of the current one. The payer is notified of a
+// failure only once the occurrence is given up.
+func (s *ScheduledTransfers) settle(ctx context.Context, scheduled *entities.ScheduledTransfer, run *entities.ScheduledTransferRun, notify bool) error {
+	scheduled.TransactionID = run.TransactionID
+	scheduled.FailureReason = run.Error
+	retry := false
+	switch {
+	case run.Status == entities.ScheduledTransferRunStatusSucceeded:
+		scheduled.CloseOccurrence(entities.ScheduledTransferStatusExecuted, s.location)
+	case s.retries(scheduled, run):
+		retry = true
+		scheduled.RetryOccurrence(s.now().Add(s.retryDelay))
+	default:
+		scheduled.CloseOccurrence(entities.ScheduledTransferStatusFailed, s.location)
+	}
+
+	return s.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		if err := repos.Scheduled.FinishRun(ctx, run); err != nil {
+			return err
+		}
+		if err := repos.Scheduled.Release(ctx, scheduled); err != nil {
+			return err
+		}
+		if !notify || retry {
+			return nil
+		}
+		return enqueue(ctx, repos, entities.ScheduledTransferFailedPayload{PayerID: scheduled.PayerID, ScheduledTransferID: scheduled.ID, Amount: scheduled.Amount, Reason: run.Error})
+	})
+}
+
+// retries reports whether a failed run is attempted again. Only a lack of
+// balance is retried, as it is the one failure the payer can fix in time.
+func (s *ScheduledTransfers) retries(scheduled *entities.ScheduledTransfer, run *entities.ScheduledTransferRun) bool {
+	return scheduled.OnInsufficientBalance == entities.InsufficientBalanceRetry &&
+		run.FailureCode == entities.ScheduledTransferRunFailureInsufficientBalance &&
+		scheduled.Attempts < s.maxRetries
+}
+
+func (s *ScheduledTransfers) getOwned(ctx context.Context, id, payerID int64) (*entities.ScheduledTransfer, error) {
+	scheduled, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled.PayerID != payerID {
+		return nil, ErrScheduledTransferAccessDenied
+	}
+	return scheduled, nil
+}
+
+func (s *ScheduledTransfers) get(ctx context.Context, id int64) (*entities.ScheduledTransfer, error) {
+	scheduled, err := s.scheduledRepo.GetByID(ctx, id)
+	if errors.Is(err, port.ErrScheduledTransferNotFound) {
+		return nil, ErrScheduledTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
@@ -0,0 +1,415 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var scheduleNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type mockScheduledTransferRepo struct{ mock.Mock }
+
+func (m *mockScheduledTransferRepo) Create(ctx context.Context, scheduled *entities.ScheduledTransfer) error {
+	args := m.Called(ctx, scheduled)
+	return args.Error(0)
+}
+
+func (m *mockScheduledTransferRepo) GetByID(ctx context.Context, id int64) (*entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, id)
+	scheduled, _ := args.Get(0).(*entities.ScheduledTransfer)
+	return scheduled, args.Error(1)
+}
+
+func (m *mockScheduledTransferRepo) ListByPayer(ctx context.Context, payerID, afterID int64, limit int) ([]entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, payerID, afterID, limit)
+	scheduled, _ := args.Get(0).([]entities.ScheduledTransfer)
+	return scheduled, args.Error(1)
+}
+
+func (m *mockScheduledTransferRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.ScheduledTransfer, error) {
+	args := m.Called(ctx, now, lease, limit)
+	scheduled, _ := args.Get(0).([]entities.ScheduledTransfer)
+	return scheduled, args.Error(1)
+}
+
+func (m *mockScheduledTransferRepo) Transition(ctx context.Context, scheduled *entities.ScheduledTransfer, from entities.ScheduledTransferStatus, now time.Time) error {
+	args := m.Called(ctx, scheduled, from, now)
+	return args.Error(0)
+}
+
+func (m *mockScheduledTransferRepo) Release(ctx context.Context, scheduled *entities.ScheduledTransfer) error {
+	args := m.Called(ctx, scheduled)
+	return args.Error(0)
+}
+
+func (m *mockScheduledTransferRepo) CreateRun(ctx context.Context, run *entities.ScheduledTransferRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *mockScheduledTransferRepo) GetRun(ctx context.Context, scheduledTransferID int64, scheduledFor time.Time, attempt int) (*entities.ScheduledTransferRun, error) {
+	args := m.Called(ctx, scheduledTransferID, scheduledFor, attempt)
+	run, _ := args.Get(0).(*entities.ScheduledTransferRun)
+	return run, args.Error(1)
+}
+
+func (m *mockScheduledTransferRepo) FinishRun(ctx context.Context, run *entities.ScheduledTransferRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *mockScheduledTransferRepo) ListRuns(ctx context.Context, scheduledTransferID int64, limit int) ([]entities.ScheduledTransferRun, error) {
+	args := m.Called(ctx, scheduledTransferID, limit)
+	runs, _ := args.Get(0).([]entities.ScheduledTransferRun)
+	return runs, args.Error(1)
+}
+
+func finishedRun(status entities.ScheduledTransferRunStatus, transactionID *int64, reason string) interface{} {
+	return mock.MatchedBy(func(run *entities.ScheduledTransferRun) bool {
+		return run.Status == status && run.Error == reason && run.FinishedAt != nil &&
+			assert.ObjectsAreEqual(transactionID, run.TransactionID)
+	})
+}
+
+func released(check func(scheduled *entities.ScheduledTransfer) bool) interface{} {
+	return mock.MatchedBy(check)
+}
+
+func newTestScheduledTransfers(scheduledRepo *mockScheduledTransferRepo, tx *Transaction, outboxRepo *MockOutboxRepository) *ScheduledTransfers {
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Scheduled: scheduledRepo, Outbox: outboxRepo}}
+	scheduled := NewScheduledTransfers(scheduledRepo, tx, unitOfWork, 5*time.Minute, time.UTC, time.Hour, 3)
+	scheduled.now = func() time.Time { return scheduleNow }
+	return scheduled
+}
+
+func dueTransfer(cents int64) entities.ScheduledTransfer {
+	return entities.ScheduledTransfer{
+		ID: 7, PayerID: 1, PayeeID: 2,
+		Amount:    entities.MoneyFromCents(cents),
+		Currency:  entities.DefaultCurrency,
+		ExecuteAt: scheduleNow.Add(-time.Minute),
+		Status:    entities.ScheduledTransferStatusScheduled,
+	}
+}
+
+func monthlyTransfer(cents int64) entities.ScheduledTransfer {
+	scheduled := dueTransfer(cents)
+	scheduled.ExecuteAt = time.Date(2024, 12, 31, 9, 0, 0, 0, time.UTC)
+	scheduled.Recurrence = entities.Recurrence{Frequency: entities.RecurrenceMonthly, DayOfMonth: 31}
+	scheduled.OnInsufficientBalance = entities.InsufficientBalanceRetry
+	return scheduled
+}
+
+func TestScheduledTransfers_Schedule(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	scheduledRepo := new(mockScheduledTransferRepo)
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+	executeAt := scheduleNow.Add(24 * time.Hour)
+
+	scheduledRepo.On("Create", ctx, mock.MatchedBy(func(scheduled *entities.ScheduledTransfer) bool {
+		return scheduled.Status == entities.ScheduledTransferStatusScheduled && scheduled.ExecuteAt.Equal(executeAt) &&
+			scheduled.Currency == entities.DefaultCurrency
+	})).Return(nil).Once()
+
+	scheduled, err := newTestScheduledTransfers(scheduledRepo, tx, nil).Schedule(ctx, ScheduleInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000), ExecuteAt: executeAt})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(5000), scheduled.Amount)
+	scheduledRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransfers_Schedule_RejectsPastDate(t *testing.T) {
+	scheduledRepo := new(mockScheduledTransferRepo)
+
+	_, err := newTestScheduledTransfers(scheduledRepo, nil, nil).Schedule(context.Background(), ScheduleInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000), ExecuteAt: scheduleNow})
+	assert.ErrorIs(t, err, ErrExecuteAtNotInFuture)
+	scheduledRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestScheduledTransfers_Schedule_StandingOrderStartsOnFirstOccurrence(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	scheduledRepo := new(mockScheduledTransferRepo)
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+	lastBusinessDay := time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC)
+
+	scheduledRepo.On("Create", ctx, mock.MatchedBy(func(scheduled *entities.ScheduledTransfer) bool {
+		return scheduled.ExecuteAt.Equal(lastBusinessDay) && scheduled.IsRecurring() &&
+			scheduled.OnInsufficientBalance == entities.InsufficientBalanceSkip
+	})).Return(nil).Once()
+
+	_, err := newTestScheduledTransfers(scheduledRepo, tx, nil).Schedule(ctx, ScheduleInput{
+		PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000),
+		ExecuteAt:  time.Date(2025, 1, 2, 9, 0, 0, 0, time.UTC),
+		Recurrence: entities.Recurrence{Frequency: entities.RecurrenceLastBusinessDay},
+	})
+	assert.NoError(t, err)
+	scheduledRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransfers_Schedule_MonthlyDefaultsToStartDay(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	scheduledRepo := new(mockScheduledTransferRepo)
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+	executeAt := time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	scheduledRepo.On("Create", ctx, mock.MatchedBy(func(scheduled *entities.ScheduledTransfer) bool {
+		return scheduled.ExecuteAt.Equal(executeAt) && scheduled.Recurrence.DayOfMonth == 15
+	})).Return(nil).Once()
+
+	_, err := newTestScheduledTransfers(scheduledRepo, tx, nil).Schedule(ctx, ScheduleInput{
+		PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000), ExecuteAt: executeAt,
+		Recurrence: entities.Recurrence{Frequency: entities.RecurrenceMonthly},
+	})
+	assert.NoError(t, err)
+	scheduledRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransfers_Schedule_RejectsInvalidRules(t *testing.T) {
+	scheduledRepo := new(mockScheduledTransferRepo)
+	scheduled := newTestScheduledTransfers(scheduledRepo, nil, nil)
+	executeAt := scheduleNow.Add(24 * time.Hour)
+	endsAt := scheduleNow.Add(time.Hour)
+
+	_, err := scheduled.Schedule(context.Background(), ScheduleInput{
+		PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000), ExecuteAt: executeAt,
+		Recurrence: entities.Recurrence{Frequency: entities.RecurrenceWeekly, EndsAt: &endsAt},
+	})
+	assert.ErrorIs(t, err, entities.ErrInvalidRecurrence)
+
+	_, err = scheduled.Schedule(context.Background(), ScheduleInput{
+		PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000), ExecuteAt: executeAt,
+		OnInsufficientBalance: "WAIT",
+	})
+	assert.ErrorIs(t, err, ErrInvalidInsufficientBalancePolicy)
+	scheduledRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestScheduledTransfers_Cancel(t *testing.T) {
+	ctx := context.Background()
+	leasedUntil := scheduleNow.Add(time.Minute)
+	pending := dueTransfer(5000)
+	pending.ExecuteAt = scheduleNow.Add(time.Hour)
+	running := dueTransfer(5000)
+	running.ID = 8
+	running.LeasedUntil = &leasedUntil
+
+	scheduledRepo := new(mockScheduledTransferRepo)
+	scheduledRepo.On("GetByID", ctx, int64(7)).Return(&pending, nil)
+	scheduledRepo.On("GetByID", ctx, int64(8)).Return(&running, nil)
+	scheduledRepo.On("Transition", ctx, mock.Anything, entities.ScheduledTransferStatusScheduled, scheduleNow).Return(nil).Once()
+	scheduled := newTestScheduledTransfers(scheduledRepo, nil, nil)
+
+	_, err := scheduled.Cancel(ctx, 7, 2)
+	assert.ErrorIs(t, err, ErrScheduledTransferAccessDenied)
+
+	_, err = scheduled.Cancel(ctx, 8, 1)
+	assert.ErrorIs(t, err, ErrScheduledTransferNotCancellable)
+
+	cancelled, err := scheduled.Cancel(ctx, 7, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ScheduledTransferStatusCancelled, cancelled.Status)
+	scheduledRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransfers_RunDue_Executes(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	review := entities.AuthorizationDecision{Outcome: entities.AuthorizationReview, ReasonCode: "MANUAL_REVIEW"}
+	expectAuthorization(transactionRepo, authService, ctx, 99, review)
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusPending, "authorization under review").Return(nil).Once()
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	transactionID := int64(99)
+	scheduledRepo := new(mockScheduledTransferRepo)
+	scheduledRepo.On("ClaimDue", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.ScheduledTransfer{dueTransfer(5000)}, nil)
+	scheduledRepo.On("CreateRun", ctx, mock.MatchedBy(func(run *entities.ScheduledTransferRun) bool {
+		return run.ScheduledTransferID == 7 && run.ScheduledFor.Equal(scheduleNow.Add(-time.Minute)) &&
+			run.Status == entities.ScheduledTransferRunStatusRunning
+	})).Return(nil).Once()
+	scheduledRepo.On("FinishRun", mock.Anything, finishedRun(entities.ScheduledTransferRunStatusSucceeded, &transactionID, "")).Return(nil).Once()
+	scheduledRepo.On("Release", mock.Anything, released(func(scheduled *entities.ScheduledTransfer) bool {
+		return scheduled.Status == entities.ScheduledTransferStatusExecuted && assert.ObjectsAreEqual(&transactionID, scheduled.TransactionID)
+	})).Return(nil).Once()
+
+	executed, err := newTestScheduledTransfers(scheduledRepo, tx, nil).RunDue(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, executed)
+	scheduledRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransfers_RunDue_InsufficientBalanceNotifiesPayer(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	scheduledRepo := new(mockScheduledTransferRepo)
+	outboxRepo := new(MockOutboxRepository)
+	scheduledRepo.On("ClaimDue", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.ScheduledTransfer{dueTransfer(20000)}, nil)
+	scheduledRepo.On("CreateRun", ctx, mock.Anything).Return(nil).Once()
+	scheduledRepo.On("FinishRun", mock.Anything, finishedRun(entities.ScheduledTransferRunStatusFailed, nil, ErrInsufficientBalance.Error())).Return(nil).Once()
+	scheduledRepo.On("Release", mock.Anything, released(func(scheduled *entities.ScheduledTransfer) bool {
+		return scheduled.Status == entities.ScheduledTransferStatusFailed && scheduled.FailureReason == ErrInsufficientBalance.Error()
+	})).Return(nil).Once()
+	outboxRepo.On("Create", mock.Anything, mock.MatchedBy(func(message *entities.OutboxMessage) bool {
+		var payload entities.ScheduledTransferFailedPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return false
+		}
+		return message.EventType == entities.OutboxEventScheduledTransferFailed &&
+			payload == entities.ScheduledTransferFailedPayload{PayerID: 1, ScheduledTransferID: 7, Amount: entities.MoneyFromCents(20000), Reason: ErrInsufficientBalance.Error()}
+	})).Return(nil).Once()
+
+	executed, err := newTestScheduledTransfers(scheduledRepo, tx, outboxRepo).RunDue(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, executed)
+	scheduledRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+	transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestScheduledTransfers_RunDue_DoesNotRepeatInterruptedRun(t *testing.T) {
+	ctx := context.Background()
+	due := dueTransfer(5000)
+	scheduledRepo := new(mockScheduledTransferRepo)
+	outboxRepo := new(MockOutboxRepository)
+	scheduledRepo.On("ClaimDue", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.ScheduledTransfer{due}, nil)
+	scheduledRepo.On("CreateRun", ctx, mock.Anything).Return(port.ErrScheduledTransferRunExists).Once()
+	scheduledRepo.On("GetRun", ctx, int64(7), due.ExecuteAt, 1).Return(&entities.ScheduledTransferRun{ID: 3, ScheduledTransferID: 7, ScheduledFor: due.ExecuteAt, Status: entities.ScheduledTransferRunStatusRunning}, nil)
+	scheduledRepo.On("FinishRun", mock.Anything, finishedRun(entities.ScheduledTransferRunStatusFailed, nil, errRunInterrupted.Error())).Return(nil).Once()
+	scheduledRepo.On("Release", mock.Anything, released(func(scheduled *entities.ScheduledTransfer) bool {
+		return scheduled.Status == entities.ScheduledTransferStatusFailed && scheduled.FailureReason == errRunInterrupted.Error()
+	})).Return(nil).Once()
+
+	executed, err := newTestScheduledTransfers(scheduledRepo, nil, outboxRepo).RunDue(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, executed)
+	scheduledRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestScheduledTransfers_PauseAndResume(t *testing.T) {
+	ctx := context.Background()
+	oneOff := dueTransfer(5000)
+	oneOff.ID = 8
+	order := monthlyTransfer(5000)
+	order.ExecuteAt = time.Date(2024, 11, 30, 9, 0, 0, 0, time.UTC)
+	order.Status = entities.ScheduledTransferStatusPaused
+
+	scheduledRepo := new(mockScheduledTransferRepo)
+	scheduledRepo.On("GetByID", ctx, int64(7)).Return(&order, nil)
+	scheduledRepo.On("GetByID", ctx, int64(8)).Return(&oneOff, nil)
+	scheduledRepo.On("Transition", ctx, mock.Anything, entities.ScheduledTransferStatusPaused, scheduleNow).Return(nil).Once()
+	scheduled := newTestScheduledTransfers(scheduledRepo, nil, nil)
+
+	_, err := scheduled.Pause(ctx, 8, 1)
+	assert.ErrorIs(t, err, ErrScheduledTransferNotRecurring)
+
+	_, err = scheduled.Pause(ctx, 7, 1)
+	assert.ErrorIs(t, err, ErrScheduledTransferNotPausable)
+
+	// Occurrences missed while paused are skipped, not counted.
+	resumed, err := scheduled.Resume(ctx, 7, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ScheduledTransferStatusScheduled, resumed.Status)
+	assert.Equal(t, time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC), resumed.ExecuteAt)
+	assert.Equal(t, 0, resumed.Occurrences)
+
+	_, err = scheduled.Resume(ctx, 7, 1)
+	assert.ErrorIs(t, err, ErrScheduledTransferNotPaused)
+	scheduledRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransfers_RunDue_StandingOrderMovesToNextOccurrence(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	expectAuthorization(transactionRepo, authService, ctx, 99, entities.AuthorizationDecision{Outcome: entities.AuthorizationReview, ReasonCode: "MANUAL_REVIEW"})
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusPending, "authorization under review").Return(nil).Once()
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	scheduledRepo := new(mockScheduledTransferRepo)
+	scheduledRepo.On("ClaimDue", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.ScheduledTransfer{monthlyTransfer(5000)}, nil)
+	scheduledRepo.On("CreateRun", ctx, mock.Anything).Return(nil).Once()
+	scheduledRepo.On("FinishRun", mock.Anything, mock.Anything).Return(nil).Once()
+	scheduledRepo.On("Release", mock.Anything, released(func(scheduled *entities.ScheduledTransfer) bool {
+		return scheduled.Status == entities.ScheduledTransferStatusScheduled && scheduled.Occurrences == 1 &&
+			scheduled.ExecuteAt.Equal(time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC))
+	})).Return(nil).Once()
+
+	executed, err := newTestScheduledTransfers(scheduledRepo, tx, nil).RunDue(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, executed)
+	scheduledRepo.AssertExpectations(t)
+}
+
+func TestScheduledTransfers_RunDue_RetriesInsufficientBalance(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+	retryAt := scheduleNow.Add(time.Hour)
+
+	scheduledRepo := new(mockScheduledTransferRepo)
+	outboxRepo := new(MockOutboxRepository)
+	scheduledRepo.On("ClaimDue", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.ScheduledTransfer{monthlyTransfer(20000)}, nil)
+	scheduledRepo.On("CreateRun", ctx, mock.MatchedBy(func(run *entities.ScheduledTransferRun) bool {
+		return run.Attempt == 1
+	})).Return(nil).Once()
+	scheduledRepo.On("FinishRun", mock.Anything, finishedRun(entities.ScheduledTransferRunStatusFailed, nil, ErrInsufficientBalance.Error())).Return(nil).Once()
+	scheduledRepo.On("Release", mock.Anything, released(func(scheduled *entities.ScheduledTransfer) bool {
+		return scheduled.Status == entities.ScheduledTransferStatusScheduled && scheduled.Attempts == 1 &&
+			scheduled.Occurrences == 0 && assert.ObjectsAreEqual(&retryAt, scheduled.NextAttemptAt)
+	})).Return(nil).Once()
+
+	executed, err := newTestScheduledTransfers(scheduledRepo, tx, outboxRepo).RunDue(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, executed)
+	scheduledRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestRunFailure(t *testing.T) {
+	assert.Equal(t, entities.ScheduledTransferRunFailureInsufficientBalance, runFailure(fmt.Errorf("settling: %w", ErrInsufficientBalance)))
+	assert.Equal(t, entities.ScheduledTransferRunFailureInterrupted, runFailure(errRunInterrupted))
+	assert.Equal(t, entities.ScheduledTransferRunFailureTransferFailed, runFailure(errors.New("insufficient balance")))
+}
+
+func TestScheduledTransfers_RunDue_SkipsOccurrenceAfterLastRetry(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+	due := monthlyTransfer(20000)
+	retryAt := scheduleNow.Add(-time.Minute)
+	due.Attempts = 3
+	due.NextAttemptAt = &retryAt
+
+	scheduledRepo := new(mockScheduledTransferRepo)
+	outboxRepo := new(MockOutboxRepository)
+	scheduledRepo.On("ClaimDue", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.ScheduledTransfer{due}, nil)
+	scheduledRepo.On("CreateRun", ctx, mock.MatchedBy(func(run *entities.ScheduledTransferRun) bool {
+		return run.Attempt == 4
+	})).Return(nil).Once()
+	scheduledRepo.On("FinishRun", mock.Anything, mock.Anything).Return(nil).Once()
+	scheduledRepo.On("Release", mock.Anything, released(func(scheduled *entities.ScheduledTransfer) bool {
+		return scheduled.Status == entities.ScheduledTransferStatusScheduled && scheduled.Attempts == 0 &&
+			scheduled.NextAttemptAt == nil && scheduled.Occurrences == 1 &&
+			scheduled.FailureReason == ErrInsufficientBalance.Error()
+	})).Return(nil).Once()
+	outboxRepo.On("Create", mock.Anything, mock.Anything).Return(nil).Once()
+
+	_, err := newTestScheduledTransfers(scheduledRepo, tx, outboxRepo).RunDue(ctx, 10)
+	assert.NoError(t, err)
+	scheduledRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
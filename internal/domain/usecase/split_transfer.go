@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"go-transfer/internal/domain/entities"
+)
+
+var (
+	ErrSplitTooFewPayees     = errors.New("a split transfer needs at least two payees")
+	ErrSplitDuplicatePayee   = errors.New("split payees must be different")
+	ErrSplitMixedShares      = errors.New("split shares must be all amounts or all percentages")
+	ErrInvalidSplitShare     = errors.New("split shares must be positive")
+	ErrSplitSumMismatch      = errors.New("split amounts must add up to the transfer amount")
+	ErrSplitNotSupported     = errors.New("split transfers cannot be held or use an exchange quote")
+	ErrSplitCurrencyMismatch = errors.New("split payees must use the payer's currency")
+)
+
+// SplitInput is the share of one payee of a split transfer, given either as
+// an amount or as a percentage of the transfer, the same way for every payee.
+type SplitInput struct {
+	PayeeID    int64
+	Amount     *entities.Money
+	Percentage *entities.Percentage
+}
+
+// validateSplit is validateTransaction for transfers with splits. Each leg is
+// charged the fees of its payee's wallet type, added up on the transfer, and
+// the payee of the first leg is returned as the transfer's.
+func (t *Transaction) validateSplit(ctx context.Context, input TransferInput) (*entities.Wallet, *entities.Wallet, *TransferQuote, error) {
+	if input.Hold || input.QuoteID != nil {
+		return nil, nil, nil, ErrSplitNotSupported
+	}
+	if len(input.Splits) < 2 {
+		return nil, nil, nil, ErrSplitTooFewPayees
+	}
+
+	var senderWallet *entities.Wallet
+	payeeWallets := make([]*entities.Wallet, 0, len(input.Splits))
+	seen := make(map[int64]bool, len(input.Splits))
+	for _, split := range input.Splits {
+		if seen[split.PayeeID] {
+			return nil, nil, nil, ErrSplitDuplicatePayee
+		}
+		seen[split.PayeeID] = true
+		payerWallet, payeeWallet, err := t.transferWallets(ctx, input.PayerID, split.PayeeID)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		senderWallet = payerWallet
+		payeeWallets = append(payeeWallets, payeeWallet)
+	}
+	if senderWallet.Type == entities.MerchantWallet {
+		return nil, nil, nil, ErrMerchantCannotTransfer
+	}
+
+	amount := entities.NewMoney(input.Amount.Cents, senderWallet.Currency)
+	shares, err := splitShares(amount, input.Splits)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	quote := &TransferQuote{PayerID: input.PayerID, PayeeID: input.Splits[0].PayeeID, Amount: amount}
+	fees := make([]entities.FeeBreakdown, 0, len(shares))
+	for i, share := range shares {
+		payeeWallet := payeeWallets[i]
+		if payeeWallet.CurrencyCode() != amount.Currency {
+			return nil, nil, nil, ErrSplitCurrencyMismatch
+		}
+		legFees, err := t.fees.Quote(senderWallet.Type, payeeWallet.Type, share)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		fees = append(fees, legFees)
+		quote.Legs = append(quote.Legs, QuoteLeg{PayeeID: input.Splits[i].PayeeID, PayeeWalletType: payeeWallet.Type, Amount: share})
+	}
+	if quote.Fees, err = entities.CombineFees(fees...); err != nil {
+		return nil, nil, nil, err
+	}
+	if quote.Fee, err = quote.Fees.Total(amount.Currency); err != nil {
+		return nil, nil, nil, err
+	}
+	if quote.Total, err = amount.Add(quote.Fee); err != nil {
+		return nil, nil, nil, err
+	}
+	return senderWallet, payeeWallets[0], quote, t.checkFunds(ctx, input.PayerID, senderWallet, quote)
+}
+
+// splitShares works out what each payee of a transfer of amount receives.
+func splitShares(amount entities.Money, splits []SplitInput) ([]entities.Money, error) {
+	byAmount := splits[0].Amount != nil
+	var percentages []entities.Percentage
+	shares := make([]entities.Money, 0, len(splits))
+	total := entities.NewMoney(0, amount.Currency)
+	for _, split := range splits {
+		if (split.Amount != nil) == (split.Percentage != nil) || (split.Amount != nil) != byAmount {
+			return nil, ErrSplitMixedShares
+		}
+		if !byAmount {
+			percentages = append(percentages, *split.Percentage)
+			continue
+		}
+		share := entities.NewMoney(split.Amount.Cents, amount.Currency)
+		if !share.IsPositive() {
+			return nil, ErrInvalidSplitShare
+		}
+		var err error
+		if total, err = total.Add(share); err != nil {
+			return nil, err
+		}
+		shares = append(shares, share)
+	}
+	if byAmount {
+		cmp, err := total.Cmp(amount)
+		if err != nil {
+			return nil, err
+		}
+		if cmp != 0 {
+			return nil, ErrSplitSumMismatch
+		}
+		return shares, nil
+	}
+
+	shares, err := entities.SplitByPercentages(amount, percentages)
+	if err != nil {
+		return nil, err
+	}
+	for _, share := range shares {
+		// A percentage too small for the amount rounds down to nothing.
+		if !share.IsPositive() {
+			return nil, ErrInvalidSplitShare
+		}
+	}
+	return shares, nil
+}
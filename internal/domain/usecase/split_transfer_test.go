@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func percentage(value entities.Percentage) *entities.Percentage {
+	return &value
+}
+
+func cents(value int64) *entities.Money {
+	amount := entities.MoneyFromCents(value)
+	return &amount
+}
+
+// newSplitFixture adds a courier (user 3, wallet 30) to the payer and the
+// merchant payee.
+func newSplitFixture(ctx context.Context) (*mockUserRepo, *mockWalletRepo) {
+	f := newTransferFixture(ctx, &entities.Wallet{ID: 30, OwnerID: 3, Type: entities.CommonWallet})
+	return f.userRepo, f.walletRepo
+}
+
+func TestTransaction_Execute_SplitPostsEveryLegTogether(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo := newSplitFixture(ctx)
+	transactionRepo := new(mockTransactionRepo)
+	ledgerRepo := new(MockLedgerRepository)
+	authService := new(mockAuthService)
+	outboxRepo := new(MockOutboxRepository)
+
+	fee := entities.MoneyFromCents(100)
+	fees := entities.NewFeeTable()
+	assert.NoError(t, fees.Set(entities.CommonWallet, entities.MerchantWallet, entities.FeeSchedule{Name: "merchant", Type: entities.FeeFlat, Flat: fee}))
+	revenueWallet := &entities.Wallet{ID: 40, Type: entities.SystemWallet}
+	walletRepo.On("GetSystemWallet", ctx, entities.SystemAccountRevenue, entities.DefaultCurrency).Return(revenueWallet, nil)
+	walletRepo.On("GetByID", ctx, revenueWallet.ID).Return(revenueWallet, nil)
+
+	seller, courier := entities.MoneyFromCents(3500), entities.MoneyFromCents(1500)
+	walletRepo.On("Debit", ctx, int64(10), entities.MoneyFromCents(5100), int64(0)).Return(nil).Once()
+	walletRepo.On("Credit", ctx, int64(20), seller, int64(0)).Return(nil).Once()
+	walletRepo.On("Credit", ctx, int64(30), courier, int64(0)).Return(nil).Once()
+	walletRepo.On("Credit", ctx, int64(40), fee, int64(0)).Return(nil).Once()
+	posting := append(entities.NewTransferPosting(99, 10, 20, seller), entities.NewTransferPosting(99, 10, 30, courier)...)
+	posting = append(posting, entities.NewTransferPosting(99, 10, 40, fee)...)
+	ledgerRepo.On("CreateEntries", ctx, posting).Return(nil).Once()
+
+	transactionRepo.On("Create", ctx, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.ReceiverID == 2 && transaction.Fee == fee && len(transaction.Legs) == 2 &&
+			transaction.Legs[0].Amount == seller && transaction.Legs[1].ReceiverID == 3 && transaction.Legs[1].Amount == courier
+	})).Return(int64(99), nil)
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusPending, entities.TransactionStatusAuthorizing, "authorization requested").Return(nil)
+	authService.On("Authorize", ctx, port.AuthorizationRequest{
+		TransferID:      99,
+		PayerID:         1,
+		PayeeID:         2,
+		Amount:          entities.MoneyFromCents(5000),
+		PayerWalletType: entities.CommonWallet,
+		PayeeWalletType: entities.MerchantWallet,
+		Splits: []port.AuthorizationSplit{
+			{PayeeID: 2, Amount: seller, PayeeWalletType: entities.MerchantWallet},
+			{PayeeID: 3, Amount: courier, PayeeWalletType: entities.CommonWallet},
+		},
+	}).Return(approved(), nil)
+	transactionRepo.On("UpdateAuthorization", ctx, int64(99), approved()).Return(nil)
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusCompleted, "transfer settled").Return(nil).Once()
+	outboxRepo.On("Create", ctx, transferNotification(2, 99, seller)).Return(nil).Once()
+	outboxRepo.On("Create", ctx, transferNotification(3, 99, courier)).Return(nil).Once()
+
+	locker := &fakeWalletLocker{}
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Ledger: ledgerRepo, Outbox: outboxRepo, Locker: locker}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, nil, fees, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{
+		PayerID: 1,
+		Amount:  entities.MoneyFromCents(5000),
+		Splits: []SplitInput{
+			{PayeeID: 2, Percentage: percentage(7000)},
+			{PayeeID: 3, Percentage: percentage(3000)},
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusCompleted, transaction.Status)
+	assert.Equal(t, [][]int64{{10, 20, 30}, {40}}, locker.locked)
+	walletRepo.AssertExpectations(t)
+	ledgerRepo.AssertExpectations(t)
+	authService.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestTransaction_Execute_SplitRejectsInvalidShares(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  TransferInput
+		expect error
+	}{
+		{"single payee", TransferInput{Splits: []SplitInput{{PayeeID: 2, Amount: cents(5000)}}}, ErrSplitTooFewPayees},
+		{"held", TransferInput{Hold: true, Splits: []SplitInput{{PayeeID: 2, Amount: cents(2500)}, {PayeeID: 3, Amount: cents(2500)}}}, ErrSplitNotSupported},
+		{"duplicate payee", TransferInput{Splits: []SplitInput{{PayeeID: 2, Amount: cents(2500)}, {PayeeID: 2, Amount: cents(2500)}}}, ErrSplitDuplicatePayee},
+		{"mixed shares", TransferInput{Splits: []SplitInput{{PayeeID: 2, Amount: cents(2500)}, {PayeeID: 3, Percentage: percentage(5000)}}}, ErrSplitMixedShares},
+		{"amounts short of total", TransferInput{Splits: []SplitInput{{PayeeID: 2, Amount: cents(2500)}, {PayeeID: 3, Amount: cents(2499)}}}, ErrSplitSumMismatch},
+		{"percentages short of 100", TransferInput{Splits: []SplitInput{{PayeeID: 2, Percentage: percentage(5000)}, {PayeeID: 3, Percentage: percentage(4999)}}}, entities.ErrInvalidSplit},
+		{"zero share", TransferInput{Splits: []SplitInput{{PayeeID: 2, Amount: cents(5000)}, {PayeeID: 3, Amount: cents(0)}}}, ErrInvalidSplitShare},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			userRepo, walletRepo := newSplitFixture(ctx)
+			transactionRepo := new(mockTransactionRepo)
+			tx := NewTransaction(userRepo, walletRepo, transactionRepo, nil, nil, nil, nil, nil, 0)
+			tt.input.PayerID = 1
+			tt.input.Amount = entities.MoneyFromCents(5000)
+
+			transaction, err := tx.Execute(ctx, tt.input)
+
+			assert.ErrorIs(t, err, tt.expect)
+			assert.Nil(t, transaction)
+			transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTransaction_GetTransfer_SplitPayee(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	stored := &entities.Transaction{ID: 99, SenderID: 1, ReceiverID: 2, Legs: []entities.TransactionLeg{{ReceiverID: 2}, {ReceiverID: 3}}}
+	transactionRepo.On("GetByID", ctx, int64(99)).Return(stored, nil)
+
+	tx := NewTransaction(nil, nil, transactionRepo, nil, nil, nil, nil, nil, 0)
+
+	transaction, err := tx.GetTransfer(ctx, 99, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, stored, transaction)
+
+	_, err = tx.GetTransfer(ctx, 99, 4)
+	assert.ErrorIs(t, err, ErrTransferAccessDenied)
+}
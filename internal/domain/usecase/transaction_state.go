@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"context"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+// transitionTransaction moves transaction to status if the state machine
+// allows it, recording reason in the status history.
+func transitionTransaction(ctx context.Context, transactionRepo port.TransactionRepository, transaction *entities.Transaction, status entities.TransactionStatus, reason string) error {
+	if err := entities.ValidateTransition(transaction.Status, status); err != nil {
+		return err
+	}
+	if err := transactionRepo.UpdateStatus(ctx, transaction.ID, transaction.Status, status, reason); err != nil {
+		return err
+	}
+	transaction.Status = status
+	return nil
+}
+
+// GetStatusHistory returns the audited status changes of a transfer to one of
+// its two parties.
+func (t *Transaction) GetStatusHistory(ctx context.Context, transactionID, requesterID int64) ([]entities.TransactionStatusChange, error) {
+	if _, err := t.GetTransfer(ctx, transactionID, requesterID); err != nil {
+		return nil, err
+	}
+	return t.transactionRepo.ListStatusHistory(ctx, transactionID)
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransitionTransaction_RejectsIllegalTransition(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	transaction := &entities.Transaction{ID: 99, Status: entities.TransactionStatusFailed}
+
+	err := transitionTransaction(ctx, transactionRepo, transaction, entities.TransactionStatusCompleted, "retry")
+	assert.ErrorIs(t, err, entities.ErrInvalidTransition)
+	assert.Equal(t, entities.TransactionStatusFailed, transaction.Status)
+	transactionRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransitionTransaction_StaleStatus(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	transaction := &entities.Transaction{ID: 99, Status: entities.TransactionStatusPending}
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusPending, entities.TransactionStatusCompleted, "settled").Return(port.ErrTransactionStatusConflict)
+
+	err := transitionTransaction(ctx, transactionRepo, transaction, entities.TransactionStatusCompleted, "settled")
+	assert.ErrorIs(t, err, port.ErrTransactionStatusConflict)
+	assert.Equal(t, entities.TransactionStatusPending, transaction.Status)
+}
+
+func TestTransaction_GetStatusHistory(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	history := []entities.TransactionStatusChange{
+		{ID: 1, TransactionID: 99, ToStatus: entities.TransactionStatusPending, Reason: "created"},
+		{ID: 2, TransactionID: 99, FromStatus: entities.TransactionStatusPending, ToStatus: entities.TransactionStatusCompleted, Reason: "transfer settled"},
+	}
+	transactionRepo.On("GetByID", ctx, int64(99)).Return(&entities.Transaction{ID: 99, SenderID: 1, ReceiverID: 2}, nil)
+	transactionRepo.On("ListStatusHistory", ctx, int64(99)).Return(history, nil)
+
+	tx := NewTransaction(nil, nil, transactionRepo, nil, nil, nil, nil, nil, 0)
+
+	result, err := tx.GetStatusHistory(ctx, 99, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, history, result)
+
+	_, err = tx.GetStatusHistory(ctx, 99, 3)
+	assert.ErrorIs(t, err, ErrTransferAccessDenied)
+}
@@ -6,177 +6,689 @@ import (
 	"fmt"
 	"go-transfer/internal/domain/entities"
 	"go-transfer/internal/domain/port"
-	"sync"
+	"slices"
+	"time"
 )
 
+const maxWalletUpdateAttempts = 3
+
+var (
+	ErrInsufficientBalance         = errors.New("insufficient balance")
+	ErrTransferNotFound            = errors.New("transfer not found")
+	ErrTransferAccessDenied        = errors.New("transfer does not belong to the requester")
+	ErrTransferNotAuthorized       = errors.New("transfer not authorized")
+	ErrUnknownAuthorizationOutcome = errors.New("unknown authorization outcome")
+	ErrSenderNotFound              = errors.New("sender not found")
+	ErrReceiverNotFound            = errors.New("receiver not found")
+	ErrMerchantCannotTransfer      = errors.New("merchant cannot transfer")
+	ErrDuplicateExternalReference  = errors.New("external reference already used on another transfer")
+)
+
+// AuthorizationDeniedError is returned when the authorizer denies a transfer.
+// Transaction is the failed transfer, carrying the decision.
+type AuthorizationDeniedError struct {
+	Transaction *entities.Transaction
+}
+
+func (e *AuthorizationDeniedError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrTransferNotAuthorized, e.Transaction.Authorization.ReasonCode)
+}
+
+func (e *AuthorizationDeniedError) Unwrap() error {
+	return ErrTransferNotAuthorized
+}
+
+type TransferInput struct {
+	PayerID int64
+	PayeeID int64
+	// Amount is in the payer's currency.
+	Amount entities.Money
+	// QuoteID names a locked exchange quote for transfers between currencies.
+	QuoteID *int64
+	// Hold only reserves the payer's funds; the transfer is settled later by
+	// Capture or released by Void.
+	Hold bool
+	// Splits pays Amount to several payees instead of PayeeID.
+	Splits []SplitInput
+	Client port.ClientMetadata
+	// Details are stored on the transfer and sent with its notifications.
+	Details entities.TransferDetails
+}
+
 type Transaction struct {
 	userRepo             port.UserRepository
 	walletRepo           port.WalletRepository
 	transactionRepo      port.TransactionRepository
-	notificationUseCase  NotificationUseCaseInterface
+	unitOfWork           port.UnitOfWork
 	authorizationService port.AuthorizationService
-	walletLocker         *sync.Map
+	// limits is nil when transfers are not limited.
+	limits *Limits
+	// fees is nil when transfers are free.
+	fees *entities.FeeTable
+	// exchange is nil when transfers between currencies are not supported.
+	exchange *Exchange
+	// holdFor is how long a two-phase transfer can wait to be captured.
+	holdFor time.Duration
+	now     func() time.Time
 }
 
 func NewTransaction(
 	userRepo port.UserRepository,
 	walletRepo port.WalletRepository,
 	transactionRepo port.TransactionRepository,
-	notificationUseCase *NotificationUseCase,
+	unitOfWork port.UnitOfWork,
 	authorizationService port.AuthorizationService,
+	limits *Limits,
+	fees *entities.FeeTable,
+	exchange *Exchange,
+	holdFor time.Duration,
 ) *Transaction {
 	return &Transaction{
 		userRepo:             userRepo,
 		walletRepo:           walletRepo,
 		transactionRepo:      transactionRepo,
-		notificationUseCase:  notificationUseCase,
+		unitOfWork:           unitOfWork,
 		authorizationService: authorizationService,
-		walletLocker:         &sync.Map{},
+		limits:               limits,
+		fees:                 fees,
+		exchange:             exchange,
+		holdFor:              holdFor,
+		now:                  time.Now,
 	}
 }
 
-func (t *Transaction) Execute(ctx context.Context, senderID, receiverID int64, amount float64) error {
-	if err := t.checkAuthorization(ctx); err != nil {
-		return err
+// Execute creates a transfer, asks the authorizer about it and settles it
+// once approved, or only holds the payer's funds if input.Hold is set. A
+// transfer with input.Splits pays each of its payees in one posting. A
+// transfer held for review is returned still PENDING.
+func (t *Transaction) Execute(ctx context.Context, input TransferInput) (*entities.Transaction, error) {
+	if err := input.Details.Validate(); err != nil {
+		return nil, err
+	}
+	payerWallet, payeeWallet, quote, err := t.validateTransaction(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction, err := t.createTransaction(ctx, t.transactionRepo, quote, input.Hold, input.Details)
+	if err != nil {
+		return nil, err
+	}
+	return t.proceed(ctx, transaction, payerWallet, payeeWallet, quote.Legs, input)
+}
+
+// proceed has a transfer created for input authorized and then settles or
+// holds it, failing it if either step goes wrong.
+func (t *Transaction) proceed(ctx context.Context, transaction *entities.Transaction, payerWallet, payeeWallet *entities.Wallet, legs []QuoteLeg, input TransferInput) (*entities.Transaction, error) {
+	if err := t.authorize(ctx, transaction, payerWallet, payeeWallet, legs, input.Client); err != nil {
+		var denied *AuthorizationDeniedError
+		if !errors.As(err, &denied) {
+			t.failTransaction(ctx, transaction, "authorization failed")
+		}
+		return nil, err
+	}
+	if transaction.Status == entities.TransactionStatusPending {
+		return transaction, nil
+	}
+
+	settle, reason := t.transfer, "settlement failed"
+	if input.Hold {
+		settle, reason = t.hold, "hold failed"
+	}
+	if err := retryOnWalletConflict(func() error { return settle(ctx, transaction) }); err != nil {
+		if errors.Is(err, ErrLimitExceeded) {
+			reason = "limit exceeded"
+		}
+		t.failTransaction(ctx, transaction, reason)
+		return nil, err
 	}
 
-	if err := t.validateTransaction(ctx, senderID, receiverID, amount); err != nil {
+	return transaction, nil
+}
+
+// GetTransfer returns a transfer only to its payer or one of its payees.
+func (t *Transaction) GetTransfer(ctx context.Context, transactionID, requesterID int64) (*entities.Transaction, error) {
+	transaction, err := t.transactionRepo.GetByID(ctx, transactionID)
+	if errors.Is(err, port.ErrTransactionNotFound) {
+		return nil, ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if transaction.SenderID != requesterID && !slices.Contains(transaction.PayeeIDs(), requesterID) {
+		return nil, ErrTransferAccessDenied
+	}
+	return transaction, nil
+}
+
+// retryOnWalletConflict runs fn again while it loses races on wallet versions.
+func retryOnWalletConflict(fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= maxWalletUpdateAttempts; attempt++ {
+		err = fn()
+		if !errors.Is(err, port.ErrWalletConflict) {
+			return err
+		}
+	}
+	return err
+}
+
+func (t *Transaction) transfer(ctx context.Context, transaction *entities.Transaction) error {
+	if transaction.IsSplit() {
+		return t.settleTogether(ctx, []*entities.Transaction{transaction}, func(port.Repositories) error { return nil })
+	}
+	// Work on a copy so a rolled back attempt leaves transaction untouched.
+	settled := *transaction
+	unlock := func() {}
+	err := t.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		senderWallet, receiverWallet, release, err := t.lockWallets(ctx, repos, settled.SenderID, settled.ReceiverID)
+		if err != nil {
+			return err
+		}
+		unlock = release
+		if err := t.spendQuote(ctx, repos, &settled); err != nil {
+			return err
+		}
+		releaseSystem, err := t.settle(ctx, repos, &settled, senderWallet, receiverWallet)
+		unlock = func() {
+			releaseSystem()
+			release()
+		}
+		if err != nil {
+			return err
+		}
+		// Checked again under the sender's lock so concurrent transfers
+		// cannot both fit in what is left of a limit.
+		if t.limits != nil {
+			if err := t.limits.Consume(ctx, repos.Counters, settled.SenderID, senderWallet.Type, settled.Amount); err != nil {
+				return err
+			}
+		}
+
+		if err := transitionTransaction(ctx, repos.Transactions, &settled, entities.TransactionStatusCompleted, "transfer settled"); err != nil {
+			return err
+		}
+		return enqueuePayeeNotifications(ctx, repos, &settled)
+	})
+	// Released only once the unit of work has committed or rolled back.
+	unlock()
+	if err != nil {
 		return err
 	}
+	*transaction = settled
+	return nil
+}
 
-	unlock := t.lockWallets(senderID, receiverID)
-	defer unlock()
+// settle posts transaction between the sender and receiver wallets, already
+// locked, and the system wallets it pays into, which it locks. The returned
+// function releases those and is never nil.
+func (t *Transaction) settle(ctx context.Context, repos port.Repositories, transaction *entities.Transaction, senderWallet, receiverWallet *entities.Wallet) (func(), error) {
+	wallets := settlementWallets{sender: senderWallet, receiver: receiverWallet}
+	var accounts []systemWallet
+	if transaction.Fee.IsPositive() {
+		accounts = append(accounts, systemWallet{entities.SystemAccountRevenue, senderWallet.CurrencyCode()})
+	}
+	if transaction.IsConverted() {
+		accounts = append(accounts,
+			systemWallet{entities.SystemAccountExchange, senderWallet.CurrencyCode()},
+			systemWallet{entities.SystemAccountExchange, receiverWallet.CurrencyCode()},
+		)
+	}
+	release := func() {}
+	if len(accounts) > 0 {
+		system, releaseSystem, err := t.lockSystemWallets(ctx, repos, accounts...)
+		if err != nil {
+			return release, err
+		}
+		release = releaseSystem
+		if transaction.Fee.IsPositive() {
+			wallets.revenue, system = system[0], system[1:]
+		}
+		if transaction.IsConverted() {
+			wallets.exchangeFrom, wallets.exchangeTo = system[0], system[1]
+		}
+	}
+	return release, t.updateWallets(ctx, repos, transaction, wallets)
+}
 
-	transactionID, err := t.createTransaction(ctx, senderID, receiverID, amount)
+// settleTogether settles approved transfers in a single unit of work, so
+// either all of them move money or none does. record runs in the same unit
+// of work once they are settled.
+func (t *Transaction) settleTogether(ctx context.Context, transactions []*entities.Transaction, record func(repos port.Repositories) error) error {
+	// Work on copies so a rolled back attempt leaves transactions untouched.
+	settled := make([]entities.Transaction, len(transactions))
+	for i, transaction := range transactions {
+		settled[i] = *transaction
+	}
+	unlock := func() {}
+	err := t.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		wallets, release, err := t.lockAllWallets(ctx, repos, settled)
+		unlock = release
+		if err != nil {
+			return err
+		}
+		for i := range settled {
+			transaction := &settled[i]
+			// Reloaded for each transfer, as the previous one changed their
+			// balances and versions.
+			posted, err := wallets.load(ctx, repos, transaction)
+			if err != nil {
+				return err
+			}
+			if err := t.updateWallets(ctx, repos, transaction, posted); err != nil {
+				return err
+			}
+			if t.limits != nil {
+				if err := t.limits.Consume(ctx, repos.Counters, transaction.SenderID, posted.sender.Type, transaction.Amount); err != nil {
+					return err
+				}
+			}
+			if err := transitionTransaction(ctx, repos.Transactions, transaction, entities.TransactionStatusCompleted, "transfer settled"); err != nil {
+				return err
+			}
+			if err := enqueuePayeeNotifications(ctx, repos, transaction); err != nil {
+				return err
+			}
+		}
+		return record(repos)
+	})
+	// Released only once the unit of work has committed or rolled back.
+	unlock()
 	if err != nil {
 		return err
 	}
+	for i, transaction := range transactions {
+		*transaction = settled[i]
+	}
+	return nil
+}
 
-	if err := t.updateWallets(ctx, senderID, receiverID, amount); err != nil {
-		_ = t.transactionRepo.UpdateStatus(ctx, transactionID, entities.TransactionStatusFailed)
+// walletIDs maps the parties and system accounts of a set of transfers to
+// their wallets.
+type walletIDs struct {
+	owners map[int64]int64
+	system map[systemWallet]int64
+}
+
+func (w walletIDs) load(ctx context.Context, repos port.Repositories, transaction *entities.Transaction) (settlementWallets, error) {
+	var wallets settlementWallets
+	var err error
+	if wallets.sender, err = repos.Wallets.GetByID(ctx, w.owners[transaction.SenderID]); err != nil {
+		return wallets, err
+	}
+	if wallets.receiver, err = repos.Wallets.GetByID(ctx, w.owners[transaction.ReceiverID]); err != nil {
+		return wallets, err
+	}
+	for _, leg := range transaction.Legs {
+		wallet, err := repos.Wallets.GetByID(ctx, w.owners[leg.ReceiverID])
+		if err != nil {
+			return wallets, err
+		}
+		wallets.legs = append(wallets.legs, wallet)
+	}
+	if transaction.Fee.IsPositive() {
+		if wallets.revenue, err = repos.Wallets.GetByID(ctx, w.system[systemWallet{entities.SystemAccountRevenue, transaction.Currency}]); err != nil {
+			return wallets, err
+		}
+	}
+	if transaction.IsConverted() {
+		if wallets.exchangeFrom, err = repos.Wallets.GetByID(ctx, w.system[systemWallet{entities.SystemAccountExchange, transaction.Currency}]); err != nil {
+			return wallets, err
+		}
+		if wallets.exchangeTo, err = repos.Wallets.GetByID(ctx, w.system[systemWallet{entities.SystemAccountExchange, *transaction.ReceivedCurrency}]); err != nil {
+			return wallets, err
+		}
+	}
+	return wallets, nil
+}
+
+// lockAllWallets locks every wallet the transfers post to: first those of
+// their parties and then the system wallets, in the same order as a single
+// transfer does. The returned function is never nil.
+func (t *Transaction) lockAllWallets(ctx context.Context, repos port.Repositories, transactions []entities.Transaction) (walletIDs, func(), error) {
+	ids := walletIDs{owners: make(map[int64]int64), system: make(map[systemWallet]int64)}
+	var partyIDs, systemIDs []int64
+	for _, transaction := range transactions {
+		for _, ownerID := range append([]int64{transaction.SenderID}, transaction.PayeeIDs()...) {
+			if _, ok := ids.owners[ownerID]; ok {
+				continue
+			}
+			wallet, err := repos.Wallets.GetByOwnerID(ctx, ownerID)
+			if err != nil {
+				return ids, func() {}, err
+			}
+			ids.owners[ownerID] = wallet.ID
+			partyIDs = append(partyIDs, wallet.ID)
+		}
+
+		var accounts []systemWallet
+		if transaction.Fee.IsPositive() {
+			accounts = append(accounts, systemWallet{entities.SystemAccountRevenue, transaction.Currency})
+		}
+		if transaction.IsConverted() {
+			accounts = append(accounts,
+				systemWallet{entities.SystemAccountExchange, transaction.Currency},
+				systemWallet{entities.SystemAccountExchange, *transaction.ReceivedCurrency},
+			)
+		}
+		for _, account := range accounts {
+			if _, ok := ids.system[account]; ok {
+				continue
+			}
+			wallet, err := repos.Wallets.GetSystemWallet(ctx, account.account, account.currency)
+			if err != nil {
+				return ids, func() {}, err
+			}
+			ids.system[account] = wallet.ID
+			systemIDs = append(systemIDs, wallet.ID)
+		}
+	}
+
+	unlockParties, err := repos.Locker.Lock(ctx, partyIDs...)
+	if err != nil {
+		return ids, func() {}, err
+	}
+	if len(systemIDs) == 0 {
+		return ids, unlockParties, nil
+	}
+	unlockSystem, err := repos.Locker.Lock(ctx, systemIDs...)
+	if err != nil {
+		unlockParties()
+		return ids, func() {}, err
+	}
+	return ids, func() {
+		unlockSystem()
+		unlockParties()
+	}, nil
+}
+
+// authorize moves transaction through AUTHORIZING and records the
+// authorizer's decision on it. Approved transfers stay AUTHORIZING until
+// settled, denied ones fail and those under review go back to PENDING.
+// legs are the payees of a split transfer, sent along so the authorizer can
+// judge each of them.
+func (t *Transaction) authorize(ctx context.Context, transaction *entities.Transaction, payerWallet, payeeWallet *entities.Wallet, legs []QuoteLeg, client port.ClientMetadata) error {
+	if err := transitionTransaction(ctx, t.transactionRepo, transaction, entities.TransactionStatusAuthorizing, "authorization requested"); err != nil {
 		return err
 	}
 
-	if err := t.transactionRepo.UpdateStatus(ctx, transactionID, entities.TransactionStatusCompleted); err != nil {
+	request := port.AuthorizationRequest{
+		TransferID:      transaction.ID,
+		PayerID:         transaction.SenderID,
+		PayeeID:         transaction.ReceiverID,
+		Amount:          transaction.Amount,
+		PayerWalletType: payerWallet.Type,
+		PayeeWalletType: payeeWallet.Type,
+		Client:          client,
+	}
+	for _, leg := range legs {
+		request.Splits = append(request.Splits, port.AuthorizationSplit{PayeeID: leg.PayeeID, Amount: leg.Amount, PayeeWalletType: leg.PayeeWalletType})
+	}
+	decision, err := t.authorizationService.Authorize(ctx, request)
+	if err != nil {
+		return err
+	}
+	if err := t.transactionRepo.UpdateAuthorization(ctx, transaction.ID, decision); err != nil {
 		return err
 	}
+	transaction.Authorization = decision
 
-	if err := t.sendNotification(ctx, senderID, receiverID, amount); err != nil {
-		fmt.Print("failed to send notification: " + err.Error())
+	switch decision.Outcome {
+	case entities.AuthorizationApproved:
+		return nil
+	case entities.AuthorizationDenied:
+		if err := transitionTransaction(ctx, t.transactionRepo, transaction, entities.TransactionStatusFailed, "authorization denied"); err != nil {
+			return err
+		}
+		return &AuthorizationDeniedError{Transaction: transaction}
+	case entities.AuthorizationReview:
+		return transitionTransaction(ctx, t.transactionRepo, transaction, entities.TransactionStatusPending, "authorization under review")
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownAuthorizationOutcome, decision.Outcome)
 	}
+}
 
-	return nil
+// validateTransaction checks that the payer can make the transfer described
+// by input and returns both wallets along with its price.
+func (t *Transaction) validateTransaction(ctx context.Context, input TransferInput) (*entities.Wallet, *entities.Wallet, *TransferQuote, error) {
+	if len(input.Splits) > 0 {
+		return t.validateSplit(ctx, input)
+	}
+	senderWallet, receiverWallet, err := t.transferWallets(ctx, input.PayerID, input.PayeeID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if senderWallet.Type == entities.MerchantWallet {
+		return nil, nil, nil, ErrMerchantCannotTransfer
+	}
+
+	quote, err := t.price(ctx, input, senderWallet, receiverWallet)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return senderWallet, receiverWallet, quote, t.checkFunds(ctx, input.PayerID, senderWallet, quote)
 }
 
-func (t *Transaction) checkAuthorization(ctx context.Context) error {
-	isAuthorized, err := t.authorizationService.Authorize(ctx)
+// checkFunds checks that the payer's wallet can pay quote, fees included,
+// within the payer's limits.
+func (t *Transaction) checkFunds(ctx context.Context, payerID int64, senderWallet *entities.Wallet, quote *TransferQuote) error {
+	short, err := senderWallet.AvailableBalance().LessThan(quote.Total)
 	if err != nil {
 		return err
 	}
-	if !isAuthorized {
-		return errors.New("unauthorized")
+	if short {
+		return ErrInsufficientBalance
+	}
+	if t.limits != nil {
+		return t.limits.Check(ctx, payerID, senderWallet.Type, quote.Amount)
 	}
 	return nil
 }
 
-func (t *Transaction) validateTransaction(ctx context.Context, senderID, receiverID int64, amount float64) error {
+func (t *Transaction) transferWallets(ctx context.Context, senderID, receiverID int64) (*entities.Wallet, *entities.Wallet, error) {
 	if senderID == receiverID {
-		return errors.New("sender and receiver must be different")
+		return nil, nil, errors.New("sender and receiver must be different")
 	}
 
 	if err := t.checkUserExists(ctx, senderID, receiverID); err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	senderWallet, err := t.walletRepo.GetByOwnerID(ctx, senderID)
 	if err != nil {
-		return err
-	}
-	if senderWallet.Type == entities.MerchantWallet {
-		return errors.New("merchant cannot transfer")
+		return nil, nil, err
 	}
-	if senderWallet.Balance < amount {
-		return errors.New("insufficient balance")
+	receiverWallet, err := t.walletRepo.GetByOwnerID(ctx, receiverID)
+	if err != nil {
+		return nil, nil, err
 	}
+	return senderWallet, receiverWallet, nil
+}
 
-	return nil
+// totalDebit is what the payer of a transfer of amount pays, fees included.
+func totalDebit(amount entities.Money, fees entities.FeeBreakdown) (entities.Money, error) {
+	fee, err := fees.Total(amount.Currency)
+	if err != nil {
+		return entities.Money{}, err
+	}
+	return amount.Add(fee)
 }
 
 func (t *Transaction) checkUserExists(ctx context.Context, senderID, receiverID int64) error {
 	user, err := t.userRepo.GetByID(ctx, senderID)
 	if err != nil || user == nil {
-		return errors.New("sender not found")
+		return ErrSenderNotFound
 	}
 
 	user, err = t.userRepo.GetByID(ctx, receiverID)
 	if err != nil || user == nil {
-		return errors.New("receiver not found")
+		return ErrReceiverNotFound
 	}
 
 	return nil
 }
 
-func (t *Transaction) lockWallets(senderID, receiverID int64) func() {
-	if senderID > receiverID {
-		senderID, receiverID = receiverID, senderID
+func (t *Transaction) lockWallets(ctx context.Context, repos port.Repositories, senderID, receiverID int64) (*entities.Wallet, *entities.Wallet, func(), error) {
+	senderWallet, err := repos.Wallets.GetByOwnerID(ctx, senderID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	receiverWallet, err := repos.Wallets.GetByOwnerID(ctx, receiverID)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-	senderLock := t.getWalletLock(senderID)
-	receiverLock := t.getWalletLock(receiverID)
-
-	senderLock.Lock()
-	receiverLock.Lock()
 
-	return func() {
-		receiverLock.Unlock()
-		senderLock.Unlock()
+	unlock, err := repos.Locker.Lock(ctx, senderWallet.ID, receiverWallet.ID)
+	if err != nil {
+		return nil, nil, nil, err
 	}
-}
 
-func (t *Transaction) getWalletLock(walletID int64) *sync.Mutex {
-	lock, ok := t.walletLocker.Load(walletID)
-	if !ok {
-		lock = &sync.Mutex{}
-		t.walletLocker.Store(walletID, lock)
+	senderWallet, err = repos.Wallets.GetByID(ctx, senderWallet.ID)
+	if err != nil {
+		unlock()
+		return nil, nil, nil, err
+	}
+	receiverWallet, err = repos.Wallets.GetByID(ctx, receiverWallet.ID)
+	if err != nil {
+		unlock()
+		return nil, nil, nil, err
 	}
-	return lock.(*sync.Mutex)
+	return senderWallet, receiverWallet, unlock, nil
 }
 
-func (t *Transaction) createTransaction(ctx context.Context, senderID, receiverID int64, amount float64) (int64, error) {
+func (t *Transaction) createTransaction(ctx context.Context, transactionRepo port.TransactionRepository, quote *TransferQuote, hold bool, details entities.TransferDetails) (*entities.Transaction, error) {
 	transaction := &entities.Transaction{
-		SenderID:   senderID,
-		ReceiverID: receiverID,
-		Amount:     amount,
-		Status:     entities.TransactionStatusPending,
+		SenderID:     quote.PayerID,
+		ReceiverID:   quote.PayeeID,
+		Amount:       quote.Amount,
+		Currency:     quote.Amount.Currency,
+		Fee:          quote.Fee,
+		FeeBreakdown: quote.Fees,
+		Type:         entities.TransactionTypeTransfer,
+		Status:       entities.TransactionStatusPending,
+		Details:      details,
+	}
+	if conversion := quote.Conversion; conversion != nil {
+		received := conversion.Amount
+		transaction.ExchangeRate = &conversion.Rate
+		transaction.ReceivedAmount = &received
+		transaction.ReceivedCurrency = &received.Currency
+		if conversion.Quote != nil {
+			transaction.ExchangeQuoteID = &conversion.Quote.ID
+		}
+	}
+	for _, leg := range quote.Legs {
+		transaction.Legs = append(transaction.Legs, entities.TransactionLeg{
+			ReceiverID: leg.PayeeID,
+			Amount:     leg.Amount,
+			Currency:   leg.Amount.Currency,
+		})
+	}
+	if hold {
+		authorized := quote.Amount
+		expiresAt := t.now().Add(t.holdFor)
+		transaction.AuthorizedAmount = &authorized
+		transaction.HoldExpiresAt = &expiresAt
+	}
+	transactionID, err := transactionRepo.Create(ctx, transaction)
+	if errors.Is(err, port.ErrExternalReferenceExists) {
+		return nil, ErrDuplicateExternalReference
 	}
-	transactionID, err := t.transactionRepo.Create(ctx, transaction)
 	if err != nil {
-		return 0, errors.New("failed to create transaction record: " + err.Error())
+		return nil, errors.New("failed to create transaction record: " + err.Error())
 	}
-	return transactionID, nil
+	transaction.ID = transactionID
+	return transaction, nil
 }
 
-func (t *Transaction) updateWallets(ctx context.Context, senderID, receiverID int64, amount float64) error {
-	senderWallet, err := t.walletRepo.GetByOwnerID(ctx, senderID)
-	if err != nil {
-		return err
+// failTransaction marks a transfer that could not complete as FAILED. It runs
+// even if ctx was cancelled so the transfer is not left in flight.
+func (t *Transaction) failTransaction(ctx context.Context, transaction *entities.Transaction, reason string) {
+	if err := transitionTransaction(context.WithoutCancel(ctx), t.transactionRepo, transaction, entities.TransactionStatusFailed, reason); err != nil {
+		fmt.Print("failed to record failed transaction: " + err.Error())
 	}
+}
 
-	receiverWallet, err := t.walletRepo.GetByOwnerID(ctx, receiverID)
+// settlementWallets are the wallets a transfer posts to. revenue is only set
+// when the transfer has fees and the exchange wallets, of the sender's and
+// the receiver's currency, only when it is converted. legs holds the wallet of
+// each leg's payee on split transfers.
+type settlementWallets struct {
+	sender       *entities.Wallet
+	receiver     *entities.Wallet
+	revenue      *entities.Wallet
+	exchangeFrom *entities.Wallet
+	exchangeTo   *entities.Wallet
+	legs         []*entities.Wallet
+}
+
+// updateWallets posts transaction to the ledger: amount from sender to
+// receiver, through the exchange wallets if converted or split among the
+// payees of its legs, and each fee line from sender to revenue.
+func (t *Transaction) updateWallets(ctx context.Context, repos port.Repositories, transaction *entities.Transaction, wallets settlementWallets) error {
+	total, err := totalDebit(transaction.Amount, transaction.FeeBreakdown)
 	if err != nil {
 		return err
 	}
-
-	if err := t.walletRepo.UpdateBalance(ctx, senderWallet.ID, senderWallet.Balance-amount); err != nil {
+	short, err := wallets.sender.AvailableBalance().LessThan(total)
+	if err != nil {
 		return err
 	}
+	if short {
+		return ErrInsufficientBalance
+	}
 
-	if err := t.walletRepo.UpdateBalance(ctx, receiverWallet.ID, receiverWallet.Balance+amount); err != nil {
-		return err
+	posted := []*entities.Wallet{wallets.sender, wallets.receiver}
+	var posting []entities.LedgerEntry
+	switch {
+	case transaction.IsConverted():
+		posted = append(posted, wallets.exchangeFrom, wallets.exchangeTo)
+		posting = entities.NewConversionPosting(transaction.ID, wallets.sender.ID, wallets.exchangeFrom.ID, wallets.exchangeTo.ID, wallets.receiver.ID, transaction.Amount, *transaction.ReceivedAmount)
+	case transaction.IsSplit():
+		posted = append(posted, wallets.legs...)
+		for i, leg := range transaction.Legs {
+			posting = append(posting, entities.NewTransferPosting(transaction.ID, wallets.sender.ID, wallets.legs[i].ID, leg.Amount)...)
+		}
+	default:
+		posting = entities.NewTransferPosting(transaction.ID, wallets.sender.ID, wallets.receiver.ID, transaction.Amount)
+	}
+	if len(transaction.FeeBreakdown.Lines) > 0 {
+		posted = append(posted, wallets.revenue)
+		posting = append(posting, entities.NewFeePosting(transaction.ID, wallets.sender.ID, wallets.revenue.ID, transaction.FeeBreakdown)...)
 	}
+	return postLedger(ctx, repos, posted, posting)
+}
 
-	return nil
+type systemWallet struct {
+	account  entities.SystemAccount
+	currency string
 }
 
-func (t *Transaction) sendNotification(ctx context.Context, senderID, receiverID int64, amount float64) error {
-	return t.notificationUseCase.Execute(ctx, senderID, receiverID, amount)
+// lockSystemWallets locks the platform wallets a settlement pays into or out
+// of, returning them in the order asked. They are always locked after the
+// transfer's own wallets.
+func (t *Transaction) lockSystemWallets(ctx context.Context, repos port.Repositories, accounts ...systemWallet) ([]*entities.Wallet, func(), error) {
+	ids := make([]int64, 0, len(accounts))
+	for _, account := range accounts {
+		wallet, err := repos.Wallets.GetSystemWallet(ctx, account.account, account.currency)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, wallet.ID)
+	}
+	unlock, err := repos.Locker.Lock(ctx, ids...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wallets := make([]*entities.Wallet, 0, len(ids))
+	for _, id := range ids {
+		wallet, err := repos.Wallets.GetByID(ctx, id)
+		if err != nil {
+			unlock()
+			return nil, nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	return wallets, unlock, nil
 }
@@ -2,9 +2,12 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -49,8 +52,28 @@ func (m *mockWalletRepo) Create(ctx context.Context, wallet *entities.Wallet) er
 	return args.Error(0)
 }
 
-func (m *mockWalletRepo) UpdateBalance(ctx context.Context, walletID int64, newBalance float64) error {
-	args := m.Called(ctx, walletID, newBalance)
+func (m *mockWalletRepo) GetSystemWallet(ctx context.Context, account entities.SystemAccount, currency string) (*entities.Wallet, error) {
+	args := m.Called(ctx, account, currency)
+	return args.Get(0).(*entities.Wallet), args.Error(1)
+}
+
+func (m *mockWalletRepo) Debit(ctx context.Context, walletID int64, amount entities.Money, version int64) error {
+	args := m.Called(ctx, walletID, amount, version)
+	return args.Error(0)
+}
+
+func (m *mockWalletRepo) Credit(ctx context.Context, walletID int64, amount entities.Money, version int64) error {
+	args := m.Called(ctx, walletID, amount, version)
+	return args.Error(0)
+}
+
+func (m *mockWalletRepo) Hold(ctx context.Context, walletID int64, amount entities.Money, version int64) error {
+	args := m.Called(ctx, walletID, amount, version)
+	return args.Error(0)
+}
+
+func (m *mockWalletRepo) ReleaseHold(ctx context.Context, walletID int64, amount entities.Money, version int64) error {
+	args := m.Called(ctx, walletID, amount, version)
 	return args.Error(0)
 }
 
@@ -61,69 +84,554 @@ func (m *mockTransactionRepo) Create(ctx context.Context, transaction *entities.
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *mockTransactionRepo) UpdateStatus(ctx context.Context, id int64, status entities.TransactionStatus) error {
-	args := m.Called(ctx, id, status)
+func (m *mockTransactionRepo) UpdateStatus(ctx context.Context, id int64, from, to entities.TransactionStatus, reason string) error {
+	args := m.Called(ctx, id, from, to, reason)
 	return args.Error(0)
 }
 
-func (m *mockTransactionRepo) GetByID(ctx context.Context, id int64) (*entities.TransactionStatus, error) {
+func (m *mockTransactionRepo) GetByID(ctx context.Context, id int64) (*entities.Transaction, error) {
 	args := m.Called(ctx, id)
-	return args.Get(0).(*entities.TransactionStatus), args.Error(1)
+	transaction, _ := args.Get(0).(*entities.Transaction)
+	return transaction, args.Error(1)
+}
+
+func (m *mockTransactionRepo) UpdateRefundedAmount(ctx context.Context, id int64, refundedAmount entities.Money) error {
+	args := m.Called(ctx, id, refundedAmount)
+	return args.Error(0)
+}
+
+func (m *mockTransactionRepo) UpdateAuthorization(ctx context.Context, id int64, decision entities.AuthorizationDecision) error {
+	args := m.Called(ctx, id, decision)
+	return args.Error(0)
+}
+
+func (m *mockTransactionRepo) UpdateHold(ctx context.Context, id int64, held entities.Money) error {
+	args := m.Called(ctx, id, held)
+	return args.Error(0)
+}
+
+func (m *mockTransactionRepo) UpdateCapture(ctx context.Context, transaction *entities.Transaction) error {
+	args := m.Called(ctx, transaction)
+	return args.Error(0)
+}
+
+func (m *mockTransactionRepo) ListStatusHistory(ctx context.Context, transactionID int64) ([]entities.TransactionStatusChange, error) {
+	args := m.Called(ctx, transactionID)
+	history, _ := args.Get(0).([]entities.TransactionStatusChange)
+	return history, args.Error(1)
+}
+
+func (m *mockTransactionRepo) ListByUser(ctx context.Context, filter port.TransactionFilter) ([]entities.Transaction, error) {
+	args := m.Called(ctx, filter)
+	transactions, _ := args.Get(0).([]entities.Transaction)
+	return transactions, args.Error(1)
+}
+
+func (m *mockTransactionRepo) SumSent(ctx context.Context, senderID int64, from, to time.Time) (entities.Money, error) {
+	args := m.Called(ctx, senderID, from, to)
+	return args.Get(0).(entities.Money), args.Error(1)
+}
+
+func (m *mockTransactionRepo) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]entities.Transaction, error) {
+	args := m.Called(ctx, now, limit)
+	transactions, _ := args.Get(0).([]entities.Transaction)
+	return transactions, args.Error(1)
 }
 
 type mockAuthService struct{ mock.Mock }
 
-func (m *mockAuthService) Authorize(ctx context.Context) (bool, error) {
-	args := m.Called(ctx)
-	return args.Bool(0), args.Error(1)
+func (m *mockAuthService) Authorize(ctx context.Context, request port.AuthorizationRequest) (entities.AuthorizationDecision, error) {
+	args := m.Called(ctx, request)
+	return args.Get(0).(entities.AuthorizationDecision), args.Error(1)
 }
 
 type mockNotificationUseCase struct{ mock.Mock }
 
-func (m *mockNotificationUseCase) Execute(ctx context.Context, senderID, receiverID int64, amount float64) error {
-	args := m.Called(ctx, senderID, receiverID, amount)
+func (m *mockNotificationUseCase) Execute(ctx context.Context, outboxMessageID int64, receiverID int64, transferID int64, amount entities.Money, details entities.TransferDetails) error {
+	args := m.Called(ctx, outboxMessageID, receiverID, transferID, amount, details)
+	return args.Error(0)
+}
+
+func (m *mockNotificationUseCase) NotifyScheduledTransferFailed(ctx context.Context, outboxMessageID int64, payerID int64, scheduledTransferID int64, amount entities.Money) error {
+	args := m.Called(ctx, outboxMessageID, payerID, scheduledTransferID, amount)
+	return args.Error(0)
+}
+
+func (m *mockNotificationUseCase) NotifyTransferBatchFinished(ctx context.Context, outboxMessageID int64, payerID int64, batchID int64, amount entities.Money) error {
+	args := m.Called(ctx, outboxMessageID, payerID, batchID, amount)
+	return args.Error(0)
+}
+
+func (m *mockNotificationUseCase) NotifyTransferCancelled(ctx context.Context, outboxMessageID int64, payeeID int64, transferID int64, amount entities.Money, details entities.TransferDetails) error {
+	args := m.Called(ctx, outboxMessageID, payeeID, transferID, amount, details)
 	return args.Error(0)
 }
 
+func (m *mockNotificationUseCase) NotifyRefund(ctx context.Context, outboxMessageID int64, receiverID int64, refundID int64, kind entities.NotificationKind, amount entities.Money, details entities.TransferDetails) error {
+	args := m.Called(ctx, outboxMessageID, receiverID, refundID, kind, amount, details)
+	return args.Error(0)
+}
+
+type fakeUnitOfWork struct {
+	repos port.Repositories
+}
+
+func (f *fakeUnitOfWork) Do(ctx context.Context, fn func(repos port.Repositories) error) error {
+	return fn(f.repos)
+}
+
+type fakeWalletLocker struct {
+	locked [][]int64
+}
+
+func (f *fakeWalletLocker) Lock(ctx context.Context, walletIDs ...int64) (func(), error) {
+	f.locked = append(f.locked, walletIDs)
+	return func() {}, nil
+}
+
+func approved() entities.AuthorizationDecision {
+	return entities.AuthorizationDecision{Outcome: entities.AuthorizationApproved, Reference: "auth-1"}
+}
+
+func expectAuthorization(transactionRepo *mockTransactionRepo, authService *mockAuthService, ctx context.Context, id int64, decision entities.AuthorizationDecision) {
+	transactionRepo.On("UpdateStatus", ctx, id, entities.TransactionStatusPending, entities.TransactionStatusAuthorizing, "authorization requested").Return(nil).Once()
+	authService.On("Authorize", ctx, mock.MatchedBy(func(request port.AuthorizationRequest) bool {
+		return request.TransferID == id
+	})).Return(decision, nil).Once()
+	transactionRepo.On("UpdateAuthorization", ctx, id, decision).Return(nil).Once()
+}
+
 func TestTransaction_Execute_Success(t *testing.T) {
 	ctx := context.Background()
 	senderID := int64(1)
 	receiverID := int64(2)
-	amount := 50.0
+	amount := entities.MoneyFromCents(5000)
 
 	userRepo := new(mockUserRepo)
 	walletRepo := new(mockWalletRepo)
 	transactionRepo := new(mockTransactionRepo)
+	ledgerRepo := new(MockLedgerRepository)
 	authService := new(mockAuthService)
-	notificationUseCase := new(mockNotificationUseCase)
+	outboxRepo := new(MockOutboxRepository)
 
 	userRepo.On("GetByID", ctx, senderID).Return(&entities.User{ID: senderID}, nil)
 	userRepo.On("GetByID", ctx, receiverID).Return(&entities.User{ID: receiverID}, nil)
 
-	senderWallet := &entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Balance: 100}
-	receiverWallet := &entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.MerchantWallet, Balance: 25}
+	senderWallet := &entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(10000)}
+	receiverWallet := &entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.MerchantWallet, Balance: entities.MoneyFromCents(2500)}
 
 	walletRepo.On("GetByOwnerID", ctx, senderID).Return(senderWallet, nil)
 	walletRepo.On("GetByOwnerID", ctx, receiverID).Return(receiverWallet, nil)
-	walletRepo.On("UpdateBalance", ctx, senderWallet.ID, senderWallet.Balance-amount).Return(nil)
-	walletRepo.On("UpdateBalance", ctx, receiverWallet.ID, receiverWallet.Balance+amount).Return(nil)
+	walletRepo.On("GetByID", ctx, senderWallet.ID).Return(senderWallet, nil)
+	walletRepo.On("GetByID", ctx, receiverWallet.ID).Return(receiverWallet, nil)
+	walletRepo.On("Debit", ctx, senderWallet.ID, amount, senderWallet.Version).Return(nil)
+	walletRepo.On("Credit", ctx, receiverWallet.ID, amount, receiverWallet.Version).Return(nil)
+	ledgerRepo.On("CreateEntries", ctx, entities.NewTransferPosting(99, senderWallet.ID, receiverWallet.ID, amount)).Return(nil)
 
+	client := port.ClientMetadata{IPAddress: "203.0.113.7", UserAgent: "app/1.0", DeviceID: "device-1"}
 	transactionRepo.On("Create", ctx, mock.Anything).Return(int64(99), nil)
-	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusCompleted).Return(nil)
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusPending, entities.TransactionStatusAuthorizing, "authorization requested").Return(nil)
+	authService.On("Authorize", ctx, port.AuthorizationRequest{
+		TransferID:      99,
+		PayerID:         senderID,
+		PayeeID:         receiverID,
+		Amount:          amount,
+		PayerWalletType: entities.CommonWallet,
+		PayeeWalletType: entities.MerchantWallet,
+		Client:          client,
+	}).Return(approved(), nil)
+	transactionRepo.On("UpdateAuthorization", ctx, int64(99), approved()).Return(nil)
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusCompleted, "transfer settled").Return(nil)
 
-	authService.On("Authorize", ctx).Return(true, nil)
-	notificationUseCase.On("Execute", ctx, senderID, receiverID, amount).Return(nil)
+	outboxRepo.On("Create", ctx, transferNotification(receiverID, 99, amount)).Return(nil)
 
-	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &NotificationUseCase{notificationRepo: nil, notificationService: nil}, authService)
-	txWithNotif := *tx
-	txWithNotif.notificationUseCase = notificationUseCase
+	locker := &fakeWalletLocker{}
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Ledger: ledgerRepo, Outbox: outboxRepo, Locker: locker}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, nil, nil, nil, 0)
 
-	err := txWithNotif.Execute(ctx, senderID, receiverID, amount)
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: senderID, PayeeID: receiverID, Amount: amount, Client: client})
 	assert.NoError(t, err)
+	assert.Equal(t, int64(99), transaction.ID)
+	assert.Equal(t, senderID, transaction.SenderID)
+	assert.Equal(t, receiverID, transaction.ReceiverID)
+	assert.Equal(t, amount, transaction.Amount)
+	assert.Equal(t, entities.TransactionStatusCompleted, transaction.Status)
+	assert.Equal(t, approved(), transaction.Authorization)
+	assert.Equal(t, [][]int64{{senderWallet.ID, receiverWallet.ID}}, locker.locked)
 
 	userRepo.AssertExpectations(t)
 	walletRepo.AssertExpectations(t)
 	transactionRepo.AssertExpectations(t)
+	ledgerRepo.AssertExpectations(t)
 	authService.AssertExpectations(t)
-	notificationUseCase.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestTransaction_Execute_WalletUpdateFails(t *testing.T) {
+	ctx := context.Background()
+	senderID := int64(1)
+	receiverID := int64(2)
+	amount := entities.MoneyFromCents(5000)
+
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+	ledgerRepo := new(MockLedgerRepository)
+	authService := new(mockAuthService)
+	outboxRepo := new(MockOutboxRepository)
+
+	userRepo.On("GetByID", ctx, senderID).Return(&entities.User{ID: senderID}, nil)
+	userRepo.On("GetByID", ctx, receiverID).Return(&entities.User{ID: receiverID}, nil)
+
+	senderWallet := &entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(10000)}
+	receiverWallet := &entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(2500)}
+
+	walletRepo.On("GetByOwnerID", ctx, senderID).Return(senderWallet, nil)
+	walletRepo.On("GetByOwnerID", ctx, receiverID).Return(receiverWallet, nil)
+	walletRepo.On("GetByID", ctx, senderWallet.ID).Return(senderWallet, nil)
+	walletRepo.On("GetByID", ctx, receiverWallet.ID).Return(receiverWallet, nil)
+	walletRepo.On("Debit", ctx, senderWallet.ID, amount, senderWallet.Version).Return(nil)
+	walletRepo.On("Credit", ctx, receiverWallet.ID, amount, receiverWallet.Version).Return(errors.New("database error"))
+
+	transactionRepo.On("Create", ctx, mock.Anything).Return(int64(99), nil).Once()
+	expectAuthorization(transactionRepo, authService, ctx, 99, approved())
+	transactionRepo.On("UpdateStatus", mock.Anything, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusFailed, "settlement failed").Return(nil).Once()
+
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Ledger: ledgerRepo, Outbox: outboxRepo, Locker: &fakeWalletLocker{}}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, nil, nil, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: senderID, PayeeID: receiverID, Amount: amount})
+	assert.EqualError(t, err, "database error")
+	assert.Nil(t, transaction)
+
+	walletRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Execute_RetriesOnWalletConflict(t *testing.T) {
+	ctx := context.Background()
+	senderID := int64(1)
+	receiverID := int64(2)
+	amount := entities.MoneyFromCents(5000)
+
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+	ledgerRepo := new(MockLedgerRepository)
+	authService := new(mockAuthService)
+	outboxRepo := new(MockOutboxRepository)
+
+	userRepo.On("GetByID", ctx, senderID).Return(&entities.User{ID: senderID}, nil)
+	userRepo.On("GetByID", ctx, receiverID).Return(&entities.User{ID: receiverID}, nil)
+
+	senderWallet := &entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(10000), Version: 4}
+	receiverWallet := &entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(2500), Version: 7}
+
+	walletRepo.On("GetByOwnerID", ctx, senderID).Return(senderWallet, nil)
+	walletRepo.On("GetByOwnerID", ctx, receiverID).Return(receiverWallet, nil)
+	walletRepo.On("GetByID", ctx, senderWallet.ID).Return(senderWallet, nil)
+	walletRepo.On("GetByID", ctx, receiverWallet.ID).Return(receiverWallet, nil)
+	walletRepo.On("Debit", ctx, senderWallet.ID, amount, int64(4)).Return(port.ErrWalletConflict).Once()
+	walletRepo.On("Debit", ctx, senderWallet.ID, amount, int64(4)).Return(nil).Once()
+	walletRepo.On("Credit", ctx, receiverWallet.ID, amount, int64(7)).Return(nil).Once()
+	ledgerRepo.On("CreateEntries", ctx, entities.NewTransferPosting(99, senderWallet.ID, receiverWallet.ID, amount)).Return(nil).Once()
+
+	transactionRepo.On("Create", ctx, mock.Anything).Return(int64(99), nil).Once()
+	expectAuthorization(transactionRepo, authService, ctx, 99, approved())
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusCompleted, "transfer settled").Return(nil).Once()
+
+	outboxRepo.On("Create", ctx, transferNotification(receiverID, 99, amount)).Return(nil).Once()
+
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Ledger: ledgerRepo, Outbox: outboxRepo, Locker: &fakeWalletLocker{}}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, nil, nil, nil, 0)
+
+	_, err := tx.Execute(ctx, TransferInput{PayerID: senderID, PayeeID: receiverID, Amount: amount})
+	assert.NoError(t, err)
+
+	walletRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	authService.AssertExpectations(t)
+}
+
+func TestTransaction_Execute_GivesUpAfterRepeatedConflicts(t *testing.T) {
+	ctx := context.Background()
+	senderID := int64(1)
+	receiverID := int64(2)
+	amount := entities.MoneyFromCents(5000)
+
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+	ledgerRepo := new(MockLedgerRepository)
+	authService := new(mockAuthService)
+
+	userRepo.On("GetByID", ctx, senderID).Return(&entities.User{ID: senderID}, nil)
+	userRepo.On("GetByID", ctx, receiverID).Return(&entities.User{ID: receiverID}, nil)
+
+	senderWallet := &entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(10000)}
+	receiverWallet := &entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(2500)}
+
+	walletRepo.On("GetByOwnerID", ctx, senderID).Return(senderWallet, nil)
+	walletRepo.On("GetByOwnerID", ctx, receiverID).Return(receiverWallet, nil)
+	walletRepo.On("GetByID", ctx, senderWallet.ID).Return(senderWallet, nil)
+	walletRepo.On("GetByID", ctx, receiverWallet.ID).Return(receiverWallet, nil)
+	walletRepo.On("Debit", ctx, senderWallet.ID, amount, int64(0)).Return(port.ErrWalletConflict).Times(maxWalletUpdateAttempts)
+
+	transactionRepo.On("Create", ctx, mock.Anything).Return(int64(99), nil).Once()
+	expectAuthorization(transactionRepo, authService, ctx, 99, approved())
+	transactionRepo.On("UpdateStatus", mock.Anything, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusFailed, "settlement failed").Return(nil).Once()
+
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Ledger: ledgerRepo, Locker: &fakeWalletLocker{}}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, nil, nil, nil, 0)
+
+	_, err := tx.Execute(ctx, TransferInput{PayerID: senderID, PayeeID: receiverID, Amount: amount})
+	assert.ErrorIs(t, err, port.ErrWalletConflict)
+
+	walletRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+}
+
+// transferFixture wires a payer (user 1, wallet 10) and a payee (user 2,
+// wallet 20), plus any other parties given, into mocked repositories and a
+// Transaction whose unit of work runs over them. Tests adjust the wallets and
+// expect the calls of the flow they exercise; looking parties up is optional.
+type transferFixture struct {
+	ctx             context.Context
+	payerWallet     *entities.Wallet
+	payeeWallet     *entities.Wallet
+	userRepo        *mockUserRepo
+	walletRepo      *mockWalletRepo
+	transactionRepo *mockTransactionRepo
+	ledgerRepo      *MockLedgerRepository
+	outboxRepo      *MockOutboxRepository
+	counterRepo     *mockTransferCounterRepo
+	authService     *mockAuthService
+	tx              *Transaction
+}
+
+func newTransferFixture(ctx context.Context, others ...*entities.Wallet) *transferFixture {
+	f := &transferFixture{
+		ctx:             ctx,
+		payerWallet:     &entities.Wallet{ID: 10, OwnerID: 1, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(10000)},
+		payeeWallet:     &entities.Wallet{ID: 20, OwnerID: 2, Type: entities.MerchantWallet},
+		userRepo:        new(mockUserRepo),
+		walletRepo:      new(mockWalletRepo),
+		transactionRepo: new(mockTransactionRepo),
+		ledgerRepo:      new(MockLedgerRepository),
+		outboxRepo:      new(MockOutboxRepository),
+		counterRepo:     new(mockTransferCounterRepo),
+		authService:     new(mockAuthService),
+	}
+	for _, wallet := range append([]*entities.Wallet{f.payerWallet, f.payeeWallet}, others...) {
+		f.userRepo.On("GetByID", ctx, wallet.OwnerID).Return(&entities.User{ID: wallet.OwnerID}, nil).Maybe()
+		f.walletRepo.On("GetByOwnerID", ctx, wallet.OwnerID).Return(wallet, nil).Maybe()
+		f.walletRepo.On("GetByID", ctx, wallet.ID).Return(wallet, nil).Maybe()
+	}
+
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: f.walletRepo, Transactions: f.transactionRepo, Ledger: f.ledgerRepo, Outbox: f.outboxRepo, Counters: f.counterRepo, Locker: &fakeWalletLocker{}}}
+	f.tx = NewTransaction(f.userRepo, f.walletRepo, f.transactionRepo, unitOfWork, f.authService, nil, nil, nil, 0)
+	return f
+}
+
+// newAuthorizationFixture is a transfer between two common wallets whose
+// record is created as 99.
+func newAuthorizationFixture(ctx context.Context) (*mockUserRepo, *mockWalletRepo, *mockTransactionRepo, *mockAuthService) {
+	f := newTransferFixture(ctx)
+	f.payeeWallet.Type = entities.CommonWallet
+	f.transactionRepo.On("Create", ctx, mock.Anything).Return(int64(99), nil).Once()
+	return f.userRepo, f.walletRepo, f.transactionRepo, f.authService
+}
+
+func TestTransaction_Execute_AuthorizationDenied(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	denied := entities.AuthorizationDecision{Outcome: entities.AuthorizationDenied, ReasonCode: "AMOUNT_LIMIT", Reference: "auth-2"}
+	expectAuthorization(transactionRepo, authService, ctx, 99, denied)
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusFailed, "authorization denied").Return(nil).Once()
+
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000)})
+	assert.Nil(t, transaction)
+	assert.ErrorIs(t, err, ErrTransferNotAuthorized)
+	var deniedErr *AuthorizationDeniedError
+	assert.ErrorAs(t, err, &deniedErr)
+	assert.Equal(t, int64(99), deniedErr.Transaction.ID)
+	assert.Equal(t, denied, deniedErr.Transaction.Authorization)
+	assert.Equal(t, entities.TransactionStatusFailed, deniedErr.Transaction.Status)
+
+	transactionRepo.AssertExpectations(t)
+}
+
+func TestTransaction_Execute_AuthorizationUnderReview(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	review := entities.AuthorizationDecision{Outcome: entities.AuthorizationReview, ReasonCode: "MANUAL_REVIEW", Reference: "auth-3"}
+	expectAuthorization(transactionRepo, authService, ctx, 99, review)
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusPending, "authorization under review").Return(nil).Once()
+
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000)})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusPending, transaction.Status)
+	assert.Equal(t, review, transaction.Authorization)
+
+	transactionRepo.AssertExpectations(t)
+	walletRepo.AssertNotCalled(t, "Debit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Execute_AuthorizationUnavailable(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusPending, entities.TransactionStatusAuthorizing, "authorization requested").Return(nil).Once()
+	authService.On("Authorize", ctx, mock.Anything).Return(entities.AuthorizationDecision{}, port.ErrServiceUnavailable).Once()
+	transactionRepo.On("UpdateStatus", mock.Anything, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusFailed, "authorization failed").Return(nil).Once()
+
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000)})
+	assert.Nil(t, transaction)
+	assert.ErrorIs(t, err, port.ErrServiceUnavailable)
+
+	transactionRepo.AssertExpectations(t)
+	transactionRepo.AssertNotCalled(t, "UpdateAuthorization", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Execute_UnknownAuthorizationOutcome(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	expectAuthorization(transactionRepo, authService, ctx, 99, entities.AuthorizationDecision{Outcome: "MAYBE"})
+	transactionRepo.On("UpdateStatus", mock.Anything, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusFailed, "authorization failed").Return(nil).Once()
+
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	_, err := tx.Execute(ctx, TransferInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000)})
+	assert.ErrorIs(t, err, ErrUnknownAuthorizationOutcome)
+
+	transactionRepo.AssertExpectations(t)
+}
+
+func TestTransaction_GetTransfer_Participant(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	stored := &entities.Transaction{ID: 99, SenderID: 1, ReceiverID: 2, Amount: entities.MoneyFromCents(5000), Status: entities.TransactionStatusCompleted}
+	transactionRepo.On("GetByID", ctx, int64(99)).Return(stored, nil)
+
+	tx := NewTransaction(nil, nil, transactionRepo, nil, nil, nil, nil, nil, 0)
+
+	for _, requesterID := range []int64{1, 2} {
+		transaction, err := tx.GetTransfer(ctx, 99, requesterID)
+		assert.NoError(t, err)
+		assert.Equal(t, stored, transaction)
+	}
+}
+
+func TestTransaction_GetTransfer_AccessDenied(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	stored := &entities.Transaction{ID: 99, SenderID: 1, ReceiverID: 2}
+	transactionRepo.On("GetByID", ctx, int64(99)).Return(stored, nil)
+
+	tx := NewTransaction(nil, nil, transactionRepo, nil, nil, nil, nil, nil, 0)
+
+	transaction, err := tx.GetTransfer(ctx, 99, 3)
+	assert.ErrorIs(t, err, ErrTransferAccessDenied)
+	assert.Nil(t, transaction)
+}
+
+func TestTransaction_GetTransfer_NotFound(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	transactionRepo.On("GetByID", ctx, int64(99)).Return(nil, port.ErrTransactionNotFound)
+
+	tx := NewTransaction(nil, nil, transactionRepo, nil, nil, nil, nil, nil, 0)
+
+	transaction, err := tx.GetTransfer(ctx, 99, 1)
+	assert.ErrorIs(t, err, ErrTransferNotFound)
+	assert.Nil(t, transaction)
+}
+
+func TestTransaction_Execute_ChargesFeeToRevenueWallet(t *testing.T) {
+	ctx := context.Background()
+	senderID := int64(1)
+	receiverID := int64(2)
+	amount := entities.MoneyFromCents(5000)
+	fee := entities.MoneyFromCents(100)
+
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+	ledgerRepo := new(MockLedgerRepository)
+	authService := new(mockAuthService)
+	outboxRepo := new(MockOutboxRepository)
+
+	fees := entities.NewFeeTable()
+	assert.NoError(t, fees.Set(entities.CommonWallet, entities.MerchantWallet, entities.FeeSchedule{Name: "merchant", Type: entities.FeeFlat, Flat: fee}))
+	breakdown := entities.FeeBreakdown{Schedule: "merchant", Lines: []entities.FeeLine{{Code: entities.FeeLineFlat, Amount: fee}}}
+
+	userRepo.On("GetByID", ctx, senderID).Return(&entities.User{ID: senderID}, nil)
+	userRepo.On("GetByID", ctx, receiverID).Return(&entities.User{ID: receiverID}, nil)
+
+	senderWallet := &entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(5100)}
+	receiverWallet := &entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.MerchantWallet}
+	revenueWallet := &entities.Wallet{ID: 30, Type: entities.SystemWallet}
+
+	walletRepo.On("GetByOwnerID", ctx, senderID).Return(senderWallet, nil)
+	walletRepo.On("GetByOwnerID", ctx, receiverID).Return(receiverWallet, nil)
+	walletRepo.On("GetByID", ctx, senderWallet.ID).Return(senderWallet, nil)
+	walletRepo.On("GetByID", ctx, receiverWallet.ID).Return(receiverWallet, nil)
+	walletRepo.On("GetSystemWallet", ctx, entities.SystemAccountRevenue, entities.DefaultCurrency).Return(revenueWallet, nil)
+	walletRepo.On("GetByID", ctx, revenueWallet.ID).Return(revenueWallet, nil)
+	walletRepo.On("Debit", ctx, senderWallet.ID, entities.MoneyFromCents(5100), senderWallet.Version).Return(nil)
+	walletRepo.On("Credit", ctx, receiverWallet.ID, amount, receiverWallet.Version).Return(nil)
+	walletRepo.On("Credit", ctx, revenueWallet.ID, fee, revenueWallet.Version).Return(nil)
+	posting := append(entities.NewTransferPosting(99, senderWallet.ID, receiverWallet.ID, amount), entities.NewTransferPosting(99, senderWallet.ID, revenueWallet.ID, fee)...)
+	ledgerRepo.On("CreateEntries", ctx, posting).Return(nil)
+
+	transactionRepo.On("Create", ctx, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.Fee == fee && assert.ObjectsAreEqual(breakdown, transaction.FeeBreakdown)
+	})).Return(int64(99), nil)
+	expectAuthorization(transactionRepo, authService, ctx, 99, approved())
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusCompleted, "transfer settled").Return(nil)
+	outboxRepo.On("Create", ctx, transferNotification(receiverID, 99, amount)).Return(nil)
+
+	locker := &fakeWalletLocker{}
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Ledger: ledgerRepo, Outbox: outboxRepo, Locker: locker}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, nil, fees, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: senderID, PayeeID: receiverID, Amount: amount})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusCompleted, transaction.Status)
+	assert.Equal(t, fee, transaction.Fee)
+	assert.Equal(t, [][]int64{{senderWallet.ID, receiverWallet.ID}, {revenueWallet.ID}}, locker.locked)
+
+	walletRepo.AssertExpectations(t)
+	ledgerRepo.AssertExpectations(t)
+}
+
+func TestTransaction_Execute_FeeExceedsBalance(t *testing.T) {
+	ctx := context.Background()
+	senderID := int64(1)
+	receiverID := int64(2)
+
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+
+	fees := entities.NewFeeTable()
+	assert.NoError(t, fees.Set(entities.CommonWallet, entities.CommonWallet, entities.FeeSchedule{Type: entities.FeeFlat, Flat: entities.MoneyFromCents(100)}))
+
+	userRepo.On("GetByID", ctx, senderID).Return(&entities.User{ID: senderID}, nil)
+	userRepo.On("GetByID", ctx, receiverID).Return(&entities.User{ID: receiverID}, nil)
+	walletRepo.On("GetByOwnerID", ctx, senderID).Return(&entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(5000)}, nil)
+	walletRepo.On("GetByOwnerID", ctx, receiverID).Return(&entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.CommonWallet}, nil)
+
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, new(mockAuthService), nil, fees, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: senderID, PayeeID: receiverID, Amount: entities.MoneyFromCents(5000)})
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	assert.Nil(t, transaction)
+	transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 }
@@ -0,0 +1,488 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+var (
+	ErrTransferBatchNotFound     = errors.New("transfer batch not found")
+	ErrTransferBatchAccessDenied = errors.New("transfer batch does not belong to the requester")
+	ErrInvalidTransferBatchMode  = errors.New("batch mode must be ALL_OR_NOTHING or BEST_EFFORT")
+	ErrEmptyTransferBatch        = errors.New("transfer batch has no items")
+	ErrTransferBatchTooLarge     = errors.New("transfer batch has too many items")
+	ErrInvalidBatchItemAmount    = errors.New("batch item amount must be greater than zero")
+)
+
+var (
+	// errBatchAborted is recorded on the items of an ALL_OR_NOTHING batch
+	// given up because of another item.
+	errBatchAborted = errors.New("batch aborted")
+	// errBatchItemUnderReview fails an ALL_OR_NOTHING batch, which cannot
+	// wait for a manual review.
+	errBatchItemUnderReview = errors.New("transfer held for review")
+)
+
+// BatchItemError tells which item of a batch, by position, caused Err.
+type BatchItemError struct {
+	Position int
+	Err      error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("item %d: %s", e.Position, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+type BatchItemInput struct {
+	PayeeID int64
+	// Amount is in the payer's currency.
+	Amount entities.Money
+}
+
+type BatchInput struct {
+	PayerID int64
+	Mode    entities.TransferBatchMode
+	Items   []BatchItemInput
+}
+
+type TransferBatches struct {
+	batchRepo    port.TransferBatchRepository
+	transactions *Transaction
+	unitOfWork   port.UnitOfWork
+	maxItems     int
+	// lease is how long a worker owns the batches it claimed.
+	lease time.Duration
+	now   func() time.Time
+}
+
+func NewTransferBatches(batchRepo port.TransferBatchRepository, transactions *Transaction, unitOfWork port.UnitOfWork, maxItems int, lease time.Duration) *TransferBatches {
+	return &TransferBatches{
+		batchRepo:    batchRepo,
+		transactions: transactions,
+		unitOfWork:   unitOfWork,
+		maxItems:     maxItems,
+		lease:        lease,
+		now:          time.Now,
+	}
+}
+
+// Submit queues a batch once it is valid as a whole: every payee exists and
+// the payer can afford all items, fees included, and stays within limits.
+// The transfers are made later by RunQueued.
+func (b *TransferBatches) Submit(ctx context.Context, input BatchInput) (*entities.TransferBatch, error) {
+	switch input.Mode {
+	case entities.TransferBatchAllOrNothing, entities.TransferBatchBestEffort:
+	default:
+		return nil, ErrInvalidTransferBatchMode
+	}
+	if len(input.Items) == 0 {
+		return nil, ErrEmptyTransferBatch
+	}
+	if len(input.Items) > b.maxItems {
+		return nil, ErrTransferBatchTooLarge
+	}
+
+	var payerWallet *entities.Wallet
+	var amount, total entities.Money
+	items := make([]entities.TransferBatchItem, 0, len(input.Items))
+	for position, item := range input.Items {
+		if !item.Amount.IsPositive() {
+			return nil, &BatchItemError{Position: position, Err: ErrInvalidBatchItemAmount}
+		}
+		senderWallet, receiverWallet, err := b.transactions.transferWallets(ctx, input.PayerID, item.PayeeID)
+		if errors.Is(err, ErrSenderNotFound) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, &BatchItemError{Position: position, Err: err}
+		}
+		if senderWallet.Type == entities.MerchantWallet {
+			return nil, ErrMerchantCannotTransfer
+		}
+		quote, err := b.transactions.price(ctx, TransferInput{PayerID: input.PayerID, PayeeID: item.PayeeID, Amount: item.Amount}, senderWallet, receiverWallet)
+		if err != nil {
+			return nil, &BatchItemError{Position: position, Err: err}
+		}
+
+		if payerWallet == nil {
+			payerWallet = senderWallet
+			amount = entities.NewMoney(0, senderWallet.CurrencyCode())
+			total = amount
+		}
+		if amount, err = amount.Add(quote.Amount); err != nil {
+			return nil, err
+		}
+		if total, err = total.Add(quote.Total); err != nil {
+			return nil, err
+		}
+		items = append(items, entities.TransferBatchItem{
+			Position: position,
+			PayeeID:  item.PayeeID,
+			Amount:   quote.Amount,
+			Currency: quote.Amount.Currency,
+			Status:   entities.TransferBatchItemStatusQueued,
+		})
+	}
+	short, err := payerWallet.AvailableBalance().LessThan(total)
+	if err != nil {
+		return nil, err
+	}
+	if short {
+		return nil, ErrInsufficientBalance
+	}
+	if limits := b.transactions.limits; limits != nil {
+		if err := limits.Check(ctx, input.PayerID, payerWallet.Type, amount); err != nil {
+			return nil, err
+		}
+	}
+
+	batch := &entities.TransferBatch{
+		PayerID:  input.PayerID,
+		Mode:     input.Mode,
+		Status:   entities.TransferBatchStatusQueued,
+		Total:    amount,
+		Currency: amount.Currency,
+		Items:    items,
+	}
+	if err := b.batchRepo.Create(ctx, batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// Get returns a batch only to its payer.
+func (b *TransferBatches) Get(ctx context.Context, id, requesterID int64) (*entities.TransferBatch, error) {
+	batch, err := b.batchRepo.GetByID(ctx, id)
+	if errors.Is(err, port.ErrTransferBatchNotFound) {
+		return nil, ErrTransferBatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if batch.PayerID != requesterID {
+		return nil, ErrTransferBatchAccessDenied
+	}
+	return batch, nil
+}
+
+// RunQueued processes up to limit queued batches and returns how many it
+// finished. The payer of each is sent one summary notification.
+func (b *TransferBatches) RunQueued(ctx context.Context, limit int) (int, error) {
+	batches, err := b.batchRepo.ClaimQueued(ctx, b.now(), b.lease, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	finished := 0
+	for i := range batches {
+		if ctx.Err() != nil {
+			return finished, ctx.Err()
+		}
+		var err error
+		if batches[i].Mode == entities.TransferBatchAllOrNothing {
+			err = b.runAllOrNothing(ctx, &batches[i])
+		} else {
+			err = b.runBestEffort(ctx, &batches[i])
+		}
+		if errors.Is(err, port.ErrTransferBatchLeaseLost) {
+			// Another worker claimed it after this one ran out of lease.
+			continue
+		}
+		if err != nil {
+			return finished, err
+		}
+		finished++
+	}
+	return finished, nil
+}
+
+// renewLease keeps batch claimed for another lease before an item is made.
+func (b *TransferBatches) renewLease(ctx context.Context, batch *entities.TransferBatch) error {
+	return b.batchRepo.ExtendLease(ctx, batch, b.now().Add(b.lease))
+}
+
+// runBestEffort makes each item as a transfer of its own. An item is
+// marked PROCESSING in the same write that creates its transfer, so one
+// found PROCESSING after a crash is recovered from that transfer rather than
+// paid twice. The lease is renewed for every item, so it only runs out if a
+// worker stalls on one, and a worker that lost its batch cannot record items
+// over the new claim's.
+func (b *TransferBatches) runBestEffort(ctx context.Context, batch *entities.TransferBatch) error {
+	// Recording outcomes outlives cancellation, or a finished transfer
+	// would be left PROCESSING.
+	bookkeeping := context.WithoutCancel(ctx)
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		switch item.Status {
+		case entities.TransferBatchItemStatusQueued, entities.TransferBatchItemStatusProcessing:
+		default:
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := b.renewLease(ctx, batch); err != nil {
+			return err
+		}
+
+		var err error
+		if item.Status == entities.TransferBatchItemStatusProcessing {
+			err = b.recoverItem(ctx, item)
+		} else {
+			err = b.makeItem(ctx, batch, item)
+		}
+		if err != nil {
+			return err
+		}
+		if err := b.batchRepo.UpdateItem(bookkeeping, batch, item); err != nil {
+			return err
+		}
+	}
+	return b.finish(bookkeeping, batch)
+}
+
+// makeItem makes the transfer of a queued item and records its outcome on
+// item. It only returns an error when the batch cannot go on.
+func (b *TransferBatches) makeItem(ctx context.Context, batch *entities.TransferBatch, item *entities.TransferBatchItem) error {
+	t := b.transactions
+	input := TransferInput{PayerID: batch.PayerID, PayeeID: item.PayeeID, Amount: item.Amount}
+	payerWallet, payeeWallet, quote, err := t.validateTransaction(ctx, input)
+	var transaction *entities.Transaction
+	if err == nil {
+		transaction, err = b.startItem(ctx, batch, item, quote)
+		if errors.Is(err, port.ErrTransferBatchLeaseLost) {
+			return err
+		}
+	}
+	if err == nil {
+		transaction, err = t.proceed(ctx, transaction, payerWallet, payeeWallet, quote.Legs, input)
+	}
+	if err != nil && ctx.Err() != nil {
+		// Left QUEUED or PROCESSING: the next claim picks it up.
+		return ctx.Err()
+	}
+	recordBatchItem(item, transaction, err)
+	return nil
+}
+
+func recordBatchItem(item *entities.TransferBatchItem, transaction *entities.Transaction, err error) {
+	if err != nil {
+		item.Fail(err.Error())
+		return
+	}
+	item.Status = entities.TransferBatchItemStatusCompleted
+	if transaction.Status == entities.TransactionStatusPending {
+		item.Status = entities.TransferBatchItemStatusPending
+	}
+}
+
+// startItem creates the transfer of item and marks the item PROCESSING with
+// it in one unit of work, so no item is left PROCESSING without the transfer
+// it was making.
+func (b *TransferBatches) startItem(ctx context.Context, batch *entities.TransferBatch, item *entities.TransferBatchItem, quote *TransferQuote) (*entities.Transaction, error) {
+	var transaction *entities.Transaction
+	err := b.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		created, err := b.transactions.createTransaction(ctx, repos.Transactions, quote, false, entities.TransferDetails{})
+		if err != nil {
+			return err
+		}
+		item.Status = entities.TransferBatchItemStatusProcessing
+		item.TransactionID = &created.ID
+		if err := repos.Batches.UpdateItem(ctx, batch, item); err != nil {
+			return err
+		}
+		transaction = created
+		return nil
+	})
+	if err != nil {
+		item.Status = entities.TransferBatchItemStatusQueued
+		item.TransactionID = nil
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// recoverItem records the outcome of an item an earlier worker left
+// PROCESSING from the transfer it was making. A transfer still in flight is
+// failed, so the earlier worker cannot settle it afterwards, and its item is
+// reported INTERRUPTED.
+func (b *TransferBatches) recoverItem(ctx context.Context, item *entities.TransferBatchItem) error {
+	if item.TransactionID == nil {
+		item.Interrupt(errRunInterrupted.Error())
+		return nil
+	}
+	t := b.transactions
+	for {
+		transaction, err := t.transactionRepo.GetByID(ctx, *item.TransactionID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case transaction.Status == entities.TransactionStatusPending && transaction.Authorization.Outcome == entities.AuthorizationReview:
+			item.Status = entities.TransferBatchItemStatusPending
+		case transaction.Status == entities.TransactionStatusPending, transaction.Status == entities.TransactionStatusAuthorizing:
+			err := transitionTransaction(ctx, t.transactionRepo, transaction, entities.TransactionStatusFailed, errRunInterrupted.Error())
+			if errors.Is(err, port.ErrTransactionStatusConflict) {
+				// The earlier worker moved it on meanwhile.
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			item.Interrupt(errRunInterrupted.Error())
+		case slices.Contains(entities.SettledStatuses, transaction.Status):
+			item.Status = entities.TransferBatchItemStatusCompleted
+		default:
+			item.Fail("transfer " + strings.ToLower(string(transaction.Status)))
+		}
+		return nil
+	}
+}
+
+// runAllOrNothing has every item authorized and then settles them all in a
+// single unit of work. If any item cannot be made, the transfers created
+// for the others are failed and no money moves. Settling records the items
+// under the batch's claim, so a worker whose batch was claimed again rolls
+// back.
+func (b *TransferBatches) runAllOrNothing(ctx context.Context, batch *entities.TransferBatch) error {
+	bookkeeping := context.WithoutCancel(ctx)
+	for _, item := range batch.Items {
+		if item.Status != entities.TransferBatchItemStatusQueued {
+			// An earlier worker stopped before settling.
+			return b.abort(bookkeeping, batch, errRunInterrupted)
+		}
+	}
+
+	transactions := make([]*entities.Transaction, 0, len(batch.Items))
+	for i := range batch.Items {
+		if err := b.renewLease(ctx, batch); err != nil {
+			return err
+		}
+		transaction, err := b.authorizeItem(ctx, batch, &batch.Items[i])
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, port.ErrTransferBatchLeaseLost) {
+			return err
+		}
+		if err != nil {
+			return b.abort(bookkeeping, batch, &BatchItemError{Position: batch.Items[i].Position, Err: err})
+		}
+		transactions = append(transactions, transaction)
+	}
+
+	err := retryOnWalletConflict(func() error {
+		return b.transactions.settleTogether(ctx, transactions, func(repos port.Repositories) error {
+			for i := range batch.Items {
+				batch.Items[i].Status = entities.TransferBatchItemStatusCompleted
+				if err := repos.Batches.UpdateItem(ctx, batch, &batch.Items[i]); err != nil {
+					return err
+				}
+			}
+			batch.Finish()
+			if err := repos.Batches.Finish(ctx, batch); err != nil {
+				return err
+			}
+			return enqueue(ctx, repos, batch.FinishedPayload())
+		})
+	})
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, port.ErrTransferBatchLeaseLost) {
+		// The new claim aborts the batch and its transfers.
+		return err
+	}
+	if err != nil {
+		return b.abort(bookkeeping, batch, err)
+	}
+	return nil
+}
+
+// authorizeItem creates the transfer of item and has it approved, without
+// settling it.
+func (b *TransferBatches) authorizeItem(ctx context.Context, batch *entities.TransferBatch, item *entities.TransferBatchItem) (*entities.Transaction, error) {
+	t := b.transactions
+	payerWallet, payeeWallet, quote, err := t.validateTransaction(ctx, TransferInput{
+		PayerID: batch.PayerID,
+		PayeeID: item.PayeeID,
+		Amount:  item.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := b.startItem(ctx, batch, item, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.authorize(ctx, transaction, payerWallet, payeeWallet, nil, port.ClientMetadata{}); err != nil {
+		return nil, err
+	}
+	if transaction.Status == entities.TransactionStatusPending {
+		return nil, errBatchItemUnderReview
+	}
+	return transaction, nil
+}
+
+// abort fails every item of an ALL_OR_NOTHING batch along with the
+// transfers created for them, and finishes the batch as FAILED.
+func (b *TransferBatches) abort(ctx context.Context, batch *entities.TransferBatch, cause error) error {
+	var itemErr *BatchItemError
+	errors.As(cause, &itemErr)
+	for i := range batch.Items {
+		item := &batch.Items[i]
+		if item.TransactionID != nil {
+			if err := b.failTransfer(ctx, *item.TransactionID); err != nil {
+				return err
+			}
+		}
+		reason := errBatchAborted.Error()
+		if itemErr == nil {
+			reason = cause.Error()
+		} else if itemErr.Position == item.Position {
+			reason = itemErr.Err.Error()
+		}
+		item.Fail(reason)
+		if err := b.batchRepo.UpdateItem(ctx, batch, item); err != nil {
+			return err
+		}
+	}
+	batch.FailureReason = cause.Error()
+	return b.finish(ctx, batch)
+}
+
+// failTransfer fails a transfer of an aborted batch unless it is already
+// over, as a denied one is.
+func (b *TransferBatches) failTransfer(ctx context.Context, transactionID int64) error {
+	t := b.transactions
+	transaction, err := t.transactionRepo.GetByID(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if entities.ValidateTransition(transaction.Status, entities.TransactionStatusFailed) != nil {
+		return nil
+	}
+	return transitionTransaction(ctx, t.transactionRepo, transaction, entities.TransactionStatusFailed, errBatchAborted.Error())
+}
+
+func (b *TransferBatches) finish(ctx context.Context, batch *entities.TransferBatch) error {
+	batch.Finish()
+	return b.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		if err := repos.Batches.Finish(ctx, batch); err != nil {
+			return err
+		}
+		return enqueue(ctx, repos, batch.FinishedPayload())
+	})
+}
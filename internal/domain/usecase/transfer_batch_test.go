@@ -0,0 +1,425 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTransferBatchRepo struct{ mock.Mock }
+
+func (m *mockTransferBatchRepo) Create(ctx context.Context, batch *entities.TransferBatch) error {
+	args := m.Called(ctx, batch)
+	return args.Error(0)
+}
+
+func (m *mockTransferBatchRepo) GetByID(ctx context.Context, id int64) (*entities.TransferBatch, error) {
+	args := m.Called(ctx, id)
+	batch, _ := args.Get(0).(*entities.TransferBatch)
+	return batch, args.Error(1)
+}
+
+func (m *mockTransferBatchRepo) ClaimQueued(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.TransferBatch, error) {
+	args := m.Called(ctx, now, lease, limit)
+	batches, _ := args.Get(0).([]entities.TransferBatch)
+	return batches, args.Error(1)
+}
+
+func (m *mockTransferBatchRepo) ExtendLease(ctx context.Context, batch *entities.TransferBatch, until time.Time) error {
+	args := m.Called(ctx, batch, until)
+	return args.Error(0)
+}
+
+func (m *mockTransferBatchRepo) UpdateItem(ctx context.Context, batch *entities.TransferBatch, item *entities.TransferBatchItem) error {
+	args := m.Called(ctx, batch, item)
+	return args.Error(0)
+}
+
+func (m *mockTransferBatchRepo) Finish(ctx context.Context, batch *entities.TransferBatch) error {
+	args := m.Called(ctx, batch)
+	return args.Error(0)
+}
+
+func newTestTransferBatches(batchRepo *mockTransferBatchRepo, tx *Transaction, outboxRepo *MockOutboxRepository) *TransferBatches {
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Batches: batchRepo, Outbox: outboxRepo}}
+	if tx != nil {
+		unitOfWork.repos.Transactions = tx.transactionRepo
+	}
+	batches := NewTransferBatches(batchRepo, tx, unitOfWork, 3, 5*time.Minute)
+	batches.now = func() time.Time { return scheduleNow }
+	return batches
+}
+
+func queuedBatch(mode entities.TransferBatchMode, cents ...int64) entities.TransferBatch {
+	batch := entities.TransferBatch{ID: 4, PayerID: 1, Mode: mode, Status: entities.TransferBatchStatusProcessing, Currency: entities.DefaultCurrency}
+	for position, amount := range cents {
+		batch.Items = append(batch.Items, entities.TransferBatchItem{
+			ID: int64(position + 1), BatchID: 4, Position: position, PayeeID: 2,
+			Amount: entities.MoneyFromCents(amount), Currency: entities.DefaultCurrency,
+			Status: entities.TransferBatchItemStatusQueued,
+		})
+	}
+	return batch
+}
+
+func batchFinished(status entities.TransferBatchStatus, settled int64) interface{} {
+	return mock.MatchedBy(func(message *entities.OutboxMessage) bool {
+		var payload entities.TransferBatchFinishedPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return false
+		}
+		return message.EventType == entities.OutboxEventTransferBatchFinished &&
+			payload == entities.TransferBatchFinishedPayload{PayerID: 1, BatchID: 4, Status: status, Amount: entities.MoneyFromCents(settled)}
+	})
+}
+
+func batchItem(position int, status entities.TransferBatchItemStatus) interface{} {
+	return mock.MatchedBy(func(item *entities.TransferBatchItem) bool {
+		return item.Position == position && item.Status == status
+	})
+}
+
+func TestTransferBatches_Submit(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	batchRepo := new(mockTransferBatchRepo)
+	batchRepo.On("Create", ctx, mock.MatchedBy(func(batch *entities.TransferBatch) bool {
+		return batch.Status == entities.TransferBatchStatusQueued && len(batch.Items) == 2 &&
+			batch.Items[1].Position == 1 && batch.Items[1].Status == entities.TransferBatchItemStatusQueued
+	})).Return(nil).Once()
+
+	batch, err := newTestTransferBatches(batchRepo, tx, nil).Submit(ctx, BatchInput{
+		PayerID: 1,
+		Mode:    entities.TransferBatchAllOrNothing,
+		Items:   []BatchItemInput{{PayeeID: 2, Amount: entities.MoneyFromCents(4000)}, {PayeeID: 2, Amount: entities.MoneyFromCents(6000)}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(10000), batch.Total)
+	batchRepo.AssertExpectations(t)
+}
+
+func TestTransferBatches_Submit_RejectsTotalAboveBalance(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+	batchRepo := new(mockTransferBatchRepo)
+
+	_, err := newTestTransferBatches(batchRepo, tx, nil).Submit(ctx, BatchInput{
+		PayerID: 1,
+		Mode:    entities.TransferBatchBestEffort,
+		Items:   []BatchItemInput{{PayeeID: 2, Amount: entities.MoneyFromCents(6000)}, {PayeeID: 2, Amount: entities.MoneyFromCents(6000)}},
+	})
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	batchRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTransferBatches_Submit_RejectsInvalidBatches(t *testing.T) {
+	one := BatchItemInput{PayeeID: 2, Amount: entities.MoneyFromCents(100)}
+	cases := []struct {
+		name  string
+		input BatchInput
+		err   error
+	}{
+		{"mode", BatchInput{PayerID: 1, Items: []BatchItemInput{one}}, ErrInvalidTransferBatchMode},
+		{"empty", BatchInput{PayerID: 1, Mode: entities.TransferBatchBestEffort}, ErrEmptyTransferBatch},
+		{"too large", BatchInput{PayerID: 1, Mode: entities.TransferBatchBestEffort, Items: []BatchItemInput{one, one, one, one}}, ErrTransferBatchTooLarge},
+		{"amount", BatchInput{PayerID: 1, Mode: entities.TransferBatchBestEffort, Items: []BatchItemInput{one, {PayeeID: 2}}}, ErrInvalidBatchItemAmount},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+			tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+			_, err := newTestTransferBatches(new(mockTransferBatchRepo), tx, nil).Submit(ctx, c.input)
+			assert.ErrorIs(t, err, c.err)
+		})
+	}
+}
+
+func TestTransferBatches_Submit_ReportsFailingItem(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	userRepo.On("GetByID", ctx, int64(3)).Return((*entities.User)(nil), nil)
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	_, err := newTestTransferBatches(new(mockTransferBatchRepo), tx, nil).Submit(ctx, BatchInput{
+		PayerID: 1,
+		Mode:    entities.TransferBatchBestEffort,
+		Items:   []BatchItemInput{{PayeeID: 2, Amount: entities.MoneyFromCents(100)}, {PayeeID: 3, Amount: entities.MoneyFromCents(100)}},
+	})
+	var itemErr *BatchItemError
+	assert.ErrorAs(t, err, &itemErr)
+	assert.Equal(t, 1, itemErr.Position)
+}
+
+func TestTransferBatches_Get_AccessDenied(t *testing.T) {
+	ctx := context.Background()
+	batch := queuedBatch(entities.TransferBatchBestEffort, 100)
+	batchRepo := new(mockTransferBatchRepo)
+	batchRepo.On("GetByID", ctx, int64(4)).Return(&batch, nil)
+	batchRepo.On("GetByID", ctx, int64(5)).Return(nil, port.ErrTransferBatchNotFound)
+	batches := newTestTransferBatches(batchRepo, nil, nil)
+
+	_, err := batches.Get(ctx, 4, 2)
+	assert.ErrorIs(t, err, ErrTransferBatchAccessDenied)
+	_, err = batches.Get(ctx, 5, 1)
+	assert.ErrorIs(t, err, ErrTransferBatchNotFound)
+	found, err := batches.Get(ctx, 4, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), found.ID)
+}
+
+func TestTransferBatches_RunQueued_BestEffortMakesEachItem(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	review := entities.AuthorizationDecision{Outcome: entities.AuthorizationReview, ReasonCode: "MANUAL_REVIEW"}
+	expectAuthorization(transactionRepo, authService, ctx, 99, review)
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusPending, "authorization under review").Return(nil).Once()
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	batchRepo := new(mockTransferBatchRepo)
+	outboxRepo := new(MockOutboxRepository)
+	batchRepo.On("ClaimQueued", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.TransferBatch{queuedBatch(entities.TransferBatchBestEffort, 5000, 20000)}, nil)
+	batchRepo.On("ExtendLease", mock.Anything, mock.Anything, scheduleNow.Add(5*time.Minute)).Return(nil).Times(2)
+	batchRepo.On("UpdateItem", mock.Anything, mock.Anything, batchItem(0, entities.TransferBatchItemStatusProcessing)).Return(nil).Once()
+	batchRepo.On("UpdateItem", mock.Anything, mock.Anything, batchItem(0, entities.TransferBatchItemStatusPending)).Return(nil).Once()
+	batchRepo.On("UpdateItem", mock.Anything, mock.Anything, mock.MatchedBy(func(item *entities.TransferBatchItem) bool {
+		return item.Position == 1 && item.Status == entities.TransferBatchItemStatusFailed && item.FailureReason == ErrInsufficientBalance.Error()
+	})).Return(nil).Once()
+	batchRepo.On("Finish", mock.Anything, mock.MatchedBy(func(batch *entities.TransferBatch) bool {
+		return batch.Status == entities.TransferBatchStatusPartiallyCompleted
+	})).Return(nil).Once()
+	outboxRepo.On("Create", mock.Anything, batchFinished(entities.TransferBatchStatusPartiallyCompleted, 0)).Return(nil).Once()
+
+	finished, err := newTestTransferBatches(batchRepo, tx, outboxRepo).RunQueued(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, finished)
+	batchRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+}
+
+func TestTransferBatches_RunQueued_BestEffortInterruptsItemWithoutTransfer(t *testing.T) {
+	ctx := context.Background()
+	batch := queuedBatch(entities.TransferBatchBestEffort, 5000)
+	batch.Items[0].Status = entities.TransferBatchItemStatusProcessing
+
+	batchRepo := new(mockTransferBatchRepo)
+	outboxRepo := new(MockOutboxRepository)
+	batchRepo.On("ClaimQueued", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.TransferBatch{batch}, nil)
+	batchRepo.On("ExtendLease", ctx, mock.Anything, scheduleNow.Add(5*time.Minute)).Return(nil).Once()
+	batchRepo.On("UpdateItem", mock.Anything, mock.Anything, mock.MatchedBy(func(item *entities.TransferBatchItem) bool {
+		return item.Status == entities.TransferBatchItemStatusInterrupted && item.FailureReason == errRunInterrupted.Error()
+	})).Return(nil).Once()
+	batchRepo.On("Finish", mock.Anything, mock.Anything).Return(nil).Once()
+	outboxRepo.On("Create", mock.Anything, batchFinished(entities.TransferBatchStatusFailed, 0)).Return(nil).Once()
+
+	finished, err := newTestTransferBatches(batchRepo, nil, outboxRepo).RunQueued(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, finished)
+	batchRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestTransferBatches_RunQueued_BestEffortRecoversInterruptedItemFromItsTransfer(t *testing.T) {
+	review := entities.AuthorizationDecision{Outcome: entities.AuthorizationReview}
+	cases := []struct {
+		name       string
+		transfer   entities.Transaction
+		interrupts bool
+		item       entities.TransferBatchItemStatus
+		batch      entities.TransferBatchStatus
+		settled    int64
+	}{
+		{"settled", entities.Transaction{Status: entities.TransactionStatusCompleted}, false, entities.TransferBatchItemStatusCompleted, entities.TransferBatchStatusCompleted, 5000},
+		{"under review", entities.Transaction{Status: entities.TransactionStatusPending, Authorization: review}, false, entities.TransferBatchItemStatusPending, entities.TransferBatchStatusCompleted, 0},
+		{"denied", entities.Transaction{Status: entities.TransactionStatusFailed}, false, entities.TransferBatchItemStatusFailed, entities.TransferBatchStatusFailed, 0},
+		{"in flight", entities.Transaction{Status: entities.TransactionStatusAuthorizing}, true, entities.TransferBatchItemStatusInterrupted, entities.TransferBatchStatusFailed, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			transactionID := int64(99)
+			batch := queuedBatch(entities.TransferBatchBestEffort, 5000)
+			batch.Items[0].Status = entities.TransferBatchItemStatusProcessing
+			batch.Items[0].TransactionID = &transactionID
+
+			transfer := c.transfer
+			transfer.ID = transactionID
+			transactionRepo := new(mockTransactionRepo)
+			transactionRepo.On("GetByID", ctx, transactionID).Return(&transfer, nil).Once()
+			if c.interrupts {
+				transactionRepo.On("UpdateStatus", ctx, transactionID, c.transfer.Status, entities.TransactionStatusFailed, errRunInterrupted.Error()).Return(nil).Once()
+			}
+			tx := NewTransaction(nil, nil, transactionRepo, &fakeUnitOfWork{}, nil, nil, nil, nil, 0)
+
+			batchRepo := new(mockTransferBatchRepo)
+			outboxRepo := new(MockOutboxRepository)
+			batchRepo.On("ClaimQueued", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.TransferBatch{batch}, nil)
+			batchRepo.On("ExtendLease", ctx, mock.Anything, scheduleNow.Add(5*time.Minute)).Return(nil).Once()
+			batchRepo.On("UpdateItem", mock.Anything, mock.Anything, mock.MatchedBy(func(item *entities.TransferBatchItem) bool {
+				return item.Status == c.item && *item.TransactionID == transactionID
+			})).Return(nil).Once()
+			batchRepo.On("Finish", mock.Anything, mock.Anything).Return(nil).Once()
+			outboxRepo.On("Create", mock.Anything, batchFinished(c.batch, c.settled)).Return(nil).Once()
+
+			finished, err := newTestTransferBatches(batchRepo, tx, outboxRepo).RunQueued(ctx, 10)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, finished)
+			batchRepo.AssertExpectations(t)
+			outboxRepo.AssertExpectations(t)
+			transactionRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestTransferBatches_RunQueued_BestEffortRecoveryLosesRaceToSettlement(t *testing.T) {
+	ctx := context.Background()
+	transactionID := int64(99)
+	batch := queuedBatch(entities.TransferBatchBestEffort, 5000)
+	batch.Items[0].Status = entities.TransferBatchItemStatusProcessing
+	batch.Items[0].TransactionID = &transactionID
+
+	transactionRepo := new(mockTransactionRepo)
+	transactionRepo.On("GetByID", ctx, transactionID).Return(&entities.Transaction{ID: transactionID, Status: entities.TransactionStatusAuthorizing}, nil).Once()
+	transactionRepo.On("UpdateStatus", ctx, transactionID, entities.TransactionStatusAuthorizing, entities.TransactionStatusFailed, errRunInterrupted.Error()).Return(port.ErrTransactionStatusConflict).Once()
+	transactionRepo.On("GetByID", ctx, transactionID).Return(&entities.Transaction{ID: transactionID, Status: entities.TransactionStatusCompleted}, nil).Once()
+	tx := NewTransaction(nil, nil, transactionRepo, &fakeUnitOfWork{}, nil, nil, nil, nil, 0)
+
+	batchRepo := new(mockTransferBatchRepo)
+	outboxRepo := new(MockOutboxRepository)
+	batchRepo.On("ClaimQueued", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.TransferBatch{batch}, nil)
+	batchRepo.On("ExtendLease", ctx, mock.Anything, scheduleNow.Add(5*time.Minute)).Return(nil).Once()
+	batchRepo.On("UpdateItem", mock.Anything, mock.Anything, batchItem(0, entities.TransferBatchItemStatusCompleted)).Return(nil).Once()
+	batchRepo.On("Finish", mock.Anything, mock.Anything).Return(nil).Once()
+	outboxRepo.On("Create", mock.Anything, batchFinished(entities.TransferBatchStatusCompleted, 5000)).Return(nil).Once()
+
+	finished, err := newTestTransferBatches(batchRepo, tx, outboxRepo).RunQueued(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, finished)
+	batchRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+}
+
+func TestTransferBatches_RunQueued_AllOrNothingSettlesTogether(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	transactionRepo.On("Create", ctx, mock.Anything).Return(int64(100), nil).Once()
+	expectAuthorization(transactionRepo, authService, ctx, 99, approved())
+	expectAuthorization(transactionRepo, authService, ctx, 100, approved())
+
+	ledgerRepo := new(MockLedgerRepository)
+	outboxRepo := new(MockOutboxRepository)
+	for id, cents := range map[int64]int64{99: 4000, 100: 6000} {
+		amount := entities.MoneyFromCents(cents)
+		walletRepo.On("Debit", ctx, int64(10), amount, int64(0)).Return(nil).Once()
+		walletRepo.On("Credit", ctx, int64(20), amount, int64(0)).Return(nil).Once()
+		ledgerRepo.On("CreateEntries", ctx, entities.NewTransferPosting(id, 10, 20, amount)).Return(nil).Once()
+		transactionRepo.On("UpdateStatus", ctx, id, entities.TransactionStatusAuthorizing, entities.TransactionStatusCompleted, "transfer settled").Return(nil).Once()
+		outboxRepo.On("Create", ctx, transferNotification(2, id, amount)).Return(nil).Once()
+	}
+	outboxRepo.On("Create", ctx, batchFinished(entities.TransferBatchStatusCompleted, 10000)).Return(nil).Once()
+
+	batchRepo := new(mockTransferBatchRepo)
+	batchRepo.On("ClaimQueued", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.TransferBatch{queuedBatch(entities.TransferBatchAllOrNothing, 4000, 6000)}, nil)
+	batchRepo.On("ExtendLease", mock.Anything, mock.Anything, scheduleNow.Add(5*time.Minute)).Return(nil).Times(2)
+	batchRepo.On("UpdateItem", ctx, mock.Anything, mock.Anything).Return(nil).Times(4)
+	batchRepo.On("Finish", ctx, mock.MatchedBy(func(batch *entities.TransferBatch) bool {
+		return batch.Status == entities.TransferBatchStatusCompleted
+	})).Return(nil).Once()
+
+	locker := &fakeWalletLocker{}
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Ledger: ledgerRepo, Outbox: outboxRepo, Batches: batchRepo, Locker: locker}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, nil, nil, nil, 0)
+	batches := NewTransferBatches(batchRepo, tx, unitOfWork, 3, 5*time.Minute)
+	batches.now = func() time.Time { return scheduleNow }
+
+	finished, err := batches.RunQueued(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, finished)
+	assert.Equal(t, [][]int64{{10, 20}}, locker.locked)
+	walletRepo.AssertExpectations(t)
+	ledgerRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+	batchRepo.AssertExpectations(t)
+}
+
+func TestTransferBatches_RunQueued_AllOrNothingAbortsOnFailingItem(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	expectAuthorization(transactionRepo, authService, ctx, 99, approved())
+	transactionRepo.On("GetByID", mock.Anything, int64(99)).Return(&entities.Transaction{ID: 99, Status: entities.TransactionStatusAuthorizing}, nil)
+	transactionRepo.On("UpdateStatus", mock.Anything, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusFailed, errBatchAborted.Error()).Return(nil).Once()
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	batchRepo := new(mockTransferBatchRepo)
+	outboxRepo := new(MockOutboxRepository)
+	batchRepo.On("ClaimQueued", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.TransferBatch{queuedBatch(entities.TransferBatchAllOrNothing, 5000, 20000)}, nil)
+	batchRepo.On("ExtendLease", mock.Anything, mock.Anything, scheduleNow.Add(5*time.Minute)).Return(nil).Times(2)
+	batchRepo.On("UpdateItem", ctx, mock.Anything, batchItem(0, entities.TransferBatchItemStatusProcessing)).Return(nil).Once()
+	batchRepo.On("UpdateItem", mock.Anything, mock.Anything, mock.MatchedBy(func(item *entities.TransferBatchItem) bool {
+		return item.Position == 0 && item.Status == entities.TransferBatchItemStatusFailed && item.FailureReason == errBatchAborted.Error()
+	})).Return(nil).Once()
+	batchRepo.On("UpdateItem", mock.Anything, mock.Anything, mock.MatchedBy(func(item *entities.TransferBatchItem) bool {
+		return item.Position == 1 && item.Status == entities.TransferBatchItemStatusFailed && item.FailureReason == ErrInsufficientBalance.Error()
+	})).Return(nil).Once()
+	batchRepo.On("Finish", mock.Anything, mock.MatchedBy(func(batch *entities.TransferBatch) bool {
+		return batch.Status == entities.TransferBatchStatusFailed && batch.FailureReason == "item 1: "+ErrInsufficientBalance.Error()
+	})).Return(nil).Once()
+	outboxRepo.On("Create", mock.Anything, batchFinished(entities.TransferBatchStatusFailed, 0)).Return(nil).Once()
+
+	finished, err := newTestTransferBatches(batchRepo, tx, outboxRepo).RunQueued(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, finished)
+	batchRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	walletRepo.AssertNotCalled(t, "Debit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransferBatches_RunQueued_BestEffortStopsWhenLeaseIsLost(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	tx := NewTransaction(nil, nil, transactionRepo, &fakeUnitOfWork{}, nil, nil, nil, nil, 0)
+
+	batchRepo := new(mockTransferBatchRepo)
+	batchRepo.On("ClaimQueued", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.TransferBatch{queuedBatch(entities.TransferBatchBestEffort, 5000)}, nil)
+	batchRepo.On("ExtendLease", ctx, mock.Anything, scheduleNow.Add(5*time.Minute)).Return(port.ErrTransferBatchLeaseLost).Once()
+
+	finished, err := newTestTransferBatches(batchRepo, tx, nil).RunQueued(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, finished)
+	batchRepo.AssertNotCalled(t, "UpdateItem", mock.Anything, mock.Anything, mock.Anything)
+	batchRepo.AssertNotCalled(t, "Finish", mock.Anything, mock.Anything)
+	transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTransferBatches_RunQueued_AllOrNothingLeavesLostBatchToNewClaim(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	batchRepo := new(mockTransferBatchRepo)
+	batchRepo.On("ClaimQueued", ctx, scheduleNow, 5*time.Minute, 10).Return([]entities.TransferBatch{queuedBatch(entities.TransferBatchAllOrNothing, 5000, 2000)}, nil)
+	batchRepo.On("ExtendLease", ctx, mock.Anything, scheduleNow.Add(5*time.Minute)).Return(nil).Once()
+	batchRepo.On("UpdateItem", ctx, mock.Anything, batchItem(0, entities.TransferBatchItemStatusProcessing)).Return(port.ErrTransferBatchLeaseLost).Once()
+
+	finished, err := newTestTransferBatches(batchRepo, tx, nil).RunQueued(ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, finished)
+	batchRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	batchRepo.AssertNotCalled(t, "Finish", mock.Anything, mock.Anything)
+	authService.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+var ErrTransferNotCancellable = errors.New("transfer can no longer be cancelled")
+
+const defaultCancelReason = "cancelled by payer"
+
+// Cancel stops a transfer that has not moved money yet, whether waiting for
+// authorization, held for review or holding the payer's funds, and records
+// reason in its history. Held funds go back to the payer and the payee, who
+// could have captured them, is told. Only the payer may cancel.
+func (t *Transaction) Cancel(ctx context.Context, transactionID, requesterID int64, reason string) (*entities.Transaction, error) {
+	if reason == "" {
+		reason = defaultCancelReason
+	}
+	var cancelled *entities.Transaction
+	err := retryOnWalletConflict(func() error {
+		var err error
+		cancelled, err = t.cancel(ctx, transactionID, requesterID, reason)
+		return err
+	})
+	if errors.Is(err, port.ErrTransactionStatusConflict) {
+		// Settled, failed or held while it was being cancelled.
+		return nil, ErrTransferNotCancellable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+func (t *Transaction) cancel(ctx context.Context, transactionID, requesterID int64, reason string) (*entities.Transaction, error) {
+	var cancelled *entities.Transaction
+	unlock := func() {}
+	err := t.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		transaction, err := t.cancellableTransfer(ctx, repos, transactionID, requesterID)
+		if err != nil {
+			return err
+		}
+		if transaction.IsHeld() {
+			senderWallet, _, release, err := t.lockWallets(ctx, repos, transaction.SenderID, transaction.ReceiverID)
+			if err != nil {
+				return err
+			}
+			unlock = release
+
+			// Re-read under the wallet locks so a concurrent capture wins.
+			transaction, err = t.cancellableTransfer(ctx, repos, transactionID, requesterID)
+			if err != nil {
+				return err
+			}
+			if err := releaseHold(ctx, repos, senderWallet, transaction.HeldAmount); err != nil {
+				return err
+			}
+			transaction.HeldAmount = entities.NewMoney(0, transaction.Currency)
+			if err := repos.Transactions.UpdateHold(ctx, transaction.ID, transaction.HeldAmount); err != nil {
+				return err
+			}
+		}
+
+		informed := transaction.IsHeld()
+		if err := transitionTransaction(ctx, repos.Transactions, transaction, entities.TransactionStatusCancelled, reason); err != nil {
+			return err
+		}
+		if informed {
+			payload := entities.TransferCancelledPayload{ReceiverID: transaction.ReceiverID, TransactionID: transaction.ID, Amount: *transaction.AuthorizedAmount, Details: transaction.Details}
+			if err := enqueue(ctx, repos, payload); err != nil {
+				return err
+			}
+		}
+		cancelled = transaction
+		return nil
+	})
+	// Released only once the unit of work has committed or rolled back.
+	unlock()
+	if err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+func (t *Transaction) cancellableTransfer(ctx context.Context, repos port.Repositories, transactionID, requesterID int64) (*entities.Transaction, error) {
+	transaction, err := repos.Transactions.GetByID(ctx, transactionID)
+	if errors.Is(err, port.ErrTransactionNotFound) {
+		return nil, ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if transaction.SenderID != requesterID {
+		return nil, ErrTransferAccessDenied
+	}
+	if transaction.Type != entities.TransactionTypeTransfer || entities.ValidateTransition(transaction.Status, entities.TransactionStatusCancelled) != nil {
+		return nil, ErrTransferNotCancellable
+	}
+	return transaction, nil
+}
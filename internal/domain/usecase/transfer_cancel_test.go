@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransaction_Cancel_UnderReview(t *testing.T) {
+	f := newHoldFixture()
+	f.held.Status = entities.TransactionStatusPending
+	f.held.AuthorizedAmount = nil
+	f.held.HeldAmount = entities.NewMoney(0, entities.DefaultCurrency)
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusPending, entities.TransactionStatusCancelled, "order abandoned").Return(nil).Once()
+
+	transaction, err := f.tx.Cancel(f.ctx, 99, 1, "order abandoned")
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusCancelled, transaction.Status)
+	f.transactionRepo.AssertExpectations(t)
+	f.walletRepo.AssertNotCalled(t, "ReleaseHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	f.outboxRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Cancel_ReleasesHoldAndTellsPayee(t *testing.T) {
+	f := newHoldFixture()
+	f.withLimits()
+	f.walletRepo.On("ReleaseHold", f.ctx, f.payerWallet.ID, entities.MoneyFromCents(5000), int64(3)).Return(nil).Once()
+	f.transactionRepo.On("UpdateHold", f.ctx, int64(99), entities.NewMoney(0, entities.DefaultCurrency)).Return(nil).Once()
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusAuthorized, entities.TransactionStatusCancelled, defaultCancelReason).Return(nil).Once()
+	f.outboxRepo.On("Create", f.ctx, mock.MatchedBy(func(message *entities.OutboxMessage) bool {
+		return message.EventType == entities.OutboxEventTransferCancelled && message.AggregateID == 99 &&
+			string(message.Payload) == `{"receiver_id":2,"transaction_id":99,"amount":"50.00"}`
+	})).Return(nil).Once()
+
+	transaction, err := f.tx.Cancel(f.ctx, 99, 1, "")
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusCancelled, transaction.Status)
+	assert.True(t, transaction.HeldAmount.IsZero())
+	f.walletRepo.AssertExpectations(t)
+	f.transactionRepo.AssertExpectations(t)
+	f.outboxRepo.AssertExpectations(t)
+	f.ledgerRepo.AssertNotCalled(t, "CreateEntries", mock.Anything, mock.Anything)
+	f.counterRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Cancel_Rejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      entities.TransactionStatus
+		requesterID int64
+		expect      error
+	}{
+		{"payee", entities.TransactionStatusAuthorized, 2, ErrTransferAccessDenied},
+		{"settled", entities.TransactionStatusCompleted, 1, ErrTransferNotCancellable},
+		{"already cancelled", entities.TransactionStatusCancelled, 1, ErrTransferNotCancellable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newHoldFixture()
+			f.held.Status = tt.status
+
+			transaction, err := f.tx.Cancel(f.ctx, 99, tt.requesterID, "")
+			assert.ErrorIs(t, err, tt.expect)
+			assert.Nil(t, transaction)
+			f.transactionRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTransaction_Cancel_LosesRaceToSettlement(t *testing.T) {
+	f := newHoldFixture()
+	f.held.Status = entities.TransactionStatusAuthorizing
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusCancelled, defaultCancelReason).Return(port.ErrTransactionStatusConflict).Once()
+
+	transaction, err := f.tx.Cancel(f.ctx, 99, 1, "")
+	assert.ErrorIs(t, err, ErrTransferNotCancellable)
+	assert.Nil(t, transaction)
+}
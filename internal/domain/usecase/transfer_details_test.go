@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func orderDetails() entities.TransferDetails {
+	reference := "order-1234"
+	return entities.TransferDetails{
+		Description:       "Order 1234",
+		ExternalReference: &reference,
+		Metadata:          map[string]string{"channel": "web"},
+	}
+}
+
+func TestTransaction_Execute_StoresAndNotifiesDetails(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	ledgerRepo := new(MockLedgerRepository)
+	outboxRepo := new(MockOutboxRepository)
+	details := orderDetails()
+	amount := entities.MoneyFromCents(5000)
+
+	transactionRepo.ExpectedCalls = nil
+	transactionRepo.On("Create", ctx, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.Details.Description == "Order 1234" && *transaction.Details.ExternalReference == "order-1234"
+	})).Return(int64(99), nil).Once()
+	expectAuthorization(transactionRepo, authService, ctx, 99, approved())
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusCompleted, "transfer settled").Return(nil).Once()
+	walletRepo.On("Debit", ctx, int64(10), amount, int64(0)).Return(nil)
+	walletRepo.On("Credit", ctx, int64(20), amount, int64(0)).Return(nil)
+	ledgerRepo.On("CreateEntries", ctx, entities.NewTransferPosting(99, 10, 20, amount)).Return(nil)
+	outboxRepo.On("Create", ctx, mock.MatchedBy(func(message *entities.OutboxMessage) bool {
+		var payload entities.TransferNotificationPayload
+		if err := json.Unmarshal(message.Payload, &payload); err != nil {
+			return false
+		}
+		return payload.ReceiverID == 2 && assert.ObjectsAreEqual(details, payload.Details)
+	})).Return(nil).Once()
+
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Ledger: ledgerRepo, Outbox: outboxRepo, Locker: &fakeWalletLocker{}}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, nil, nil, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: 1, PayeeID: 2, Amount: amount, Details: details})
+	assert.NoError(t, err)
+	assert.Equal(t, details, transaction.Details)
+	transactionRepo.AssertExpectations(t)
+	outboxRepo.AssertExpectations(t)
+}
+
+func TestTransaction_Execute_DuplicateExternalReference(t *testing.T) {
+	ctx := context.Background()
+	userRepo, walletRepo, transactionRepo, authService := newAuthorizationFixture(ctx)
+	transactionRepo.ExpectedCalls = nil
+	transactionRepo.On("Create", ctx, mock.Anything).Return(int64(0), port.ErrExternalReferenceExists).Once()
+
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, authService, nil, nil, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000), Details: orderDetails()})
+	assert.Nil(t, transaction)
+	assert.ErrorIs(t, err, ErrDuplicateExternalReference)
+	authService.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Execute_InvalidDetails(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	tx := NewTransaction(nil, nil, transactionRepo, nil, nil, nil, nil, nil, 0)
+
+	details := entities.TransferDetails{Description: strings.Repeat("a", entities.MaxDescriptionLength+1)}
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(5000), Details: details})
+	assert.Nil(t, transaction)
+	assert.ErrorIs(t, err, entities.ErrInvalidTransferDetails)
+	transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+const (
+	DefaultTransferPageSize = 20
+	MaxTransferPageSize     = 100
+)
+
+var (
+	ErrInvalidTransferFilter = errors.New("invalid transfer filter")
+	ErrInvalidCursor         = errors.New("invalid pagination cursor")
+)
+
+type TransferPage struct {
+	Transfers  []entities.Transaction
+	NextCursor string
+}
+
+// ListTransfers returns one page of the user's statement. The requester may
+// only list their own transfers.
+func (t *Transaction) ListTransfers(ctx context.Context, requesterID int64, filter port.TransactionFilter, cursor string) (*TransferPage, error) {
+	if requesterID != filter.UserID {
+		return nil, ErrTransferAccessDenied
+	}
+	if err := normalizeTransferFilter(&filter); err != nil {
+		return nil, err
+	}
+	if cursor != "" {
+		after, err := DecodeTransferCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	pageSize := filter.Limit
+	filter.Limit = pageSize + 1
+	transfers, err := t.transactionRepo.ListByUser(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &TransferPage{Transfers: transfers}
+	if len(transfers) > pageSize {
+		page.Transfers = transfers[:pageSize]
+		last := page.Transfers[pageSize-1]
+		page.NextCursor = EncodeTransferCursor(port.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+	return page, nil
+}
+
+func normalizeTransferFilter(filter *port.TransactionFilter) error {
+	switch filter.Direction {
+	case port.TransferDirectionAll, port.TransferDirectionSent, port.TransferDirectionReceived:
+	default:
+		return fmt.Errorf("%w: unknown direction %q", ErrInvalidTransferFilter, filter.Direction)
+	}
+	switch filter.Order {
+	case "":
+		filter.Order = port.SortDescending
+	case port.SortDescending, port.SortAscending:
+	default:
+		return fmt.Errorf("%w: unknown sort order %q", ErrInvalidTransferFilter, filter.Order)
+	}
+	switch {
+	case filter.Limit == 0:
+		filter.Limit = DefaultTransferPageSize
+	case filter.Limit < 0 || filter.Limit > MaxTransferPageSize:
+		return fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidTransferFilter, MaxTransferPageSize)
+	}
+	if filter.MinAmount != nil && filter.MaxAmount != nil {
+		inverted, err := filter.MaxAmount.LessThan(*filter.MinAmount)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTransferFilter, err)
+		}
+		if inverted {
+			return fmt.Errorf("%w: min amount is greater than max amount", ErrInvalidTransferFilter)
+		}
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return fmt.Errorf("%w: from must be before to", ErrInvalidTransferFilter)
+	}
+	return nil
+}
+
+func EncodeTransferCursor(cursor port.TransactionCursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + ":" + strconv.FormatInt(cursor.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeTransferCursor(value string) (*port.TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	transactionID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &port.TransactionCursor{CreatedAt: time.Unix(0, createdAt).UTC(), ID: transactionID}, nil
+}
@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransaction_ListTransfers_ReturnsNextCursor(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	stored := []entities.Transaction{
+		{ID: 3, SenderID: 1, ReceiverID: 2, CreatedAt: createdAt.Add(2 * time.Minute)},
+		{ID: 2, SenderID: 2, ReceiverID: 1, CreatedAt: createdAt.Add(time.Minute)},
+		{ID: 1, SenderID: 1, ReceiverID: 2, CreatedAt: createdAt},
+	}
+	transactionRepo.On("ListByUser", ctx, port.TransactionFilter{UserID: 1, Order: port.SortDescending, Limit: 3}).Return(stored, nil)
+
+	tx := NewTransaction(nil, nil, transactionRepo, nil, nil, nil, nil, nil, 0)
+
+	page, err := tx.ListTransfers(ctx, 1, port.TransactionFilter{UserID: 1, Limit: 2}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, stored[:2], page.Transfers)
+
+	cursor, err := DecodeTransferCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cursor.ID)
+	assert.True(t, stored[1].CreatedAt.Equal(cursor.CreatedAt))
+	transactionRepo.AssertExpectations(t)
+}
+
+func TestTransaction_ListTransfers_LastPage(t *testing.T) {
+	ctx := context.Background()
+	transactionRepo := new(mockTransactionRepo)
+	after := port.TransactionCursor{CreatedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), ID: 2}
+	stored := []entities.Transaction{{ID: 1, SenderID: 1, ReceiverID: 2}}
+	transactionRepo.On("ListByUser", ctx, mock.MatchedBy(func(filter port.TransactionFilter) bool {
+		return filter.After != nil && *filter.After == after && filter.Limit == DefaultTransferPageSize+1
+	})).Return(stored, nil)
+
+	tx := NewTransaction(nil, nil, transactionRepo, nil, nil, nil, nil, nil, 0)
+
+	page, err := tx.ListTransfers(ctx, 1, port.TransactionFilter{UserID: 1}, EncodeTransferCursor(after))
+	assert.NoError(t, err)
+	assert.Equal(t, stored, page.Transfers)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestTransaction_ListTransfers_AccessDenied(t *testing.T) {
+	tx := NewTransaction(nil, nil, new(mockTransactionRepo), nil, nil, nil, nil, nil, 0)
+
+	page, err := tx.ListTransfers(context.Background(), 2, port.TransactionFilter{UserID: 1}, "")
+	assert.ErrorIs(t, err, ErrTransferAccessDenied)
+	assert.Nil(t, page)
+}
+
+func TestTransaction_ListTransfers_InvalidFilter(t *testing.T) {
+	tx := NewTransaction(nil, nil, new(mockTransactionRepo), nil, nil, nil, nil, nil, 0)
+	minAmount := entities.MoneyFromCents(500)
+	maxAmount := entities.MoneyFromCents(100)
+	from := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+
+	filters := []port.TransactionFilter{
+		{UserID: 1, Direction: "sideways"},
+		{UserID: 1, Order: "random"},
+		{UserID: 1, Limit: MaxTransferPageSize + 1},
+		{UserID: 1, MinAmount: &minAmount, MaxAmount: &maxAmount},
+		{UserID: 1, From: &from, To: &to},
+	}
+	for _, filter := range filters {
+		_, err := tx.ListTransfers(context.Background(), 1, filter, "")
+		assert.ErrorIs(t, err, ErrInvalidTransferFilter)
+	}
+}
+
+func TestTransaction_ListTransfers_InvalidCursor(t *testing.T) {
+	tx := NewTransaction(nil, nil, new(mockTransactionRepo), nil, nil, nil, nil, nil, 0)
+
+	_, err := tx.ListTransfers(context.Background(), 1, port.TransactionFilter{UserID: 1}, "not-a-cursor")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -0,0 +1,303 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+var (
+	ErrTransferNotHeld      = errors.New("transfer is not waiting to be captured")
+	ErrHoldExpired          = errors.New("transfer hold expired")
+	ErrInvalidCaptureAmount = errors.New("capture amount must be greater than zero")
+	ErrCaptureExceedsAmount = errors.New("capture exceeds the authorized amount")
+)
+
+// hold reserves what an approved two-phase transfer will cost its payer,
+// fees included, and leaves the transfer AUTHORIZED. Limits were checked for
+// the authorized amount but are only consumed by Capture, for what is
+// captured, so voided, expired and cancelled holds use up nothing.
+func (t *Transaction) hold(ctx context.Context, transaction *entities.Transaction) error {
+	// Work on a copy so a rolled back attempt leaves transaction untouched.
+	held := *transaction
+	unlock := func() {}
+	err := t.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		senderWallet, _, release, err := t.lockWallets(ctx, repos, held.SenderID, held.ReceiverID)
+		if err != nil {
+			return err
+		}
+		unlock = release
+		if err := t.spendQuote(ctx, repos, &held); err != nil {
+			return err
+		}
+
+		total, err := totalDebit(held.Amount, held.FeeBreakdown)
+		if err != nil {
+			return err
+		}
+		short, err := senderWallet.AvailableBalance().LessThan(total)
+		if err != nil {
+			return err
+		}
+		if short {
+			return ErrInsufficientBalance
+		}
+		if err := repos.Wallets.Hold(ctx, senderWallet.ID, total, senderWallet.Version); err != nil {
+			return err
+		}
+		if err := repos.Transactions.UpdateHold(ctx, held.ID, total); err != nil {
+			return err
+		}
+		held.HeldAmount = total
+		return transitionTransaction(ctx, repos.Transactions, &held, entities.TransactionStatusAuthorized, "funds held")
+	})
+	// Released only once the unit of work has committed or rolled back.
+	unlock()
+	if err != nil {
+		return err
+	}
+	*transaction = held
+	return nil
+}
+
+// Capture settles an AUTHORIZED transfer for amount, or for everything
+// authorized if amount is nil, and releases the rest of the hold. Fees are
+// priced again on the captured amount, which is what counts towards the
+// payer's limits, and a converted transfer keeps its original rate. Only the
+// payee may capture.
+func (t *Transaction) Capture(ctx context.Context, transactionID, requesterID int64, amount *entities.Money) (*entities.Transaction, error) {
+	if amount != nil && !amount.IsPositive() {
+		return nil, ErrInvalidCaptureAmount
+	}
+
+	var captured *entities.Transaction
+	err := retryOnWalletConflict(func() error {
+		var err error
+		captured, err = t.capture(ctx, transactionID, requesterID, amount)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return captured, nil
+}
+
+func (t *Transaction) capture(ctx context.Context, transactionID, requesterID int64, amount *entities.Money) (*entities.Transaction, error) {
+	var captured *entities.Transaction
+	unlock := func() {}
+	err := t.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		held, err := t.heldTransfer(ctx, repos, transactionID, &requesterID)
+		if err != nil {
+			return err
+		}
+		senderWallet, receiverWallet, release, err := t.lockWallets(ctx, repos, held.SenderID, held.ReceiverID)
+		if err != nil {
+			return err
+		}
+		unlock = release
+
+		// Re-read under the wallet locks so a concurrent capture or void wins.
+		held, err = t.heldTransfer(ctx, repos, transactionID, &requesterID)
+		if err != nil {
+			return err
+		}
+		if held.IsHoldExpired(t.now()) {
+			return ErrHoldExpired
+		}
+		settled, err := t.priceCapture(held, amount, senderWallet, receiverWallet)
+		if err != nil {
+			return err
+		}
+
+		if err := releaseHold(ctx, repos, senderWallet, held.HeldAmount); err != nil {
+			return err
+		}
+		settled.HeldAmount = entities.NewMoney(0, held.Currency)
+		releaseSystem, err := t.settle(ctx, repos, settled, senderWallet, receiverWallet)
+		unlock = func() {
+			releaseSystem()
+			release()
+		}
+		if err != nil {
+			return err
+		}
+		if t.limits != nil {
+			if err := t.limits.Consume(ctx, repos.Counters, settled.SenderID, senderWallet.Type, settled.Amount); err != nil {
+				return err
+			}
+		}
+
+		if err := repos.Transactions.UpdateCapture(ctx, settled); err != nil {
+			return err
+		}
+		if err := transitionTransaction(ctx, repos.Transactions, settled, entities.TransactionStatusCompleted, "transfer captured"); err != nil {
+			return err
+		}
+		if err := enqueuePayeeNotifications(ctx, repos, settled); err != nil {
+			return err
+		}
+		captured = settled
+		return nil
+	})
+	// Released only once the unit of work has committed or rolled back.
+	unlock()
+	if err != nil {
+		return nil, err
+	}
+	return captured, nil
+}
+
+// priceCapture returns a copy of held for the amount being captured, in the
+// payer's currency, with its fees and converted amount worked out again.
+func (t *Transaction) priceCapture(held *entities.Transaction, amount *entities.Money, senderWallet, receiverWallet *entities.Wallet) (*entities.Transaction, error) {
+	settled := *held
+	if amount == nil {
+		return &settled, nil
+	}
+	value := entities.NewMoney(amount.Cents, held.Currency)
+	exceeds, err := held.Amount.LessThan(value)
+	if err != nil {
+		return nil, err
+	}
+	if exceeds {
+		return nil, ErrCaptureExceedsAmount
+	}
+
+	fees, err := t.fees.Quote(senderWallet.Type, receiverWallet.Type, value)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := fees.Total(value.Currency)
+	if err != nil {
+		return nil, err
+	}
+	settled.Amount = value
+	settled.Fee = fee
+	settled.FeeBreakdown = fees
+	if held.IsConverted() {
+		received, err := held.ExchangeRate.Convert(value, held.ReceivedAmount.Currency)
+		if err != nil {
+			return nil, err
+		}
+		settled.ReceivedAmount = &received
+	}
+	return &settled, nil
+}
+
+// Void releases the hold of an AUTHORIZED transfer without moving any money.
+// Only the payee may void.
+func (t *Transaction) Void(ctx context.Context, transactionID, requesterID int64) (*entities.Transaction, error) {
+	var voided *entities.Transaction
+	err := retryOnWalletConflict(func() error {
+		var err error
+		voided, err = t.endHold(ctx, transactionID, &requesterID, entities.TransactionStatusVoided, "hold voided")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return voided, nil
+}
+
+// ExpireHolds releases up to limit holds that were neither captured nor
+// voided in time and returns how many it expired.
+func (t *Transaction) ExpireHolds(ctx context.Context, limit int) (int, error) {
+	transactions, err := t.transactionRepo.ListExpiredHolds(ctx, t.now(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, transaction := range transactions {
+		if ctx.Err() != nil {
+			return expired, ctx.Err()
+		}
+		err := retryOnWalletConflict(func() error {
+			_, err := t.endHold(ctx, transaction.ID, nil, entities.TransactionStatusExpired, "hold expired")
+			return err
+		})
+		if errors.Is(err, ErrTransferNotHeld) {
+			// Captured or voided since it was listed.
+			continue
+		}
+		if err != nil {
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// endHold gives the held funds of a transfer back to its payer and moves it
+// to status. requesterID is nil when the platform ends the hold itself.
+func (t *Transaction) endHold(ctx context.Context, transactionID int64, requesterID *int64, status entities.TransactionStatus, reason string) (*entities.Transaction, error) {
+	var ended *entities.Transaction
+	unlock := func() {}
+	err := t.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		held, err := t.heldTransfer(ctx, repos, transactionID, requesterID)
+		if err != nil {
+			return err
+		}
+		senderWallet, _, release, err := t.lockWallets(ctx, repos, held.SenderID, held.ReceiverID)
+		if err != nil {
+			return err
+		}
+		unlock = release
+
+		held, err = t.heldTransfer(ctx, repos, transactionID, requesterID)
+		if err != nil {
+			return err
+		}
+		if err := releaseHold(ctx, repos, senderWallet, held.HeldAmount); err != nil {
+			return err
+		}
+		held.HeldAmount = entities.NewMoney(0, held.Currency)
+		if err := repos.Transactions.UpdateHold(ctx, held.ID, held.HeldAmount); err != nil {
+			return err
+		}
+		if err := transitionTransaction(ctx, repos.Transactions, held, status, reason); err != nil {
+			return err
+		}
+		ended = held
+		return nil
+	})
+	// Released only once the unit of work has committed or rolled back.
+	unlock()
+	if err != nil {
+		return nil, err
+	}
+	return ended, nil
+}
+
+func (t *Transaction) heldTransfer(ctx context.Context, repos port.Repositories, transactionID int64, requesterID *int64) (*entities.Transaction, error) {
+	held, err := repos.Transactions.GetByID(ctx, transactionID)
+	if errors.Is(err, port.ErrTransactionNotFound) {
+		return nil, ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if requesterID != nil && held.ReceiverID != *requesterID {
+		return nil, ErrTransferAccessDenied
+	}
+	if !held.IsHeld() {
+		return nil, ErrTransferNotHeld
+	}
+	return held, nil
+}
+
+// releaseHold gives amount held on wallet back to its available balance and
+// keeps wallet in step, so it can be posted to in the same unit of work.
+func releaseHold(ctx context.Context, repos port.Repositories, wallet *entities.Wallet, amount entities.Money) error {
+	if !amount.IsPositive() {
+		return nil
+	}
+	if err := repos.Wallets.ReleaseHold(ctx, wallet.ID, amount, wallet.Version); err != nil {
+		return err
+	}
+	wallet.HeldBalance = entities.NewMoney(wallet.HeldBalance.Cents-amount.Cents, wallet.CurrencyCode())
+	wallet.Version++
+	return nil
+}
@@ -0,0 +1,263 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var holdNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type holdFixture struct {
+	*transferFixture
+	held *entities.Transaction
+}
+
+// newHoldFixture has transfer 99 hold 50.00 of the payer for an hour.
+func newHoldFixture() *holdFixture {
+	authorized := entities.MoneyFromCents(5000)
+	expiresAt := holdNow.Add(time.Hour)
+	f := &holdFixture{
+		transferFixture: newTransferFixture(context.Background()),
+		held: &entities.Transaction{
+			ID: 99, SenderID: 1, ReceiverID: 2,
+			Amount:           authorized,
+			Currency:         entities.DefaultCurrency,
+			AuthorizedAmount: &authorized,
+			HeldAmount:       authorized,
+			HoldExpiresAt:    &expiresAt,
+			Type:             entities.TransactionTypeTransfer,
+			Status:           entities.TransactionStatusAuthorized,
+		},
+	}
+	f.payerWallet.Balance, f.payerWallet.HeldBalance, f.payerWallet.Version = entities.MoneyFromCents(8000), authorized, 3
+	f.payeeWallet.Version = 1
+	f.transactionRepo.On("GetByID", f.ctx, f.held.ID).Return(f.held, nil)
+	f.tx.holdFor = time.Hour
+	f.tx.now = func() time.Time { return holdNow }
+	return f
+}
+
+// withLimits turns on transfer limits, without ceilings, so tests can see
+// what the payer's counters are charged.
+func (f *holdFixture) withLimits() {
+	limitRepo := new(mockTransferLimitRepo)
+	limitRepo.On("GetByUserID", f.ctx, int64(1)).Return(nil, port.ErrTransferLimitNotFound)
+	f.tx.limits = newTestLimits(limitRepo, f.counterRepo, f.walletRepo, entities.TransferLimits{})
+}
+
+func TestTransaction_Execute_HoldReservesFunds(t *testing.T) {
+	ctx := context.Background()
+	senderID := int64(1)
+	receiverID := int64(2)
+	amount := entities.MoneyFromCents(5000)
+
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+	authService := new(mockAuthService)
+
+	userRepo.On("GetByID", ctx, senderID).Return(&entities.User{ID: senderID}, nil)
+	userRepo.On("GetByID", ctx, receiverID).Return(&entities.User{ID: receiverID}, nil)
+	senderWallet := &entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(8000), Version: 2}
+	receiverWallet := &entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.MerchantWallet}
+	walletRepo.On("GetByOwnerID", ctx, senderID).Return(senderWallet, nil)
+	walletRepo.On("GetByOwnerID", ctx, receiverID).Return(receiverWallet, nil)
+	walletRepo.On("GetByID", ctx, senderWallet.ID).Return(senderWallet, nil)
+	walletRepo.On("GetByID", ctx, receiverWallet.ID).Return(receiverWallet, nil)
+	walletRepo.On("Hold", ctx, senderWallet.ID, amount, int64(2)).Return(nil).Once()
+
+	transactionRepo.On("Create", ctx, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.AuthorizedAmount != nil && *transaction.AuthorizedAmount == amount &&
+			transaction.HoldExpiresAt != nil && transaction.HoldExpiresAt.Equal(holdNow.Add(time.Hour))
+	})).Return(int64(99), nil)
+	expectAuthorization(transactionRepo, authService, ctx, 99, approved())
+	transactionRepo.On("UpdateHold", ctx, int64(99), amount).Return(nil).Once()
+	transactionRepo.On("UpdateStatus", ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusAuthorized, "funds held").Return(nil).Once()
+
+	limitRepo := new(mockTransferLimitRepo)
+	counterRepo := new(mockTransferCounterRepo)
+	limitRepo.On("GetByUserID", ctx, senderID).Return(nil, port.ErrTransferLimitNotFound)
+
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{Wallets: walletRepo, Transactions: transactionRepo, Counters: counterRepo, Locker: &fakeWalletLocker{}}}
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, unitOfWork, authService, newTestLimits(limitRepo, counterRepo, walletRepo, entities.TransferLimits{}), nil, nil, time.Hour)
+	tx.now = func() time.Time { return holdNow }
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: senderID, PayeeID: receiverID, Amount: amount, Hold: true})
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusAuthorized, transaction.Status)
+	assert.Equal(t, amount, transaction.HeldAmount)
+	walletRepo.AssertExpectations(t)
+	walletRepo.AssertNotCalled(t, "Debit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	transactionRepo.AssertExpectations(t)
+	// Limits are only charged once the hold is captured.
+	counterRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Execute_HoldRespectsHeldBalance(t *testing.T) {
+	ctx := context.Background()
+	senderID := int64(1)
+	receiverID := int64(2)
+
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+
+	userRepo.On("GetByID", ctx, senderID).Return(&entities.User{ID: senderID}, nil)
+	userRepo.On("GetByID", ctx, receiverID).Return(&entities.User{ID: receiverID}, nil)
+	walletRepo.On("GetByOwnerID", ctx, senderID).Return(&entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(8000), HeldBalance: entities.MoneyFromCents(4000)}, nil)
+	walletRepo.On("GetByOwnerID", ctx, receiverID).Return(&entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.MerchantWallet}, nil)
+
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, new(mockAuthService), nil, nil, nil, time.Hour)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: senderID, PayeeID: receiverID, Amount: entities.MoneyFromCents(5000), Hold: true})
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	assert.Nil(t, transaction)
+	transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Capture_Partial(t *testing.T) {
+	f := newHoldFixture()
+	f.withLimits()
+	captured := entities.MoneyFromCents(3000)
+
+	for _, period := range entities.LimitPeriods {
+		start, _ := period.Bounds(limitsNow, time.UTC)
+		f.counterRepo.On("Add", f.ctx, int64(1), period, start, captured).Return(nil).Once()
+	}
+
+	f.walletRepo.On("ReleaseHold", f.ctx, f.payerWallet.ID, entities.MoneyFromCents(5000), int64(3)).Return(nil).Once()
+	f.walletRepo.On("Debit", f.ctx, f.payerWallet.ID, captured, int64(4)).Return(nil).Once()
+	f.walletRepo.On("Credit", f.ctx, f.payeeWallet.ID, captured, int64(1)).Return(nil).Once()
+	f.ledgerRepo.On("CreateEntries", f.ctx, entities.NewTransferPosting(99, f.payerWallet.ID, f.payeeWallet.ID, captured)).Return(nil).Once()
+	f.transactionRepo.On("UpdateCapture", f.ctx, mock.MatchedBy(func(transaction *entities.Transaction) bool {
+		return transaction.ID == 99 && transaction.Amount == captured && transaction.HeldAmount.IsZero()
+	})).Return(nil).Once()
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusAuthorized, entities.TransactionStatusCompleted, "transfer captured").Return(nil).Once()
+	f.outboxRepo.On("Create", f.ctx, transferNotification(2, 99, captured)).Return(nil).Once()
+
+	transaction, err := f.tx.Capture(f.ctx, 99, 2, &captured)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusCompleted, transaction.Status)
+	assert.Equal(t, captured, transaction.Amount)
+	assert.Equal(t, entities.MoneyFromCents(5000), *transaction.AuthorizedAmount)
+	f.walletRepo.AssertExpectations(t)
+	f.transactionRepo.AssertExpectations(t)
+	f.ledgerRepo.AssertExpectations(t)
+	f.outboxRepo.AssertExpectations(t)
+	f.counterRepo.AssertExpectations(t)
+}
+
+func TestTransaction_Capture_RepricesFees(t *testing.T) {
+	f := newHoldFixture()
+	fees := entities.NewFeeTable()
+	assert.NoError(t, fees.Set(entities.CommonWallet, entities.MerchantWallet, entities.FeeSchedule{Type: entities.FeePercentage, Rate: 100}))
+	f.tx.fees = fees
+	captured := entities.MoneyFromCents(2000)
+	fee := entities.MoneyFromCents(20)
+	revenueWallet := &entities.Wallet{ID: 30, Type: entities.SystemWallet}
+
+	f.walletRepo.On("GetSystemWallet", f.ctx, entities.SystemAccountRevenue, entities.DefaultCurrency).Return(revenueWallet, nil)
+	f.walletRepo.On("GetByID", f.ctx, revenueWallet.ID).Return(revenueWallet, nil)
+	f.walletRepo.On("ReleaseHold", f.ctx, f.payerWallet.ID, entities.MoneyFromCents(5000), int64(3)).Return(nil).Once()
+	f.walletRepo.On("Debit", f.ctx, f.payerWallet.ID, entities.MoneyFromCents(2020), int64(4)).Return(nil).Once()
+	f.walletRepo.On("Credit", f.ctx, f.payeeWallet.ID, captured, int64(1)).Return(nil).Once()
+	f.walletRepo.On("Credit", f.ctx, revenueWallet.ID, fee, int64(0)).Return(nil).Once()
+	f.ledgerRepo.On("CreateEntries", f.ctx, mock.Anything).Return(nil).Once()
+	f.transactionRepo.On("UpdateCapture", f.ctx, mock.Anything).Return(nil).Once()
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusAuthorized, entities.TransactionStatusCompleted, "transfer captured").Return(nil).Once()
+	f.outboxRepo.On("Create", f.ctx, transferNotification(2, 99, captured)).Return(nil).Once()
+
+	transaction, err := f.tx.Capture(f.ctx, 99, 2, &captured)
+	assert.NoError(t, err)
+	assert.Equal(t, fee, transaction.Fee)
+	f.walletRepo.AssertExpectations(t)
+}
+
+func TestTransaction_Capture_Rejected(t *testing.T) {
+	tooMuch := entities.MoneyFromCents(5001)
+	zero := entities.MoneyFromCents(0)
+
+	tests := []struct {
+		name        string
+		prepare     func(f *holdFixture)
+		requesterID int64
+		amount      *entities.Money
+		want        error
+	}{
+		{name: "payer", requesterID: 1, want: ErrTransferAccessDenied},
+		{name: "exceeds authorized amount", requesterID: 2, amount: &tooMuch, want: ErrCaptureExceedsAmount},
+		{name: "zero amount", requesterID: 2, amount: &zero, want: ErrInvalidCaptureAmount},
+		{name: "expired", requesterID: 2, want: ErrHoldExpired, prepare: func(f *holdFixture) {
+			f.tx.now = func() time.Time { return holdNow.Add(time.Hour) }
+		}},
+		{name: "already captured", requesterID: 2, want: ErrTransferNotHeld, prepare: func(f *holdFixture) {
+			f.held.Status = entities.TransactionStatusCompleted
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newHoldFixture()
+			if tt.prepare != nil {
+				tt.prepare(f)
+			}
+
+			transaction, err := f.tx.Capture(f.ctx, 99, tt.requesterID, tt.amount)
+			assert.ErrorIs(t, err, tt.want)
+			assert.Nil(t, transaction)
+			f.walletRepo.AssertNotCalled(t, "ReleaseHold", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+			f.walletRepo.AssertNotCalled(t, "Debit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTransaction_Void(t *testing.T) {
+	f := newHoldFixture()
+	f.withLimits()
+
+	f.walletRepo.On("ReleaseHold", f.ctx, f.payerWallet.ID, entities.MoneyFromCents(5000), int64(3)).Return(nil).Once()
+	f.transactionRepo.On("UpdateHold", f.ctx, int64(99), entities.NewMoney(0, entities.DefaultCurrency)).Return(nil).Once()
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusAuthorized, entities.TransactionStatusVoided, "hold voided").Return(nil).Once()
+
+	transaction, err := f.tx.Void(f.ctx, 99, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusVoided, transaction.Status)
+	f.walletRepo.AssertExpectations(t)
+	f.walletRepo.AssertNotCalled(t, "Debit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	f.transactionRepo.AssertExpectations(t)
+	f.ledgerRepo.AssertNotCalled(t, "CreateEntries", mock.Anything, mock.Anything)
+	f.counterRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Void_OnlyPayee(t *testing.T) {
+	f := newHoldFixture()
+
+	transaction, err := f.tx.Void(f.ctx, 99, 1)
+	assert.ErrorIs(t, err, ErrTransferAccessDenied)
+	assert.Nil(t, transaction)
+}
+
+func TestTransaction_ExpireHolds(t *testing.T) {
+	f := newHoldFixture()
+	f.withLimits()
+	captured := &entities.Transaction{ID: 98, SenderID: 1, ReceiverID: 2, Type: entities.TransactionTypeTransfer, Status: entities.TransactionStatusCompleted}
+
+	f.transactionRepo.On("ListExpiredHolds", f.ctx, holdNow, 10).Return([]entities.Transaction{*captured, *f.held}, nil)
+	f.transactionRepo.On("GetByID", f.ctx, int64(98)).Return(captured, nil)
+	f.walletRepo.On("ReleaseHold", f.ctx, f.payerWallet.ID, entities.MoneyFromCents(5000), int64(3)).Return(nil).Once()
+	f.transactionRepo.On("UpdateHold", f.ctx, int64(99), entities.NewMoney(0, entities.DefaultCurrency)).Return(nil).Once()
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusAuthorized, entities.TransactionStatusExpired, "hold expired").Return(nil).Once()
+
+	expired, err := f.tx.ExpireHolds(f.ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	f.walletRepo.AssertExpectations(t)
+	f.transactionRepo.AssertExpectations(t)
+	f.counterRepo.AssertNotCalled(t, "Add", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
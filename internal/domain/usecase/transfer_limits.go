@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+var (
+	ErrLimitExceeded     = errors.New("transfer limit exceeded")
+	ErrInvalidLimit      = errors.New("limits must be greater than zero")
+	ErrLimitUserNotFound = errors.New("user wallet not found")
+)
+
+type LimitKind string
+
+const (
+	LimitSingleTransfer LimitKind = "single_transfer"
+	LimitDailyAmount    LimitKind = "daily_amount"
+	LimitMonthlyAmount  LimitKind = "monthly_amount"
+	LimitHourlyCount    LimitKind = "hourly_count"
+)
+
+// LimitExceededError tells which limit a transfer would exceed, what is left
+// of it and, for limits counted per period, when it resets.
+type LimitExceededError struct {
+	Limit           LimitKind
+	RemainingAmount *entities.Money
+	RemainingCount  *int
+	ResetsAt        *time.Time
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrLimitExceeded, e.Limit)
+}
+
+func (e *LimitExceededError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// UserLimits describes the limits applied to a user.
+type UserLimits struct {
+	UserID     int64
+	WalletType entities.WalletType
+	// Override is nil when the user has the limits of its wallet type.
+	Override  *entities.UserTransferLimit
+	Effective entities.TransferLimits
+}
+
+// Limits enforces what a user may send per transfer, per day, per month and
+// how many transfers per hour. Each wallet type has default limits that can
+// be overridden per user. Limits are set in the default currency and
+// converted at the current rate for payers of other currencies.
+type Limits struct {
+	limitRepo   port.TransferLimitRepository
+	counterRepo port.TransferCounterRepository
+	walletRepo  port.WalletRepository
+	defaults    map[entities.WalletType]entities.TransferLimits
+	location    *time.Location
+	now         func() time.Time
+	// rates is nil when no exchange rates are configured, and then only
+	// payers of the default currency can be checked.
+	rates port.ExchangeRateProvider
+}
+
+func NewLimits(
+	limitRepo port.TransferLimitRepository,
+	counterRepo port.TransferCounterRepository,
+	walletRepo port.WalletRepository,
+	defaults map[entities.WalletType]entities.TransferLimits,
+	location *time.Location,
+	rates port.ExchangeRateProvider,
+) *Limits {
+	return &Limits{
+		limitRepo:   limitRepo,
+		counterRepo: counterRepo,
+		walletRepo:  walletRepo,
+		defaults:    defaults,
+		location:    location,
+		now:         time.Now,
+		rates:       rates,
+	}
+}
+
+// Check returns a *LimitExceededError if sending amount would exceed a limit
+// of userID.
+func (l *Limits) Check(ctx context.Context, userID int64, walletType entities.WalletType, amount entities.Money) error {
+	return l.check(ctx, l.counterRepo, userID, walletType, amount)
+}
+
+// Consume checks the limits again against counters, which must belong to the
+// unit of work settling the transfer, and counts the transfer in them.
+func (l *Limits) Consume(ctx context.Context, counters port.TransferCounterRepository, userID int64, walletType entities.WalletType, amount entities.Money) error {
+	if err := l.check(ctx, counters, userID, walletType, amount); err != nil {
+		return err
+	}
+	now := l.now()
+	for _, period := range entities.LimitPeriods {
+		start, _ := period.Bounds(now, l.location)
+		if err := counters.Add(ctx, userID, period, start, amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Limits) GetUserLimits(ctx context.Context, userID int64) (*UserLimits, error) {
+	wallet, err := l.walletRepo.GetByOwnerID(ctx, userID)
+	if err != nil || wallet == nil {
+		return nil, ErrLimitUserNotFound
+	}
+
+	override, err := l.limitRepo.GetByUserID(ctx, userID)
+	if errors.Is(err, port.ErrTransferLimitNotFound) {
+		override = nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	limits := &UserLimits{
+		UserID:     userID,
+		WalletType: wallet.Type,
+		Override:   override,
+		Effective:  l.defaults[wallet.Type],
+	}
+	if override != nil {
+		limits.Effective = limits.Effective.Merge(override.TransferLimits)
+	}
+	return limits, nil
+}
+
+// SetUserLimits replaces the override of userID. Limits left nil fall back to
+// those of the user's wallet type.
+func (l *Limits) SetUserLimits(ctx context.Context, userID int64, limits entities.TransferLimits) (*UserLimits, error) {
+	if err := validateLimits(limits); err != nil {
+		return nil, err
+	}
+	if _, err := l.GetUserLimits(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := l.limitRepo.Save(ctx, &entities.UserTransferLimit{UserID: userID, TransferLimits: limits}); err != nil {
+		return nil, err
+	}
+	return l.GetUserLimits(ctx, userID)
+}
+
+func (l *Limits) ResetUserLimits(ctx context.Context, userID int64) error {
+	return l.limitRepo.Delete(ctx, userID)
+}
+
+func (l *Limits) check(ctx context.Context, counters port.TransferCounterRepository, userID int64, walletType entities.WalletType, amount entities.Money) error {
+	limits, err := l.effective(ctx, userID, walletType)
+	if err != nil {
+		return err
+	}
+
+	if limits.SingleMax != nil {
+		singleMax, err := l.inCurrency(ctx, *limits.SingleMax, amount.Currency)
+		if err != nil {
+			return err
+		}
+		exceeded, err := singleMax.LessThan(amount)
+		if err != nil {
+			return err
+		}
+		if exceeded {
+			return &LimitExceededError{Limit: LimitSingleTransfer, RemainingAmount: &singleMax}
+		}
+	}
+
+	now := l.now()
+	if limits.HourlyCount != nil {
+		start, end := entities.LimitPeriodHour.Bounds(now, l.location)
+		counter, err := counters.Get(ctx, userID, entities.LimitPeriodHour, start)
+		if err != nil {
+			return err
+		}
+		if counter.Count >= *limits.HourlyCount {
+			remaining := 0
+			return &LimitExceededError{Limit: LimitHourlyCount, RemainingCount: &remaining, ResetsAt: &end}
+		}
+	}
+
+	if err := l.checkAmount(ctx, counters, userID, LimitDailyAmount, entities.LimitPeriodDay, limits.DailyAmount, amount, now); err != nil {
+		return err
+	}
+	return l.checkAmount(ctx, counters, userID, LimitMonthlyAmount, entities.LimitPeriodMonth, limits.MonthlyAmount, amount, now)
+}
+
+func (l *Limits) checkAmount(ctx context.Context, counters port.TransferCounterRepository, userID int64, kind LimitKind, period entities.LimitPeriod, limit *entities.Money, amount entities.Money, now time.Time) error {
+	if limit == nil {
+		return nil
+	}
+	start, end := period.Bounds(now, l.location)
+	counter, err := counters.Get(ctx, userID, period, start)
+	if err != nil {
+		return err
+	}
+	// Counters carry no currency of their own: they count the transfers of
+	// the payer's wallet, in its currency.
+	sent := entities.NewMoney(counter.Amount.Cents, amount.Currency)
+	ceiling, err := l.inCurrency(ctx, *limit, amount.Currency)
+	if err != nil {
+		return err
+	}
+	total, err := sent.Add(amount)
+	if err != nil {
+		return err
+	}
+	cmp, err := total.Cmp(ceiling)
+	if err != nil {
+		return err
+	}
+	if cmp <= 0 {
+		return nil
+	}
+
+	remaining, err := ceiling.Sub(sent)
+	if err != nil {
+		return err
+	}
+	if remaining.IsNegative() {
+		// The limit was lowered below what was already sent.
+		remaining = entities.NewMoney(0, amount.Currency)
+	}
+	return &LimitExceededError{Limit: kind, RemainingAmount: &remaining, ResetsAt: &end}
+}
+
+// inCurrency converts limit to currency at the current rate.
+func (l *Limits) inCurrency(ctx context.Context, limit entities.Money, currency string) (entities.Money, error) {
+	if limit.Currency == currency {
+		return limit, nil
+	}
+	if l.rates == nil {
+		return entities.Money{}, fmt.Errorf("%w: %s to %s", ErrConversionUnavailable, limit.Currency, currency)
+	}
+	rate, err := l.rates.Rate(ctx, limit.Currency, currency)
+	if errors.Is(err, port.ErrExchangeRateNotFound) {
+		return entities.Money{}, fmt.Errorf("%w: %s to %s", ErrConversionUnavailable, limit.Currency, currency)
+	}
+	if err != nil {
+		return entities.Money{}, err
+	}
+	return rate.Convert(limit, currency)
+}
+
+func (l *Limits) effective(ctx context.Context, userID int64, walletType entities.WalletType) (entities.TransferLimits, error) {
+	limits := l.defaults[walletType]
+	override, err := l.limitRepo.GetByUserID(ctx, userID)
+	if errors.Is(err, port.ErrTransferLimitNotFound) {
+		return limits, nil
+	}
+	if err != nil {
+		return entities.TransferLimits{}, err
+	}
+	return limits.Merge(override.TransferLimits), nil
+}
+
+func validateLimits(limits entities.TransferLimits) error {
+	for _, amount := range []*entities.Money{limits.SingleMax, limits.DailyAmount, limits.MonthlyAmount} {
+		if amount != nil && !amount.IsPositive() {
+			return ErrInvalidLimit
+		}
+	}
+	if limits.HourlyCount != nil && *limits.HourlyCount <= 0 {
+		return ErrInvalidLimit
+	}
+	return nil
+}
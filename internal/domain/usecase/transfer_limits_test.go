@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTransferLimitRepo struct{ mock.Mock }
+
+func (m *mockTransferLimitRepo) GetByUserID(ctx context.Context, userID int64) (*entities.UserTransferLimit, error) {
+	args := m.Called(ctx, userID)
+	limit, _ := args.Get(0).(*entities.UserTransferLimit)
+	return limit, args.Error(1)
+}
+
+func (m *mockTransferLimitRepo) Save(ctx context.Context, limit *entities.UserTransferLimit) error {
+	args := m.Called(ctx, limit)
+	return args.Error(0)
+}
+
+func (m *mockTransferLimitRepo) Delete(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+type mockTransferCounterRepo struct{ mock.Mock }
+
+func (m *mockTransferCounterRepo) Get(ctx context.Context, userID int64, period entities.LimitPeriod, start time.Time) (entities.TransferCounter, error) {
+	args := m.Called(ctx, userID, period, start)
+	return args.Get(0).(entities.TransferCounter), args.Error(1)
+}
+
+func (m *mockTransferCounterRepo) Add(ctx context.Context, userID int64, period entities.LimitPeriod, start time.Time, amount entities.Money) error {
+	args := m.Called(ctx, userID, period, start, amount)
+	return args.Error(0)
+}
+
+var limitsNow = time.Date(2024, time.March, 10, 14, 20, 0, 0, time.UTC)
+
+func moneyPtr(cents int64) *entities.Money {
+	amount := entities.MoneyFromCents(cents)
+	return &amount
+}
+
+func newTestLimits(limitRepo port.TransferLimitRepository, counterRepo port.TransferCounterRepository, walletRepo port.WalletRepository, defaults entities.TransferLimits) *Limits {
+	limits := NewLimits(limitRepo, counterRepo, walletRepo, map[entities.WalletType]entities.TransferLimits{
+		entities.CommonWallet: defaults,
+	}, time.UTC, nil)
+	limits.now = func() time.Time { return limitsNow }
+	return limits
+}
+
+func limitCounter(period entities.LimitPeriod, cents int64, count int) entities.TransferCounter {
+	start, _ := period.Bounds(limitsNow, time.UTC)
+	return entities.TransferCounter{UserID: 1, Period: period, PeriodStart: start, Amount: entities.MoneyFromCents(cents), Count: count}
+}
+
+func TestLimits_Check_SingleTransfer(t *testing.T) {
+	ctx := context.Background()
+	limitRepo := new(mockTransferLimitRepo)
+	limitRepo.On("GetByUserID", ctx, int64(1)).Return(nil, port.ErrTransferLimitNotFound)
+
+	limits := newTestLimits(limitRepo, new(mockTransferCounterRepo), nil, entities.TransferLimits{SingleMax: moneyPtr(100000)})
+
+	err := limits.Check(ctx, 1, entities.CommonWallet, entities.MoneyFromCents(100001))
+
+	var exceeded *LimitExceededError
+	assert.True(t, errors.As(err, &exceeded))
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.Equal(t, LimitSingleTransfer, exceeded.Limit)
+	assert.Equal(t, moneyPtr(100000), exceeded.RemainingAmount)
+	assert.Nil(t, exceeded.ResetsAt)
+}
+
+func TestLimits_Check_DailyAmountReportsRemaining(t *testing.T) {
+	ctx := context.Background()
+	limitRepo := new(mockTransferLimitRepo)
+	counterRepo := new(mockTransferCounterRepo)
+	day := limitCounter(entities.LimitPeriodDay, 400000, 3)
+	limitRepo.On("GetByUserID", ctx, int64(1)).Return(nil, port.ErrTransferLimitNotFound)
+	counterRepo.On("Get", ctx, int64(1), entities.LimitPeriodDay, day.PeriodStart).Return(day, nil)
+
+	limits := newTestLimits(limitRepo, counterRepo, nil, entities.TransferLimits{DailyAmount: moneyPtr(500000)})
+
+	assert.NoError(t, limits.Check(ctx, 1, entities.CommonWallet, entities.MoneyFromCents(100000)))
+
+	err := limits.Check(ctx, 1, entities.CommonWallet, entities.MoneyFromCents(100001))
+	var exceeded *LimitExceededError
+	assert.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, LimitDailyAmount, exceeded.Limit)
+	assert.Equal(t, moneyPtr(100000), exceeded.RemainingAmount)
+	assert.Equal(t, time.Date(2024, time.March, 11, 0, 0, 0, 0, time.UTC), *exceeded.ResetsAt)
+}
+
+func TestLimits_Check_HourlyCount(t *testing.T) {
+	ctx := context.Background()
+	limitRepo := new(mockTransferLimitRepo)
+	counterRepo := new(mockTransferCounterRepo)
+	hour := limitCounter(entities.LimitPeriodHour, 1000, 3)
+	hourly := 3
+	limitRepo.On("GetByUserID", ctx, int64(1)).Return(nil, port.ErrTransferLimitNotFound)
+	counterRepo.On("Get", ctx, int64(1), entities.LimitPeriodHour, hour.PeriodStart).Return(hour, nil)
+
+	limits := newTestLimits(limitRepo, counterRepo, nil, entities.TransferLimits{HourlyCount: &hourly})
+
+	err := limits.Check(ctx, 1, entities.CommonWallet, entities.MoneyFromCents(100))
+	var exceeded *LimitExceededError
+	assert.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, LimitHourlyCount, exceeded.Limit)
+	assert.Equal(t, 0, *exceeded.RemainingCount)
+	assert.Equal(t, time.Date(2024, time.March, 10, 15, 0, 0, 0, time.UTC), *exceeded.ResetsAt)
+}
+
+func TestLimits_Check_UserOverrideReplacesDefault(t *testing.T) {
+	ctx := context.Background()
+	limitRepo := new(mockTransferLimitRepo)
+	limitRepo.On("GetByUserID", ctx, int64(1)).Return(&entities.UserTransferLimit{
+		UserID:         1,
+		TransferLimits: entities.TransferLimits{SingleMax: moneyPtr(1000000)},
+	}, nil)
+
+	limits := newTestLimits(limitRepo, new(mockTransferCounterRepo), nil, entities.TransferLimits{SingleMax: moneyPtr(100000)})
+
+	assert.NoError(t, limits.Check(ctx, 1, entities.CommonWallet, entities.MoneyFromCents(500000)))
+}
+
+func TestLimits_Check_ConvertsLimitsToPayerCurrency(t *testing.T) {
+	ctx := context.Background()
+	limitRepo := new(mockTransferLimitRepo)
+	counterRepo := new(mockTransferCounterRepo)
+	day := limitCounter(entities.LimitPeriodDay, 15000, 1)
+	limitRepo.On("GetByUserID", ctx, int64(1)).Return(nil, port.ErrTransferLimitNotFound)
+	counterRepo.On("Get", ctx, int64(1), entities.LimitPeriodDay, day.PeriodStart).Return(day, nil)
+	rates := new(mockExchangeRateProvider)
+	rates.On("Rate", ctx, "BRL", "USD").Return(exchangeRate("0.2"), nil)
+
+	limits := newTestLimits(limitRepo, counterRepo, nil, entities.TransferLimits{SingleMax: moneyPtr(100000), DailyAmount: moneyPtr(100000)})
+	limits.rates = rates
+
+	// 1000.00 BRL is 200.00 USD, of which 150.00 were sent today.
+	err := limits.Check(ctx, 1, entities.CommonWallet, entities.NewMoney(20001, "USD"))
+	var exceeded *LimitExceededError
+	assert.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, LimitSingleTransfer, exceeded.Limit)
+	assert.Equal(t, entities.NewMoney(20000, "USD"), *exceeded.RemainingAmount)
+
+	err = limits.Check(ctx, 1, entities.CommonWallet, entities.NewMoney(5001, "USD"))
+	assert.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, LimitDailyAmount, exceeded.Limit)
+	assert.Equal(t, entities.NewMoney(5000, "USD"), *exceeded.RemainingAmount)
+
+	assert.NoError(t, limits.Check(ctx, 1, entities.CommonWallet, entities.NewMoney(5000, "USD")))
+}
+
+func TestLimits_Check_WithoutRatesRejectsOtherCurrencies(t *testing.T) {
+	ctx := context.Background()
+	limitRepo := new(mockTransferLimitRepo)
+	limitRepo.On("GetByUserID", ctx, int64(1)).Return(nil, port.ErrTransferLimitNotFound)
+
+	limits := newTestLimits(limitRepo, new(mockTransferCounterRepo), nil, entities.TransferLimits{SingleMax: moneyPtr(100000)})
+
+	err := limits.Check(ctx, 1, entities.CommonWallet, entities.NewMoney(100, "USD"))
+	assert.ErrorIs(t, err, ErrConversionUnavailable)
+}
+
+func TestLimits_Consume_CountsEveryPeriod(t *testing.T) {
+	ctx := context.Background()
+	limitRepo := new(mockTransferLimitRepo)
+	counterRepo := new(mockTransferCounterRepo)
+	amount := entities.MoneyFromCents(2500)
+	limitRepo.On("GetByUserID", ctx, int64(1)).Return(nil, port.ErrTransferLimitNotFound)
+	for _, period := range entities.LimitPeriods {
+		start, _ := period.Bounds(limitsNow, time.UTC)
+		counterRepo.On("Add", ctx, int64(1), period, start, amount).Return(nil).Once()
+	}
+
+	limits := newTestLimits(limitRepo, nil, nil, entities.TransferLimits{})
+
+	assert.NoError(t, limits.Consume(ctx, counterRepo, 1, entities.CommonWallet, amount))
+	counterRepo.AssertExpectations(t)
+}
+
+func TestLimits_SetUserLimits(t *testing.T) {
+	ctx := context.Background()
+	limitRepo := new(mockTransferLimitRepo)
+	walletRepo := new(mockWalletRepo)
+	override := entities.TransferLimits{DailyAmount: moneyPtr(2000000)}
+	walletRepo.On("GetByOwnerID", ctx, int64(1)).Return(&entities.Wallet{OwnerID: 1, Type: entities.CommonWallet}, nil)
+	limitRepo.On("GetByUserID", ctx, int64(1)).Return(nil, port.ErrTransferLimitNotFound).Once()
+	limitRepo.On("Save", ctx, &entities.UserTransferLimit{UserID: 1, TransferLimits: override}).Return(nil).Once()
+	limitRepo.On("GetByUserID", ctx, int64(1)).Return(&entities.UserTransferLimit{UserID: 1, TransferLimits: override}, nil).Once()
+
+	limits := newTestLimits(limitRepo, nil, walletRepo, entities.TransferLimits{SingleMax: moneyPtr(100000), DailyAmount: moneyPtr(500000)})
+
+	userLimits, err := limits.SetUserLimits(ctx, 1, override)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.CommonWallet, userLimits.WalletType)
+	assert.Equal(t, override, userLimits.Override.TransferLimits)
+	assert.Equal(t, entities.TransferLimits{SingleMax: moneyPtr(100000), DailyAmount: moneyPtr(2000000)}, userLimits.Effective)
+	limitRepo.AssertExpectations(t)
+}
+
+func TestLimits_SetUserLimits_RejectsNonPositive(t *testing.T) {
+	zero := 0
+	limits := newTestLimits(new(mockTransferLimitRepo), nil, new(mockWalletRepo), entities.TransferLimits{})
+
+	_, err := limits.SetUserLimits(context.Background(), 1, entities.TransferLimits{HourlyCount: &zero})
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+
+	_, err = limits.SetUserLimits(context.Background(), 1, entities.TransferLimits{MonthlyAmount: moneyPtr(0)})
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+}
+
+func TestTransaction_Execute_LimitExceeded(t *testing.T) {
+	ctx := context.Background()
+	senderID := int64(1)
+	receiverID := int64(2)
+
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+	transactionRepo := new(mockTransactionRepo)
+	limitRepo := new(mockTransferLimitRepo)
+
+	userRepo.On("GetByID", ctx, senderID).Return(&entities.User{ID: senderID}, nil)
+	userRepo.On("GetByID", ctx, receiverID).Return(&entities.User{ID: receiverID}, nil)
+	walletRepo.On("GetByOwnerID", ctx, senderID).Return(&entities.Wallet{ID: 10, OwnerID: senderID, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(1000000)}, nil)
+	walletRepo.On("GetByOwnerID", ctx, receiverID).Return(&entities.Wallet{ID: 20, OwnerID: receiverID, Type: entities.CommonWallet}, nil)
+	limitRepo.On("GetByUserID", ctx, senderID).Return(nil, port.ErrTransferLimitNotFound)
+
+	limits := newTestLimits(limitRepo, new(mockTransferCounterRepo), walletRepo, entities.TransferLimits{SingleMax: moneyPtr(100000)})
+	tx := NewTransaction(userRepo, walletRepo, transactionRepo, &fakeUnitOfWork{}, new(mockAuthService), limits, nil, nil, 0)
+
+	transaction, err := tx.Execute(ctx, TransferInput{PayerID: senderID, PayeeID: receiverID, Amount: entities.MoneyFromCents(200000)})
+	assert.ErrorIs(t, err, ErrLimitExceeded)
+	assert.Nil(t, transaction)
+	transactionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
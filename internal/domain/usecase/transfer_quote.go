@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+// TransferQuote is what a transfer would cost its payer: Amount, in the
+// payer's currency, reaches the payee and Fees are charged on top of it.
+type TransferQuote struct {
+	PayerID int64
+	PayeeID int64
+	Amount  entities.Money
+	Fees    entities.FeeBreakdown
+	Fee     entities.Money
+	Total   entities.Money
+	// Conversion is nil when both wallets share a currency.
+	Conversion *Conversion
+	// Legs are only set on split transfers, PayeeID being the first one's.
+	Legs []QuoteLeg
+}
+
+// QuoteLeg is what one payee of a split transfer receives.
+type QuoteLeg struct {
+	PayeeID         int64
+	PayeeWalletType entities.WalletType
+	Amount          entities.Money
+}
+
+// Quote prices a transfer without checking the payer's balance or limits.
+// The rate of a transfer between currencies is locked for a while and can be
+// used by passing the returned quote's ID along with the transfer.
+func (t *Transaction) Quote(ctx context.Context, payerID, payeeID int64, amount entities.Money) (*TransferQuote, error) {
+	payerWallet, payeeWallet, err := t.transferWallets(ctx, payerID, payeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := t.price(ctx, TransferInput{PayerID: payerID, PayeeID: payeeID, Amount: amount}, payerWallet, payeeWallet)
+	if err != nil {
+		return nil, err
+	}
+	if quote.Conversion != nil {
+		if err := t.exchange.Lock(ctx, payerID, payeeID, quote.Amount, quote.Conversion); err != nil {
+			return nil, err
+		}
+	}
+	return quote, nil
+}
+
+// price works out the fees and, between currencies, the conversion of a
+// transfer. input.Amount is taken to be in the payer's currency.
+func (t *Transaction) price(ctx context.Context, input TransferInput, payerWallet, payeeWallet *entities.Wallet) (*TransferQuote, error) {
+	amount := entities.NewMoney(input.Amount.Cents, payerWallet.Currency)
+	fees, err := t.fees.Quote(payerWallet.Type, payeeWallet.Type, amount)
+	if err != nil {
+		return nil, err
+	}
+	fee, err := fees.Total(amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+	total, err := amount.Add(fee)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion, err := t.convert(ctx, input, amount, payeeWallet.CurrencyCode())
+	if err != nil {
+		return nil, err
+	}
+
+	return &TransferQuote{
+		PayerID:    input.PayerID,
+		PayeeID:    input.PayeeID,
+		Amount:     amount,
+		Fees:       fees,
+		Fee:        fee,
+		Total:      total,
+		Conversion: conversion,
+	}, nil
+}
+
+// convert returns nil when amount is already in currency, the locked rate
+// when input names a quote and the current rate otherwise.
+func (t *Transaction) convert(ctx context.Context, input TransferInput, amount entities.Money, currency string) (*Conversion, error) {
+	if amount.Currency == currency {
+		if input.QuoteID != nil {
+			return nil, ErrExchangeQuoteMismatch
+		}
+		return nil, nil
+	}
+	if t.exchange == nil {
+		return nil, ErrConversionUnavailable
+	}
+	if input.QuoteID != nil {
+		return t.exchange.Redeem(ctx, *input.QuoteID, input.PayerID, input.PayeeID, amount, currency)
+	}
+	return t.exchange.Convert(ctx, amount, currency)
+}
+
+// spendQuote uses up the exchange quote transaction was priced with, if any,
+// in the unit of work that moves or holds its money, so the quote can still
+// be redeemed if that is rolled back.
+func (t *Transaction) spendQuote(ctx context.Context, repos port.Repositories, transaction *entities.Transaction) error {
+	if transaction.ExchangeQuoteID == nil {
+		return nil
+	}
+	return t.exchange.Spend(ctx, repos.Quotes, *transaction.ExchangeQuoteID)
+}
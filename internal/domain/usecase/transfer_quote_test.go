@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransaction_Quote(t *testing.T) {
+	ctx := context.Background()
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+
+	fees := entities.NewFeeTable()
+	assert.NoError(t, fees.Set(entities.CommonWallet, entities.MerchantWallet, entities.FeeSchedule{Name: "merchant", Type: entities.FeePercentage, Rate: 250}))
+
+	userRepo.On("GetByID", ctx, int64(1)).Return(&entities.User{ID: 1}, nil)
+	userRepo.On("GetByID", ctx, int64(2)).Return(&entities.User{ID: 2}, nil)
+	// Quotes do not depend on the payer's balance.
+	walletRepo.On("GetByOwnerID", ctx, int64(1)).Return(&entities.Wallet{ID: 10, OwnerID: 1, Type: entities.CommonWallet}, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(2)).Return(&entities.Wallet{ID: 20, OwnerID: 2, Type: entities.MerchantWallet}, nil)
+
+	tx := NewTransaction(userRepo, walletRepo, nil, nil, nil, nil, fees, nil, 0)
+
+	quote, err := tx.Quote(ctx, 1, 2, entities.MoneyFromCents(10000))
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(10000), quote.Amount)
+	assert.Equal(t, entities.MoneyFromCents(250), quote.Fee)
+	assert.Equal(t, entities.MoneyFromCents(10250), quote.Total)
+	assert.Equal(t, "merchant", quote.Fees.Schedule)
+	assert.Equal(t, []entities.FeeLine{{Code: entities.FeeLinePercentage, Amount: entities.MoneyFromCents(250)}}, quote.Fees.Lines)
+}
+
+func TestTransaction_Quote_WithoutFees(t *testing.T) {
+	ctx := context.Background()
+	userRepo := new(mockUserRepo)
+	walletRepo := new(mockWalletRepo)
+
+	userRepo.On("GetByID", ctx, int64(1)).Return(&entities.User{ID: 1}, nil)
+	userRepo.On("GetByID", ctx, int64(2)).Return(&entities.User{ID: 2}, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(1)).Return(&entities.Wallet{ID: 10, OwnerID: 1, Type: entities.CommonWallet}, nil)
+	walletRepo.On("GetByOwnerID", ctx, int64(2)).Return(&entities.Wallet{ID: 20, OwnerID: 2, Type: entities.CommonWallet}, nil)
+
+	tx := NewTransaction(userRepo, walletRepo, nil, nil, nil, nil, nil, nil, 0)
+
+	quote, err := tx.Quote(ctx, 1, 2, entities.MoneyFromCents(10000))
+	assert.NoError(t, err)
+	assert.True(t, quote.Fee.IsZero())
+	assert.Equal(t, entities.MoneyFromCents(10000), quote.Total)
+	assert.Empty(t, quote.Fees.Lines)
+}
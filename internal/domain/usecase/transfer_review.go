@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+var ErrTransferNotUnderReview = errors.New("transfer is not held for review")
+
+// reviewReasonCode marks decisions taken by hand on transfers the authorizer
+// held for review.
+const reviewReasonCode = "MANUAL_REVIEW"
+
+// Approve settles a transfer the authorizer held for review, or only holds
+// the payer's funds if it is a two-phase transfer, as Execute would have done
+// had it been approved. Balance and limits are checked again; a transfer that
+// no longer fits fails.
+func (t *Transaction) Approve(ctx context.Context, transactionID int64) (*entities.Transaction, error) {
+	transaction, err := t.transferUnderReview(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.decideReview(ctx, transaction, entities.AuthorizationApproved, entities.TransactionStatusAuthorizing, "review approved"); err != nil {
+		return nil, err
+	}
+
+	settle, reason := t.transfer, "settlement failed"
+	if transaction.AuthorizedAmount != nil {
+		settle, reason = t.hold, "hold failed"
+	}
+	if err := retryOnWalletConflict(func() error { return settle(ctx, transaction) }); err != nil {
+		if errors.Is(err, ErrLimitExceeded) {
+			reason = "limit exceeded"
+		}
+		t.failTransaction(ctx, transaction, reason)
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// Reject fails a transfer the authorizer held for review. No money moved, so
+// there is nothing to release.
+func (t *Transaction) Reject(ctx context.Context, transactionID int64) (*entities.Transaction, error) {
+	transaction, err := t.transferUnderReview(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.decideReview(ctx, transaction, entities.AuthorizationDenied, entities.TransactionStatusFailed, "review rejected"); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+func (t *Transaction) transferUnderReview(ctx context.Context, transactionID int64) (*entities.Transaction, error) {
+	transaction, err := t.transactionRepo.GetByID(ctx, transactionID)
+	if errors.Is(err, port.ErrTransactionNotFound) {
+		return nil, ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if transaction.Status != entities.TransactionStatusPending || transaction.Authorization.Outcome != entities.AuthorizationReview {
+		return nil, ErrTransferNotUnderReview
+	}
+	return transaction, nil
+}
+
+// decideReview moves transaction out of PENDING before recording the
+// decision, so a transfer cancelled or decided concurrently is left alone.
+func (t *Transaction) decideReview(ctx context.Context, transaction *entities.Transaction, outcome entities.AuthorizationOutcome, status entities.TransactionStatus, reason string) error {
+	err := transitionTransaction(ctx, t.transactionRepo, transaction, status, reason)
+	if errors.Is(err, port.ErrTransactionStatusConflict) {
+		return ErrTransferNotUnderReview
+	}
+	if err != nil {
+		return err
+	}
+	decision := entities.AuthorizationDecision{
+		Outcome:    outcome,
+		ReasonCode: reviewReasonCode,
+		Reference:  transaction.Authorization.Reference,
+	}
+	if err := t.transactionRepo.UpdateAuthorization(ctx, transaction.ID, decision); err != nil {
+		return err
+	}
+	transaction.Authorization = decision
+	return nil
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newReviewFixture holds a plain transfer of 50.00 for review.
+func newReviewFixture() *holdFixture {
+	f := newHoldFixture()
+	f.held.Status = entities.TransactionStatusPending
+	f.held.AuthorizedAmount = nil
+	f.held.HoldExpiresAt = nil
+	f.held.HeldAmount = entities.NewMoney(0, entities.DefaultCurrency)
+	f.held.Authorization = entities.AuthorizationDecision{Outcome: entities.AuthorizationReview, ReasonCode: "HIGH_VALUE", Reference: "auth-1"}
+	f.payerWallet.HeldBalance = entities.NewMoney(0, entities.DefaultCurrency)
+	return f
+}
+
+func manualDecision(outcome entities.AuthorizationOutcome) entities.AuthorizationDecision {
+	return entities.AuthorizationDecision{Outcome: outcome, ReasonCode: reviewReasonCode, Reference: "auth-1"}
+}
+
+func TestTransaction_Approve_SettlesTransfer(t *testing.T) {
+	f := newReviewFixture()
+	amount := f.held.Amount
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusPending, entities.TransactionStatusAuthorizing, "review approved").Return(nil).Once()
+	f.transactionRepo.On("UpdateAuthorization", f.ctx, int64(99), manualDecision(entities.AuthorizationApproved)).Return(nil).Once()
+	f.walletRepo.On("Debit", f.ctx, f.payerWallet.ID, amount, int64(3)).Return(nil).Once()
+	f.walletRepo.On("Credit", f.ctx, f.payeeWallet.ID, amount, int64(1)).Return(nil).Once()
+	f.ledgerRepo.On("CreateEntries", f.ctx, entities.NewTransferPosting(99, f.payerWallet.ID, f.payeeWallet.ID, amount)).Return(nil).Once()
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusCompleted, "transfer settled").Return(nil).Once()
+	f.outboxRepo.On("Create", f.ctx, transferNotification(2, 99, amount)).Return(nil).Once()
+
+	transaction, err := f.tx.Approve(f.ctx, 99)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusCompleted, transaction.Status)
+	assert.Equal(t, manualDecision(entities.AuthorizationApproved), transaction.Authorization)
+	f.walletRepo.AssertExpectations(t)
+	f.transactionRepo.AssertExpectations(t)
+	f.ledgerRepo.AssertExpectations(t)
+	f.outboxRepo.AssertExpectations(t)
+}
+
+func TestTransaction_Approve_FailsWhenFundsAreGone(t *testing.T) {
+	f := newReviewFixture()
+	f.payerWallet.Balance = entities.MoneyFromCents(1000)
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusPending, entities.TransactionStatusAuthorizing, "review approved").Return(nil).Once()
+	f.transactionRepo.On("UpdateAuthorization", f.ctx, int64(99), manualDecision(entities.AuthorizationApproved)).Return(nil).Once()
+	f.transactionRepo.On("UpdateStatus", mock.Anything, int64(99), entities.TransactionStatusAuthorizing, entities.TransactionStatusFailed, "settlement failed").Return(nil).Once()
+
+	transaction, err := f.tx.Approve(f.ctx, 99)
+	assert.ErrorIs(t, err, ErrInsufficientBalance)
+	assert.Nil(t, transaction)
+	f.transactionRepo.AssertExpectations(t)
+	f.walletRepo.AssertNotCalled(t, "Debit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Reject_FailsTransfer(t *testing.T) {
+	f := newReviewFixture()
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusPending, entities.TransactionStatusFailed, "review rejected").Return(nil).Once()
+	f.transactionRepo.On("UpdateAuthorization", f.ctx, int64(99), manualDecision(entities.AuthorizationDenied)).Return(nil).Once()
+
+	transaction, err := f.tx.Reject(f.ctx, 99)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusFailed, transaction.Status)
+	assert.Equal(t, manualDecision(entities.AuthorizationDenied), transaction.Authorization)
+	f.transactionRepo.AssertExpectations(t)
+	f.walletRepo.AssertNotCalled(t, "Debit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Review_NotUnderReview(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  entities.TransactionStatus
+		outcome entities.AuthorizationOutcome
+	}{
+		{"awaiting authorization", entities.TransactionStatusPending, ""},
+		{"already approved", entities.TransactionStatusCompleted, entities.AuthorizationApproved},
+		{"cancelled", entities.TransactionStatusCancelled, entities.AuthorizationReview},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newReviewFixture()
+			f.held.Status = tt.status
+			f.held.Authorization.Outcome = tt.outcome
+
+			_, err := f.tx.Approve(f.ctx, 99)
+			assert.ErrorIs(t, err, ErrTransferNotUnderReview)
+			_, err = f.tx.Reject(f.ctx, 99)
+			assert.ErrorIs(t, err, ErrTransferNotUnderReview)
+			f.transactionRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTransaction_Reject_LosesRaceToCancel(t *testing.T) {
+	f := newReviewFixture()
+	f.transactionRepo.On("UpdateStatus", f.ctx, int64(99), entities.TransactionStatusPending, entities.TransactionStatusFailed, "review rejected").Return(port.ErrTransactionStatusConflict).Once()
+
+	transaction, err := f.tx.Reject(f.ctx, 99)
+	assert.ErrorIs(t, err, ErrTransferNotUnderReview)
+	assert.Nil(t, transaction)
+	f.transactionRepo.AssertNotCalled(t, "UpdateAuthorization", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTransaction_Approve_NotFound(t *testing.T) {
+	f := newReviewFixture()
+	f.transactionRepo.On("GetByID", f.ctx, int64(7)).Return(nil, port.ErrTransactionNotFound)
+
+	_, err := f.tx.Approve(f.ctx, 7)
+	assert.ErrorIs(t, err, ErrTransferNotFound)
+}
@@ -13,7 +13,8 @@ type UserInput struct {
 	Email    string              `json:"email"`
 	Password string              `json:"password"`
 	Type     entities.WalletType `json:"type"`
-	Balance  float64             `json:"balance"`
+	Balance  entities.Money      `json:"balance"`
+	Currency string              `json:"currency"`
 }
 
 type User struct {
@@ -64,7 +64,7 @@ func TestUserUseCase_CreateUser_Success(t *testing.T) {
 		Email:    "john.doe@example.com",
 		Password: "securepassword",
 		Type:     entities.CommonWallet,
-		Balance:  100.0,
+		Balance:  entities.MoneyFromCents(10000),
 	}
 
 	expectedUser := &entities.User{
@@ -105,7 +105,7 @@ func TestUserUseCase_CreateUser_Error(t *testing.T) {
 		Email:    "john.doe@example.com",
 		Password: "securepassword",
 		Type:     entities.MerchantWallet,
-		Balance:  100.0,
+		Balance:  entities.MoneyFromCents(10000),
 	}
 
 	mockRepo.On("Create", ctx, mock.AnythingOfType("*entities.User")).Return(errors.New("database error"))
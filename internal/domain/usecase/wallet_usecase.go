@@ -2,34 +2,88 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+
 	"go-transfer/internal/domain/entities"
 	"go-transfer/internal/domain/port"
 )
 
+var (
+	ErrInvalidDepositAmount = errors.New("deposit amount must be greater than zero")
+	ErrUnsupportedCurrency  = errors.New("unsupported currency")
+)
+
 type WalletInput struct {
-	OwnerID int64               `json:"owner_id"`
-	Type    entities.WalletType `json:"type"`
-	Balance float64             `json:"balance"`
+	OwnerID  int64               `json:"owner_id"`
+	Type     entities.WalletType `json:"type"`
+	Currency string              `json:"currency"`
+	Balance  entities.Money      `json:"balance"`
 }
 
 type Wallet struct {
 	walletRepo port.WalletRepository
+	unitOfWork port.UnitOfWork
+	// currencies wallets may be opened in; only the default one if empty.
+	currencies []string
 }
 
-func NewWallet(walletRepo port.WalletRepository) *Wallet {
+func NewWallet(walletRepo port.WalletRepository, unitOfWork port.UnitOfWork, currencies []string) *Wallet {
 	return &Wallet{
 		walletRepo: walletRepo,
+		unitOfWork: unitOfWork,
+		currencies: currencies,
 	}
 }
 
+// ParseCurrency returns the ISO 4217 code of currency if wallets may be
+// opened in it. An empty currency is the default one.
+func (w *Wallet) ParseCurrency(currency string) (string, error) {
+	code, err := entities.ParseCurrency(currency)
+	if err != nil {
+		return "", err
+	}
+	if len(w.currencies) == 0 && code == entities.DefaultCurrency {
+		return code, nil
+	}
+	for _, supported := range w.currencies {
+		if supported == code {
+			return code, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedCurrency, code)
+}
+
+// CreateWallet opens an empty wallet and, when an initial balance is given,
+// funds it through a ledger deposit in the same unit of work.
 func (w *Wallet) CreateWallet(ctx context.Context, input WalletInput) error {
-	wallet := &entities.Wallet{
-		OwnerID: input.OwnerID,
-		Type:    input.Type,
-		Balance: input.Balance,
+	currency, err := w.ParseCurrency(input.Currency)
+	if err != nil {
+		return err
 	}
 
-	return w.walletRepo.Create(ctx, wallet)
+	unlock := func() {}
+	err = w.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		wallet := &entities.Wallet{
+			OwnerID:  input.OwnerID,
+			Type:     input.Type,
+			Currency: currency,
+		}
+		if err := repos.Wallets.Create(ctx, wallet); err != nil {
+			return err
+		}
+		if !input.Balance.IsPositive() {
+			return nil
+		}
+
+		release, err := w.deposit(ctx, repos, wallet.ID, input.Balance)
+		if release != nil {
+			unlock = release
+		}
+		return err
+	})
+	unlock()
+	return err
 }
 
 func (w *Wallet) GetWalletByID(ctx context.Context, id int64) (*entities.Wallet, error) {
@@ -40,6 +94,66 @@ func (w *Wallet) GetWalletByOwnerID(ctx context.Context, ownerID int64) (*entiti
 	return w.walletRepo.GetByOwnerID(ctx, ownerID)
 }
 
-func (w *Wallet) UpdateWalletBalance(ctx context.Context, id int64, balance float64) error {
-	return w.walletRepo.UpdateBalance(ctx, id, balance)
+func (w *Wallet) Deposit(ctx context.Context, walletID int64, amount entities.Money) error {
+	if !amount.IsPositive() {
+		return ErrInvalidDepositAmount
+	}
+
+	unlock := func() {}
+	err := w.unitOfWork.Do(ctx, func(repos port.Repositories) error {
+		release, err := w.deposit(ctx, repos, walletID, amount)
+		if release != nil {
+			unlock = release
+		}
+		return err
+	})
+	unlock()
+	return err
+}
+
+// deposit funds walletID from the funding wallet of its currency; amount is
+// taken to be in that currency.
+func (w *Wallet) deposit(ctx context.Context, repos port.Repositories, walletID int64, amount entities.Money) (func(), error) {
+	wallet, err := repos.Wallets.GetByID(ctx, walletID)
+	if err != nil {
+		return nil, err
+	}
+	funding, err := repos.Wallets.GetSystemWallet(ctx, entities.SystemAccountFunding, wallet.CurrencyCode())
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := repos.Locker.Lock(ctx, funding.ID, walletID)
+	if err != nil {
+		return nil, err
+	}
+
+	funding, err = repos.Wallets.GetByID(ctx, funding.ID)
+	if err != nil {
+		return unlock, err
+	}
+	wallet, err = repos.Wallets.GetByID(ctx, walletID)
+	if err != nil {
+		return unlock, err
+	}
+
+	amount = entities.NewMoney(amount.Cents, wallet.Currency)
+	deposit := &entities.Transaction{
+		SenderID:   funding.OwnerID,
+		ReceiverID: wallet.OwnerID,
+		Amount:     amount,
+		Currency:   amount.Currency,
+		Type:       entities.TransactionTypeDeposit,
+		Status:     entities.TransactionStatusPending,
+	}
+	deposit.ID, err = repos.Transactions.Create(ctx, deposit)
+	if err != nil {
+		return unlock, err
+	}
+
+	posting := entities.NewTransferPosting(deposit.ID, funding.ID, wallet.ID, amount)
+	if err := postLedger(ctx, repos, []*entities.Wallet{funding, wallet}, posting); err != nil {
+		return unlock, err
+	}
+	return unlock, transitionTransaction(ctx, repos.Transactions, deposit, entities.TransactionStatusCompleted, "deposit settled")
 }
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -38,50 +39,144 @@ func (m *MockWalletRepository) GetByOwnerID(ctx context.Context, ownerID int64)
 	return args.Get(0).(*entities.Wallet), args.Error(1)
 }
 
-func (m *MockWalletRepository) UpdateBalance(ctx context.Context, id int64, balance float64) error {
-	args := m.Called(ctx, id, balance)
+func (m *MockWalletRepository) GetSystemWallet(ctx context.Context, account entities.SystemAccount, currency string) (*entities.Wallet, error) {
+	args := m.Called(ctx, account, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.Wallet), args.Error(1)
+}
+
+func (m *MockWalletRepository) Debit(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	args := m.Called(ctx, id, amount, version)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) Credit(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	args := m.Called(ctx, id, amount, version)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) Hold(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	args := m.Called(ctx, id, amount, version)
+	return args.Error(0)
+}
+
+func (m *MockWalletRepository) ReleaseHold(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	args := m.Called(ctx, id, amount, version)
+	return args.Error(0)
+}
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) CreateEntries(ctx context.Context, entries []entities.LedgerEntry) error {
+	args := m.Called(ctx, entries)
 	return args.Error(0)
 }
 
+func (m *MockLedgerRepository) ListByTransaction(ctx context.Context, transactionID int64) ([]entities.LedgerEntry, error) {
+	args := m.Called(ctx, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]entities.LedgerEntry), args.Error(1)
+}
+
+func newWalletUseCaseWithMocks(walletRepo *MockWalletRepository, transactionRepo *mockTransactionRepo, ledgerRepo *MockLedgerRepository) *Wallet {
+	unitOfWork := &fakeUnitOfWork{repos: port.Repositories{
+		Wallets:      walletRepo,
+		Transactions: transactionRepo,
+		Ledger:       ledgerRepo,
+		Locker:       &fakeWalletLocker{},
+	}}
+	return NewWallet(walletRepo, unitOfWork, nil)
+}
+
+func expectDeposit(ctx context.Context, walletRepo *MockWalletRepository, transactionRepo *mockTransactionRepo, ledgerRepo *MockLedgerRepository, wallet *entities.Wallet, amount entities.Money) {
+	funding := &entities.Wallet{ID: 100, OwnerID: 50, Type: entities.SystemWallet, Version: 3}
+	walletRepo.On("GetSystemWallet", ctx, entities.SystemAccountFunding, entities.DefaultCurrency).Return(funding, nil)
+	walletRepo.On("GetByID", ctx, funding.ID).Return(funding, nil)
+	walletRepo.On("GetByID", ctx, wallet.ID).Return(wallet, nil)
+	walletRepo.On("Debit", ctx, funding.ID, amount, funding.Version).Return(nil)
+	walletRepo.On("Credit", ctx, wallet.ID, amount, wallet.Version).Return(nil)
+
+	transactionRepo.On("Create", ctx, mock.MatchedBy(func(tr *entities.Transaction) bool {
+		return tr.Type == entities.TransactionTypeDeposit &&
+			tr.SenderID == funding.OwnerID &&
+			tr.ReceiverID == wallet.OwnerID &&
+			tr.Status == entities.TransactionStatusPending
+	})).Return(int64(7), nil)
+
+	ledgerRepo.On("CreateEntries", ctx, entities.NewTransferPosting(7, funding.ID, wallet.ID, amount)).Return(nil)
+	transactionRepo.On("UpdateStatus", ctx, int64(7), entities.TransactionStatusPending, entities.TransactionStatusCompleted, "deposit settled").Return(nil)
+}
+
 func TestWalletUseCase_CreateWallet_Success(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	walletUseCase := NewWallet(mockRepo)
+	transactionRepo := new(mockTransactionRepo)
+	ledgerRepo := new(MockLedgerRepository)
+	walletUseCase := newWalletUseCaseWithMocks(mockRepo, transactionRepo, ledgerRepo)
 	ctx := context.Background()
 
 	input := WalletInput{
 		OwnerID: 1,
 		Type:    entities.CommonWallet,
-		Balance: 100.0,
+		Balance: entities.MoneyFromCents(10000),
 	}
 
 	wallet := &entities.Wallet{
-		OwnerID: input.OwnerID,
-		Type:    input.Type,
-		Balance: input.Balance,
+		OwnerID:  input.OwnerID,
+		Type:     input.Type,
+		Currency: entities.DefaultCurrency,
+	}
+
+	mockRepo.On("Create", ctx, wallet).Return(nil).Run(func(args mock.Arguments) {
+		args.Get(1).(*entities.Wallet).ID = 1
+	})
+	expectDeposit(ctx, mockRepo, transactionRepo, ledgerRepo, &entities.Wallet{ID: 1, OwnerID: 1, Type: input.Type}, input.Balance)
+
+	err := walletUseCase.CreateWallet(ctx, input)
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	ledgerRepo.AssertExpectations(t)
+}
+
+func TestWalletUseCase_CreateWallet_WithoutBalance(t *testing.T) {
+	mockRepo := new(MockWalletRepository)
+	walletUseCase := newWalletUseCaseWithMocks(mockRepo, new(mockTransactionRepo), new(MockLedgerRepository))
+	ctx := context.Background()
+
+	input := WalletInput{
+		OwnerID: 1,
+		Type:    entities.MerchantWallet,
 	}
 
-	mockRepo.On("Create", ctx, wallet).Return(nil)
+	mockRepo.On("Create", ctx, &entities.Wallet{OwnerID: 1, Type: entities.MerchantWallet, Currency: entities.DefaultCurrency}).Return(nil)
 
 	err := walletUseCase.CreateWallet(ctx, input)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetSystemWallet", mock.Anything, mock.Anything, mock.Anything)
 }
 
 func TestWalletUseCase_CreateWallet_Error(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	walletUseCase := NewWallet(mockRepo)
+	walletUseCase := newWalletUseCaseWithMocks(mockRepo, new(mockTransactionRepo), new(MockLedgerRepository))
 	ctx := context.Background()
 
 	input := WalletInput{
 		OwnerID: 1,
 		Type:    entities.MerchantWallet,
-		Balance: 100.0,
+		Balance: entities.MoneyFromCents(10000),
 	}
 
 	wallet := &entities.Wallet{
-		OwnerID: input.OwnerID,
-		Type:    input.Type,
-		Balance: input.Balance,
+		OwnerID:  input.OwnerID,
+		Type:     input.Type,
+		Currency: entities.DefaultCurrency,
 	}
 
 	mockRepo.On("Create", ctx, wallet).Return(errors.New("database error"))
@@ -94,7 +189,7 @@ func TestWalletUseCase_CreateWallet_Error(t *testing.T) {
 
 func TestWalletUseCase_GetWalletByID_Success(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	walletUseCase := NewWallet(mockRepo)
+	walletUseCase := NewWallet(mockRepo, nil, nil)
 	ctx := context.Background()
 	walletID := int64(1)
 
@@ -102,7 +197,7 @@ func TestWalletUseCase_GetWalletByID_Success(t *testing.T) {
 		ID:        walletID,
 		OwnerID:   1,
 		Type:      entities.MerchantWallet,
-		Balance:   100.0,
+		Balance:   entities.MoneyFromCents(10000),
 		CreatedAt: time.Now(),
 	}
 
@@ -116,7 +211,7 @@ func TestWalletUseCase_GetWalletByID_Success(t *testing.T) {
 
 func TestWalletUseCase_GetWalletByID_NotFound(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	walletUseCase := NewWallet(mockRepo)
+	walletUseCase := NewWallet(mockRepo, nil, nil)
 	ctx := context.Background()
 	walletID := int64(1)
 
@@ -131,7 +226,7 @@ func TestWalletUseCase_GetWalletByID_NotFound(t *testing.T) {
 
 func TestWalletUseCase_GetWalletByOwnerID_Success(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	walletUseCase := NewWallet(mockRepo)
+	walletUseCase := NewWallet(mockRepo, nil, nil)
 	ctx := context.Background()
 	ownerID := int64(1)
 
@@ -139,7 +234,7 @@ func TestWalletUseCase_GetWalletByOwnerID_Success(t *testing.T) {
 		ID:        1,
 		OwnerID:   ownerID,
 		Type:      entities.MerchantWallet,
-		Balance:   100.0,
+		Balance:   entities.MoneyFromCents(10000),
 		CreatedAt: time.Now(),
 	}
 
@@ -153,7 +248,7 @@ func TestWalletUseCase_GetWalletByOwnerID_Success(t *testing.T) {
 
 func TestWalletUseCase_GetWalletByOwnerID_NotFound(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	walletUseCase := NewWallet(mockRepo)
+	walletUseCase := NewWallet(mockRepo, nil, nil)
 	ctx := context.Background()
 	ownerID := int64(1)
 
@@ -166,31 +261,85 @@ func TestWalletUseCase_GetWalletByOwnerID_NotFound(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-func TestWalletUseCase_UpdateWalletBalance_Success(t *testing.T) {
+func TestWalletUseCase_Deposit_Success(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	walletUseCase := NewWallet(mockRepo)
+	transactionRepo := new(mockTransactionRepo)
+	ledgerRepo := new(MockLedgerRepository)
+	walletUseCase := newWalletUseCaseWithMocks(mockRepo, transactionRepo, ledgerRepo)
 	ctx := context.Background()
-	walletID := int64(1)
-	newBalance := 150.0
+	amount := entities.MoneyFromCents(15000)
 
-	mockRepo.On("UpdateBalance", ctx, walletID, newBalance).Return(nil)
+	wallet := &entities.Wallet{ID: 1, OwnerID: 9, Type: entities.CommonWallet, Balance: entities.MoneyFromCents(500), Version: 2}
+	expectDeposit(ctx, mockRepo, transactionRepo, ledgerRepo, wallet, amount)
 
-	err := walletUseCase.UpdateWalletBalance(ctx, walletID, newBalance)
+	err := walletUseCase.Deposit(ctx, wallet.ID, amount)
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	ledgerRepo.AssertExpectations(t)
 }
 
-func TestWalletUseCase_UpdateWalletBalance_Error(t *testing.T) {
+func TestWalletUseCase_Deposit_UsesFundingWalletOfCurrency(t *testing.T) {
 	mockRepo := new(MockWalletRepository)
-	walletUseCase := NewWallet(mockRepo)
+	transactionRepo := new(mockTransactionRepo)
+	ledgerRepo := new(MockLedgerRepository)
+	walletUseCase := newWalletUseCaseWithMocks(mockRepo, transactionRepo, ledgerRepo)
 	ctx := context.Background()
-	walletID := int64(1)
-	newBalance := 150.0
+	amount := entities.NewMoney(2000, "USD")
+
+	wallet := &entities.Wallet{ID: 1, OwnerID: 9, Type: entities.CommonWallet, Currency: "USD"}
+	funding := &entities.Wallet{ID: 101, OwnerID: 50, Type: entities.SystemWallet, Currency: "USD"}
+	mockRepo.On("GetByID", ctx, wallet.ID).Return(wallet, nil)
+	mockRepo.On("GetSystemWallet", ctx, entities.SystemAccountFunding, "USD").Return(funding, nil)
+	mockRepo.On("GetByID", ctx, funding.ID).Return(funding, nil)
+	mockRepo.On("Debit", ctx, funding.ID, amount, funding.Version).Return(nil)
+	mockRepo.On("Credit", ctx, wallet.ID, amount, wallet.Version).Return(nil)
+	transactionRepo.On("Create", ctx, mock.MatchedBy(func(tr *entities.Transaction) bool {
+		return tr.Currency == "USD" && tr.Amount == amount
+	})).Return(int64(8), nil)
+	ledgerRepo.On("CreateEntries", ctx, entities.NewTransferPosting(8, funding.ID, wallet.ID, amount)).Return(nil)
+	transactionRepo.On("UpdateStatus", ctx, int64(8), entities.TransactionStatusPending, entities.TransactionStatusCompleted, "deposit settled").Return(nil)
+
+	// Deposits are always in the currency of the wallet.
+	err := walletUseCase.Deposit(ctx, wallet.ID, entities.MoneyFromCents(2000))
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	transactionRepo.AssertExpectations(t)
+	ledgerRepo.AssertExpectations(t)
+}
+
+func TestWalletUseCase_CreateWallet_UnsupportedCurrency(t *testing.T) {
+	mockRepo := new(MockWalletRepository)
+	walletUseCase := NewWallet(mockRepo, &fakeUnitOfWork{}, []string{"BRL", "USD"})
 
-	mockRepo.On("UpdateBalance", ctx, walletID, newBalance).Return(errors.New("failed to update balance"))
+	err := walletUseCase.CreateWallet(context.Background(), WalletInput{OwnerID: 1, Type: entities.CommonWallet, Currency: "EUR"})
+	assert.ErrorIs(t, err, ErrUnsupportedCurrency)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
 
-	err := walletUseCase.UpdateWalletBalance(ctx, walletID, newBalance)
-	assert.Error(t, err)
-	assert.Equal(t, "failed to update balance", err.Error())
+	currency, err := walletUseCase.ParseCurrency("usd")
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", currency)
+}
+
+func TestWalletUseCase_Deposit_InvalidAmount(t *testing.T) {
+	mockRepo := new(MockWalletRepository)
+	walletUseCase := newWalletUseCaseWithMocks(mockRepo, new(mockTransactionRepo), new(MockLedgerRepository))
+	ctx := context.Background()
+
+	err := walletUseCase.Deposit(ctx, 1, entities.MoneyFromCents(0))
+	assert.ErrorIs(t, err, ErrInvalidDepositAmount)
+	mockRepo.AssertNotCalled(t, "GetSystemWallet", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWalletUseCase_Deposit_Error(t *testing.T) {
+	mockRepo := new(MockWalletRepository)
+	walletUseCase := newWalletUseCaseWithMocks(mockRepo, new(mockTransactionRepo), new(MockLedgerRepository))
+	ctx := context.Background()
+
+	mockRepo.On("GetByID", ctx, int64(1)).Return(&entities.Wallet{ID: 1, OwnerID: 9}, nil)
+	mockRepo.On("GetSystemWallet", ctx, entities.SystemAccountFunding, entities.DefaultCurrency).Return(nil, errors.New("funding wallet not found"))
+
+	err := walletUseCase.Deposit(ctx, 1, entities.MoneyFromCents(100))
+	assert.EqualError(t, err, "funding wallet not found")
 	mockRepo.AssertExpectations(t)
 }
@@ -3,19 +3,91 @@ package env
 import (
 	"log"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-transfer/internal/domain/entities"
 
 	"github.com/joho/godotenv"
 )
 
+const (
+	WalletLockerMemory   = "memory"
+	WalletLockerPostgres = "postgres"
+
+	AuthorizerRemote    = "remote"
+	AuthorizerRules     = "rules"
+	AuthorizerComposite = "composite"
+)
+
 type Config struct {
-	Port             string
-	NotificationURL  string
-	AuthorizationURL string
-	DatabaseHost     string
-	DatabasePort     string
-	DatabaseUser     string
-	DatabasePassword string
-	DatabaseName     string
+	Port                       string
+	NotificationURL            string
+	AuthorizationURL           string
+	DatabaseHost               string
+	DatabasePort               string
+	DatabaseUser               string
+	DatabasePassword           string
+	DatabaseName               string
+	IdempotencyTTL             time.Duration
+	IdempotencyCleanupInterval time.Duration
+	WalletLocker               string
+	OutboxDispatchInterval     time.Duration
+	OutboxBatchSize            int
+	OutboxLease                time.Duration
+	OutboxRetryDelay           time.Duration
+	OutboxMaxAttempts          int
+	NotificationMaxAttempts    int
+	NotificationRetryBaseDelay time.Duration
+	NotificationRetryMaxDelay  time.Duration
+	NotificationRetryInterval  time.Duration
+	NotificationRetryLease     time.Duration
+	NotificationRetryBatchSize int
+	AdminToken                 string
+	AuthorizationTimeout       time.Duration
+	AuthorizationBreaker       BreakerConfig
+	NotificationTimeout        time.Duration
+	NotificationBreaker        BreakerConfig
+	Authorizer                 string
+	AuthorizationRulesFile     string
+	AuthorizationRulesReload   time.Duration
+	LimitsTimezone             string
+	CommonLimits               LimitConfig
+	MerchantLimits             LimitConfig
+	FeeSchedulesFile           string
+	Currencies                 []string
+	ExchangeRatesFile          string
+	ExchangeQuoteTTL           time.Duration
+	HoldTTL                    time.Duration
+	HoldExpiryInterval         time.Duration
+	HoldExpiryBatchSize        int
+	ScheduledTransferInterval  time.Duration
+	ScheduledTransferBatchSize int
+	ScheduledTransferLease     time.Duration
+	ScheduledTransferTimezone  string
+	ScheduledTransferRetry     time.Duration
+	ScheduledTransferRetries   int
+	TransferBatchMaxItems      int
+	TransferBatchInterval      time.Duration
+	TransferBatchClaimSize     int
+	TransferBatchLease         time.Duration
+	IdempotencyLease           time.Duration
+}
+
+// LimitConfig holds the default transfer limits of a wallet type. Empty
+// amounts and a zero count mean no limit.
+type LimitConfig struct {
+	SingleMax     string
+	DailyAmount   string
+	MonthlyAmount string
+	HourlyCount   int
+}
+
+type BreakerConfig struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
 }
 
 func LoadEnv() *Config {
@@ -25,14 +97,67 @@ func LoadEnv() *Config {
 	}
 
 	cfg := &Config{
-		Port:             os.Getenv("PORT"),
-		NotificationURL:  os.Getenv("NOTIFICATION_BASE_URL"),
-		AuthorizationURL: os.Getenv("AUTHORIZATION_BASE_URL"),
-		DatabaseHost:     os.Getenv("DATABASE_URL"),
-		DatabasePort:     os.Getenv("DATABASE_PORT"),
-		DatabaseUser:     os.Getenv("DATABASE_USERNAME"),
-		DatabasePassword: os.Getenv("DATABASE_PASSWORD"),
-		DatabaseName:     os.Getenv("DATABASE_NAME"),
+		Port:                       os.Getenv("PORT"),
+		NotificationURL:            os.Getenv("NOTIFICATION_BASE_URL"),
+		AuthorizationURL:           os.Getenv("AUTHORIZATION_BASE_URL"),
+		DatabaseHost:               os.Getenv("DATABASE_URL"),
+		DatabasePort:               os.Getenv("DATABASE_PORT"),
+		DatabaseUser:               os.Getenv("DATABASE_USERNAME"),
+		DatabasePassword:           os.Getenv("DATABASE_PASSWORD"),
+		DatabaseName:               os.Getenv("DATABASE_NAME"),
+		IdempotencyTTL:             getDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		IdempotencyCleanupInterval: getDuration("IDEMPOTENCY_CLEANUP_INTERVAL", time.Hour),
+		WalletLocker:               getString("WALLET_LOCKER", WalletLockerMemory),
+		OutboxDispatchInterval:     getDuration("OUTBOX_DISPATCH_INTERVAL", time.Second),
+		OutboxBatchSize:            getInt("OUTBOX_BATCH_SIZE", 100),
+		OutboxLease:                getDuration("OUTBOX_LEASE", 30*time.Second),
+		OutboxRetryDelay:           getDuration("OUTBOX_RETRY_DELAY", 10*time.Second),
+		OutboxMaxAttempts:          getInt("OUTBOX_MAX_ATTEMPTS", 10),
+		NotificationMaxAttempts:    getInt("NOTIFICATION_MAX_ATTEMPTS", 5),
+		NotificationRetryBaseDelay: getDuration("NOTIFICATION_RETRY_BASE_DELAY", 30*time.Second),
+		NotificationRetryMaxDelay:  getDuration("NOTIFICATION_RETRY_MAX_DELAY", time.Hour),
+		NotificationRetryInterval:  getDuration("NOTIFICATION_RETRY_INTERVAL", 10*time.Second),
+		NotificationRetryLease:     getDuration("NOTIFICATION_RETRY_LEASE", 30*time.Second),
+		NotificationRetryBatchSize: getInt("NOTIFICATION_RETRY_BATCH_SIZE", 100),
+		AdminToken:                 os.Getenv("ADMIN_TOKEN"),
+		AuthorizationTimeout:       getDuration("AUTHORIZATION_TIMEOUT", 5*time.Second),
+		AuthorizationBreaker:       getBreaker("AUTHORIZATION"),
+		NotificationTimeout:        getDuration("NOTIFICATION_TIMEOUT", 5*time.Second),
+		NotificationBreaker:        getBreaker("NOTIFICATION"),
+		Authorizer:                 getString("AUTHORIZER", AuthorizerRemote),
+		AuthorizationRulesFile:     getString("AUTHORIZATION_RULES_FILE", "authorization_rules.yaml"),
+		AuthorizationRulesReload:   getDuration("AUTHORIZATION_RULES_RELOAD_INTERVAL", 30*time.Second),
+		LimitsTimezone:             getString("LIMITS_TIMEZONE", "UTC"),
+		CommonLimits:               getLimits("COMMON"),
+		MerchantLimits:             getLimits("MERCHANT"),
+		FeeSchedulesFile:           os.Getenv("FEE_SCHEDULES_FILE"),
+		Currencies:                 getCurrencies("CURRENCIES"),
+		ExchangeRatesFile:          os.Getenv("EXCHANGE_RATES_FILE"),
+		ExchangeQuoteTTL:           getDuration("EXCHANGE_QUOTE_TTL", 30*time.Second),
+		HoldTTL:                    getDuration("HOLD_TTL", 7*24*time.Hour),
+		HoldExpiryInterval:         getDuration("HOLD_EXPIRY_INTERVAL", time.Minute),
+		HoldExpiryBatchSize:        getInt("HOLD_EXPIRY_BATCH_SIZE", 100),
+		ScheduledTransferInterval:  getDuration("SCHEDULED_TRANSFER_INTERVAL", 30*time.Second),
+		ScheduledTransferBatchSize: getInt("SCHEDULED_TRANSFER_BATCH_SIZE", 50),
+		ScheduledTransferLease:     getDuration("SCHEDULED_TRANSFER_LEASE", 5*time.Minute),
+		ScheduledTransferTimezone:  getString("SCHEDULED_TRANSFER_TIMEZONE", "UTC"),
+		ScheduledTransferRetry:     getDuration("SCHEDULED_TRANSFER_RETRY_DELAY", time.Hour),
+		ScheduledTransferRetries:   getInt("SCHEDULED_TRANSFER_MAX_RETRIES", 3),
+		TransferBatchMaxItems:      getInt("TRANSFER_BATCH_MAX_ITEMS", 100),
+		TransferBatchInterval:      getDuration("TRANSFER_BATCH_INTERVAL", 10*time.Second),
+		TransferBatchClaimSize:     getInt("TRANSFER_BATCH_CLAIM_SIZE", 5),
+		TransferBatchLease:         getDuration("TRANSFER_BATCH_LEASE", 10*time.Minute),
+		IdempotencyLease:           getDuration("IDEMPOTENCY_LEASE", 2*time.Minute),
+	}
+
+	if cfg.WalletLocker != WalletLockerMemory && cfg.WalletLocker != WalletLockerPostgres {
+		log.Fatalf("WALLET_LOCKER inválido: %q. Use %q ou %q.", cfg.WalletLocker, WalletLockerMemory, WalletLockerPostgres)
+	}
+
+	switch cfg.Authorizer {
+	case AuthorizerRemote, AuthorizerRules, AuthorizerComposite:
+	default:
+		log.Fatalf("AUTHORIZER inválido: %q. Use %q, %q ou %q.", cfg.Authorizer, AuthorizerRemote, AuthorizerRules, AuthorizerComposite)
 	}
 
 	if cfg.DatabaseHost == "" || cfg.DatabaseUser == "" || cfg.DatabaseName == "" {
@@ -41,3 +166,73 @@ func LoadEnv() *Config {
 
 	return cfg
 }
+
+func getString(key string, fallback string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Valor inválido para %s: %v. Usando %s.", key, err, fallback)
+		return fallback
+	}
+	return duration
+}
+
+func getInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		log.Printf("Valor inválido para %s: %q. Usando %d.", key, value, fallback)
+		return fallback
+	}
+	return number
+}
+
+func getBreaker(prefix string) BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: getInt(prefix+"_BREAKER_FAILURE_THRESHOLD", 5),
+		OpenTimeout:      getDuration(prefix+"_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+	}
+}
+
+func getLimits(walletType string) LimitConfig {
+	prefix := "LIMIT_" + walletType
+	return LimitConfig{
+		SingleMax:     os.Getenv(prefix + "_SINGLE_MAX"),
+		DailyAmount:   os.Getenv(prefix + "_DAILY_AMOUNT"),
+		MonthlyAmount: os.Getenv(prefix + "_MONTHLY_AMOUNT"),
+		HourlyCount:   getInt(prefix+"_HOURLY_COUNT", 0),
+	}
+}
+
+// getCurrencies reads a comma separated list of ISO 4217 codes. The default
+// currency is always part of it.
+func getCurrencies(key string) []string {
+	currencies := []string{entities.DefaultCurrency}
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		currency, err := entities.ParseCurrency(value)
+		if err != nil {
+			log.Fatalf("Moeda inválida em %s: %q.", key, value)
+		}
+		if !slices.Contains(currencies, currency) {
+			currencies = append(currencies, currency)
+		}
+	}
+	return currencies
+}
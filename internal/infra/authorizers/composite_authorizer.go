@@ -0,0 +1,52 @@
+package authorizers
+
+import (
+	"context"
+	"errors"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+var ErrNoAuthorizers = errors.New("composite authorizer needs at least one authorizer")
+
+// CompositeAuthorizer asks each authorizer in order. The first denial ends
+// the chain; a review is reported only if no later authorizer denies; when
+// all approve, the last decision is returned.
+type CompositeAuthorizer struct {
+	authorizers []port.AuthorizationService
+}
+
+// NewCompositeAuthorizer refuses an empty chain, which would approve every
+// transfer with an empty decision.
+func NewCompositeAuthorizer(authorizers ...port.AuthorizationService) (*CompositeAuthorizer, error) {
+	if len(authorizers) == 0 {
+		return nil, ErrNoAuthorizers
+	}
+	return &CompositeAuthorizer{authorizers: authorizers}, nil
+}
+
+func (c *CompositeAuthorizer) Authorize(ctx context.Context, request port.AuthorizationRequest) (entities.AuthorizationDecision, error) {
+	var approved entities.AuthorizationDecision
+	var review *entities.AuthorizationDecision
+	for _, authorizer := range c.authorizers {
+		decision, err := authorizer.Authorize(ctx, request)
+		if err != nil {
+			return entities.AuthorizationDecision{}, err
+		}
+		switch decision.Outcome {
+		case entities.AuthorizationApproved:
+			approved = decision
+		case entities.AuthorizationReview:
+			if review == nil {
+				review = &decision
+			}
+		default:
+			return decision, nil
+		}
+	}
+	if review != nil {
+		return *review, nil
+	}
+	return approved, nil
+}
@@ -0,0 +1,81 @@
+package authorizers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedAuthorizer struct {
+	decision entities.AuthorizationDecision
+	err      error
+	calls    int
+}
+
+func (f *fixedAuthorizer) Authorize(ctx context.Context, request port.AuthorizationRequest) (entities.AuthorizationDecision, error) {
+	f.calls++
+	return f.decision, f.err
+}
+
+func decide(outcome entities.AuthorizationOutcome, reference string) *fixedAuthorizer {
+	return &fixedAuthorizer{decision: entities.AuthorizationDecision{Outcome: outcome, Reference: reference}}
+}
+
+func composite(t *testing.T, authorizers ...port.AuthorizationService) *CompositeAuthorizer {
+	t.Helper()
+	c, err := NewCompositeAuthorizer(authorizers...)
+	assert.NoError(t, err)
+	return c
+}
+
+func TestCompositeAuthorizer_RequiresAuthorizers(t *testing.T) {
+	c, err := NewCompositeAuthorizer()
+
+	assert.ErrorIs(t, err, ErrNoAuthorizers)
+	assert.Nil(t, c)
+}
+
+func TestCompositeAuthorizer_AllApprove(t *testing.T) {
+	local, remote := decide(entities.AuthorizationApproved, "local"), decide(entities.AuthorizationApproved, "remote")
+
+	decision, err := composite(t, local, remote).Authorize(context.Background(), request(100))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", decision.Reference)
+}
+
+func TestCompositeAuthorizer_DenialStopsChain(t *testing.T) {
+	local, remote := decide(entities.AuthorizationDenied, "local"), decide(entities.AuthorizationApproved, "remote")
+
+	decision, err := composite(t, local, remote).Authorize(context.Background(), request(100))
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AuthorizationDenied, decision.Outcome)
+	assert.Equal(t, 0, remote.calls)
+}
+
+func TestCompositeAuthorizer_ReviewUnlessLaterDenied(t *testing.T) {
+	review := decide(entities.AuthorizationReview, "local")
+
+	decision, err := composite(t, review, decide(entities.AuthorizationApproved, "remote")).Authorize(context.Background(), request(100))
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AuthorizationReview, decision.Outcome)
+	assert.Equal(t, "local", decision.Reference)
+
+	decision, err = composite(t, review, decide(entities.AuthorizationDenied, "remote")).Authorize(context.Background(), request(100))
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AuthorizationDenied, decision.Outcome)
+}
+
+func TestCompositeAuthorizer_PropagatesErrors(t *testing.T) {
+	failing := &fixedAuthorizer{err: errors.New("unavailable")}
+
+	_, err := composite(t, decide(entities.AuthorizationApproved, "local"), failing).Authorize(context.Background(), request(100))
+
+	assert.EqualError(t, err, "unavailable")
+}
@@ -0,0 +1,158 @@
+package authorizers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata"
+
+	"go-transfer/internal/domain/entities"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidRules = errors.New("invalid authorization rules")
+
+// Reason codes reported by RulesAuthorizer.
+const (
+	ReasonBlockedPayee         = "BLOCKED_PAYEE"
+	ReasonWalletPairNotAllowed = "WALLET_PAIR_NOT_ALLOWED"
+	ReasonAmountLimit          = "AMOUNT_LIMIT"
+	ReasonOutsideTimeWindow    = "OUTSIDE_TIME_WINDOW"
+	ReasonDailyLimit           = "DAILY_LIMIT"
+)
+
+// rulesFile is the on-disk shape of the rules. Being YAML, the parser also
+// accepts the equivalent JSON document.
+type rulesFile struct {
+	Timezone           string           `yaml:"timezone"`
+	Currency           string           `yaml:"currency"`
+	MaxAmount          string           `yaml:"max_amount"`
+	DailySenderLimit   string           `yaml:"daily_sender_limit"`
+	BlockedPayees      []int64          `yaml:"blocked_payees"`
+	AllowedWalletPairs []walletPairFile `yaml:"allowed_wallet_pairs"`
+	TimeWindows        []timeWindowFile `yaml:"time_windows"`
+}
+
+type walletPairFile struct {
+	Payer entities.WalletType `yaml:"payer"`
+	Payee entities.WalletType `yaml:"payee"`
+}
+
+type timeWindowFile struct {
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+type walletPair struct {
+	payer entities.WalletType
+	payee entities.WalletType
+}
+
+// timeWindow is a daily window in minutes since midnight. A window whose end
+// is before its start crosses midnight.
+type timeWindow struct {
+	start int
+	end   int
+}
+
+func (w timeWindow) contains(minute int) bool {
+	if w.start <= w.end {
+		return minute >= w.start && minute < w.end
+	}
+	return minute >= w.start || minute < w.end
+}
+
+// Rules is a parsed, immutable rule set. Empty rules allow everything.
+type Rules struct {
+	version            string
+	location           *time.Location
+	maxAmount          *entities.Money
+	dailySenderLimit   *entities.Money
+	blockedPayees      map[int64]bool
+	allowedWalletPairs map[walletPair]bool
+	timeWindows        []timeWindow
+}
+
+// Version identifies the rule set by the hash of its source.
+func (r *Rules) Version() string {
+	return r.version
+}
+
+func ParseRules(data []byte) (*Rules, error) {
+	var file rulesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+
+	sum := sha256.Sum256(data)
+	rules := &Rules{
+		version:            hex.EncodeToString(sum[:6]),
+		location:           time.UTC,
+		blockedPayees:      make(map[int64]bool, len(file.BlockedPayees)),
+		allowedWalletPairs: make(map[walletPair]bool, len(file.AllowedWalletPairs)),
+	}
+
+	if file.Timezone != "" {
+		location, err := time.LoadLocation(file.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("%w: timezone: %v", ErrInvalidRules, err)
+		}
+		rules.location = location
+	}
+	currency, err := entities.ParseCurrency(file.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("%w: currency: %v", ErrInvalidRules, err)
+	}
+	if rules.maxAmount, err = parseLimit("max_amount", file.MaxAmount, currency); err != nil {
+		return nil, err
+	}
+	if rules.dailySenderLimit, err = parseLimit("daily_sender_limit", file.DailySenderLimit, currency); err != nil {
+		return nil, err
+	}
+	for _, payeeID := range file.BlockedPayees {
+		rules.blockedPayees[payeeID] = true
+	}
+	for _, pair := range file.AllowedWalletPairs {
+		if pair.Payer == "" || pair.Payee == "" {
+			return nil, fmt.Errorf("%w: allowed_wallet_pairs entries need payer and payee", ErrInvalidRules)
+		}
+		rules.allowedWalletPairs[walletPair{payer: pair.Payer, payee: pair.Payee}] = true
+	}
+	for _, window := range file.TimeWindows {
+		start, err := parseClock(window.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(window.End)
+		if err != nil {
+			return nil, err
+		}
+		if start == end {
+			return nil, fmt.Errorf("%w: time window %s-%s is empty", ErrInvalidRules, window.Start, window.End)
+		}
+		rules.timeWindows = append(rules.timeWindows, timeWindow{start: start, end: end})
+	}
+	return rules, nil
+}
+
+func parseLimit(name, value, currency string) (*entities.Money, error) {
+	if value == "" {
+		return nil, nil
+	}
+	limit, err := entities.ParseMoney(value, currency)
+	if err != nil || !limit.IsPositive() {
+		return nil, fmt.Errorf("%w: %s must be a positive amount", ErrInvalidRules, name)
+	}
+	return &limit, nil
+}
+
+func parseClock(value string) (int, error) {
+	clock, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q is not a HH:MM time", ErrInvalidRules, value)
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
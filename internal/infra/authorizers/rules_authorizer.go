@@ -0,0 +1,168 @@
+package authorizers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+)
+
+// RulesAuthorizer decides transfers locally from a rules file. Reload swaps
+// in a new version of the file without restarting; an invalid file is
+// rejected and the previous rules stay in force. Amounts in the rules are
+// converted through rates for transfers in other currencies; rates may be
+// nil when there are none.
+type RulesAuthorizer struct {
+	path            string
+	transactionRepo port.TransactionRepository
+	rates           port.ExchangeRateProvider
+	now             func() time.Time
+
+	rules    atomic.Pointer[Rules]
+	reloadMu sync.Mutex
+	source   []byte
+}
+
+func NewRulesAuthorizer(path string, transactionRepo port.TransactionRepository, rates port.ExchangeRateProvider) (*RulesAuthorizer, error) {
+	authorizer := &RulesAuthorizer{
+		path:            path,
+		transactionRepo: transactionRepo,
+		rates:           rates,
+		now:             time.Now,
+	}
+	if _, err := authorizer.Reload(context.Background()); err != nil {
+		return nil, err
+	}
+	return authorizer, nil
+}
+
+// Reload re-reads the rules file and reports whether the rules changed.
+func (a *RulesAuthorizer) Reload(ctx context.Context) (bool, error) {
+	a.reloadMu.Lock()
+	defer a.reloadMu.Unlock()
+
+	data, err := os.ReadFile(a.path)
+	if err != nil {
+		return false, fmt.Errorf("read authorization rules: %w", err)
+	}
+	if a.source != nil && bytes.Equal(data, a.source) {
+		return false, nil
+	}
+	rules, err := ParseRules(data)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", a.path, err)
+	}
+	a.rules.Store(rules)
+	a.source = data
+	return true, nil
+}
+
+func (a *RulesAuthorizer) Authorize(ctx context.Context, request port.AuthorizationRequest) (entities.AuthorizationDecision, error) {
+	rules := a.rules.Load()
+	reason, err := a.evaluate(ctx, rules, request)
+	if err != nil {
+		return entities.AuthorizationDecision{}, err
+	}
+
+	decision := entities.AuthorizationDecision{
+		Outcome:   entities.AuthorizationApproved,
+		Reference: "rules:" + rules.Version(),
+	}
+	if reason != "" {
+		decision.Outcome = entities.AuthorizationDenied
+		decision.ReasonCode = reason
+	}
+	return decision, nil
+}
+
+// evaluate returns the reason code of the first rule the request breaks, or
+// "" if it breaks none. The daily limit needs a query and is checked last.
+func (a *RulesAuthorizer) evaluate(ctx context.Context, rules *Rules, request port.AuthorizationRequest) (string, error) {
+	// Every payee of a split transfer is checked, so a blocked one cannot be
+	// paid as a share of it.
+	payees := []port.AuthorizationSplit{{PayeeID: request.PayeeID, PayeeWalletType: request.PayeeWalletType}}
+	if len(request.Splits) > 0 {
+		payees = request.Splits
+	}
+	for _, payee := range payees {
+		if rules.blockedPayees[payee.PayeeID] {
+			return ReasonBlockedPayee, nil
+		}
+	}
+	for _, payee := range payees {
+		if len(rules.allowedWalletPairs) > 0 && !rules.allowedWalletPairs[walletPair{payer: request.PayerWalletType, payee: payee.PayeeWalletType}] {
+			return ReasonWalletPairNotAllowed, nil
+		}
+	}
+	if rules.maxAmount != nil {
+		exceeded, err := a.exceeds(ctx, request.Amount, *rules.maxAmount)
+		if err != nil {
+			return "", err
+		}
+		if exceeded {
+			return ReasonAmountLimit, nil
+		}
+	}
+
+	now := a.now().In(rules.location)
+	if len(rules.timeWindows) > 0 && !insideAnyWindow(rules.timeWindows, now) {
+		return ReasonOutsideTimeWindow, nil
+	}
+
+	if rules.dailySenderLimit != nil {
+		dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, rules.location)
+		sent, err := a.transactionRepo.SumSent(ctx, request.PayerID, dayStart, dayStart.AddDate(0, 0, 1))
+		if err != nil {
+			return "", err
+		}
+		// What the payer sent is in the currency of their wallet, as is the
+		// amount of the request.
+		total, err := entities.NewMoney(sent.Cents, request.Amount.Currency).Add(request.Amount)
+		if err != nil {
+			return "", err
+		}
+		exceeded, err := a.exceeds(ctx, total, *rules.dailySenderLimit)
+		if err != nil {
+			return "", err
+		}
+		if exceeded {
+			return ReasonDailyLimit, nil
+		}
+	}
+	return "", nil
+}
+
+// exceeds reports whether amount is above limit, converting limit to the
+// currency of amount at the current rate.
+func (a *RulesAuthorizer) exceeds(ctx context.Context, amount, limit entities.Money) (bool, error) {
+	if limit.Currency != amount.Currency {
+		if a.rates == nil {
+			return false, fmt.Errorf("authorization rules in %s cannot judge a transfer in %s", limit.Currency, amount.Currency)
+		}
+		rate, err := a.rates.Rate(ctx, limit.Currency, amount.Currency)
+		if err != nil {
+			return false, err
+		}
+		if limit, err = rate.Convert(limit, amount.Currency); err != nil {
+			return false, err
+		}
+	}
+	cmp, err := amount.Cmp(limit)
+	return cmp > 0, err
+}
+
+func insideAnyWindow(windows []timeWindow, now time.Time) bool {
+	minute := now.Hour()*60 + now.Minute()
+	for _, window := range windows {
+		if window.contains(minute) {
+			return true
+		}
+	}
+	return false
+}
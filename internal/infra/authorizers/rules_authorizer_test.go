@@ -0,0 +1,246 @@
+package authorizers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRules = `
+timezone: America/Sao_Paulo
+max_amount: "5000.00"
+daily_sender_limit: "8000.00"
+blocked_payees: [13]
+allowed_wallet_pairs:
+  - payer: COMMON
+    payee: COMMON
+  - payer: COMMON
+    payee: MERCHANT
+time_windows:
+  - start: "06:00"
+    end: "23:00"
+`
+
+// sentTotals stubs the daily total lookup of the transaction repository.
+type sentTotals struct {
+	port.TransactionRepository
+	sent map[int64]entities.Money
+	from time.Time
+	to   time.Time
+}
+
+func (s *sentTotals) SumSent(ctx context.Context, senderID int64, from, to time.Time) (entities.Money, error) {
+	s.from, s.to = from, to
+	if total, ok := s.sent[senderID]; ok {
+		return total, nil
+	}
+	return entities.MoneyFromCents(0), nil
+}
+
+func writeRules(t *testing.T, path, content string) {
+	t.Helper()
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func newTestRulesAuthorizer(t *testing.T, content string, totals *sentTotals) *RulesAuthorizer {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	writeRules(t, path, content)
+	authorizer, err := NewRulesAuthorizer(path, totals, nil)
+	assert.NoError(t, err)
+	// 12:00 in São Paulo.
+	authorizer.now = func() time.Time { return time.Date(2025, 1, 10, 15, 0, 0, 0, time.UTC) }
+	return authorizer
+}
+
+func request(amount int64) port.AuthorizationRequest {
+	return port.AuthorizationRequest{
+		TransferID:      1,
+		PayerID:         1,
+		PayeeID:         2,
+		Amount:          entities.MoneyFromCents(amount),
+		PayerWalletType: entities.CommonWallet,
+		PayeeWalletType: entities.MerchantWallet,
+	}
+}
+
+func TestRulesAuthorizer_Approves(t *testing.T) {
+	authorizer := newTestRulesAuthorizer(t, testRules, &sentTotals{})
+
+	decision, err := authorizer.Authorize(context.Background(), request(1000))
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AuthorizationApproved, decision.Outcome)
+	assert.Equal(t, "rules:"+authorizer.rules.Load().Version(), decision.Reference)
+}
+
+func TestRulesAuthorizer_Denies(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*port.AuthorizationRequest)
+		reason string
+	}{
+		{"blocked payee", func(r *port.AuthorizationRequest) { r.PayeeID = 13 }, ReasonBlockedPayee},
+		{"wallet pair", func(r *port.AuthorizationRequest) { r.PayeeWalletType = entities.SystemWallet }, ReasonWalletPairNotAllowed},
+		{"blocked split payee", func(r *port.AuthorizationRequest) {
+			r.Splits = []port.AuthorizationSplit{
+				{PayeeID: 2, Amount: entities.MoneyFromCents(600), PayeeWalletType: entities.MerchantWallet},
+				{PayeeID: 13, Amount: entities.MoneyFromCents(400), PayeeWalletType: entities.MerchantWallet},
+			}
+		}, ReasonBlockedPayee},
+		{"split wallet pair", func(r *port.AuthorizationRequest) {
+			r.Splits = []port.AuthorizationSplit{
+				{PayeeID: 2, Amount: entities.MoneyFromCents(600), PayeeWalletType: entities.MerchantWallet},
+				{PayeeID: 3, Amount: entities.MoneyFromCents(400), PayeeWalletType: entities.SystemWallet},
+			}
+		}, ReasonWalletPairNotAllowed},
+		{"max amount", func(r *port.AuthorizationRequest) { r.Amount = entities.MoneyFromCents(500001) }, ReasonAmountLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer := newTestRulesAuthorizer(t, testRules, &sentTotals{})
+			req := request(1000)
+			tt.modify(&req)
+
+			decision, err := authorizer.Authorize(context.Background(), req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, entities.AuthorizationDenied, decision.Outcome)
+			assert.Equal(t, tt.reason, decision.ReasonCode)
+		})
+	}
+}
+
+func TestRulesAuthorizer_OutsideTimeWindow(t *testing.T) {
+	authorizer := newTestRulesAuthorizer(t, testRules, &sentTotals{})
+	// 02:30 in São Paulo.
+	authorizer.now = func() time.Time { return time.Date(2025, 1, 10, 5, 30, 0, 0, time.UTC) }
+
+	decision, err := authorizer.Authorize(context.Background(), request(1000))
+
+	assert.NoError(t, err)
+	assert.Equal(t, ReasonOutsideTimeWindow, decision.ReasonCode)
+}
+
+func TestRulesAuthorizer_WindowAcrossMidnight(t *testing.T) {
+	authorizer := newTestRulesAuthorizer(t, "time_windows:\n  - start: \"22:00\"\n    end: \"02:00\"\n", &sentTotals{})
+
+	authorizer.now = func() time.Time { return time.Date(2025, 1, 10, 1, 0, 0, 0, time.UTC) }
+	decision, _ := authorizer.Authorize(context.Background(), request(1000))
+	assert.Equal(t, entities.AuthorizationApproved, decision.Outcome)
+
+	authorizer.now = func() time.Time { return time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC) }
+	decision, _ = authorizer.Authorize(context.Background(), request(1000))
+	assert.Equal(t, ReasonOutsideTimeWindow, decision.ReasonCode)
+}
+
+func TestRulesAuthorizer_DailySenderLimit(t *testing.T) {
+	totals := &sentTotals{sent: map[int64]entities.Money{1: entities.MoneyFromCents(750000)}}
+	authorizer := newTestRulesAuthorizer(t, testRules, totals)
+
+	decision, err := authorizer.Authorize(context.Background(), request(50000))
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AuthorizationApproved, decision.Outcome, "exactly reaching the limit is allowed")
+
+	decision, err = authorizer.Authorize(context.Background(), request(50001))
+	assert.NoError(t, err)
+	assert.Equal(t, ReasonDailyLimit, decision.ReasonCode)
+
+	saoPaulo, _ := time.LoadLocation("America/Sao_Paulo")
+	assert.True(t, totals.from.Equal(time.Date(2025, 1, 10, 0, 0, 0, 0, saoPaulo)))
+	assert.True(t, totals.to.Equal(time.Date(2025, 1, 11, 0, 0, 0, 0, saoPaulo)))
+}
+
+// fixedRates converts every pair at one rate.
+type fixedRates struct{ rate entities.ExchangeRate }
+
+func (r fixedRates) Rate(ctx context.Context, from, to string) (entities.ExchangeRate, error) {
+	return r.rate, nil
+}
+
+func TestRulesAuthorizer_ConvertsAmountsToTransferCurrency(t *testing.T) {
+	totals := &sentTotals{sent: map[int64]entities.Money{1: entities.NewMoney(100000, "USD")}}
+	authorizer := newTestRulesAuthorizer(t, testRules, totals)
+	rate, _ := entities.ParseExchangeRate("0.2")
+	authorizer.rates = fixedRates{rate: rate}
+	usd := func(cents int64) port.AuthorizationRequest {
+		r := request(cents)
+		r.Amount = entities.NewMoney(cents, "USD")
+		return r
+	}
+
+	// max_amount is 5000.00 BRL, 1000.00 USD.
+	decision, err := authorizer.Authorize(context.Background(), usd(100001))
+	assert.NoError(t, err)
+	assert.Equal(t, ReasonAmountLimit, decision.ReasonCode)
+
+	// daily_sender_limit is 8000.00 BRL, 1600.00 USD.
+	decision, err = authorizer.Authorize(context.Background(), usd(60000))
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AuthorizationApproved, decision.Outcome)
+	decision, err = authorizer.Authorize(context.Background(), usd(60001))
+	assert.NoError(t, err)
+	assert.Equal(t, ReasonDailyLimit, decision.ReasonCode)
+}
+
+func TestRulesAuthorizer_AmountsInRulesCurrency(t *testing.T) {
+	authorizer := newTestRulesAuthorizer(t, "currency: usd\nmax_amount: \"10.00\"\n", &sentTotals{})
+	usd := request(1001)
+	usd.Amount = entities.NewMoney(1001, "USD")
+
+	decision, err := authorizer.Authorize(context.Background(), usd)
+	assert.NoError(t, err)
+	assert.Equal(t, ReasonAmountLimit, decision.ReasonCode)
+
+	_, err = authorizer.Authorize(context.Background(), request(1001))
+	assert.Error(t, err, "rules in USD cannot judge BRL without rates")
+}
+
+func TestRulesAuthorizer_AcceptsJSON(t *testing.T) {
+	authorizer := newTestRulesAuthorizer(t, `{"max_amount": "10.00", "blocked_payees": [7]}`, &sentTotals{})
+
+	decision, err := authorizer.Authorize(context.Background(), request(1001))
+
+	assert.NoError(t, err)
+	assert.Equal(t, ReasonAmountLimit, decision.ReasonCode)
+}
+
+func TestRulesAuthorizer_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	writeRules(t, path, `max_amount: "100.00"`)
+	authorizer, err := NewRulesAuthorizer(path, &sentTotals{}, nil)
+	assert.NoError(t, err)
+
+	changed, err := authorizer.Reload(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	writeRules(t, path, `max_amount: "5.00"`)
+	changed, err = authorizer.Reload(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	decision, _ := authorizer.Authorize(context.Background(), request(1000))
+	assert.Equal(t, ReasonAmountLimit, decision.ReasonCode)
+
+	writeRules(t, path, `max_amount: "not money"`)
+	_, err = authorizer.Reload(context.Background())
+	assert.ErrorIs(t, err, ErrInvalidRules)
+	decision, _ = authorizer.Authorize(context.Background(), request(1000))
+	assert.Equal(t, ReasonAmountLimit, decision.ReasonCode, "invalid rules keep the previous version in force")
+}
+
+func TestNewRulesAuthorizer_RejectsInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	writeRules(t, path, "time_windows:\n  - start: \"25:00\"\n    end: \"02:00\"\n")
+
+	_, err := NewRulesAuthorizer(path, &sentTotals{}, nil)
+
+	assert.ErrorIs(t, err, ErrInvalidRules)
+}
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type moneyColumn struct {
+	table  string
+	column string
+}
+
+var moneyColumns = []moneyColumn{
+	{table: "wallets", column: "balance"},
+	{table: "transactions", column: "amount"},
+	{table: "notifications", column: "amount"},
+}
+
+// MigrateMoneyColumns converts amounts previously stored as double precision
+// into exact numeric(20,2) values, rounding each row to the nearest cent.
+func MigrateMoneyColumns(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, money := range moneyColumns {
+			var dataType string
+			err := tx.Raw(
+				"SELECT data_type FROM information_schema.columns WHERE table_schema = CURRENT_SCHEMA() AND table_name = ? AND column_name = ?",
+				money.table, money.column,
+			).Scan(&dataType).Error
+			if err != nil {
+				return err
+			}
+			if dataType != "double precision" {
+				continue
+			}
+
+			fmt.Printf("Migrating %s.%s to numeric...\n", money.table, money.column)
+			err = tx.Exec(fmt.Sprintf(
+				"ALTER TABLE %q ALTER COLUMN %q TYPE numeric(20,2) USING round(%q::numeric, 2)",
+				money.table, money.column, money.column,
+			)).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+const senderReferenceIndex = "idx_transactions_sender_reference"
+
+// MigrateSenderReferenceIndex drops the unique index on the external
+// references of each sender if it still covers failed transfers, so that
+// AutoMigrate creates it again without them.
+func MigrateSenderReferenceIndex(db *gorm.DB) error {
+	var definition string
+	err := db.Raw(
+		"SELECT indexdef FROM pg_indexes WHERE schemaname = CURRENT_SCHEMA() AND indexname = ?",
+		senderReferenceIndex,
+	).Scan(&definition).Error
+	if err != nil {
+		return err
+	}
+	if definition == "" || strings.Contains(definition, " WHERE ") {
+		return nil
+	}
+
+	fmt.Printf("Migrating %s to leave out failed transfers...\n", senderReferenceIndex)
+	return db.Exec(fmt.Sprintf("DROP INDEX %q", senderReferenceIndex)).Error
+}
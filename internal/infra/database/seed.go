@@ -0,0 +1,104 @@
+package database
+
+import (
+	"fmt"
+
+	"go-transfer/internal/domain/entities"
+
+	"gorm.io/gorm"
+)
+
+const platformDocument = "PLATFORM"
+
+var systemAccounts = []entities.SystemAccount{
+	entities.SystemAccountFunding,
+	entities.SystemAccountRevenue,
+	entities.SystemAccountExchange,
+}
+
+// SeedSystemAccounts makes sure the platform user and its system wallets, one
+// per account and currency, exist. Ledger postings that bring money in or out
+// of the platform use them as the counterpart account.
+func SeedSystemAccounts(db *gorm.DB, currencies []string) error {
+	fmt.Println("Seeding system accounts...")
+	return db.Transaction(func(tx *gorm.DB) error {
+		platform := &entities.User{}
+		err := tx.Where(entities.User{Document: platformDocument}).
+			Attrs(entities.User{FullName: "Go-Transfer Platform", Email: "platform@go-transfer.local"}).
+			FirstOrCreate(platform).Error
+		if err != nil {
+			return err
+		}
+
+		for _, account := range systemAccounts {
+			for _, currency := range currencies {
+				account := account
+				wallet := &entities.Wallet{}
+				err := tx.Where("system_account = ? AND currency = ?", account, currency).
+					Attrs(entities.Wallet{OwnerID: platform.ID, Type: entities.SystemWallet, SystemAccount: &account, Currency: currency}).
+					FirstOrCreate(wallet).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// BackfillOpeningBalances records a deposit for every wallet whose balance
+// predates the ledger, so that balances can be traced back to ledger entries.
+func BackfillOpeningBalances(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var wallets []entities.Wallet
+		err := tx.Where("system_account IS NULL AND balance > 0").
+			Where("NOT EXISTS (SELECT 1 FROM ledger_entries WHERE ledger_entries.wallet_id = wallets.id)").
+			Find(&wallets).Error
+		if err != nil {
+			return err
+		}
+
+		for _, wallet := range wallets {
+			funding := &entities.Wallet{}
+			err := tx.Where("system_account = ? AND currency = ?", entities.SystemAccountFunding, wallet.CurrencyCode()).First(funding).Error
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Backfilling opening balance for wallet %d...\n", wallet.ID)
+			transaction := &entities.Transaction{
+				SenderID:   funding.OwnerID,
+				ReceiverID: wallet.OwnerID,
+				Amount:     wallet.Balance,
+				Currency:   wallet.CurrencyCode(),
+				Type:       entities.TransactionTypeDeposit,
+				Status:     entities.TransactionStatusCompleted,
+			}
+			if err := tx.Create(transaction).Error; err != nil {
+				return err
+			}
+			change := &entities.TransactionStatusChange{
+				TransactionID: transaction.ID,
+				ToStatus:      transaction.Status,
+				Reason:        "opening balance",
+			}
+			if err := tx.Create(change).Error; err != nil {
+				return err
+			}
+
+			entries := entities.NewTransferPosting(transaction.ID, funding.ID, wallet.ID, wallet.Balance)
+			if err := tx.Omit("Transaction", "Wallet").Create(&entries).Error; err != nil {
+				return err
+			}
+
+			err = tx.Model(&entities.Wallet{}).Where("id = ?", funding.ID).Updates(map[string]interface{}{
+				"balance": gorm.Expr("balance - ?", wallet.Balance),
+				"version": gorm.Expr("version + 1"),
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -28,9 +28,23 @@ func SetupDB(config *env.Config) (*gorm.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	err = SeedSystemAccounts(db, config.Currencies)
+	if err != nil {
+		return nil, err
+	}
+	err = BackfillOpeningBalances(db)
+	if err != nil {
+		return nil, err
+	}
 	return db, nil
 }
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&entities.User{}, &entities.Wallet{}, &entities.Transaction{}, &entities.Notification{})
+	if err := MigrateMoneyColumns(db); err != nil {
+		return err
+	}
+	if err := MigrateSenderReferenceIndex(db); err != nil {
+		return err
+	}
+	return db.AutoMigrate(&entities.User{}, &entities.Wallet{}, &entities.Transaction{}, &entities.TransactionLeg{}, &entities.ScheduledTransfer{}, &entities.ScheduledTransferRun{}, &entities.TransferBatch{}, &entities.TransferBatchItem{}, &entities.Notification{}, &entities.IdempotencyRecord{}, &entities.LedgerEntry{}, &entities.TransactionStatusChange{}, &entities.OutboxMessage{}, &entities.UserTransferLimit{}, &entities.TransferCounter{}, &entities.ExchangeQuote{})
 }
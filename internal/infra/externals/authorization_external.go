@@ -4,37 +4,77 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"go-transfer/internal/domain/entities"
 	"go-transfer/internal/domain/port"
 	"io"
 	"net/http"
+	"strconv"
 )
 
 type AuthorizationServiceImpl struct {
 	baseURL string
+	client  *http.Client
+	breaker *CircuitBreaker
 }
 
-func NewAuthorizationService(baseURL string) port.AuthorizationService {
+func NewAuthorizationService(baseURL string, client *http.Client, breaker *CircuitBreaker) port.AuthorizationService {
 	return &AuthorizationServiceImpl{
 		baseURL: baseURL,
+		client:  client,
+		breaker: breaker,
 	}
 }
 
 type AuthorizationResponse struct {
 	Status string `json:"status"`
 	Data   struct {
-		Authorization bool `json:"authorization"`
+		Authorization bool   `json:"authorization"`
+		Review        bool   `json:"review"`
+		ReasonCode    string `json:"reason_code"`
+		Reference     string `json:"reference"`
 	} `json:"data"`
 }
 
-func (s *AuthorizationServiceImpl) Authorize(ctx context.Context) (bool, error) {
+const reasonRemoteDenied = "REMOTE_DENIED"
+
+func (s *AuthorizationServiceImpl) Authorize(ctx context.Context, request port.AuthorizationRequest) (entities.AuthorizationDecision, error) {
+	var decision entities.AuthorizationDecision
+	err := s.breaker.Execute(func() error {
+		var err error
+		decision, err = s.authorize(ctx, request)
+		return err
+	}, isUpstreamFailure(ctx))
+	return decision, err
+}
+
+func (s *AuthorizationServiceImpl) authorize(ctx context.Context, request port.AuthorizationRequest) (entities.AuthorizationDecision, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL, nil)
 	if err != nil {
-		return false, err
+		return entities.AuthorizationDecision{}, err
+	}
+	query := req.URL.Query()
+	query.Set("transfer_id", strconv.FormatInt(request.TransferID, 10))
+	query.Set("payer", strconv.FormatInt(request.PayerID, 10))
+	query.Set("payee", strconv.FormatInt(request.PayeeID, 10))
+	query.Set("amount", request.Amount.String())
+	query.Set("currency", request.Amount.Currency)
+	query.Set("payer_wallet_type", string(request.PayerWalletType))
+	query.Set("payee_wallet_type", string(request.PayeeWalletType))
+	// Split transfers send one value of each split_ parameter per payee.
+	for _, split := range request.Splits {
+		query.Add("split_payee", strconv.FormatInt(split.PayeeID, 10))
+		query.Add("split_amount", split.Amount.String())
+		query.Add("split_currency", split.Amount.Currency)
+		query.Add("split_payee_wallet_type", string(split.PayeeWalletType))
 	}
+	req.URL.RawQuery = query.Encode()
+	setHeaderIfPresent(req, "X-Client-IP", request.Client.IPAddress)
+	setHeaderIfPresent(req, "X-Client-User-Agent", request.Client.UserAgent)
+	setHeaderIfPresent(req, "X-Device-ID", request.Client.DeviceID)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := s.client.Do(req)
 	if err != nil {
-		return false, err
+		return entities.AuthorizationDecision{}, fmt.Errorf("%w: authorization request failed: %v", port.ErrServiceUnavailable, err)
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -43,12 +83,40 @@ func (s *AuthorizationServiceImpl) Authorize(ctx context.Context) (bool, error)
 		}
 	}(resp.Body)
 
+	if status := (&statusError{code: resp.StatusCode}); status.unhealthy() {
+		return entities.AuthorizationDecision{}, fmt.Errorf("%w: authorization service returned %w", port.ErrServiceUnavailable, status)
+	}
+
 	var authResp AuthorizationResponse
 	err = json.NewDecoder(resp.Body).Decode(&authResp)
 	if err != nil {
+		return entities.AuthorizationDecision{}, fmt.Errorf("%w: invalid authorization response: %v", port.ErrServiceUnavailable, err)
+	}
+	return authResp.decision(), nil
+}
 
-		return false, err
+func (r AuthorizationResponse) decision() entities.AuthorizationDecision {
+	decision := entities.AuthorizationDecision{
+		ReasonCode: r.Data.ReasonCode,
+		Reference:  r.Data.Reference,
 	}
 	statusSuccess := "success"
-	return authResp.Status == statusSuccess && authResp.Data.Authorization, nil
+	switch {
+	case r.Status == statusSuccess && r.Data.Authorization:
+		decision.Outcome = entities.AuthorizationApproved
+	case r.Data.Review:
+		decision.Outcome = entities.AuthorizationReview
+	default:
+		decision.Outcome = entities.AuthorizationDenied
+		if decision.ReasonCode == "" {
+			decision.ReasonCode = reasonRemoteDenied
+		}
+	}
+	return decision
+}
+
+func setHeaderIfPresent(req *http.Request, key, value string) {
+	if value != "" {
+		req.Header.Set(key, value)
+	}
 }
@@ -0,0 +1,168 @@
+package externals
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testAuthorizationRequest = port.AuthorizationRequest{
+	TransferID:      42,
+	PayerID:         1,
+	PayeeID:         2,
+	Amount:          entities.MoneyFromCents(10050),
+	PayerWalletType: entities.CommonWallet,
+	PayeeWalletType: entities.MerchantWallet,
+	Client:          port.ClientMetadata{IPAddress: "203.0.113.7", UserAgent: "app/1.0"},
+}
+
+func newTestAuthorizationService(t *testing.T, handler http.HandlerFunc, timeout time.Duration, threshold int) port.AuthorizationService {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	breaker := NewCircuitBreaker(t.Name(), BreakerSettings{FailureThreshold: threshold, OpenTimeout: time.Minute})
+	return NewAuthorizationService(server.URL, NewHTTPClient(timeout), breaker)
+}
+
+func TestAuthorizationService_Authorized(t *testing.T) {
+	service := newTestAuthorizationService(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"authorization":true}}`))
+	}, time.Second, 3)
+
+	decision, err := service.Authorize(context.Background(), testAuthorizationRequest)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AuthorizationApproved, decision.Outcome)
+}
+
+func TestAuthorizationService_DeniedIsNotAFailure(t *testing.T) {
+	calls := 0
+	service := newTestAuthorizationService(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"status":"fail","data":{"authorization":false}}`))
+	}, time.Second, 1)
+
+	for i := 0; i < 3; i++ {
+		decision, err := service.Authorize(context.Background(), testAuthorizationRequest)
+		assert.NoError(t, err)
+		assert.Equal(t, entities.AuthorizationDecision{Outcome: entities.AuthorizationDenied, ReasonCode: reasonRemoteDenied}, decision)
+	}
+	assert.Equal(t, 3, calls)
+}
+
+func TestAuthorizationService_TimesOutSlowUpstream(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	service := newTestAuthorizationService(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}, 50*time.Millisecond, 3)
+
+	start := time.Now()
+	_, err := service.Authorize(context.Background(), testAuthorizationRequest)
+
+	assert.ErrorIs(t, err, port.ErrServiceUnavailable)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestAuthorizationService_OpensCircuitOnServerErrors(t *testing.T) {
+	calls := 0
+	service := newTestAuthorizationService(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}, time.Second, 2)
+
+	for i := 0; i < 2; i++ {
+		_, err := service.Authorize(context.Background(), testAuthorizationRequest)
+		assert.ErrorIs(t, err, port.ErrServiceUnavailable)
+	}
+	_, err := service.Authorize(context.Background(), testAuthorizationRequest)
+
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.ErrorIs(t, err, port.ErrServiceUnavailable)
+	assert.Equal(t, 2, calls)
+}
+
+func TestAuthorizationService_CallerCancellationDoesNotOpenCircuit(t *testing.T) {
+	calls := 0
+	service := newTestAuthorizationService(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`{"status":"success","data":{"authorization":true}}`))
+	}, time.Second, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := service.Authorize(ctx, testAuthorizationRequest)
+	assert.Error(t, err)
+
+	decision, err := service.Authorize(context.Background(), testAuthorizationRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AuthorizationApproved, decision.Outcome)
+}
+
+func TestAuthorizationService_SendsTransferContext(t *testing.T) {
+	var query url.Values
+	var header http.Header
+	service := newTestAuthorizationService(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		header = r.Header
+		_, _ = w.Write([]byte(`{"status":"success","data":{"authorization":true,"reference":"auth-42"}}`))
+	}, time.Second, 3)
+
+	decision, err := service.Authorize(context.Background(), testAuthorizationRequest)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "auth-42", decision.Reference)
+	assert.Equal(t, "42", query.Get("transfer_id"))
+	assert.Equal(t, "1", query.Get("payer"))
+	assert.Equal(t, "2", query.Get("payee"))
+	assert.Equal(t, "100.50", query.Get("amount"))
+	assert.Equal(t, "BRL", query.Get("currency"))
+	assert.Equal(t, "COMMON", query.Get("payer_wallet_type"))
+	assert.Equal(t, "MERCHANT", query.Get("payee_wallet_type"))
+	assert.Equal(t, "203.0.113.7", header.Get("X-Client-IP"))
+	assert.Equal(t, "app/1.0", header.Get("X-Client-User-Agent"))
+	assert.Empty(t, header.Get("X-Device-ID"))
+}
+
+func TestAuthorizationService_SendsSplitPayees(t *testing.T) {
+	var query url.Values
+	service := newTestAuthorizationService(t, func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`{"status":"success","data":{"authorization":true}}`))
+	}, time.Second, 3)
+	request := testAuthorizationRequest
+	request.Splits = []port.AuthorizationSplit{
+		{PayeeID: 2, Amount: entities.MoneyFromCents(8000), PayeeWalletType: entities.MerchantWallet},
+		{PayeeID: 3, Amount: entities.MoneyFromCents(2050), PayeeWalletType: entities.CommonWallet},
+	}
+
+	_, err := service.Authorize(context.Background(), request)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2", "3"}, query["split_payee"])
+	assert.Equal(t, []string{"80.00", "20.50"}, query["split_amount"])
+	assert.Equal(t, []string{"BRL", "BRL"}, query["split_currency"])
+	assert.Equal(t, []string{"MERCHANT", "COMMON"}, query["split_payee_wallet_type"])
+}
+
+func TestAuthorizationService_Review(t *testing.T) {
+	service := newTestAuthorizationService(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"authorization":false,"review":true,"reason_code":"NEW_PAYEE","reference":"auth-43"}}`))
+	}, time.Second, 3)
+
+	decision, err := service.Authorize(context.Background(), testAuthorizationRequest)
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.AuthorizationDecision{Outcome: entities.AuthorizationReview, ReasonCode: "NEW_PAYEE", Reference: "auth-43"}, decision)
+}
@@ -0,0 +1,212 @@
+package externals
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-transfer/internal/domain/port"
+)
+
+var ErrCircuitOpen = fmt.Errorf("circuit breaker is open: %w", port.ErrServiceUnavailable)
+
+// breakerMetrics is published through expvar, one entry per breaker name.
+var breakerMetrics = expvar.NewMap("circuit_breakers")
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+type BreakerSettings struct {
+	// FailureThreshold consecutive failures open the circuit.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit rejects calls before letting a
+	// single trial call through in the half-open state.
+	OpenTimeout time.Duration
+}
+
+type breakerStats struct {
+	state       expvar.String
+	failures    expvar.Int
+	rejections  expvar.Int
+	opened      expvar.Int
+	consecutive expvar.Int
+}
+
+// CircuitBreaker stops calling an upstream that keeps failing, so callers
+// fail fast instead of piling up behind timeouts.
+type CircuitBreaker struct {
+	name     string
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu               sync.Mutex
+	state            BreakerState
+	consecutiveFails int
+	openedAt         time.Time
+	trialInFlight    bool
+
+	stats *breakerStats
+}
+
+func NewCircuitBreaker(name string, settings BreakerSettings) *CircuitBreaker {
+	stats := &breakerStats{}
+	metrics := new(expvar.Map).Init()
+	metrics.Set("state", &stats.state)
+	metrics.Set("failures_total", &stats.failures)
+	metrics.Set("rejections_total", &stats.rejections)
+	metrics.Set("opened_total", &stats.opened)
+	metrics.Set("consecutive_failures", &stats.consecutive)
+	breakerMetrics.Set(name, metrics)
+
+	b := &CircuitBreaker{
+		name:     name,
+		settings: settings,
+		now:      time.Now,
+		stats:    stats,
+	}
+	b.setState(BreakerClosed)
+	return b
+}
+
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && b.openTimeoutElapsed() {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Execute runs call unless the circuit is open. Errors for which countsAsFailure
+// returns false, such as the caller cancelling, leave the circuit untouched.
+func (b *CircuitBreaker) Execute(call func() error, countsAsFailure func(error) bool) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+
+	err := call()
+	switch {
+	case err == nil:
+		b.onSuccess()
+	case countsAsFailure(err):
+		b.onFailure()
+	default:
+		b.release()
+	}
+	return err
+}
+
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.openTimeoutElapsed() {
+		b.setState(BreakerHalfOpen)
+	}
+	switch b.state {
+	case BreakerOpen:
+		b.stats.rejections.Add(1)
+		return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+	case BreakerHalfOpen:
+		if b.trialInFlight {
+			b.stats.rejections.Add(1)
+			return fmt.Errorf("%s: %w", b.name, ErrCircuitOpen)
+		}
+		b.trialInFlight = true
+	}
+	return nil
+}
+
+func (b *CircuitBreaker) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trialInFlight = false
+	b.consecutiveFails = 0
+	b.stats.consecutive.Set(0)
+	if b.state == BreakerHalfOpen {
+		b.setState(BreakerClosed)
+	}
+}
+
+func (b *CircuitBreaker) onFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasTrial := b.state == BreakerHalfOpen
+	b.trialInFlight = false
+	b.consecutiveFails++
+	b.stats.failures.Add(1)
+	b.stats.consecutive.Set(int64(b.consecutiveFails))
+	if wasTrial || b.consecutiveFails >= b.settings.FailureThreshold {
+		b.openedAt = b.now()
+		b.stats.opened.Add(1)
+		b.setState(BreakerOpen)
+	}
+}
+
+func (b *CircuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialInFlight = false
+}
+
+func (b *CircuitBreaker) openTimeoutElapsed() bool {
+	return !b.now().Before(b.openedAt.Add(b.settings.OpenTimeout))
+}
+
+func (b *CircuitBreaker) setState(state BreakerState) {
+	b.state = state
+	b.stats.state.Set(state.String())
+}
+
+// statusError is an upstream answering with a status the client did not
+// expect.
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("status code: %d", e.code)
+}
+
+// unhealthy reports whether the status blames the upstream rather than the
+// request sent to it.
+func (e *statusError) unhealthy() bool {
+	return e.code >= http.StatusInternalServerError || e.code == http.StatusTooManyRequests
+}
+
+// isUpstreamFailure reports whether a failed call says something about the
+// upstream's health, as opposed to the caller giving up on the request or
+// the upstream refusing that one request.
+func isUpstreamFailure(ctx context.Context) func(error) bool {
+	return func(err error) bool {
+		var status *statusError
+		if errors.As(err, &status) && !status.unhealthy() {
+			return false
+		}
+		return ctx.Err() == nil
+	}
+}
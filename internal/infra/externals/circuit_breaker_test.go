@@ -0,0 +1,106 @@
+package externals
+
+import (
+	"errors"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errUpstream = errors.New("upstream failed")
+
+func always(error) bool { return true }
+
+func newTestBreaker(name string, threshold int, openTimeout time.Duration) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker(name, BreakerSettings{FailureThreshold: threshold, OpenTimeout: openTimeout})
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	breaker, _ := newTestBreaker("test-opens", 3, time.Minute)
+	calls := 0
+	failing := func() error { calls++; return errUpstream }
+
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, breaker.Execute(failing, always), errUpstream)
+	}
+	assert.Equal(t, BreakerOpen, breaker.State())
+
+	err := breaker.Execute(failing, always)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 3, calls, "an open circuit must not call the upstream")
+}
+
+func TestCircuitBreaker_SuccessResetsFailureCount(t *testing.T) {
+	breaker, _ := newTestBreaker("test-resets", 2, time.Minute)
+	failing := func() error { return errUpstream }
+	succeeding := func() error { return nil }
+
+	_ = breaker.Execute(failing, always)
+	assert.NoError(t, breaker.Execute(succeeding, always))
+	_ = breaker.Execute(failing, always)
+
+	assert.Equal(t, BreakerClosed, breaker.State())
+}
+
+func TestCircuitBreaker_HalfOpenTrialClosesOnSuccess(t *testing.T) {
+	breaker, now := newTestBreaker("test-half-open-success", 1, time.Minute)
+	_ = breaker.Execute(func() error { return errUpstream }, always)
+	assert.Equal(t, BreakerOpen, breaker.State())
+
+	*now = now.Add(time.Minute)
+	assert.Equal(t, BreakerHalfOpen, breaker.State())
+
+	assert.NoError(t, breaker.Execute(func() error { return nil }, always))
+	assert.Equal(t, BreakerClosed, breaker.State())
+}
+
+func TestCircuitBreaker_HalfOpenTrialReopensOnFailure(t *testing.T) {
+	breaker, now := newTestBreaker("test-half-open-failure", 3, time.Minute)
+	for i := 0; i < 3; i++ {
+		_ = breaker.Execute(func() error { return errUpstream }, always)
+	}
+
+	*now = now.Add(time.Minute)
+	assert.ErrorIs(t, breaker.Execute(func() error { return errUpstream }, always), errUpstream)
+	assert.Equal(t, BreakerOpen, breaker.State(), "a failed trial reopens the circuit immediately")
+
+	*now = now.Add(30 * time.Second)
+	assert.ErrorIs(t, breaker.Execute(func() error { return nil }, always), ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_HalfOpenAllowsSingleTrial(t *testing.T) {
+	breaker, now := newTestBreaker("test-single-trial", 1, time.Minute)
+	_ = breaker.Execute(func() error { return errUpstream }, always)
+	*now = now.Add(time.Minute)
+
+	err := breaker.Execute(func() error {
+		return breaker.Execute(func() error { return nil }, always)
+	}, always)
+
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
+
+func TestCircuitBreaker_IgnoresErrorsNotCountedAsFailures(t *testing.T) {
+	breaker, _ := newTestBreaker("test-ignored", 1, time.Minute)
+	never := func(error) bool { return false }
+
+	assert.ErrorIs(t, breaker.Execute(func() error { return errUpstream }, never), errUpstream)
+	assert.Equal(t, BreakerClosed, breaker.State())
+}
+
+func TestCircuitBreaker_PublishesMetrics(t *testing.T) {
+	breaker, _ := newTestBreaker("test-metrics", 1, time.Minute)
+	_ = breaker.Execute(func() error { return errUpstream }, always)
+	_ = breaker.Execute(func() error { return nil }, always)
+
+	metrics := breakerMetrics.Get("test-metrics").(*expvar.Map)
+	assert.Equal(t, `"open"`, metrics.Get("state").String())
+	assert.Equal(t, "1", metrics.Get("failures_total").String())
+	assert.Equal(t, "1", metrics.Get("rejections_total").String())
+	assert.Equal(t, "1", metrics.Get("opened_total").String())
+}
@@ -0,0 +1,12 @@
+package externals
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewHTTPClient returns a client whose requests, including reading the
+// response body, give up after timeout.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"go-transfer/internal/domain/entities"
 	"go-transfer/internal/domain/port"
 	"io"
 	"net/http"
@@ -12,23 +13,35 @@ import (
 
 type NotificationServiceImpl struct {
 	baseURL string
+	client  *http.Client
+	breaker *CircuitBreaker
 }
 
-func NewNotificationService(baseURL string) port.NotificationService {
+func NewNotificationService(baseURL string, client *http.Client, breaker *CircuitBreaker) port.NotificationService {
 	return &NotificationServiceImpl{
 		baseURL: baseURL,
+		client:  client,
+		breaker: breaker,
 	}
 }
 
 type NotificationRequest struct {
-	ReceiverID int64   `json:"receiverID"`
-	Amount     float64 `json:"amount"`
+	ReceiverID        int64                     `json:"receiverID"`
+	Kind              entities.NotificationKind `json:"kind"`
+	Amount            entities.Money            `json:"amount"`
+	Description       string                    `json:"description,omitempty"`
+	ExternalReference *string                   `json:"externalReference,omitempty"`
+	Metadata          map[string]string         `json:"metadata,omitempty"`
 }
 
-func (s *NotificationServiceImpl) Notify(ctx context.Context, receiverID int64, amount float64) error {
+func (s *NotificationServiceImpl) Notify(ctx context.Context, receiverID int64, kind entities.NotificationKind, amount entities.Money, details entities.TransferDetails) error {
 	reqBody := NotificationRequest{
-		ReceiverID: receiverID,
-		Amount:     amount,
+		ReceiverID:        receiverID,
+		Kind:              kind,
+		Amount:            amount,
+		Description:       details.Description,
+		ExternalReference: details.ExternalReference,
+		Metadata:          details.Metadata,
 	}
 
 	reqBytes, err := json.Marshal(reqBody)
@@ -36,6 +49,12 @@ func (s *NotificationServiceImpl) Notify(ctx context.Context, receiverID int64,
 		return err
 	}
 
+	return s.breaker.Execute(func() error {
+		return s.notify(ctx, reqBytes)
+	}, isUpstreamFailure(ctx))
+}
+
+func (s *NotificationServiceImpl) notify(ctx context.Context, reqBytes []byte) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL, bytes.NewBuffer(reqBytes))
 	if err != nil {
 		return err
@@ -43,20 +62,22 @@ func (s *NotificationServiceImpl) Notify(ctx context.Context, receiverID int64,
 
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := s.client.Do(req)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: notification request failed: %v", port.ErrServiceUnavailable, err)
 	}
 	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-
-		}
+		_, _ = io.Copy(io.Discard, Body)
+		_ = Body.Close()
 	}(resp.Body)
 
 	if resp.StatusCode == http.StatusNoContent {
 		return nil
 	}
 
-	return fmt.Errorf("erro ao enviar notificação, status code: %d", resp.StatusCode)
+	status := &statusError{code: resp.StatusCode}
+	if status.unhealthy() {
+		return fmt.Errorf("%w: erro ao enviar notificação, %w", port.ErrServiceUnavailable, status)
+	}
+	return fmt.Errorf("erro ao enviar notificação, %w", status)
 }
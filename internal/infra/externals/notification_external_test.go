@@ -0,0 +1,101 @@
+package externals
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestNotificationService(t *testing.T, handler http.HandlerFunc, timeout time.Duration, threshold int) port.NotificationService {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	breaker := NewCircuitBreaker(t.Name(), BreakerSettings{FailureThreshold: threshold, OpenTimeout: time.Minute})
+	return NewNotificationService(server.URL, NewHTTPClient(timeout), breaker)
+}
+
+func TestNotificationService_Delivered(t *testing.T) {
+	var received NotificationRequest
+	service := newTestNotificationService(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}, time.Second, 3)
+
+	reference := "order-1234"
+	details := entities.TransferDetails{Description: "Order 1234", ExternalReference: &reference, Metadata: map[string]string{"channel": "web"}}
+	err := service.Notify(context.Background(), 7, entities.NotificationKindTransferReceived, entities.MoneyFromCents(1050), details)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), received.ReceiverID)
+	assert.Equal(t, entities.NotificationKindTransferReceived, received.Kind)
+	assert.Equal(t, entities.MoneyFromCents(1050), received.Amount)
+	assert.Equal(t, "Order 1234", received.Description)
+	assert.Equal(t, &reference, received.ExternalReference)
+	assert.Equal(t, map[string]string{"channel": "web"}, received.Metadata)
+}
+
+func TestNotificationService_TimesOutSlowUpstream(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	service := newTestNotificationService(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}, 50*time.Millisecond, 3)
+
+	start := time.Now()
+	err := service.Notify(context.Background(), 7, entities.NotificationKindTransferReceived, entities.MoneyFromCents(100), entities.TransferDetails{})
+
+	assert.ErrorIs(t, err, port.ErrServiceUnavailable)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestNotificationService_OpensCircuitOnFailures(t *testing.T) {
+	calls := 0
+	service := newTestNotificationService(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, time.Second, 3)
+
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, service.Notify(context.Background(), 7, entities.NotificationKindTransferReceived, entities.MoneyFromCents(100), entities.TransferDetails{}), port.ErrServiceUnavailable)
+	}
+	err := service.Notify(context.Background(), 7, entities.NotificationKindTransferReceived, entities.MoneyFromCents(100), entities.TransferDetails{})
+
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 3, calls)
+}
+
+func TestNotificationService_RejectedRequestsDoNotOpenCircuit(t *testing.T) {
+	calls := 0
+	service := newTestNotificationService(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+	}, time.Second, 1)
+
+	for i := 0; i < 3; i++ {
+		err := service.Notify(context.Background(), 7, entities.NotificationKindTransferReceived, entities.MoneyFromCents(100), entities.TransferDetails{})
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, port.ErrServiceUnavailable)
+	}
+	assert.Equal(t, 3, calls)
+}
+
+func TestNotificationService_ThrottlingOpensCircuit(t *testing.T) {
+	service := newTestNotificationService(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}, time.Second, 1)
+
+	err := service.Notify(context.Background(), 7, entities.NotificationKindTransferReceived, entities.MoneyFromCents(100), entities.TransferDetails{})
+	assert.ErrorIs(t, err, port.ErrServiceUnavailable)
+	err = service.Notify(context.Background(), 7, entities.NotificationKindTransferReceived, entities.MoneyFromCents(100), entities.TransferDetails{})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
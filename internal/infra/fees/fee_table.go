@@ -0,0 +1,136 @@
+package fees
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go-transfer/internal/domain/entities"
+
+	"gopkg.in/yaml.v3"
+)
+
+// feeFile is the on-disk shape of the fee schedules. Being YAML, the parser
+// also accepts the equivalent JSON document.
+type feeFile struct {
+	Schedules []scheduleFile `yaml:"schedules"`
+}
+
+type scheduleFile struct {
+	Name  string              `yaml:"name"`
+	Payer entities.WalletType `yaml:"payer"`
+	Payee entities.WalletType `yaml:"payee"`
+	Type  string              `yaml:"type"`
+	Flat  string              `yaml:"flat"`
+	Rate  string              `yaml:"rate"`
+	Min   string              `yaml:"min"`
+	Max   string              `yaml:"max"`
+	Tiers []tierFile          `yaml:"tiers"`
+}
+
+type tierFile struct {
+	UpTo string `yaml:"up_to"`
+	Flat string `yaml:"flat"`
+	Rate string `yaml:"rate"`
+}
+
+func LoadFeeTable(path string) (*entities.FeeTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFeeTable(data)
+}
+
+func ParseFeeTable(data []byte) (*entities.FeeTable, error) {
+	var file feeFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", entities.ErrInvalidFeeSchedule, err)
+	}
+
+	table := entities.NewFeeTable()
+	seen := make(map[[2]entities.WalletType]bool, len(file.Schedules))
+	for _, entry := range file.Schedules {
+		if entry.Payer == "" || entry.Payee == "" {
+			return nil, fmt.Errorf("%w: schedules need payer and payee", entities.ErrInvalidFeeSchedule)
+		}
+		pair := [2]entities.WalletType{entry.Payer, entry.Payee}
+		if seen[pair] {
+			return nil, fmt.Errorf("%w: more than one schedule for %s -> %s", entities.ErrInvalidFeeSchedule, entry.Payer, entry.Payee)
+		}
+		seen[pair] = true
+
+		schedule, err := parseSchedule(entry)
+		if err != nil {
+			return nil, err
+		}
+		if err := table.Set(entry.Payer, entry.Payee, schedule); err != nil {
+			return nil, err
+		}
+	}
+	return table, nil
+}
+
+func parseSchedule(entry scheduleFile) (entities.FeeSchedule, error) {
+	schedule := entities.FeeSchedule{
+		Name: entry.Name,
+		Type: entities.FeeScheduleType(strings.ToUpper(entry.Type)),
+	}
+	if schedule.Name == "" {
+		schedule.Name = strings.ToLower(fmt.Sprintf("%s_to_%s", entry.Payer, entry.Payee))
+	}
+
+	var err error
+	if schedule.Flat, err = parseAmount(schedule.Name, "flat", entry.Flat); err != nil {
+		return schedule, err
+	}
+	if schedule.Rate, err = parseRate(entry.Rate); err != nil {
+		return schedule, err
+	}
+	if schedule.Min, err = parseOptionalAmount(schedule.Name, "min", entry.Min); err != nil {
+		return schedule, err
+	}
+	if schedule.Max, err = parseOptionalAmount(schedule.Name, "max", entry.Max); err != nil {
+		return schedule, err
+	}
+	for _, tierEntry := range entry.Tiers {
+		var tier entities.FeeTier
+		if tier.UpTo, err = parseOptionalAmount(schedule.Name, "up_to", tierEntry.UpTo); err != nil {
+			return schedule, err
+		}
+		if tier.Flat, err = parseAmount(schedule.Name, "flat", tierEntry.Flat); err != nil {
+			return schedule, err
+		}
+		if tier.Rate, err = parseRate(tierEntry.Rate); err != nil {
+			return schedule, err
+		}
+		schedule.Tiers = append(schedule.Tiers, tier)
+	}
+	return schedule, nil
+}
+
+func parseAmount(schedule, name, value string) (entities.Money, error) {
+	amount, err := parseOptionalAmount(schedule, name, value)
+	if err != nil || amount == nil {
+		return entities.MoneyFromCents(0), err
+	}
+	return *amount, nil
+}
+
+func parseOptionalAmount(schedule, name, value string) (*entities.Money, error) {
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := entities.ParseMoney(value, entities.DefaultCurrency)
+	if err != nil || amount.IsNegative() {
+		return nil, fmt.Errorf("%w: %s: %s must be a non-negative amount, got %q", entities.ErrInvalidFeeSchedule, schedule, name, value)
+	}
+	return &amount, nil
+}
+
+func parseRate(value string) (entities.FeeRate, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return entities.ParseFeeRate(value)
+}
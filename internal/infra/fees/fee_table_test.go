@@ -0,0 +1,55 @@
+package fees
+
+import (
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSchedules = `
+schedules:
+  - payer: COMMON
+    payee: COMMON
+    type: flat
+    flat: "1.00"
+  - name: merchant_payments
+    payer: COMMON
+    payee: MERCHANT
+    type: capped
+    flat: "0.30"
+    rate: "2.5"
+    min: "0.50"
+    max: "15.00"
+`
+
+func TestParseFeeTable(t *testing.T) {
+	table, err := ParseFeeTable([]byte(testSchedules))
+	assert.NoError(t, err)
+
+	breakdown, err := table.Quote(entities.CommonWallet, entities.CommonWallet, entities.MoneyFromCents(10000))
+	assert.NoError(t, err)
+	assert.Equal(t, "common_to_common", breakdown.Schedule)
+	assert.Equal(t, []entities.FeeLine{{Code: entities.FeeLineFlat, Amount: entities.MoneyFromCents(100)}}, breakdown.Lines)
+
+	breakdown, err = table.Quote(entities.CommonWallet, entities.MerchantWallet, entities.MoneyFromCents(100000))
+	assert.NoError(t, err)
+	assert.Equal(t, "merchant_payments", breakdown.Schedule)
+	assert.Equal(t, []entities.FeeLine{{Code: entities.FeeLineCap, Amount: entities.MoneyFromCents(1500)}}, breakdown.Lines)
+}
+
+func TestParseFeeTable_RejectsInvalidSchedules(t *testing.T) {
+	invalid := []string{
+		"schedules: [{payer: COMMON, type: flat, flat: '1.00'}]",
+		"schedules: [{payer: COMMON, payee: COMMON, type: flat, flat: 'abc'}]",
+		"schedules: [{payer: COMMON, payee: COMMON, type: percentage, rate: '-1'}]",
+		"schedules: [{payer: COMMON, payee: COMMON, type: capped, rate: '1'}]",
+		"schedules: [{payer: COMMON, payee: COMMON, type: flat}, {payer: COMMON, payee: COMMON, type: flat}]",
+		"schedules: {",
+	}
+	for _, data := range invalid {
+		_, err := ParseFeeTable([]byte(data))
+		assert.ErrorIs(t, err, entities.ErrInvalidFeeSchedule, data)
+	}
+}
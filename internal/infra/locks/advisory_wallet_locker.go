@@ -0,0 +1,29 @@
+package locks
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// AdvisoryWalletLocker takes Postgres transaction-scoped advisory locks, so
+// it must be built on the *gorm.DB of the surrounding transaction. The locks
+// are released by Postgres on commit or rollback.
+type AdvisoryWalletLocker struct {
+	db *gorm.DB
+}
+
+func NewAdvisoryWalletLocker(db *gorm.DB) *AdvisoryWalletLocker {
+	return &AdvisoryWalletLocker{
+		db: db,
+	}
+}
+
+func (l *AdvisoryWalletLocker) Lock(ctx context.Context, walletIDs ...int64) (func(), error) {
+	for _, id := range orderedWalletIDs(walletIDs) {
+		if err := l.db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(?)", id).Error; err != nil {
+			return nil, err
+		}
+	}
+	return func() {}, nil
+}
@@ -0,0 +1,91 @@
+package locks
+
+import (
+	"context"
+	"sync"
+)
+
+type walletLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// MemoryWalletLocker serialises transfers inside a single process. Entries
+// are reference counted and evicted once no caller holds or waits for them.
+type MemoryWalletLocker struct {
+	mu    sync.Mutex
+	locks map[int64]*walletLock
+}
+
+func NewMemoryWalletLocker() *MemoryWalletLocker {
+	return &MemoryWalletLocker{
+		locks: make(map[int64]*walletLock),
+	}
+}
+
+func (l *MemoryWalletLocker) Lock(ctx context.Context, walletIDs ...int64) (func(), error) {
+	ids := orderedWalletIDs(walletIDs)
+	acquired := make([]int64, 0, len(ids))
+
+	for _, id := range ids {
+		if err := l.acquire(ctx, id); err != nil {
+			l.releaseAll(acquired)
+			return nil, err
+		}
+		acquired = append(acquired, id)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.releaseAll(acquired) })
+	}, nil
+}
+
+func (l *MemoryWalletLocker) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.locks)
+}
+
+func (l *MemoryWalletLocker) acquire(ctx context.Context, id int64) error {
+	lock := l.ref(id)
+	select {
+	case lock.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		l.unref(id)
+		return ctx.Err()
+	}
+}
+
+func (l *MemoryWalletLocker) releaseAll(ids []int64) {
+	for i := len(ids) - 1; i >= 0; i-- {
+		l.mu.Lock()
+		lock := l.locks[ids[i]]
+		l.mu.Unlock()
+		<-lock.ch
+		l.unref(ids[i])
+	}
+}
+
+func (l *MemoryWalletLocker) ref(id int64) *walletLock {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock, ok := l.locks[id]
+	if !ok {
+		lock = &walletLock{ch: make(chan struct{}, 1)}
+		l.locks[id] = lock
+	}
+	lock.refs++
+	return lock
+}
+
+func (l *MemoryWalletLocker) unref(id int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	lock := l.locks[id]
+	lock.refs--
+	if lock.refs == 0 {
+		delete(l.locks, id)
+	}
+}
@@ -0,0 +1,73 @@
+package locks
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryWalletLocker_SerialisesSameWallet(t *testing.T) {
+	locker := NewMemoryWalletLocker()
+	ctx := context.Background()
+
+	counter := 0
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			unlock, err := locker.Lock(ctx, 1, int64(2+i%3))
+			assert.NoError(t, err)
+			current := counter
+			time.Sleep(time.Microsecond)
+			counter = current + 1
+			unlock()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 50, counter)
+}
+
+func TestMemoryWalletLocker_EvictsReleasedLocks(t *testing.T) {
+	locker := NewMemoryWalletLocker()
+	ctx := context.Background()
+
+	for id := int64(1); id <= 100; id++ {
+		unlock, err := locker.Lock(ctx, id, id+1000)
+		assert.NoError(t, err)
+		unlock()
+	}
+
+	assert.Equal(t, 0, locker.Len())
+}
+
+func TestMemoryWalletLocker_UnlockIsIdempotent(t *testing.T) {
+	locker := NewMemoryWalletLocker()
+	ctx := context.Background()
+
+	unlock, err := locker.Lock(ctx, 1, 1, 2)
+	assert.NoError(t, err)
+	unlock()
+	unlock()
+
+	assert.Equal(t, 0, locker.Len())
+}
+
+func TestMemoryWalletLocker_HonoursContextCancellation(t *testing.T) {
+	locker := NewMemoryWalletLocker()
+
+	unlock, err := locker.Lock(context.Background(), 1)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = locker.Lock(ctx, 2, 1)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	unlock()
+	assert.Equal(t, 0, locker.Len())
+}
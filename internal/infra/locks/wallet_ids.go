@@ -0,0 +1,19 @@
+package locks
+
+import "sort"
+
+// orderedWalletIDs returns the distinct ids in ascending order so that every
+// caller acquires locks in the same sequence and cannot deadlock.
+func orderedWalletIDs(walletIDs []int64) []int64 {
+	ids := make([]int64, 0, len(walletIDs))
+	seen := make(map[int64]struct{}, len(walletIDs))
+	for _, id := range walletIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,88 @@
+package rates
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ratesFile is the on-disk shape of the static rates. Being YAML, the parser
+// also accepts the equivalent JSON document.
+type ratesFile struct {
+	Rates []rateFile `yaml:"rates"`
+}
+
+type rateFile struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+	Rate string `yaml:"rate"`
+}
+
+// StaticProvider serves fixed exchange rates. A pair set in one direction
+// also answers the opposite one with the inverse rate, unless that one is
+// set too.
+type StaticProvider struct {
+	rates map[[2]string]entities.ExchangeRate
+}
+
+func NewStaticProvider() *StaticProvider {
+	return &StaticProvider{rates: make(map[[2]string]entities.ExchangeRate)}
+}
+
+func (p *StaticProvider) Set(from, to string, rate entities.ExchangeRate) {
+	p.rates[[2]string{from, to}] = rate
+}
+
+func (p *StaticProvider) Rate(ctx context.Context, from, to string) (entities.ExchangeRate, error) {
+	if rate, ok := p.rates[[2]string{from, to}]; ok {
+		return rate, nil
+	}
+	if rate, ok := p.rates[[2]string{to, from}]; ok {
+		return rate.Inverse(), nil
+	}
+	return 0, fmt.Errorf("%w: %s to %s", port.ErrExchangeRateNotFound, from, to)
+}
+
+func LoadStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStaticProvider(data)
+}
+
+func ParseStaticProvider(data []byte) (*StaticProvider, error) {
+	var file ratesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", entities.ErrInvalidExchangeRate, err)
+	}
+
+	provider := NewStaticProvider()
+	for _, entry := range file.Rates {
+		from, err := entities.ParseCurrency(entry.From)
+		if err != nil || entry.From == "" {
+			return nil, fmt.Errorf("%w: from %q", entities.ErrInvalidCurrency, entry.From)
+		}
+		to, err := entities.ParseCurrency(entry.To)
+		if err != nil || entry.To == "" {
+			return nil, fmt.Errorf("%w: to %q", entities.ErrInvalidCurrency, entry.To)
+		}
+		if from == to {
+			return nil, fmt.Errorf("%w: %s to itself", entities.ErrInvalidExchangeRate, from)
+		}
+		if _, ok := provider.rates[[2]string{from, to}]; ok {
+			return nil, fmt.Errorf("%w: more than one rate for %s to %s", entities.ErrInvalidExchangeRate, from, to)
+		}
+		rate, err := entities.ParseExchangeRate(entry.Rate)
+		if err != nil {
+			return nil, err
+		}
+		provider.Set(from, to, rate)
+	}
+	return provider, nil
+}
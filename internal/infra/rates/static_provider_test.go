@@ -0,0 +1,62 @@
+package rates
+
+import (
+	"context"
+	"testing"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRates = `
+rates:
+  - from: USD
+    to: BRL
+    rate: "5.00"
+  - from: eur
+    to: brl
+    rate: "6.25"
+  - from: BRL
+    to: EUR
+    rate: "0.15"
+`
+
+func TestParseStaticProvider(t *testing.T) {
+	provider, err := ParseStaticProvider([]byte(testRates))
+	assert.NoError(t, err)
+	ctx := context.Background()
+
+	rate, err := provider.Rate(ctx, "USD", "BRL")
+	assert.NoError(t, err)
+	assert.Equal(t, "5", rate.String())
+
+	// Only USD -> BRL is set, so the other way uses its inverse.
+	rate, err = provider.Rate(ctx, "BRL", "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.2", rate.String())
+
+	// Both directions are set and each is used as is.
+	rate, err = provider.Rate(ctx, "BRL", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.15", rate.String())
+
+	_, err = provider.Rate(ctx, "USD", "EUR")
+	assert.ErrorIs(t, err, port.ErrExchangeRateNotFound)
+}
+
+func TestParseStaticProvider_RejectsInvalidRates(t *testing.T) {
+	invalid := map[string]error{
+		"rates: [{from: USD, to: BRL, rate: '0'}]":                                    entities.ErrInvalidExchangeRate,
+		"rates: [{from: USD, to: USD, rate: '1'}]":                                    entities.ErrInvalidExchangeRate,
+		"rates: [{from: USD, to: BRL, rate: '5'}, {from: USD, to: BRL, rate: '5.1'}]": entities.ErrInvalidExchangeRate,
+		"rates: [{from: US, to: BRL, rate: '5'}]":                                     entities.ErrInvalidCurrency,
+		"rates: [{to: BRL, rate: '5'}]":                                               entities.ErrInvalidCurrency,
+		"rates: {":                                                                    entities.ErrInvalidExchangeRate,
+	}
+	for data, expected := range invalid {
+		_, err := ParseStaticProvider([]byte(data))
+		assert.ErrorIs(t, err, expected, data)
+	}
+}
@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"gorm.io/gorm"
+)
+
+type ExchangeQuoteRepository struct {
+	db *gorm.DB
+}
+
+func NewExchangeQuoteRepository(db *gorm.DB) *ExchangeQuoteRepository {
+	return &ExchangeQuoteRepository{
+		db: db,
+	}
+}
+
+func (r *ExchangeQuoteRepository) Create(ctx context.Context, quote *entities.ExchangeQuote) error {
+	return r.db.WithContext(ctx).Create(quote).Error
+}
+
+func (r *ExchangeQuoteRepository) GetByID(ctx context.Context, id int64) (*entities.ExchangeQuote, error) {
+	quote := &entities.ExchangeQuote{}
+	err := r.db.WithContext(ctx).First(quote, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, port.ErrExchangeQuoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+func (r *ExchangeQuoteRepository) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	result := r.db.WithContext(ctx).Model(&entities.ExchangeQuote{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrExchangeQuoteUsed
+	}
+	return nil
+}
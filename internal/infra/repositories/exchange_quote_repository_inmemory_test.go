@@ -0,0 +1,106 @@
+package repositories_test
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ExchangeQuoteRepositoryInMemory struct {
+	quotes map[int64]entities.ExchangeQuote
+	mu     sync.RWMutex
+	nextID int64
+}
+
+func NewExchangeQuoteRepositoryInMemory() port.ExchangeQuoteRepository {
+	return &ExchangeQuoteRepositoryInMemory{
+		quotes: make(map[int64]entities.ExchangeQuote),
+		mu:     sync.RWMutex{},
+		nextID: 1,
+	}
+}
+
+func (r *ExchangeQuoteRepositoryInMemory) Create(ctx context.Context, quote *entities.ExchangeQuote) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	quote.ID = r.nextID
+	quote.CreatedAt = time.Now()
+	r.quotes[quote.ID] = *quote
+	r.nextID++
+	return nil
+}
+
+func (r *ExchangeQuoteRepositoryInMemory) GetByID(ctx context.Context, id int64) (*entities.ExchangeQuote, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	quote, ok := r.quotes[id]
+	if !ok {
+		return nil, port.ErrExchangeQuoteNotFound
+	}
+	return &quote, nil
+}
+
+func (r *ExchangeQuoteRepositoryInMemory) MarkUsed(ctx context.Context, id int64, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	quote, ok := r.quotes[id]
+	if !ok || quote.UsedAt != nil {
+		return port.ErrExchangeQuoteUsed
+	}
+	quote.UsedAt = &at
+	r.quotes[id] = quote
+	return nil
+}
+
+func (r *ExchangeQuoteRepositoryInMemory) Snapshot() func() {
+	r.mu.RLock()
+	saved := maps.Clone(r.quotes)
+	nextID := r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.quotes = saved
+		r.nextID = nextID
+	}
+}
+
+func TestExchangeQuoteRepositoryInMemory_CreateAndGet(t *testing.T) {
+	repo := NewExchangeQuoteRepositoryInMemory()
+	ctx := context.Background()
+	rate, _ := entities.ParseExchangeRate("5.4321")
+
+	quote := &entities.ExchangeQuote{PayerID: 1, PayeeID: 2, Amount: entities.NewMoney(10000, "USD"), Currency: "USD", Rate: rate, ConvertedAmount: entities.MoneyFromCents(54321), ConvertedCurrency: "BRL"}
+	assert.NoError(t, repo.Create(ctx, quote))
+	assert.NotZero(t, quote.ID)
+
+	retrieved, err := repo.GetByID(ctx, quote.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, quote, retrieved)
+
+	_, err = repo.GetByID(ctx, 999)
+	assert.ErrorIs(t, err, port.ErrExchangeQuoteNotFound)
+}
+
+func TestExchangeQuoteRepositoryInMemory_MarkUsedOnlyOnce(t *testing.T) {
+	repo := NewExchangeQuoteRepositoryInMemory()
+	ctx := context.Background()
+
+	quote := &entities.ExchangeQuote{PayerID: 1, PayeeID: 2}
+	assert.NoError(t, repo.Create(ctx, quote))
+
+	assert.NoError(t, repo.MarkUsed(ctx, quote.ID, time.Now()))
+	assert.ErrorIs(t, repo.MarkUsed(ctx, quote.ID, time.Now()), port.ErrExchangeQuoteUsed)
+
+	retrieved, err := repo.GetByID(ctx, quote.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, retrieved.UsedAt)
+}
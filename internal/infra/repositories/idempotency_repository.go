@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{
+		db: db,
+	}
+}
+
+func (r *IdempotencyRepository) Create(ctx context.Context, record *entities.IdempotencyRecord) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrIdempotencyKeyExists
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) GetByKey(ctx context.Context, key string) (*entities.IdempotencyRecord, error) {
+	record := &entities.IdempotencyRecord{}
+	err := r.db.WithContext(ctx).Where("key = ?", key).First(record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, port.ErrIdempotencyRecordNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *IdempotencyRepository) Replace(ctx context.Context, record *entities.IdempotencyRecord, now time.Time) error {
+	result := r.db.WithContext(ctx).Model(&entities.IdempotencyRecord{}).
+		Where("key = ?", record.Key).
+		Where("expires_at <= ? OR (status = ? AND leased_until <= ? AND request_hash = ?)",
+			now, entities.IdempotencyStatusInProgress, now, record.RequestHash).
+		Updates(map[string]interface{}{
+			"request_hash":          record.RequestHash,
+			"status":                entities.IdempotencyStatusInProgress,
+			"response_code":         0,
+			"response_content_type": "",
+			"response_location":     "",
+			"response_body":         nil,
+			"expires_at":            record.ExpiresAt,
+			"leased_until":          record.LeasedUntil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrIdempotencyKeyExists
+	}
+	return nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, key string, code int, contentType, location string, body []byte) error {
+	return r.db.WithContext(ctx).Model(&entities.IdempotencyRecord{}).Where("key = ?", key).Updates(map[string]interface{}{
+		"status":                entities.IdempotencyStatusCompleted,
+		"response_code":         code,
+		"response_content_type": contentType,
+		"response_location":     location,
+		"response_body":         body,
+	}).Error
+}
+
+func (r *IdempotencyRepository) Delete(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("key = ?", key).Delete(&entities.IdempotencyRecord{}).Error
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&entities.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
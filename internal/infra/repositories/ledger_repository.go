@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+
+	"go-transfer/internal/domain/entities"
+
+	"gorm.io/gorm"
+)
+
+type LedgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) *LedgerRepository {
+	return &LedgerRepository{
+		db: db,
+	}
+}
+
+func (r *LedgerRepository) CreateEntries(ctx context.Context, entries []entities.LedgerEntry) error {
+	return r.db.WithContext(ctx).Omit("Transaction", "Wallet").Create(&entries).Error
+}
+
+func (r *LedgerRepository) ListByTransaction(ctx context.Context, transactionID int64) ([]entities.LedgerEntry, error) {
+	var entries []entities.LedgerEntry
+	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).Order("id").Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
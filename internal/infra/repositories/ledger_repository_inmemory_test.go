@@ -0,0 +1,90 @@
+package repositories_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type LedgerRepositoryInMemory struct {
+	entries []entities.LedgerEntry
+	mu      sync.RWMutex
+	nextID  int64
+}
+
+func NewLedgerRepositoryInMemory() port.LedgerRepository {
+	return &LedgerRepositoryInMemory{
+		mu:     sync.RWMutex{},
+		nextID: 1,
+	}
+}
+
+func (r *LedgerRepositoryInMemory) CreateEntries(ctx context.Context, entries []entities.LedgerEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range entries {
+		entries[i].ID = r.nextID
+		entries[i].CreatedAt = time.Now()
+		r.entries = append(r.entries, entries[i])
+		r.nextID++
+	}
+	return nil
+}
+
+func (r *LedgerRepositoryInMemory) ListByTransaction(ctx context.Context, transactionID int64) ([]entities.LedgerEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var entries []entities.LedgerEntry
+	for _, entry := range r.entries {
+		if entry.TransactionID == transactionID {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (r *LedgerRepositoryInMemory) Snapshot() func() {
+	r.mu.RLock()
+	length := len(r.entries)
+	nextID := r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.entries = r.entries[:length]
+		r.nextID = nextID
+	}
+}
+
+func TestLedgerRepositoryInMemory_CreateEntries(t *testing.T) {
+	repo := NewLedgerRepositoryInMemory()
+	ctx := context.Background()
+
+	err := repo.CreateEntries(ctx, entities.NewTransferPosting(1, 10, 20, entities.MoneyFromCents(500)))
+	assert.NoError(t, err)
+	err = repo.CreateEntries(ctx, entities.NewTransferPosting(2, 20, 10, entities.MoneyFromCents(100)))
+	assert.NoError(t, err)
+
+	entries, err := repo.ListByTransaction(ctx, 1)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.NoError(t, entities.ValidatePosting(entries))
+	assert.Equal(t, int64(10), entries[0].WalletID)
+	assert.Equal(t, entities.LedgerDebit, entries[0].Direction)
+}
+
+func TestLedgerRepositoryInMemory_ListByTransaction_Empty(t *testing.T) {
+	repo := NewLedgerRepositoryInMemory()
+	ctx := context.Background()
+
+	entries, err := repo.ListByTransaction(ctx, 999)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -2,10 +2,14 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type NotificationRepository struct {
@@ -18,16 +22,34 @@ func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
 	}
 }
 func (r *NotificationRepository) Create(ctx context.Context, notification *entities.Notification) (int64, error) {
-	result := r.db.WithContext(ctx).Create(notification)
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
 	if result.Error != nil {
 		return 0, result.Error
 	}
+	if result.RowsAffected == 0 {
+		return 0, port.ErrNotificationExists
+	}
 	return notification.ID, nil
 }
 
 func (r *NotificationRepository) GetByID(ctx context.Context, id int64) (*entities.Notification, error) {
 	notification := &entities.Notification{}
 	err := r.db.WithContext(ctx).First(notification, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, port.ErrNotificationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return notification, nil
+}
+
+func (r *NotificationRepository) GetByOutboxMessageID(ctx context.Context, outboxMessageID int64) (*entities.Notification, error) {
+	notification := &entities.Notification{}
+	err := r.db.WithContext(ctx).Where("outbox_message_id = ?", outboxMessageID).First(notification).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, port.ErrNotificationNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -37,3 +59,42 @@ func (r *NotificationRepository) GetByID(ctx context.Context, id int64) (*entiti
 func (r *NotificationRepository) UpdateStatus(ctx context.Context, id int64, status entities.NotificationStatus) error {
 	return r.db.WithContext(ctx).Model(&entities.Notification{}).Where("id = ?", id).Update("status", status).Error
 }
+
+func (r *NotificationRepository) UpdateDelivery(ctx context.Context, notification *entities.Notification) error {
+	return r.db.WithContext(ctx).Model(&entities.Notification{}).Where("id = ?", notification.ID).Updates(map[string]interface{}{
+		"status":          notification.Status,
+		"attempts":        notification.Attempts,
+		"last_error":      notification.LastError,
+		"next_attempt_at": notification.NextAttemptAt,
+	}).Error
+}
+
+func (r *NotificationRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.Notification, error) {
+	var notifications []entities.Notification
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE notifications
+		SET next_attempt_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status = ? AND next_attempt_at <= ? AND deleted_at IS NULL
+			ORDER BY next_attempt_at
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), now, entities.NotificationStatusFailed, now, limit,
+	).Scan(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *NotificationRepository) ListByStatus(ctx context.Context, status entities.NotificationStatus, afterID int64, limit int) ([]entities.Notification, error) {
+	var notifications []entities.Notification
+	err := r.db.WithContext(ctx).Where("status = ? AND id > ?", status, afterID).Order("id").Limit(limit).Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
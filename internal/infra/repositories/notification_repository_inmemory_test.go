@@ -3,6 +3,7 @@ package repositories_test
 import (
 	"context"
 	"errors"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -30,6 +31,11 @@ func NewNotificationRepositoryInMemory() port.NotificationRepository {
 func (r *NotificationRepositoryInMemory) Create(ctx context.Context, notification *entities.Notification) (int64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if notification.OutboxMessageID != nil {
+		if _, err := r.byOutboxMessageID(*notification.OutboxMessageID); err == nil {
+			return 0, port.ErrNotificationExists
+		}
+	}
 	notification.ID = r.nextID
 	r.notifications[notification.ID] = notification
 	r.nextID++
@@ -46,6 +52,21 @@ func (r *NotificationRepositoryInMemory) GetByID(ctx context.Context, id int64)
 	return notification, nil
 }
 
+func (r *NotificationRepositoryInMemory) GetByOutboxMessageID(ctx context.Context, outboxMessageID int64) (*entities.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.byOutboxMessageID(outboxMessageID)
+}
+
+func (r *NotificationRepositoryInMemory) byOutboxMessageID(outboxMessageID int64) (*entities.Notification, error) {
+	for _, notification := range r.notifications {
+		if notification.OutboxMessageID != nil && *notification.OutboxMessageID == outboxMessageID {
+			return notification, nil
+		}
+	}
+	return nil, port.ErrNotificationNotFound
+}
+
 func (r *NotificationRepositoryInMemory) UpdateStatus(ctx context.Context, id int64, status entities.NotificationStatus) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -58,14 +79,101 @@ func (r *NotificationRepositoryInMemory) UpdateStatus(ctx context.Context, id in
 	return nil
 }
 
+func (r *NotificationRepositoryInMemory) UpdateDelivery(ctx context.Context, notification *entities.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.notifications[notification.ID]
+	if !ok {
+		return errors.New("notificação não encontrada")
+	}
+	stored.Status = notification.Status
+	stored.Attempts = notification.Attempts
+	stored.LastError = notification.LastError
+	stored.NextAttemptAt = notification.NextAttemptAt
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *NotificationRepositoryInMemory) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []*entities.Notification
+	for _, notification := range r.notifications {
+		if notification.Status == entities.NotificationStatusFailed && notification.NextAttemptAt != nil && !notification.NextAttemptAt.After(now) {
+			due = append(due, notification)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	leaseUntil := now.Add(lease)
+	claimed := make([]entities.Notification, 0, len(due))
+	for _, notification := range due {
+		notification.NextAttemptAt = &leaseUntil
+		claimed = append(claimed, *notification)
+	}
+	return claimed, nil
+}
+
+func (r *NotificationRepositoryInMemory) ListByStatus(ctx context.Context, status entities.NotificationStatus, afterID int64, limit int) ([]entities.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var notifications []entities.Notification
+	for _, notification := range r.notifications {
+		if notification.Status == status && notification.ID > afterID {
+			notifications = append(notifications, *notification)
+		}
+	}
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].ID < notifications[j].ID
+	})
+	if len(notifications) > limit {
+		notifications = notifications[:limit]
+	}
+	return notifications, nil
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func (r *NotificationRepositoryInMemory) Snapshot() func() {
+	r.mu.RLock()
+	saved := make(map[int64]entities.Notification, len(r.notifications))
+	for id, notification := range r.notifications {
+		saved[id] = *notification
+	}
+	nextID := r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for id, notification := range r.notifications {
+			if previous, ok := saved[id]; ok {
+				*notification = previous
+			} else {
+				delete(r.notifications, id)
+			}
+		}
+		r.nextID = nextID
+	}
+}
+
 func TestNotificationRepositoryInMemory_Create(t *testing.T) {
 	repo := NewNotificationRepositoryInMemory()
 	ctx := context.Background()
 
 	notification := &entities.Notification{
 		ReceiverID:    1,
-		TransactionID: 100,
-		Amount:        50.00,
+		TransactionID: int64Ptr(100),
+		Amount:        entities.MoneyFromCents(5000),
 		Status:        entities.NotificationStatusPending,
 		CreatedAt:     time.Now(),
 	}
@@ -88,8 +196,8 @@ func TestNotificationRepositoryInMemory_GetByID_Found(t *testing.T) {
 
 	expectedNotification := &entities.Notification{
 		ReceiverID:    2,
-		TransactionID: 200,
-		Amount:        100.00,
+		TransactionID: int64Ptr(200),
+		Amount:        entities.MoneyFromCents(10000),
 		Status:        entities.NotificationStatusSent,
 		CreatedAt:     time.Now(),
 	}
@@ -118,8 +226,8 @@ func TestNotificationRepositoryInMemory_UpdateStatus_Success(t *testing.T) {
 
 	initialNotification := &entities.Notification{
 		ReceiverID:    3,
-		TransactionID: 300,
-		Amount:        25.50,
+		TransactionID: int64Ptr(300),
+		Amount:        entities.MoneyFromCents(2550),
 		Status:        entities.NotificationStatusPending,
 		CreatedAt:     time.Now(),
 	}
@@ -148,3 +256,63 @@ func TestNotificationRepositoryInMemory_UpdateStatus_NotFound(t *testing.T) {
 	assert.ErrorContains(t, err, "notificação não encontrada")
 	assert.Nil(t, retrievedNotification)
 }
+
+func TestNotificationRepositoryInMemory_ClaimDue(t *testing.T) {
+	repo := NewNotificationRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	dueID, _ := repo.Create(ctx, &entities.Notification{Status: entities.NotificationStatusFailed, NextAttemptAt: &past})
+	_, _ = repo.Create(ctx, &entities.Notification{Status: entities.NotificationStatusFailed, NextAttemptAt: &future})
+	_, _ = repo.Create(ctx, &entities.Notification{Status: entities.NotificationStatusDead})
+
+	claimed, err := repo.ClaimDue(ctx, now, 30*time.Second, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+	assert.Equal(t, dueID, claimed[0].ID)
+
+	claimed, err = repo.ClaimDue(ctx, now, 30*time.Second, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed, "claimed notifications are leased until the lease expires")
+}
+
+func TestNotificationRepositoryInMemory_UpdateDelivery(t *testing.T) {
+	repo := NewNotificationRepositoryInMemory()
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, &entities.Notification{Status: entities.NotificationStatusPending})
+	assert.NoError(t, err)
+
+	err = repo.UpdateDelivery(ctx, &entities.Notification{ID: id, Status: entities.NotificationStatusDead, Attempts: 5, LastError: "timeout"})
+	assert.NoError(t, err)
+
+	stored, err := repo.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.NotificationStatusDead, stored.Status)
+	assert.Equal(t, 5, stored.Attempts)
+	assert.Equal(t, "timeout", stored.LastError)
+	assert.Nil(t, stored.NextAttemptAt)
+}
+
+func TestNotificationRepositoryInMemory_ListByStatus(t *testing.T) {
+	repo := NewNotificationRepositoryInMemory()
+	ctx := context.Background()
+
+	first, _ := repo.Create(ctx, &entities.Notification{Status: entities.NotificationStatusDead})
+	_, _ = repo.Create(ctx, &entities.Notification{Status: entities.NotificationStatusSent})
+	second, _ := repo.Create(ctx, &entities.Notification{Status: entities.NotificationStatusDead})
+	third, _ := repo.Create(ctx, &entities.Notification{Status: entities.NotificationStatusDead})
+
+	page, err := repo.ListByStatus(ctx, entities.NotificationStatusDead, 0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+	assert.Equal(t, first, page[0].ID)
+	assert.Equal(t, second, page[1].ID)
+
+	page, err = repo.ListByStatus(ctx, entities.NotificationStatusDead, second, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page, 1)
+	assert.Equal(t, third, page[0].ID)
+}
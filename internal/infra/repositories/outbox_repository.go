@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{
+		db: db,
+	}
+}
+
+func (r *OutboxRepository) Create(ctx context.Context, message *entities.OutboxMessage) error {
+	return r.db.WithContext(ctx).Create(message).Error
+}
+
+func (r *OutboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.OutboxMessage, error) {
+	var messages []entities.OutboxMessage
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE outbox_messages
+		SET available_at = ?, attempts = attempts + 1, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM outbox_messages
+			WHERE status = ? AND available_at <= ?
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), now, entities.OutboxStatusPending, now, limit,
+	).Scan(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkDone(ctx context.Context, id int64, processedAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       entities.OutboxStatusDone,
+		"processed_at": processedAt,
+		"last_error":   "",
+	}).Error
+}
+
+func (r *OutboxRepository) MarkDead(ctx context.Context, id int64, lastError string) error {
+	return r.db.WithContext(ctx).Model(&entities.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":     entities.OutboxStatusDead,
+		"last_error": lastError,
+	}).Error
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, lastError string, retryAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&entities.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"last_error":   lastError,
+		"available_at": retryAt,
+	}).Error
+}
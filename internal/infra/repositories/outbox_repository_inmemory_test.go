@@ -0,0 +1,181 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type OutboxRepositoryInMemory struct {
+	messages []*entities.OutboxMessage
+	mu       sync.RWMutex
+	nextID   int64
+}
+
+func NewOutboxRepositoryInMemory() port.OutboxRepository {
+	return &OutboxRepositoryInMemory{
+		mu:     sync.RWMutex{},
+		nextID: 1,
+	}
+}
+
+func (r *OutboxRepositoryInMemory) Create(ctx context.Context, message *entities.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	message.ID = r.nextID
+	message.CreatedAt = time.Now()
+	stored := *message
+	r.messages = append(r.messages, &stored)
+	r.nextID++
+	return nil
+}
+
+func (r *OutboxRepositoryInMemory) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []entities.OutboxMessage
+	for _, message := range r.messages {
+		if len(claimed) == limit {
+			break
+		}
+		if message.Status != entities.OutboxStatusPending || message.AvailableAt.After(now) {
+			continue
+		}
+		message.AvailableAt = now.Add(lease)
+		message.Attempts++
+		claimed = append(claimed, *message)
+	}
+	return claimed, nil
+}
+
+func (r *OutboxRepositoryInMemory) MarkDone(ctx context.Context, id int64, processedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	message, err := r.find(id)
+	if err != nil {
+		return err
+	}
+	message.Status = entities.OutboxStatusDone
+	message.ProcessedAt = &processedAt
+	message.LastError = ""
+	return nil
+}
+
+func (r *OutboxRepositoryInMemory) MarkFailed(ctx context.Context, id int64, lastError string, retryAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	message, err := r.find(id)
+	if err != nil {
+		return err
+	}
+	message.LastError = lastError
+	message.AvailableAt = retryAt
+	return nil
+}
+
+func (r *OutboxRepositoryInMemory) MarkDead(ctx context.Context, id int64, lastError string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	message, err := r.find(id)
+	if err != nil {
+		return err
+	}
+	message.Status = entities.OutboxStatusDead
+	message.LastError = lastError
+	return nil
+}
+
+func (r *OutboxRepositoryInMemory) find(id int64) (*entities.OutboxMessage, error) {
+	for _, message := range r.messages {
+		if message.ID == id {
+			return message, nil
+		}
+	}
+	return nil, errors.New("mensagem não encontrada")
+}
+
+func (r *OutboxRepositoryInMemory) Snapshot() func() {
+	r.mu.RLock()
+	length := len(r.messages)
+	nextID := r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.messages = r.messages[:length]
+		r.nextID = nextID
+	}
+}
+
+func TestOutboxRepositoryInMemory_ClaimPending_LeasesMessages(t *testing.T) {
+	repo := NewOutboxRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for transactionID := int64(1); transactionID <= 3; transactionID++ {
+		message, err := entities.NewOutboxMessage(entities.TransferNotificationPayload{ReceiverID: 2, TransactionID: transactionID, Amount: entities.MoneyFromCents(100)}, now)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Create(ctx, message))
+	}
+
+	claimed, err := repo.ClaimPending(ctx, now, time.Minute, 2)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 2)
+	assert.Equal(t, int64(1), claimed[0].ID)
+	assert.Equal(t, 1, claimed[0].Attempts)
+
+	claimed, err = repo.ClaimPending(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+	assert.Equal(t, int64(3), claimed[0].ID)
+
+	claimed, err = repo.ClaimPending(ctx, now.Add(time.Minute), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 3)
+	assert.Equal(t, 2, claimed[0].Attempts)
+}
+
+func TestOutboxRepositoryInMemory_MarkDone(t *testing.T) {
+	repo := NewOutboxRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	message, err := entities.NewOutboxMessage(entities.TransferNotificationPayload{ReceiverID: 2, TransactionID: 1, Amount: entities.MoneyFromCents(100)}, now)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Create(ctx, message))
+
+	assert.NoError(t, repo.MarkDone(ctx, message.ID, now))
+
+	claimed, err := repo.ClaimPending(ctx, now.Add(time.Hour), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestOutboxRepositoryInMemory_MarkFailed(t *testing.T) {
+	repo := NewOutboxRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	message, err := entities.NewOutboxMessage(entities.TransferNotificationPayload{ReceiverID: 2, TransactionID: 1, Amount: entities.MoneyFromCents(100)}, now)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.Create(ctx, message))
+
+	assert.NoError(t, repo.MarkFailed(ctx, message.ID, "notifier down", now.Add(10*time.Second)))
+
+	claimed, err := repo.ClaimPending(ctx, now.Add(5*time.Second), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	claimed, err = repo.ClaimPending(ctx, now.Add(10*time.Second), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+	assert.Equal(t, "notifier down", claimed[0].LastError)
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ScheduledTransferRepository struct {
+	db *gorm.DB
+}
+
+func NewScheduledTransferRepository(db *gorm.DB) *ScheduledTransferRepository {
+	return &ScheduledTransferRepository{
+		db: db,
+	}
+}
+
+func (r *ScheduledTransferRepository) Create(ctx context.Context, scheduled *entities.ScheduledTransfer) error {
+	return r.db.WithContext(ctx).Create(scheduled).Error
+}
+
+func (r *ScheduledTransferRepository) GetByID(ctx context.Context, id int64) (*entities.ScheduledTransfer, error) {
+	scheduled := &entities.ScheduledTransfer{}
+	err := r.db.WithContext(ctx).First(scheduled, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, port.ErrScheduledTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+func (r *ScheduledTransferRepository) ListByPayer(ctx context.Context, payerID, afterID int64, limit int) ([]entities.ScheduledTransfer, error) {
+	var scheduled []entities.ScheduledTransfer
+	err := r.db.WithContext(ctx).
+		Where("payer_id = ? AND id > ?", payerID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&scheduled).Error
+	if err != nil {
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+func (r *ScheduledTransferRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.ScheduledTransfer, error) {
+	var scheduled []entities.ScheduledTransfer
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE scheduled_transfers
+		SET leased_until = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM scheduled_transfers
+			WHERE status = ? AND execute_at <= ? AND COALESCE(next_attempt_at, execute_at) <= ?
+				AND (leased_until IS NULL OR leased_until <= ?)
+			ORDER BY execute_at, id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now.Add(lease), now, entities.ScheduledTransferStatusScheduled, now, now, now, limit,
+	).Scan(&scheduled).Error
+	if err != nil {
+		return nil, err
+	}
+	// Raw scans skip AfterFind.
+	for i := range scheduled {
+		scheduled[i].Amount = entities.NewMoney(scheduled[i].Amount.Cents, scheduled[i].Currency)
+	}
+	return scheduled, nil
+}
+
+func (r *ScheduledTransferRepository) Transition(ctx context.Context, scheduled *entities.ScheduledTransfer, from entities.ScheduledTransferStatus, now time.Time) error {
+	result := r.db.WithContext(ctx).Model(&entities.ScheduledTransfer{}).
+		Where("id = ? AND status = ? AND (leased_until IS NULL OR leased_until <= ?)", scheduled.ID, from, now).
+		Updates(progress(scheduled))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrScheduledTransferChanged
+	}
+	return nil
+}
+
+func (r *ScheduledTransferRepository) Release(ctx context.Context, scheduled *entities.ScheduledTransfer) error {
+	values := progress(scheduled)
+	values["leased_until"] = nil
+	return r.db.WithContext(ctx).Model(&entities.ScheduledTransfer{}).Where("id = ?", scheduled.ID).Updates(values).Error
+}
+
+// progress holds the columns that change as a scheduled transfer runs.
+func progress(scheduled *entities.ScheduledTransfer) map[string]interface{} {
+	return map[string]interface{}{
+		"status":          scheduled.Status,
+		"execute_at":      scheduled.ExecuteAt,
+		"occurrences":     scheduled.Occurrences,
+		"attempts":        scheduled.Attempts,
+		"next_attempt_at": scheduled.NextAttemptAt,
+		"transaction_id":  scheduled.TransactionID,
+		"failure_reason":  scheduled.FailureReason,
+	}
+}
+
+func (r *ScheduledTransferRepository) CreateRun(ctx context.Context, run *entities.ScheduledTransferRun) error {
+	result := r.db.WithContext(ctx).Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(run)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrScheduledTransferRunExists
+	}
+	return nil
+}
+
+func (r *ScheduledTransferRepository) GetRun(ctx context.Context, scheduledTransferID int64, scheduledFor time.Time, attempt int) (*entities.ScheduledTransferRun, error) {
+	run := &entities.ScheduledTransferRun{}
+	err := r.db.WithContext(ctx).
+		Where("scheduled_transfer_id = ? AND scheduled_for = ? AND attempt = ?", scheduledTransferID, scheduledFor, attempt).
+		First(run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, port.ErrScheduledTransferRunNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (r *ScheduledTransferRepository) FinishRun(ctx context.Context, run *entities.ScheduledTransferRun) error {
+	return r.db.WithContext(ctx).Model(&entities.ScheduledTransferRun{}).Where("id = ?", run.ID).Updates(map[string]interface{}{
+		"status":         run.Status,
+		"transaction_id": run.TransactionID,
+		"error":          run.Error,
+		"failure_code":   run.FailureCode,
+		"finished_at":    run.FinishedAt,
+	}).Error
+}
+
+func (r *ScheduledTransferRepository) ListRuns(ctx context.Context, scheduledTransferID int64, limit int) ([]entities.ScheduledTransferRun, error) {
+	var runs []entities.ScheduledTransferRun
+	err := r.db.WithContext(ctx).
+		Where("scheduled_transfer_id = ?", scheduledTransferID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&runs).Error
+	if err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
@@ -0,0 +1,321 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ScheduledTransferRepositoryInMemory struct {
+	transfers map[int64]entities.ScheduledTransfer
+	runs      map[int64]entities.ScheduledTransferRun
+	mu        sync.RWMutex
+	nextID    int64
+	nextRunID int64
+}
+
+func NewScheduledTransferRepositoryInMemory() port.ScheduledTransferRepository {
+	return &ScheduledTransferRepositoryInMemory{
+		transfers: make(map[int64]entities.ScheduledTransfer),
+		runs:      make(map[int64]entities.ScheduledTransferRun),
+		mu:        sync.RWMutex{},
+		nextID:    1,
+		nextRunID: 1,
+	}
+}
+
+func (r *ScheduledTransferRepositoryInMemory) Create(ctx context.Context, scheduled *entities.ScheduledTransfer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	scheduled.ID = r.nextID
+	scheduled.CreatedAt = time.Now()
+	r.transfers[scheduled.ID] = *scheduled
+	r.nextID++
+	return nil
+}
+
+func (r *ScheduledTransferRepositoryInMemory) GetByID(ctx context.Context, id int64) (*entities.ScheduledTransfer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scheduled, ok := r.transfers[id]
+	if !ok {
+		return nil, port.ErrScheduledTransferNotFound
+	}
+	return &scheduled, nil
+}
+
+func (r *ScheduledTransferRepositoryInMemory) ListByPayer(ctx context.Context, payerID, afterID int64, limit int) ([]entities.ScheduledTransfer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var scheduled []entities.ScheduledTransfer
+	for _, candidate := range r.transfers {
+		if candidate.PayerID == payerID && candidate.ID > afterID {
+			scheduled = append(scheduled, candidate)
+		}
+	}
+	sort.Slice(scheduled, func(i, j int) bool { return scheduled[i].ID < scheduled[j].ID })
+	if len(scheduled) > limit {
+		scheduled = scheduled[:limit]
+	}
+	return scheduled, nil
+}
+
+func (r *ScheduledTransferRepositoryInMemory) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.ScheduledTransfer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []entities.ScheduledTransfer
+	for _, scheduled := range r.transfers {
+		if scheduled.Status == entities.ScheduledTransferStatusScheduled && !scheduled.DueAt().After(now) && !scheduled.IsLeased(now) {
+			due = append(due, scheduled)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].ExecuteAt.Equal(due[j].ExecuteAt) {
+			return due[i].ExecuteAt.Before(due[j].ExecuteAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	leasedUntil := now.Add(lease)
+	for i := range due {
+		due[i].LeasedUntil = &leasedUntil
+		r.transfers[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (r *ScheduledTransferRepositoryInMemory) Transition(ctx context.Context, scheduled *entities.ScheduledTransfer, from entities.ScheduledTransferStatus, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.transfers[scheduled.ID]
+	if !ok || stored.Status != from || stored.IsLeased(now) {
+		return port.ErrScheduledTransferChanged
+	}
+	r.transfers[scheduled.ID] = progress(stored, scheduled)
+	return nil
+}
+
+func (r *ScheduledTransferRepositoryInMemory) Release(ctx context.Context, scheduled *entities.ScheduledTransfer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.transfers[scheduled.ID]
+	if !ok {
+		return errors.New("transferência agendada não encontrada")
+	}
+	stored = progress(stored, scheduled)
+	stored.LeasedUntil = nil
+	r.transfers[scheduled.ID] = stored
+	return nil
+}
+
+func progress(stored entities.ScheduledTransfer, scheduled *entities.ScheduledTransfer) entities.ScheduledTransfer {
+	stored.Status = scheduled.Status
+	stored.ExecuteAt = scheduled.ExecuteAt
+	stored.Occurrences = scheduled.Occurrences
+	stored.Attempts = scheduled.Attempts
+	stored.NextAttemptAt = scheduled.NextAttemptAt
+	stored.TransactionID = scheduled.TransactionID
+	stored.FailureReason = scheduled.FailureReason
+	return stored
+}
+
+func (r *ScheduledTransferRepositoryInMemory) CreateRun(ctx context.Context, run *entities.ScheduledTransferRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.findRun(run.ScheduledTransferID, run.ScheduledFor, run.Attempt); err == nil {
+		return port.ErrScheduledTransferRunExists
+	}
+	run.ID = r.nextRunID
+	r.runs[run.ID] = *run
+	r.nextRunID++
+	return nil
+}
+
+func (r *ScheduledTransferRepositoryInMemory) GetRun(ctx context.Context, scheduledTransferID int64, scheduledFor time.Time, attempt int) (*entities.ScheduledTransferRun, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.findRun(scheduledTransferID, scheduledFor, attempt)
+}
+
+func (r *ScheduledTransferRepositoryInMemory) FinishRun(ctx context.Context, run *entities.ScheduledTransferRun) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.runs[run.ID]
+	if !ok {
+		return errors.New("execução não encontrada")
+	}
+	stored.Status = run.Status
+	stored.TransactionID = run.TransactionID
+	stored.Error = run.Error
+	stored.FailureCode = run.FailureCode
+	stored.FinishedAt = run.FinishedAt
+	r.runs[run.ID] = stored
+	return nil
+}
+
+func (r *ScheduledTransferRepositoryInMemory) ListRuns(ctx context.Context, scheduledTransferID int64, limit int) ([]entities.ScheduledTransferRun, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var runs []entities.ScheduledTransferRun
+	for _, run := range r.runs {
+		if run.ScheduledTransferID == scheduledTransferID {
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID > runs[j].ID })
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func (r *ScheduledTransferRepositoryInMemory) findRun(scheduledTransferID int64, scheduledFor time.Time, attempt int) (*entities.ScheduledTransferRun, error) {
+	for _, run := range r.runs {
+		if run.ScheduledTransferID == scheduledTransferID && run.ScheduledFor.Equal(scheduledFor) && run.Attempt == attempt {
+			return &run, nil
+		}
+	}
+	return nil, port.ErrScheduledTransferRunNotFound
+}
+
+func (r *ScheduledTransferRepositoryInMemory) Snapshot() func() {
+	r.mu.RLock()
+	transfers := maps.Clone(r.transfers)
+	runs := maps.Clone(r.runs)
+	nextID, nextRunID := r.nextID, r.nextRunID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.transfers = transfers
+		r.runs = runs
+		r.nextID, r.nextRunID = nextID, nextRunID
+	}
+}
+
+func TestScheduledTransferRepositoryInMemory_ClaimDue_LeasesDueTransfers(t *testing.T) {
+	repo := NewScheduledTransferRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, executeAt := range []time.Time{now.Add(-time.Minute), now.Add(-time.Hour), now.Add(time.Hour)} {
+		scheduled := &entities.ScheduledTransfer{PayerID: 1, PayeeID: 2, Amount: entities.MoneyFromCents(100), Currency: entities.DefaultCurrency, ExecuteAt: executeAt, Status: entities.ScheduledTransferStatusScheduled}
+		assert.NoError(t, repo.Create(ctx, scheduled))
+	}
+
+	claimed, err := repo.ClaimDue(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 2)
+	assert.Equal(t, int64(2), claimed[0].ID)
+	assert.Equal(t, int64(1), claimed[1].ID)
+
+	claimed, err = repo.ClaimDue(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	claimed, err = repo.ClaimDue(ctx, now.Add(time.Minute), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 2)
+}
+
+func TestScheduledTransferRepositoryInMemory_ClaimDue_WaitsForRetry(t *testing.T) {
+	repo := NewScheduledTransferRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	retryAt := now.Add(time.Hour)
+
+	scheduled := &entities.ScheduledTransfer{PayerID: 1, PayeeID: 2, ExecuteAt: now.Add(-time.Minute), Status: entities.ScheduledTransferStatusScheduled, Attempts: 1, NextAttemptAt: &retryAt}
+	assert.NoError(t, repo.Create(ctx, scheduled))
+
+	claimed, err := repo.ClaimDue(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	claimed, err = repo.ClaimDue(ctx, retryAt, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+}
+
+func TestScheduledTransferRepositoryInMemory_TransitionOnlyFromExpectedStatus(t *testing.T) {
+	repo := NewScheduledTransferRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	first := &entities.ScheduledTransfer{PayerID: 1, PayeeID: 2, ExecuteAt: now, Status: entities.ScheduledTransferStatusScheduled}
+	second := &entities.ScheduledTransfer{PayerID: 1, PayeeID: 2, ExecuteAt: now.Add(time.Hour), Status: entities.ScheduledTransferStatusScheduled}
+	assert.NoError(t, repo.Create(ctx, first))
+	assert.NoError(t, repo.Create(ctx, second))
+	_, err := repo.ClaimDue(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+
+	first.Status = entities.ScheduledTransferStatusCancelled
+	second.Status = entities.ScheduledTransferStatusCancelled
+	assert.ErrorIs(t, repo.Transition(ctx, first, entities.ScheduledTransferStatusScheduled, now), port.ErrScheduledTransferChanged)
+	assert.NoError(t, repo.Transition(ctx, second, entities.ScheduledTransferStatusScheduled, now))
+	assert.ErrorIs(t, repo.Transition(ctx, second, entities.ScheduledTransferStatusScheduled, now), port.ErrScheduledTransferChanged)
+
+	cancelled, err := repo.GetByID(ctx, second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ScheduledTransferStatusCancelled, cancelled.Status)
+}
+
+func TestScheduledTransferRepositoryInMemory_ListByPayer(t *testing.T) {
+	repo := NewScheduledTransferRepositoryInMemory()
+	ctx := context.Background()
+
+	for _, payerID := range []int64{1, 2, 1, 1} {
+		assert.NoError(t, repo.Create(ctx, &entities.ScheduledTransfer{PayerID: payerID, PayeeID: 3, Status: entities.ScheduledTransferStatusScheduled}))
+	}
+
+	page, err := repo.ListByPayer(ctx, 1, 0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+	assert.Equal(t, int64(1), page[0].ID)
+	assert.Equal(t, int64(3), page[1].ID)
+
+	page, err = repo.ListByPayer(ctx, 1, 3, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page, 1)
+	assert.Equal(t, int64(4), page[0].ID)
+}
+
+func TestScheduledTransferRepositoryInMemory_CreateRunOncePerAttempt(t *testing.T) {
+	repo := NewScheduledTransferRepositoryInMemory()
+	ctx := context.Background()
+	scheduledFor := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, repo.CreateRun(ctx, &entities.ScheduledTransferRun{ScheduledTransferID: 1, ScheduledFor: scheduledFor, Attempt: 1, Status: entities.ScheduledTransferRunStatusRunning}))
+	err := repo.CreateRun(ctx, &entities.ScheduledTransferRun{ScheduledTransferID: 1, ScheduledFor: scheduledFor, Attempt: 1, Status: entities.ScheduledTransferRunStatusRunning})
+	assert.ErrorIs(t, err, port.ErrScheduledTransferRunExists)
+	assert.NoError(t, repo.CreateRun(ctx, &entities.ScheduledTransferRun{ScheduledTransferID: 1, ScheduledFor: scheduledFor, Attempt: 2, Status: entities.ScheduledTransferRunStatusRunning}))
+	assert.NoError(t, repo.CreateRun(ctx, &entities.ScheduledTransferRun{ScheduledTransferID: 1, ScheduledFor: scheduledFor.Add(24 * time.Hour), Attempt: 1, Status: entities.ScheduledTransferRunStatusRunning}))
+
+	runs, err := repo.ListRuns(ctx, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, runs, 3)
+	assert.Equal(t, int64(3), runs[0].ID)
+
+	run, err := repo.GetRun(ctx, 1, scheduledFor, 1)
+	assert.NoError(t, err)
+	transactionID := int64(99)
+	run.Status = entities.ScheduledTransferRunStatusSucceeded
+	run.TransactionID = &transactionID
+	assert.NoError(t, repo.FinishRun(ctx, run))
+
+	finished, err := repo.GetRun(ctx, 1, scheduledFor, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.ScheduledTransferRunStatusSucceeded, finished.Status)
+	assert.Equal(t, &transactionID, finished.TransactionID)
+}
@@ -2,10 +2,14 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository struct {
@@ -19,22 +23,188 @@ func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
 }
 
 func (r *TransactionRepository) Create(ctx context.Context, transfer *entities.Transaction) (int64, error) {
-	result := r.db.WithContext(ctx).Create(transfer)
-	if result.Error != nil {
-		return 0, result.Error
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createTransaction(tx, transfer); err != nil {
+			return err
+		}
+		return tx.Create(&entities.TransactionStatusChange{
+			TransactionID: transfer.ID,
+			ToStatus:      transfer.Status,
+			Reason:        "created",
+		}).Error
+	})
+	if err != nil {
+		return 0, err
 	}
 	return transfer.ID, nil
 }
 
-func (r *TransactionRepository) UpdateStatus(ctx context.Context, id int64, status entities.TransactionStatus) error {
-	return r.db.WithContext(ctx).Model(&entities.Transaction{}).Where("id = ?", id).Update("status", status).Error
+// createTransaction inserts transfer and its legs. A transfer reusing an
+// external reference of another of its sender's transfers that did not fail
+// is not inserted, and its legs are only inserted once the transfer is.
+func createTransaction(tx *gorm.DB, transfer *entities.Transaction) error {
+	if transfer.Details.ExternalReference == nil {
+		return tx.Create(transfer).Error
+	}
+	result := tx.Omit("Legs").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "sender_id"}, {Name: "external_reference"}},
+		// Matches the partial unique index, which leaves out failed transfers.
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "status <> 'FAILED'"}}},
+		DoNothing:   true,
+	}).Create(transfer)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrExternalReferenceExists
+	}
+	if len(transfer.Legs) == 0 {
+		return nil
+	}
+	for i := range transfer.Legs {
+		transfer.Legs[i].TransactionID = transfer.ID
+	}
+	return tx.Create(&transfer.Legs).Error
+}
+
+func (r *TransactionRepository) UpdateStatus(ctx context.Context, id int64, from, to entities.TransactionStatus, reason string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entities.Transaction{}).Where("id = ? AND status = ?", id, from).Update("status", to)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return port.ErrTransactionStatusConflict
+		}
+		return tx.Create(&entities.TransactionStatusChange{
+			TransactionID: id,
+			FromStatus:    from,
+			ToStatus:      to,
+			Reason:        reason,
+		}).Error
+	})
+}
+
+func (r *TransactionRepository) UpdateRefundedAmount(ctx context.Context, id int64, refundedAmount entities.Money) error {
+	return r.db.WithContext(ctx).Model(&entities.Transaction{}).Where("id = ?", id).Update("refunded_amount", refundedAmount).Error
+}
+
+func (r *TransactionRepository) UpdateAuthorization(ctx context.Context, id int64, decision entities.AuthorizationDecision) error {
+	return r.db.WithContext(ctx).Model(&entities.Transaction{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"authorization_outcome":     decision.Outcome,
+		"authorization_reason_code": decision.ReasonCode,
+		"authorization_reference":   decision.Reference,
+	}).Error
+}
+
+func (r *TransactionRepository) UpdateHold(ctx context.Context, id int64, held entities.Money) error {
+	return r.db.WithContext(ctx).Model(&entities.Transaction{}).Where("id = ?", id).Update("held_amount", held).Error
 }
 
-func (r *TransactionRepository) GetByID(ctx context.Context, id int64) (*entities.TransactionStatus, error) {
+func (r *TransactionRepository) UpdateCapture(ctx context.Context, transaction *entities.Transaction) error {
+	return r.db.WithContext(ctx).Model(&entities.Transaction{ID: transaction.ID}).
+		Select("amount", "fee", "fee_breakdown", "received_amount", "held_amount").
+		Updates(transaction).Error
+}
+
+func (r *TransactionRepository) ListStatusHistory(ctx context.Context, transactionID int64) ([]entities.TransactionStatusChange, error) {
+	var history []entities.TransactionStatusChange
+	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).Order("id").Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (r *TransactionRepository) GetByID(ctx context.Context, id int64) (*entities.Transaction, error) {
 	transaction := &entities.Transaction{}
-	err := r.db.WithContext(ctx).First(transaction, id).Error
+	err := r.db.WithContext(ctx).Preload("Legs", orderLegs).First(transaction, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, port.ErrTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+func (r *TransactionRepository) ListByUser(ctx context.Context, filter port.TransactionFilter) ([]entities.Transaction, error) {
+	query := r.db.WithContext(ctx).Model(&entities.Transaction{}).Preload("Legs", orderLegs)
+
+	// The payees of split transfers other than the first are only on their legs.
+	legPayee := r.db.Model(&entities.TransactionLeg{}).Select("transaction_id").Where("receiver_id = ?", filter.UserID)
+	switch filter.Direction {
+	case port.TransferDirectionSent:
+		query = query.Where("sender_id = ?", filter.UserID)
+	case port.TransferDirectionReceived:
+		query = query.Where("(receiver_id = ? OR id IN (?))", filter.UserID, legPayee)
+	default:
+		query = query.Where("(sender_id = ? OR receiver_id = ? OR id IN (?))", filter.UserID, filter.UserID, legPayee)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.MinAmount != nil {
+		query = query.Where("amount >= ?", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		query = query.Where("amount <= ?", *filter.MaxAmount)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.ExternalReference != nil {
+		query = query.Where("sender_id = ? AND external_reference = ?", filter.UserID, *filter.ExternalReference)
+	}
+
+	order := "created_at DESC, id DESC"
+	if filter.Order == port.SortAscending {
+		order = "created_at ASC, id ASC"
+		if filter.After != nil {
+			query = query.Where("(created_at, id) > (?, ?)", filter.After.CreatedAt, filter.After.ID)
+		}
+	} else if filter.After != nil {
+		query = query.Where("(created_at, id) < (?, ?)", filter.After.CreatedAt, filter.After.ID)
+	}
+
+	var transactions []entities.Transaction
+	err := query.Order(order).Limit(filter.Limit).Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// orderLegs keeps the legs of a split transfer in the order they were given.
+func orderLegs(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+func (r *TransactionRepository) SumSent(ctx context.Context, senderID int64, from, to time.Time) (entities.Money, error) {
+	var total entities.Money
+	err := r.db.WithContext(ctx).Model(&entities.Transaction{}).
+		Select("COALESCE(SUM(amount - refunded_amount), 0)").
+		Where("sender_id = ? AND type = ? AND status IN ?", senderID, entities.TransactionTypeTransfer, entities.SettledStatuses).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Scan(&total).Error
+	if err != nil {
+		return entities.Money{}, err
+	}
+	return total, nil
+}
+
+func (r *TransactionRepository) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]entities.Transaction, error) {
+	var transactions []entities.Transaction
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND hold_expires_at <= ?", entities.TransactionStatusAuthorized, now).
+		Order("hold_expires_at, id").
+		Limit(limit).
+		Find(&transactions).Error
 	if err != nil {
 		return nil, err
 	}
-	return &transaction.Status, nil
+	return transactions, nil
 }
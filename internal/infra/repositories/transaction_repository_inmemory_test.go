@@ -3,6 +3,8 @@ package repositories_test
 import (
 	"context"
 	"errors"
+	"slices"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -15,6 +17,7 @@ import (
 
 type TransactionRepositoryInMemory struct {
 	transactions map[int64]*entities.Transaction
+	history      []entities.TransactionStatusChange
 	mu           sync.RWMutex
 	nextID       int64
 }
@@ -30,32 +33,267 @@ func NewTransactionRepositoryInMemory() port.TransactionRepository {
 func (r *TransactionRepositoryInMemory) Create(ctx context.Context, transfer *entities.Transaction) (int64, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	if reference := transfer.Details.ExternalReference; reference != nil {
+		for _, existing := range r.transactions {
+			if existing.SenderID == transfer.SenderID && existing.Status != entities.TransactionStatusFailed &&
+				existing.Details.ExternalReference != nil && *existing.Details.ExternalReference == *reference {
+				return 0, port.ErrExternalReferenceExists
+			}
+		}
+	}
 	transfer.ID = r.nextID
 	r.transactions[transfer.ID] = transfer
 	r.nextID++
+	r.recordStatusChange(transfer.ID, "", transfer.Status, "created")
 	return transfer.ID, nil
 }
 
-func (r *TransactionRepositoryInMemory) UpdateStatus(ctx context.Context, id int64, status entities.TransactionStatus) error {
+func (r *TransactionRepositoryInMemory) UpdateStatus(ctx context.Context, id int64, from, to entities.TransactionStatus, reason string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	transaction, ok := r.transactions[id]
+	if !ok {
+		return errors.New("transação não encontrada")
+	}
+	if transaction.Status != from {
+		return port.ErrTransactionStatusConflict
+	}
+	transaction.Status = to
+	transaction.UpdatedAt = time.Now()
+	r.recordStatusChange(id, from, to, reason)
+	return nil
+}
+
+func (r *TransactionRepositoryInMemory) recordStatusChange(id int64, from, to entities.TransactionStatus, reason string) {
+	r.history = append(r.history, entities.TransactionStatusChange{
+		ID:            int64(len(r.history) + 1),
+		TransactionID: id,
+		FromStatus:    from,
+		ToStatus:      to,
+		Reason:        reason,
+		CreatedAt:     time.Now(),
+	})
+}
+
+func (r *TransactionRepositoryInMemory) UpdateRefundedAmount(ctx context.Context, id int64, refundedAmount entities.Money) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	transaction, ok := r.transactions[id]
+	if !ok {
+		return errors.New("transação não encontrada")
+	}
+	transaction.RefundedAmount = refundedAmount
+	transaction.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *TransactionRepositoryInMemory) UpdateAuthorization(ctx context.Context, id int64, decision entities.AuthorizationDecision) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	transaction, ok := r.transactions[id]
+	if !ok {
+		return errors.New("transação não encontrada")
+	}
+	transaction.Authorization = decision
+	transaction.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *TransactionRepositoryInMemory) UpdateHold(ctx context.Context, id int64, held entities.Money) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	transaction, ok := r.transactions[id]
 	if !ok {
 		return errors.New("transação não encontrada")
 	}
-	transaction.Status = status
+	transaction.HeldAmount = held
+	transaction.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *TransactionRepositoryInMemory) UpdateCapture(ctx context.Context, captured *entities.Transaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	transaction, ok := r.transactions[captured.ID]
+	if !ok {
+		return errors.New("transação não encontrada")
+	}
+	transaction.Amount = captured.Amount
+	transaction.Fee = captured.Fee
+	transaction.FeeBreakdown = captured.FeeBreakdown
+	transaction.ReceivedAmount = captured.ReceivedAmount
+	transaction.HeldAmount = captured.HeldAmount
 	transaction.UpdatedAt = time.Now()
 	return nil
 }
 
-func (r *TransactionRepositoryInMemory) GetByID(ctx context.Context, id int64) (*entities.TransactionStatus, error) {
+func (r *TransactionRepositoryInMemory) ListStatusHistory(ctx context.Context, transactionID int64) ([]entities.TransactionStatusChange, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var history []entities.TransactionStatusChange
+	for _, change := range r.history {
+		if change.TransactionID == transactionID {
+			history = append(history, change)
+		}
+	}
+	return history, nil
+}
+
+func (r *TransactionRepositoryInMemory) GetByID(ctx context.Context, id int64) (*entities.Transaction, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	transaction, ok := r.transactions[id]
 	if !ok {
 		return nil, errors.New("transação não encontrada")
 	}
-	return &transaction.Status, nil
+	return transaction, nil
+}
+
+func (r *TransactionRepositoryInMemory) ListByUser(ctx context.Context, filter port.TransactionFilter) ([]entities.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var transactions []entities.Transaction
+	for _, transaction := range r.transactions {
+		if matchesTransactionFilter(transaction, filter) {
+			transactions = append(transactions, *transaction)
+		}
+	}
+
+	ascending := filter.Order == port.SortAscending
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactionBefore(transactions[i], transactions[j]) == ascending
+	})
+	if filter.Limit > 0 && len(transactions) > filter.Limit {
+		transactions = transactions[:filter.Limit]
+	}
+	return transactions, nil
+}
+
+func (r *TransactionRepositoryInMemory) SumSent(ctx context.Context, senderID int64, from, to time.Time) (entities.Money, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	total := entities.MoneyFromCents(0)
+	for _, transaction := range r.transactions {
+		if transaction.SenderID != senderID || transaction.Type != entities.TransactionTypeTransfer {
+			continue
+		}
+		if !slices.Contains(entities.SettledStatuses, transaction.Status) {
+			continue
+		}
+		if transaction.CreatedAt.Before(from) || !transaction.CreatedAt.Before(to) {
+			continue
+		}
+		net, err := transaction.RefundableAmount()
+		if err != nil {
+			return entities.Money{}, err
+		}
+		if total, err = total.Add(net); err != nil {
+			return entities.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+func (r *TransactionRepositoryInMemory) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]entities.Transaction, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var expired []entities.Transaction
+	for _, transaction := range r.transactions {
+		if transaction.Status == entities.TransactionStatusAuthorized && transaction.IsHoldExpired(now) {
+			expired = append(expired, *transaction)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		if !expired[i].HoldExpiresAt.Equal(*expired[j].HoldExpiresAt) {
+			return expired[i].HoldExpiresAt.Before(*expired[j].HoldExpiresAt)
+		}
+		return expired[i].ID < expired[j].ID
+	})
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	return expired, nil
+}
+
+func matchesTransactionFilter(transaction *entities.Transaction, filter port.TransactionFilter) bool {
+	switch filter.Direction {
+	case port.TransferDirectionSent:
+		if transaction.SenderID != filter.UserID {
+			return false
+		}
+	case port.TransferDirectionReceived:
+		if !slices.Contains(transaction.PayeeIDs(), filter.UserID) {
+			return false
+		}
+	default:
+		if transaction.SenderID != filter.UserID && !slices.Contains(transaction.PayeeIDs(), filter.UserID) {
+			return false
+		}
+	}
+	if filter.Status != "" && transaction.Status != filter.Status {
+		return false
+	}
+	// Like the amount column, the bounds are compared as plain numbers.
+	if filter.MinAmount != nil && transaction.Amount.Cents < filter.MinAmount.Cents {
+		return false
+	}
+	if filter.MaxAmount != nil && transaction.Amount.Cents > filter.MaxAmount.Cents {
+		return false
+	}
+	if filter.From != nil && transaction.CreatedAt.Before(*filter.From) {
+		return false
+	}
+	if filter.To != nil && !transaction.CreatedAt.Before(*filter.To) {
+		return false
+	}
+	if filter.ExternalReference != nil {
+		reference := transaction.Details.ExternalReference
+		if transaction.SenderID != filter.UserID || reference == nil || *reference != *filter.ExternalReference {
+			return false
+		}
+	}
+	if filter.After != nil {
+		after := entities.Transaction{ID: filter.After.ID, CreatedAt: filter.After.CreatedAt}
+		if filter.Order == port.SortAscending {
+			return transactionBefore(after, *transaction)
+		}
+		return transactionBefore(*transaction, after)
+	}
+	return true
+}
+
+func transactionBefore(a, b entities.Transaction) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+func (r *TransactionRepositoryInMemory) Snapshot() func() {
+	r.mu.RLock()
+	saved := make(map[int64]entities.Transaction, len(r.transactions))
+	for id, transaction := range r.transactions {
+		saved[id] = *transaction
+	}
+	nextID := r.nextID
+	historyLen := len(r.history)
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for id, transaction := range r.transactions {
+			if previous, ok := saved[id]; ok {
+				*transaction = previous
+			} else {
+				delete(r.transactions, id)
+			}
+		}
+		r.nextID = nextID
+		r.history = r.history[:historyLen]
+	}
 }
 
 func TestTransactionRepositoryInMemory_Create(t *testing.T) {
@@ -65,7 +303,7 @@ func TestTransactionRepositoryInMemory_Create(t *testing.T) {
 	transfer := &entities.Transaction{
 		SenderID:   1,
 		ReceiverID: 2,
-		Amount:     50.00,
+		Amount:     entities.MoneyFromCents(5000),
 		Status:     entities.TransactionStatusPending,
 		CreatedAt:  time.Now(),
 	}
@@ -74,9 +312,9 @@ func TestTransactionRepositoryInMemory_Create(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotZero(t, id)
 
-	status, err := repo.GetByID(ctx, id)
+	retrieved, err := repo.GetByID(ctx, id)
 	assert.NoError(t, err)
-	assert.Equal(t, transfer.Status, *status)
+	assert.Equal(t, transfer.Status, retrieved.Status)
 }
 
 func TestTransactionRepositoryInMemory_UpdateStatus_Success(t *testing.T) {
@@ -86,7 +324,7 @@ func TestTransactionRepositoryInMemory_UpdateStatus_Success(t *testing.T) {
 	initialTransfer := &entities.Transaction{
 		SenderID:   1,
 		ReceiverID: 2,
-		Amount:     50.00,
+		Amount:     entities.MoneyFromCents(5000),
 		Status:     entities.TransactionStatusPending,
 		CreatedAt:  time.Now(),
 	}
@@ -94,12 +332,12 @@ func TestTransactionRepositoryInMemory_UpdateStatus_Success(t *testing.T) {
 	assert.NoError(t, err)
 
 	newStatus := entities.TransactionStatusCompleted
-	err = repo.UpdateStatus(ctx, id, newStatus)
+	err = repo.UpdateStatus(ctx, id, entities.TransactionStatusPending, newStatus, "settled")
 	assert.NoError(t, err)
 
-	retrievedStatus, err := repo.GetByID(ctx, id)
+	retrieved, err := repo.GetByID(ctx, id)
 	assert.NoError(t, err)
-	assert.Equal(t, newStatus, *retrievedStatus)
+	assert.Equal(t, newStatus, retrieved.Status)
 }
 
 func TestTransactionRepositoryInMemory_UpdateStatus_NotFound(t *testing.T) {
@@ -108,14 +346,14 @@ func TestTransactionRepositoryInMemory_UpdateStatus_NotFound(t *testing.T) {
 
 	nonExistentID := int64(999)
 	newStatus := entities.TransactionStatusFailed
-	err := repo.UpdateStatus(ctx, nonExistentID, newStatus)
+	err := repo.UpdateStatus(ctx, nonExistentID, entities.TransactionStatusPending, newStatus, "failed")
 	assert.Error(t, err)
 	assert.ErrorContains(t, err, "transação não encontrada")
 
-	status, err := repo.GetByID(ctx, nonExistentID)
+	retrieved, err := repo.GetByID(ctx, nonExistentID)
 	assert.Error(t, err)
 	assert.ErrorContains(t, err, "transação não encontrada")
-	assert.Nil(t, status)
+	assert.Nil(t, retrieved)
 }
 
 func TestTransactionRepositoryInMemory_GetByID_Found(t *testing.T) {
@@ -125,24 +363,312 @@ func TestTransactionRepositoryInMemory_GetByID_Found(t *testing.T) {
 	transfer := &entities.Transaction{
 		SenderID:   3,
 		ReceiverID: 4,
-		Amount:     100.00,
+		Amount:     entities.MoneyFromCents(10000),
 		Status:     entities.TransactionStatusPending,
 		CreatedAt:  time.Now(),
 	}
 	id, err := repo.Create(ctx, transfer)
 	assert.NoError(t, err)
 
-	status, err := repo.GetByID(ctx, id)
+	retrieved, err := repo.GetByID(ctx, id)
 	assert.NoError(t, err)
-	assert.Equal(t, transfer.Status, *status)
+	assert.Equal(t, transfer.ID, retrieved.ID)
+	assert.Equal(t, transfer.SenderID, retrieved.SenderID)
+	assert.Equal(t, transfer.ReceiverID, retrieved.ReceiverID)
+	assert.Equal(t, transfer.Amount, retrieved.Amount)
+	assert.Equal(t, transfer.Status, retrieved.Status)
 }
 
 func TestTransactionRepositoryInMemory_GetByID_NotFound(t *testing.T) {
 	repo := NewTransactionRepositoryInMemory()
 	ctx := context.Background()
 
-	status, err := repo.GetByID(ctx, 999)
+	retrieved, err := repo.GetByID(ctx, 999)
 	assert.Error(t, err)
 	assert.ErrorContains(t, err, "transação não encontrada")
-	assert.Nil(t, status)
+	assert.Nil(t, retrieved)
+}
+
+func seedTransactionHistory(t *testing.T, repo port.TransactionRepository) time.Time {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	transfers := []*entities.Transaction{
+		{SenderID: 1, ReceiverID: 2, Amount: entities.MoneyFromCents(1000), Status: entities.TransactionStatusCompleted, CreatedAt: base},
+		{SenderID: 2, ReceiverID: 1, Amount: entities.MoneyFromCents(2000), Status: entities.TransactionStatusCompleted, CreatedAt: base.Add(time.Hour)},
+		{SenderID: 1, ReceiverID: 3, Amount: entities.MoneyFromCents(3000), Status: entities.TransactionStatusFailed, CreatedAt: base.Add(2 * time.Hour)},
+		{SenderID: 3, ReceiverID: 2, Amount: entities.MoneyFromCents(4000), Status: entities.TransactionStatusCompleted, CreatedAt: base.Add(3 * time.Hour)},
+		{SenderID: 1, ReceiverID: 2, Amount: entities.MoneyFromCents(5000), Status: entities.TransactionStatusCompleted, CreatedAt: base.Add(3 * time.Hour)},
+	}
+	for _, transfer := range transfers {
+		_, err := repo.Create(ctx, transfer)
+		assert.NoError(t, err)
+	}
+	return base
+}
+
+func transactionIDs(transactions []entities.Transaction) []int64 {
+	ids := make([]int64, 0, len(transactions))
+	for _, transaction := range transactions {
+		ids = append(ids, transaction.ID)
+	}
+	return ids
+}
+
+func TestTransactionRepositoryInMemory_ListByUser_Directions(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+	seedTransactionHistory(t, repo)
+
+	all, err := repo.ListByUser(ctx, port.TransactionFilter{UserID: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 3, 2, 1}, transactionIDs(all))
+
+	sent, err := repo.ListByUser(ctx, port.TransactionFilter{UserID: 1, Direction: port.TransferDirectionSent, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{5, 3, 1}, transactionIDs(sent))
+
+	received, err := repo.ListByUser(ctx, port.TransactionFilter{UserID: 1, Direction: port.TransferDirectionReceived, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2}, transactionIDs(received))
+}
+
+func TestTransactionRepositoryInMemory_ListByUser_SplitPayees(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+	seedTransactionHistory(t, repo)
+	split := &entities.Transaction{
+		SenderID:   3,
+		ReceiverID: 2,
+		Amount:     entities.MoneyFromCents(1000),
+		Status:     entities.TransactionStatusCompleted,
+		CreatedAt:  time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC),
+		Legs: []entities.TransactionLeg{
+			{ReceiverID: 2, Amount: entities.MoneyFromCents(700)},
+			{ReceiverID: 1, Amount: entities.MoneyFromCents(300)},
+		},
+	}
+	_, err := repo.Create(ctx, split)
+	assert.NoError(t, err)
+
+	received, err := repo.ListByUser(ctx, port.TransactionFilter{UserID: 1, Direction: port.TransferDirectionReceived, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{6, 2}, transactionIDs(received))
+
+	all, err := repo.ListByUser(ctx, port.TransactionFilter{UserID: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{6, 5, 3, 2, 1}, transactionIDs(all))
+}
+
+func TestTransactionRepositoryInMemory_ListByUser_Filters(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+	base := seedTransactionHistory(t, repo)
+
+	minAmount := entities.MoneyFromCents(2000)
+	maxAmount := entities.MoneyFromCents(4000)
+	transactions, err := repo.ListByUser(ctx, port.TransactionFilter{UserID: 1, MinAmount: &minAmount, MaxAmount: &maxAmount, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, transactionIDs(transactions))
+
+	transactions, err = repo.ListByUser(ctx, port.TransactionFilter{UserID: 1, Status: entities.TransactionStatusFailed, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3}, transactionIDs(transactions))
+
+	from := base.Add(time.Hour)
+	to := base.Add(3 * time.Hour)
+	transactions, err = repo.ListByUser(ctx, port.TransactionFilter{UserID: 1, From: &from, To: &to, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 2}, transactionIDs(transactions))
+}
+
+func TestTransactionRepositoryInMemory_ExternalReference(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+	reference := "order-1234"
+
+	id, err := repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Details: entities.TransferDetails{ExternalReference: &reference}})
+	assert.NoError(t, err)
+	_, err = repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 3, Details: entities.TransferDetails{ExternalReference: &reference}})
+	assert.ErrorIs(t, err, port.ErrExternalReferenceExists)
+	_, err = repo.Create(ctx, &entities.Transaction{SenderID: 2, ReceiverID: 1, Details: entities.TransferDetails{ExternalReference: &reference}})
+	assert.NoError(t, err)
+
+	transactions, err := repo.ListByUser(ctx, port.TransactionFilter{UserID: 1, ExternalReference: &reference, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{id}, transactionIDs(transactions))
+}
+
+func TestTransactionRepositoryInMemory_ExternalReferenceOfFailedTransferIsReused(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+	reference := "order-1234"
+
+	failed, err := repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Status: entities.TransactionStatusPending, Details: entities.TransferDetails{ExternalReference: &reference}})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.UpdateStatus(ctx, failed, entities.TransactionStatusPending, entities.TransactionStatusFailed, "authorization denied"))
+
+	retried, err := repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Status: entities.TransactionStatusPending, Details: entities.TransferDetails{ExternalReference: &reference}})
+	assert.NoError(t, err)
+	_, err = repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Status: entities.TransactionStatusPending, Details: entities.TransferDetails{ExternalReference: &reference}})
+	assert.ErrorIs(t, err, port.ErrExternalReferenceExists)
+
+	transactions, err := repo.ListByUser(ctx, port.TransactionFilter{UserID: 1, ExternalReference: &reference, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{retried, failed}, transactionIDs(transactions))
+}
+
+func TestTransactionRepositoryInMemory_ListByUser_Cursor(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+	base := seedTransactionHistory(t, repo)
+
+	after := &port.TransactionCursor{CreatedAt: base.Add(3 * time.Hour), ID: 5}
+	transactions, err := repo.ListByUser(ctx, port.TransactionFilter{UserID: 2, After: after, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{4, 2}, transactionIDs(transactions))
+
+	after = &port.TransactionCursor{CreatedAt: base.Add(time.Hour), ID: 2}
+	transactions, err = repo.ListByUser(ctx, port.TransactionFilter{UserID: 2, Order: port.SortAscending, After: after, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{4, 5}, transactionIDs(transactions))
+}
+
+func TestTransactionRepositoryInMemory_UpdateStatus_StaleStatus(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Amount: entities.MoneyFromCents(5000), Status: entities.TransactionStatusFailed})
+	assert.NoError(t, err)
+
+	err = repo.UpdateStatus(ctx, id, entities.TransactionStatusPending, entities.TransactionStatusCompleted, "settled")
+	assert.ErrorIs(t, err, port.ErrTransactionStatusConflict)
+
+	retrieved, err := repo.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusFailed, retrieved.Status)
+}
+
+func TestTransactionRepositoryInMemory_ListStatusHistory(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Amount: entities.MoneyFromCents(5000), Status: entities.TransactionStatusPending})
+	assert.NoError(t, err)
+	_, err = repo.Create(ctx, &entities.Transaction{SenderID: 3, ReceiverID: 4, Amount: entities.MoneyFromCents(100), Status: entities.TransactionStatusPending})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.UpdateStatus(ctx, id, entities.TransactionStatusPending, entities.TransactionStatusCompleted, "settled"))
+
+	history, err := repo.ListStatusHistory(ctx, id)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, entities.TransactionStatus(""), history[0].FromStatus)
+	assert.Equal(t, entities.TransactionStatusPending, history[0].ToStatus)
+	assert.Equal(t, "created", history[0].Reason)
+	assert.Equal(t, entities.TransactionStatusPending, history[1].FromStatus)
+	assert.Equal(t, entities.TransactionStatusCompleted, history[1].ToStatus)
+	assert.Equal(t, "settled", history[1].Reason)
+}
+
+func TestTransactionRepositoryInMemory_UpdateRefundedAmount(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Amount: entities.MoneyFromCents(5000), Status: entities.TransactionStatusCompleted})
+	assert.NoError(t, err)
+
+	err = repo.UpdateRefundedAmount(ctx, id, entities.MoneyFromCents(2000))
+	assert.NoError(t, err)
+
+	retrieved, err := repo.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(2000), retrieved.RefundedAmount)
+	assert.Equal(t, entities.TransactionStatusCompleted, retrieved.Status)
+}
+
+func TestTransactionRepositoryInMemory_UpdateAuthorization(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Amount: entities.MoneyFromCents(5000), Status: entities.TransactionStatusAuthorizing})
+	assert.NoError(t, err)
+
+	decision := entities.AuthorizationDecision{Outcome: entities.AuthorizationDenied, ReasonCode: "AMOUNT_LIMIT", Reference: "auth-123"}
+	err = repo.UpdateAuthorization(ctx, id, decision)
+	assert.NoError(t, err)
+
+	retrieved, err := repo.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, decision, retrieved.Authorization)
+	assert.Equal(t, entities.TransactionStatusAuthorizing, retrieved.Status)
+}
+
+func TestTransactionRepositoryInMemory_SumSent(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	create := func(senderID int64, cents int64, status entities.TransactionStatus, createdAt time.Time) int64 {
+		id, err := repo.Create(ctx, &entities.Transaction{SenderID: senderID, ReceiverID: 9, Amount: entities.MoneyFromCents(cents), Type: entities.TransactionTypeTransfer, Status: status, CreatedAt: createdAt})
+		assert.NoError(t, err)
+		return id
+	}
+	create(1, 1000, entities.TransactionStatusCompleted, day.Add(time.Hour))
+	refunded := create(1, 3000, entities.TransactionStatusPartiallyRefunded, day.Add(2*time.Hour))
+	create(1, 5000, entities.TransactionStatusFailed, day.Add(3*time.Hour))
+	create(1, 7000, entities.TransactionStatusCompleted, day.Add(-time.Hour))
+	create(2, 9000, entities.TransactionStatusCompleted, day.Add(time.Hour))
+	assert.NoError(t, repo.UpdateRefundedAmount(ctx, refunded, entities.MoneyFromCents(500)))
+
+	total, err := repo.SumSent(ctx, 1, day, day.Add(24*time.Hour))
+
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(3500), total)
+}
+
+func TestTransactionRepositoryInMemory_UpdateCapture(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+
+	authorized := entities.MoneyFromCents(5000)
+	id, err := repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Amount: authorized, AuthorizedAmount: &authorized, Status: entities.TransactionStatusAuthorized})
+	assert.NoError(t, err)
+	assert.NoError(t, repo.UpdateHold(ctx, id, entities.MoneyFromCents(5100)))
+
+	retrieved, err := repo.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(5100), retrieved.HeldAmount)
+
+	err = repo.UpdateCapture(ctx, &entities.Transaction{ID: id, Amount: entities.MoneyFromCents(3000), Fee: entities.MoneyFromCents(100), HeldAmount: entities.MoneyFromCents(0)})
+	assert.NoError(t, err)
+
+	retrieved, err = repo.GetByID(ctx, id)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(3000), retrieved.Amount)
+	assert.Equal(t, entities.MoneyFromCents(100), retrieved.Fee)
+	assert.True(t, retrieved.HeldAmount.IsZero())
+	assert.Equal(t, authorized, *retrieved.AuthorizedAmount)
+}
+
+func TestTransactionRepositoryInMemory_ListExpiredHolds(t *testing.T) {
+	repo := NewTransactionRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	create := func(status entities.TransactionStatus, expiresAt time.Time) int64 {
+		id, err := repo.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Amount: entities.MoneyFromCents(1000), Type: entities.TransactionTypeTransfer, Status: status, HoldExpiresAt: &expiresAt})
+		assert.NoError(t, err)
+		return id
+	}
+	late := create(entities.TransactionStatusAuthorized, now)
+	early := create(entities.TransactionStatusAuthorized, now.Add(-time.Hour))
+	create(entities.TransactionStatusAuthorized, now.Add(time.Minute))
+	create(entities.TransactionStatusCompleted, now.Add(-time.Hour))
+	create(entities.TransactionStatusAuthorized, now.Add(-time.Minute))
+
+	expired, err := repo.ListExpiredHolds(ctx, now, 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, expired, 2)
+	assert.Equal(t, early, expired[0].ID)
+	assert.NotEqual(t, late, expired[1].ID)
 }
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"gorm.io/gorm"
+)
+
+type TransferBatchRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferBatchRepository(db *gorm.DB) *TransferBatchRepository {
+	return &TransferBatchRepository{
+		db: db,
+	}
+}
+
+func (r *TransferBatchRepository) Create(ctx context.Context, batch *entities.TransferBatch) error {
+	return r.db.WithContext(ctx).Create(batch).Error
+}
+
+func (r *TransferBatchRepository) GetByID(ctx context.Context, id int64) (*entities.TransferBatch, error) {
+	batch := &entities.TransferBatch{}
+	err := r.db.WithContext(ctx).Preload("Items", withItemOrder).First(batch, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, port.ErrTransferBatchNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (r *TransferBatchRepository) ClaimQueued(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.TransferBatch, error) {
+	var ids []int64
+	err := r.db.WithContext(ctx).Raw(`
+		UPDATE transfer_batches
+		SET status = ?, leased_until = ?, updated_at = ?, claim_token = gen_random_uuid()::text
+		WHERE id IN (
+			SELECT id FROM transfer_batches
+			WHERE status IN (?, ?) AND (leased_until IS NULL OR leased_until <= ?)
+			ORDER BY id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id`,
+		entities.TransferBatchStatusProcessing, now.Add(lease), now,
+		entities.TransferBatchStatusQueued, entities.TransferBatchStatusProcessing, now, limit,
+	).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var batches []entities.TransferBatch
+	err = r.db.WithContext(ctx).Preload("Items", withItemOrder).Order("id").Find(&batches, ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (r *TransferBatchRepository) ExtendLease(ctx context.Context, batch *entities.TransferBatch, until time.Time) error {
+	result := r.db.WithContext(ctx).Model(&entities.TransferBatch{}).
+		Where("id = ? AND claim_token = ?", batch.ID, batch.ClaimToken).
+		Update("leased_until", until)
+	if err := claimed(result); err != nil {
+		return err
+	}
+	batch.LeasedUntil = &until
+	return nil
+}
+
+func (r *TransferBatchRepository) UpdateItem(ctx context.Context, batch *entities.TransferBatch, item *entities.TransferBatchItem) error {
+	result := r.db.WithContext(ctx).Model(&entities.TransferBatchItem{}).
+		Where("id = ? AND batch_id IN (SELECT id FROM transfer_batches WHERE id = ? AND claim_token = ?)", item.ID, batch.ID, batch.ClaimToken).
+		Updates(map[string]interface{}{
+			"status":         item.Status,
+			"transaction_id": item.TransactionID,
+			"failure_reason": item.FailureReason,
+		})
+	return claimed(result)
+}
+
+func (r *TransferBatchRepository) Finish(ctx context.Context, batch *entities.TransferBatch) error {
+	result := r.db.WithContext(ctx).Model(&entities.TransferBatch{}).
+		Where("id = ? AND claim_token = ?", batch.ID, batch.ClaimToken).
+		Updates(map[string]interface{}{
+			"status":         batch.Status,
+			"failure_reason": batch.FailureReason,
+			"leased_until":   nil,
+		})
+	return claimed(result)
+}
+
+// claimed reports a write that matched no row as the claim being lost.
+func claimed(result *gorm.DB) error {
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrTransferBatchLeaseLost
+	}
+	return nil
+}
+
+func withItemOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
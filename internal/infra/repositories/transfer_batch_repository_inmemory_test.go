@@ -0,0 +1,262 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TransferBatchRepositoryInMemory struct {
+	batches    map[int64]entities.TransferBatch
+	mu         sync.RWMutex
+	nextID     int64
+	nextItemID int64
+	claims     int
+}
+
+func NewTransferBatchRepositoryInMemory() port.TransferBatchRepository {
+	return &TransferBatchRepositoryInMemory{
+		batches:    make(map[int64]entities.TransferBatch),
+		mu:         sync.RWMutex{},
+		nextID:     1,
+		nextItemID: 1,
+	}
+}
+
+func (r *TransferBatchRepositoryInMemory) Create(ctx context.Context, batch *entities.TransferBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	batch.ID = r.nextID
+	batch.CreatedAt = time.Now()
+	for i := range batch.Items {
+		batch.Items[i].ID = r.nextItemID
+		batch.Items[i].BatchID = batch.ID
+		r.nextItemID++
+	}
+	r.store(*batch)
+	r.nextID++
+	return nil
+}
+
+func (r *TransferBatchRepositoryInMemory) GetByID(ctx context.Context, id int64) (*entities.TransferBatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	batch, ok := r.batches[id]
+	if !ok {
+		return nil, port.ErrTransferBatchNotFound
+	}
+	batch.Items = slices.Clone(batch.Items)
+	return &batch, nil
+}
+
+func (r *TransferBatchRepositoryInMemory) ClaimQueued(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entities.TransferBatch, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []entities.TransferBatch
+	for _, batch := range r.batches {
+		leased := batch.LeasedUntil != nil && batch.LeasedUntil.After(now)
+		if !batch.IsFinished() && !leased {
+			claimed = append(claimed, batch)
+		}
+	}
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].ID < claimed[j].ID })
+	if len(claimed) > limit {
+		claimed = claimed[:limit]
+	}
+	leasedUntil := now.Add(lease)
+	for i := range claimed {
+		r.claims++
+		claimed[i].Status = entities.TransferBatchStatusProcessing
+		claimed[i].LeasedUntil = &leasedUntil
+		claimed[i].ClaimToken = fmt.Sprintf("claim-%d", r.claims)
+		r.store(claimed[i])
+		claimed[i].Items = slices.Clone(claimed[i].Items)
+	}
+	return claimed, nil
+}
+
+func (r *TransferBatchRepositoryInMemory) ExtendLease(ctx context.Context, batch *entities.TransferBatch, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.batches[batch.ID]
+	if !ok || stored.ClaimToken != batch.ClaimToken {
+		return port.ErrTransferBatchLeaseLost
+	}
+	stored.LeasedUntil = &until
+	r.batches[batch.ID] = stored
+	batch.LeasedUntil = &until
+	return nil
+}
+
+func (r *TransferBatchRepositoryInMemory) UpdateItem(ctx context.Context, claim *entities.TransferBatch, item *entities.TransferBatchItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	batch, ok := r.batches[claim.ID]
+	if !ok {
+		return errors.New("lote não encontrado")
+	}
+	if batch.ClaimToken != claim.ClaimToken {
+		return port.ErrTransferBatchLeaseLost
+	}
+	for i := range batch.Items {
+		if batch.Items[i].ID == item.ID {
+			batch.Items[i].Status = item.Status
+			batch.Items[i].TransactionID = item.TransactionID
+			batch.Items[i].FailureReason = item.FailureReason
+			return nil
+		}
+	}
+	return errors.New("item do lote não encontrado")
+}
+
+func (r *TransferBatchRepositoryInMemory) Finish(ctx context.Context, batch *entities.TransferBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.batches[batch.ID]
+	if !ok {
+		return errors.New("lote não encontrado")
+	}
+	if stored.ClaimToken != batch.ClaimToken {
+		return port.ErrTransferBatchLeaseLost
+	}
+	stored.Status = batch.Status
+	stored.FailureReason = batch.FailureReason
+	stored.LeasedUntil = nil
+	r.batches[batch.ID] = stored
+	return nil
+}
+
+// store keeps its own copy of the items so callers cannot change them.
+func (r *TransferBatchRepositoryInMemory) store(batch entities.TransferBatch) {
+	batch.Items = slices.Clone(batch.Items)
+	r.batches[batch.ID] = batch
+}
+
+func (r *TransferBatchRepositoryInMemory) Snapshot() func() {
+	r.mu.RLock()
+	batches := maps.Clone(r.batches)
+	for id, batch := range batches {
+		batch.Items = slices.Clone(batch.Items)
+		batches[id] = batch
+	}
+	nextID, nextItemID, claims := r.nextID, r.nextItemID, r.claims
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.batches = batches
+		r.nextID, r.nextItemID, r.claims = nextID, nextItemID, claims
+	}
+}
+
+func newTransferBatch(payerID int64, payees ...int64) *entities.TransferBatch {
+	batch := &entities.TransferBatch{PayerID: payerID, Mode: entities.TransferBatchBestEffort, Status: entities.TransferBatchStatusQueued, Currency: entities.DefaultCurrency}
+	for position, payeeID := range payees {
+		batch.Items = append(batch.Items, entities.TransferBatchItem{Position: position, PayeeID: payeeID, Amount: entities.MoneyFromCents(100), Currency: entities.DefaultCurrency, Status: entities.TransferBatchItemStatusQueued})
+	}
+	return batch
+}
+
+func TestTransferBatchRepositoryInMemory_ClaimQueued_LeasesUnfinishedBatches(t *testing.T) {
+	repo := NewTransferBatchRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	finished := newTransferBatch(1, 2)
+	finished.Status = entities.TransferBatchStatusCompleted
+	assert.NoError(t, repo.Create(ctx, finished))
+	assert.NoError(t, repo.Create(ctx, newTransferBatch(1, 2, 3)))
+	assert.NoError(t, repo.Create(ctx, newTransferBatch(1, 3)))
+
+	claimed, err := repo.ClaimQueued(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 2)
+	assert.Equal(t, int64(2), claimed[0].ID)
+	assert.Equal(t, entities.TransferBatchStatusProcessing, claimed[0].Status)
+	assert.Len(t, claimed[0].Items, 2)
+
+	claimed, err = repo.ClaimQueued(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	claimed, err = repo.ClaimQueued(ctx, now.Add(time.Minute), time.Minute, 1)
+	assert.NoError(t, err)
+	assert.Len(t, claimed, 1)
+}
+
+func TestTransferBatchRepositoryInMemory_UpdateItemAndFinish(t *testing.T) {
+	repo := NewTransferBatchRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, repo.Create(ctx, newTransferBatch(1, 2, 3)))
+	claimed, err := repo.ClaimQueued(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+	batch := &claimed[0]
+
+	transactionID := int64(99)
+	batch.Items[0].Status = entities.TransferBatchItemStatusCompleted
+	batch.Items[0].TransactionID = &transactionID
+	batch.Items[1].Fail("payee not found")
+	assert.NoError(t, repo.UpdateItem(ctx, batch, &batch.Items[0]))
+	assert.NoError(t, repo.UpdateItem(ctx, batch, &batch.Items[1]))
+	batch.Finish()
+	assert.NoError(t, repo.Finish(ctx, batch))
+
+	stored, err := repo.GetByID(ctx, batch.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransferBatchStatusPartiallyCompleted, stored.Status)
+	assert.Nil(t, stored.LeasedUntil)
+	assert.Equal(t, &transactionID, stored.Items[0].TransactionID)
+	assert.Equal(t, "payee not found", stored.Items[1].FailureReason)
+
+	claimed, err = repo.ClaimQueued(ctx, now.Add(time.Hour), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestTransferBatchRepositoryInMemory_StaleClaimCannotWrite(t *testing.T) {
+	repo := NewTransferBatchRepositoryInMemory()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, repo.Create(ctx, newTransferBatch(1, 2)))
+	first, err := repo.ClaimQueued(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.NoError(t, repo.ExtendLease(ctx, &first[0], now.Add(2*time.Minute)))
+
+	// Still leased by the renewal.
+	claimed, err := repo.ClaimQueued(ctx, now.Add(time.Minute), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	second, err := repo.ClaimQueued(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Len(t, second, 1)
+	assert.NotEqual(t, first[0].ClaimToken, second[0].ClaimToken)
+
+	stale := &first[0].Items[0]
+	stale.Status = entities.TransferBatchItemStatusCompleted
+	assert.ErrorIs(t, repo.UpdateItem(ctx, &first[0], stale), port.ErrTransferBatchLeaseLost)
+	assert.ErrorIs(t, repo.ExtendLease(ctx, &first[0], now.Add(time.Hour)), port.ErrTransferBatchLeaseLost)
+	first[0].Finish()
+	assert.ErrorIs(t, repo.Finish(ctx, &first[0]), port.ErrTransferBatchLeaseLost)
+
+	second[0].Items[0].Fail("execution interrupted")
+	assert.NoError(t, repo.UpdateItem(ctx, &second[0], &second[0].Items[0]))
+	stored, err := repo.GetByID(ctx, second[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransferBatchItemStatusFailed, stored.Items[0].Status)
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+
+	"gorm.io/gorm"
+)
+
+type TransferCounterRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferCounterRepository(db *gorm.DB) *TransferCounterRepository {
+	return &TransferCounterRepository{
+		db: db,
+	}
+}
+
+func (r *TransferCounterRepository) Get(ctx context.Context, userID int64, period entities.LimitPeriod, start time.Time) (entities.TransferCounter, error) {
+	counter := entities.TransferCounter{}
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND period = ? AND period_start = ?", userID, period, start.UTC()).
+		First(&counter).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entities.TransferCounter{UserID: userID, Period: period, PeriodStart: start}, nil
+	}
+	return counter, err
+}
+
+func (r *TransferCounterRepository) Add(ctx context.Context, userID int64, period entities.LimitPeriod, start time.Time, amount entities.Money) error {
+	return r.db.WithContext(ctx).Exec(`
+		INSERT INTO transfer_counters (user_id, period, period_start, amount, count, updated_at)
+		VALUES (?, ?, ?, ?, 1, ?)
+		ON CONFLICT (user_id, period, period_start)
+		DO UPDATE SET amount = transfer_counters.amount + EXCLUDED.amount,
+			count = transfer_counters.count + 1,
+			updated_at = EXCLUDED.updated_at`,
+		userID, period, start.UTC(), amount, time.Now(),
+	).Error
+}
@@ -0,0 +1,95 @@
+package repositories_test
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type transferCounterKey struct {
+	userID int64
+	period entities.LimitPeriod
+	start  int64
+}
+
+type TransferCounterRepositoryInMemory struct {
+	counters map[transferCounterKey]entities.TransferCounter
+	mu       sync.RWMutex
+}
+
+func NewTransferCounterRepositoryInMemory() port.TransferCounterRepository {
+	return &TransferCounterRepositoryInMemory{
+		counters: make(map[transferCounterKey]entities.TransferCounter),
+		mu:       sync.RWMutex{},
+	}
+}
+
+func (r *TransferCounterRepositoryInMemory) Get(ctx context.Context, userID int64, period entities.LimitPeriod, start time.Time) (entities.TransferCounter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	counter, ok := r.counters[transferCounterKey{userID, period, start.Unix()}]
+	if !ok {
+		return entities.TransferCounter{UserID: userID, Period: period, PeriodStart: start}, nil
+	}
+	return counter, nil
+}
+
+func (r *TransferCounterRepositoryInMemory) Add(ctx context.Context, userID int64, period entities.LimitPeriod, start time.Time, amount entities.Money) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := transferCounterKey{userID, period, start.Unix()}
+	counter, ok := r.counters[key]
+	if !ok {
+		counter = entities.TransferCounter{UserID: userID, Period: period, PeriodStart: start}
+	}
+	total, err := counter.Amount.Add(amount)
+	if err != nil {
+		return err
+	}
+	counter.Amount = total
+	counter.Count++
+	counter.UpdatedAt = time.Now()
+	r.counters[key] = counter
+	return nil
+}
+
+func (r *TransferCounterRepositoryInMemory) Snapshot() func() {
+	r.mu.RLock()
+	saved := maps.Clone(r.counters)
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.counters = saved
+	}
+}
+
+func TestTransferCounterRepositoryInMemory_AddAccumulatesPerPeriod(t *testing.T) {
+	repo := NewTransferCounterRepositoryInMemory()
+	ctx := context.Background()
+	today := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	assert.NoError(t, repo.Add(ctx, 1, entities.LimitPeriodDay, today, entities.MoneyFromCents(1000)))
+	assert.NoError(t, repo.Add(ctx, 1, entities.LimitPeriodDay, today, entities.MoneyFromCents(2500)))
+	assert.NoError(t, repo.Add(ctx, 1, entities.LimitPeriodDay, tomorrow, entities.MoneyFromCents(700)))
+	assert.NoError(t, repo.Add(ctx, 2, entities.LimitPeriodDay, today, entities.MoneyFromCents(900)))
+
+	counter, err := repo.Get(ctx, 1, entities.LimitPeriodDay, today)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(3500), counter.Amount)
+	assert.Equal(t, 2, counter.Count)
+
+	counter, err = repo.Get(ctx, 1, entities.LimitPeriodMonth, today)
+	assert.NoError(t, err)
+	assert.True(t, counter.Amount.IsZero())
+	assert.Equal(t, 0, counter.Count)
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransferLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferLimitRepository(db *gorm.DB) *TransferLimitRepository {
+	return &TransferLimitRepository{
+		db: db,
+	}
+}
+
+func (r *TransferLimitRepository) GetByUserID(ctx context.Context, userID int64) (*entities.UserTransferLimit, error) {
+	limit := &entities.UserTransferLimit{}
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(limit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, port.ErrTransferLimitNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return limit, nil
+}
+
+func (r *TransferLimitRepository) Save(ctx context.Context, limit *entities.UserTransferLimit) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"single_max", "daily_amount", "monthly_amount", "hourly_count", "updated_at"}),
+	}).Create(limit).Error
+}
+
+func (r *TransferLimitRepository) Delete(ctx context.Context, userID int64) error {
+	result := r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entities.UserTransferLimit{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrTransferLimitNotFound
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package repositories_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TransferLimitRepositoryInMemory struct {
+	limits map[int64]entities.UserTransferLimit
+	mu     sync.RWMutex
+}
+
+func NewTransferLimitRepositoryInMemory() port.TransferLimitRepository {
+	return &TransferLimitRepositoryInMemory{
+		limits: make(map[int64]entities.UserTransferLimit),
+		mu:     sync.RWMutex{},
+	}
+}
+
+func (r *TransferLimitRepositoryInMemory) GetByUserID(ctx context.Context, userID int64) (*entities.UserTransferLimit, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	limit, ok := r.limits[userID]
+	if !ok {
+		return nil, port.ErrTransferLimitNotFound
+	}
+	return &limit, nil
+}
+
+func (r *TransferLimitRepositoryInMemory) Save(ctx context.Context, limit *entities.UserTransferLimit) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if existing, ok := r.limits[limit.UserID]; ok {
+		limit.CreatedAt = existing.CreatedAt
+	} else {
+		limit.CreatedAt = now
+	}
+	limit.UpdatedAt = now
+	r.limits[limit.UserID] = *limit
+	return nil
+}
+
+func (r *TransferLimitRepositoryInMemory) Delete(ctx context.Context, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.limits[userID]; !ok {
+		return port.ErrTransferLimitNotFound
+	}
+	delete(r.limits, userID)
+	return nil
+}
+
+func TestTransferLimitRepositoryInMemory_SaveReplacesOverride(t *testing.T) {
+	repo := NewTransferLimitRepositoryInMemory()
+	ctx := context.Background()
+	daily := entities.MoneyFromCents(100000)
+	hourly := 5
+
+	assert.NoError(t, repo.Save(ctx, &entities.UserTransferLimit{UserID: 1, TransferLimits: entities.TransferLimits{DailyAmount: &daily}}))
+	assert.NoError(t, repo.Save(ctx, &entities.UserTransferLimit{UserID: 1, TransferLimits: entities.TransferLimits{HourlyCount: &hourly}}))
+
+	limit, err := repo.GetByUserID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, limit.DailyAmount)
+	assert.Equal(t, &hourly, limit.HourlyCount)
+}
+
+func TestTransferLimitRepositoryInMemory_Delete(t *testing.T) {
+	repo := NewTransferLimitRepositoryInMemory()
+	ctx := context.Background()
+	hourly := 5
+
+	assert.ErrorIs(t, repo.Delete(ctx, 1), port.ErrTransferLimitNotFound)
+
+	assert.NoError(t, repo.Save(ctx, &entities.UserTransferLimit{UserID: 1, TransferLimits: entities.TransferLimits{HourlyCount: &hourly}}))
+	assert.NoError(t, repo.Delete(ctx, 1))
+
+	_, err := repo.GetByUserID(ctx, 1)
+	assert.ErrorIs(t, err, port.ErrTransferLimitNotFound)
+}
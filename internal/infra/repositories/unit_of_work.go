@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"context"
+
+	"go-transfer/internal/domain/port"
+
+	"gorm.io/gorm"
+)
+
+type WalletLockerFactory func(tx *gorm.DB) port.WalletLocker
+
+type UnitOfWork struct {
+	db           *gorm.DB
+	walletLocker WalletLockerFactory
+}
+
+func NewUnitOfWork(db *gorm.DB, walletLocker WalletLockerFactory) *UnitOfWork {
+	return &UnitOfWork{
+		db:           db,
+		walletLocker: walletLocker,
+	}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos port.Repositories) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(port.Repositories{
+			Users:         NewUserRepository(tx),
+			Wallets:       NewWalletRepository(tx),
+			Transactions:  NewTransactionRepository(tx),
+			Notifications: NewNotificationRepository(tx),
+			Ledger:        NewLedgerRepository(tx),
+			Outbox:        NewOutboxRepository(tx),
+			Counters:      NewTransferCounterRepository(tx),
+			Scheduled:     NewScheduledTransferRepository(tx),
+			Batches:       NewTransferBatchRepository(tx),
+			Quotes:        NewExchangeQuoteRepository(tx),
+			Locker:        u.walletLocker(tx),
+		})
+	})
+}
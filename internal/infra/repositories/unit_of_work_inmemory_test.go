@@ -0,0 +1,171 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
+	"go-transfer/internal/infra/locks"
+	"go-transfer/internal/infra/repositories"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type snapshotter interface {
+	Snapshot() func()
+}
+
+type UnitOfWorkInMemory struct {
+	repos port.Repositories
+	mu    sync.Mutex
+}
+
+func NewUnitOfWorkInMemory(repos port.Repositories) port.UnitOfWork {
+	return &UnitOfWorkInMemory{
+		repos: repos,
+		mu:    sync.Mutex{},
+	}
+}
+
+func (u *UnitOfWorkInMemory) Do(ctx context.Context, fn func(repos port.Repositories) error) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	var restores []func()
+	for _, repo := range []any{
+		u.repos.Users, u.repos.Wallets, u.repos.Transactions, u.repos.Notifications, u.repos.Ledger,
+		u.repos.Outbox, u.repos.Counters, u.repos.Scheduled, u.repos.Batches, u.repos.Quotes,
+	} {
+		if s, ok := repo.(snapshotter); ok {
+			restores = append(restores, s.Snapshot())
+		}
+	}
+
+	if err := fn(u.repos); err != nil {
+		for _, restore := range restores {
+			restore()
+		}
+		return err
+	}
+	return nil
+}
+
+func newInMemoryRepositories() port.Repositories {
+	return port.Repositories{
+		Users:         repositories.NewUserRepositoryInMemory(),
+		Wallets:       repositories.NewWalletRepositoryInMemory(),
+		Transactions:  NewTransactionRepositoryInMemory(),
+		Notifications: NewNotificationRepositoryInMemory(),
+		Ledger:        NewLedgerRepositoryInMemory(),
+		Outbox:        NewOutboxRepositoryInMemory(),
+		Counters:      NewTransferCounterRepositoryInMemory(),
+		Scheduled:     NewScheduledTransferRepositoryInMemory(),
+		Batches:       NewTransferBatchRepositoryInMemory(),
+		Quotes:        NewExchangeQuoteRepositoryInMemory(),
+		Locker:        locks.NewMemoryWalletLocker(),
+	}
+}
+
+func TestUnitOfWorkInMemory_Do_Commit(t *testing.T) {
+	repos := newInMemoryRepositories()
+	uow := NewUnitOfWorkInMemory(repos)
+	ctx := context.Background()
+
+	wallet := &entities.Wallet{OwnerID: 1, Balance: entities.MoneyFromCents(10000)}
+	assert.NoError(t, repos.Wallets.Create(ctx, wallet))
+
+	var transactionID int64
+	err := uow.Do(ctx, func(tx port.Repositories) error {
+		id, err := tx.Transactions.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Amount: entities.MoneyFromCents(4000), Status: entities.TransactionStatusPending})
+		if err != nil {
+			return err
+		}
+		transactionID = id
+		return tx.Wallets.Debit(ctx, wallet.ID, entities.MoneyFromCents(4000), wallet.Version)
+	})
+	assert.NoError(t, err)
+
+	retrievedWallet, err := repos.Wallets.GetByID(ctx, wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(6000), retrievedWallet.Balance)
+
+	transaction, err := repos.Transactions.GetByID(ctx, transactionID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.TransactionStatusPending, transaction.Status)
+}
+
+func TestUnitOfWorkInMemory_Do_Rollback(t *testing.T) {
+	repos := newInMemoryRepositories()
+	uow := NewUnitOfWorkInMemory(repos)
+	ctx := context.Background()
+
+	wallet := &entities.Wallet{OwnerID: 1, Balance: entities.MoneyFromCents(10000)}
+	assert.NoError(t, repos.Wallets.Create(ctx, wallet))
+
+	var transactionID int64
+	err := uow.Do(ctx, func(tx port.Repositories) error {
+		id, err := tx.Transactions.Create(ctx, &entities.Transaction{SenderID: 1, ReceiverID: 2, Amount: entities.MoneyFromCents(4000), Status: entities.TransactionStatusPending})
+		if err != nil {
+			return err
+		}
+		transactionID = id
+		if err := tx.Wallets.Debit(ctx, wallet.ID, entities.MoneyFromCents(4000), wallet.Version); err != nil {
+			return err
+		}
+		return errors.New("credit failed")
+	})
+	assert.EqualError(t, err, "credit failed")
+
+	retrievedWallet, err := repos.Wallets.GetByID(ctx, wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(10000), retrievedWallet.Balance)
+
+	transaction, err := repos.Transactions.GetByID(ctx, transactionID)
+	assert.Error(t, err)
+	assert.Nil(t, transaction)
+}
+
+func TestUnitOfWorkInMemory_Do_RollsBackEveryRepository(t *testing.T) {
+	repos := newInMemoryRepositories()
+	uow := NewUnitOfWorkInMemory(repos)
+	ctx := context.Background()
+
+	quote := &entities.ExchangeQuote{PayerID: 1, PayeeID: 2}
+	assert.NoError(t, repos.Quotes.Create(ctx, quote))
+
+	err := uow.Do(ctx, func(tx port.Repositories) error {
+		message, err := entities.NewOutboxMessage(entities.TransferNotificationPayload{ReceiverID: 2, TransactionID: 1, Amount: entities.MoneyFromCents(4000)}, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := tx.Outbox.Create(ctx, message); err != nil {
+			return err
+		}
+		if _, err := tx.Notifications.Create(ctx, &entities.Notification{ReceiverID: 2}); err != nil {
+			return err
+		}
+		if err := tx.Users.Create(ctx, &entities.User{Email: "payee@example.com"}); err != nil {
+			return err
+		}
+		if err := tx.Quotes.MarkUsed(ctx, quote.ID, time.Now()); err != nil {
+			return err
+		}
+		return errors.New("settlement failed")
+	})
+	assert.EqualError(t, err, "settlement failed")
+
+	pending, err := repos.Outbox.ClaimPending(ctx, time.Now(), time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+	_, err = repos.Notifications.GetByID(ctx, 1)
+	assert.Error(t, err)
+	_, err = repos.Users.GetByEmail(ctx, "payee@example.com")
+	assert.Error(t, err)
+	restored, err := repos.Quotes.GetByID(ctx, quote.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, restored.UsedAt)
+}
@@ -78,6 +78,29 @@ func (r *UserRepositoryInMemory) ListAll(ctx context.Context) ([]entities.User,
 	return users, nil
 }
 
+func (r *UserRepositoryInMemory) Snapshot() func() {
+	r.mu.RLock()
+	saved := make(map[int64]entities.User, len(r.users))
+	for id, user := range r.users {
+		saved[id] = *user
+	}
+	nextID := r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for id, user := range r.users {
+			if previous, ok := saved[id]; ok {
+				*user = previous
+			} else {
+				delete(r.users, id)
+			}
+		}
+		r.nextID = nextID
+	}
+}
+
 func TestUserRepositoryInMemory_Create(t *testing.T) {
 	repo := NewUserRepositoryInMemory()
 	ctx := context.Background()
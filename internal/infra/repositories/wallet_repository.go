@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"go-transfer/internal/domain/entities"
+	"go-transfer/internal/domain/port"
 
 	"gorm.io/gorm"
 )
@@ -40,6 +41,77 @@ func (r *WalletRepository) GetByOwnerID(ctx context.Context, ownerID int64) (*en
 	return wallet, nil
 }
 
-func (r *WalletRepository) UpdateBalance(ctx context.Context, id int64, balance float64) error {
-	return r.db.WithContext(ctx).Model(&entities.Wallet{}).Where("id = ?", id).Update("balance", balance).Error
+func (r *WalletRepository) GetSystemWallet(ctx context.Context, account entities.SystemAccount, currency string) (*entities.Wallet, error) {
+	wallet := &entities.Wallet{}
+	err := r.db.WithContext(ctx).Where("system_account = ? AND currency = ?", account, currency).First(wallet).Error
+	if err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+// Debit refuses to overdraw user wallets or to spend what they hold; system
+// wallets may go negative since they mirror money held outside the platform.
+func (r *WalletRepository) Debit(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	result := r.db.WithContext(ctx).Model(&entities.Wallet{}).
+		Where("id = ? AND version = ? AND (balance - held_balance >= ? OR type = ?)", id, version, amount, entities.SystemWallet).
+		Updates(map[string]interface{}{
+			"balance": gorm.Expr("balance - ?", amount),
+			"version": gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrWalletConflict
+	}
+	return nil
+}
+
+func (r *WalletRepository) Credit(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	result := r.db.WithContext(ctx).Model(&entities.Wallet{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(map[string]interface{}{
+			"balance": gorm.Expr("balance + ?", amount),
+			"version": gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrWalletConflict
+	}
+	return nil
+}
+
+func (r *WalletRepository) Hold(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	result := r.db.WithContext(ctx).Model(&entities.Wallet{}).
+		Where("id = ? AND version = ? AND balance - held_balance >= ?", id, version, amount).
+		Updates(map[string]interface{}{
+			"held_balance": gorm.Expr("held_balance + ?", amount),
+			"version":      gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrWalletConflict
+	}
+	return nil
+}
+
+func (r *WalletRepository) ReleaseHold(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	result := r.db.WithContext(ctx).Model(&entities.Wallet{}).
+		Where("id = ? AND version = ? AND held_balance >= ?", id, version, amount).
+		Updates(map[string]interface{}{
+			"held_balance": gorm.Expr("held_balance - ?", amount),
+			"version":      gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return port.ErrWalletConflict
+	}
+	return nil
 }
@@ -56,25 +56,139 @@ func (r *WalletRepositoryInMemory) GetByOwnerID(ctx context.Context, ownerID int
 	return nil, errors.New("carteira não encontrada para o OwnerID")
 }
 
-func (r *WalletRepositoryInMemory) UpdateBalance(ctx context.Context, id int64, balance float64) error {
+func (r *WalletRepositoryInMemory) GetSystemWallet(ctx context.Context, account entities.SystemAccount, currency string) (*entities.Wallet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, wallet := range r.wallets {
+		if wallet.SystemAccount != nil && *wallet.SystemAccount == account && wallet.CurrencyCode() == currency {
+			return wallet, nil
+		}
+	}
+	return nil, errors.New("carteira do sistema não encontrada")
+}
+
+func (r *WalletRepositoryInMemory) Debit(ctx context.Context, id int64, amount entities.Money, version int64) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	wallet, ok := r.wallets[id]
 	if !ok {
 		return errors.New("carteira não encontrada")
 	}
+	short, err := wallet.AvailableBalance().LessThan(amount)
+	if err != nil {
+		return err
+	}
+	if wallet.Version != version || (wallet.Type != entities.SystemWallet && short) {
+		return port.ErrWalletConflict
+	}
+	balance, err := wallet.Balance.Sub(amount)
+	if err != nil {
+		return err
+	}
 	wallet.Balance = balance
+	wallet.Version++
 	wallet.UpdatedAt = time.Now()
 	return nil
 }
 
+func (r *WalletRepositoryInMemory) Credit(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wallet, ok := r.wallets[id]
+	if !ok {
+		return errors.New("carteira não encontrada")
+	}
+	if wallet.Version != version {
+		return port.ErrWalletConflict
+	}
+	balance, err := wallet.Balance.Add(amount)
+	if err != nil {
+		return err
+	}
+	wallet.Balance = balance
+	wallet.Version++
+	wallet.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *WalletRepositoryInMemory) Hold(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wallet, ok := r.wallets[id]
+	if !ok {
+		return errors.New("carteira não encontrada")
+	}
+	short, err := wallet.AvailableBalance().LessThan(amount)
+	if err != nil {
+		return err
+	}
+	if wallet.Version != version || short {
+		return port.ErrWalletConflict
+	}
+	held, err := entities.NewMoney(wallet.HeldBalance.Cents, wallet.CurrencyCode()).Add(amount)
+	if err != nil {
+		return err
+	}
+	wallet.HeldBalance = held
+	wallet.Version++
+	wallet.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *WalletRepositoryInMemory) ReleaseHold(ctx context.Context, id int64, amount entities.Money, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	wallet, ok := r.wallets[id]
+	if !ok {
+		return errors.New("carteira não encontrada")
+	}
+	short, err := entities.NewMoney(wallet.HeldBalance.Cents, wallet.CurrencyCode()).LessThan(amount)
+	if err != nil {
+		return err
+	}
+	if wallet.Version != version || short {
+		return port.ErrWalletConflict
+	}
+	held, err := entities.NewMoney(wallet.HeldBalance.Cents, wallet.CurrencyCode()).Sub(amount)
+	if err != nil {
+		return err
+	}
+	wallet.HeldBalance = held
+	wallet.Version++
+	wallet.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *WalletRepositoryInMemory) Snapshot() func() {
+	r.mu.RLock()
+	saved := make(map[int64]entities.Wallet, len(r.wallets))
+	for id, wallet := range r.wallets {
+		saved[id] = *wallet
+	}
+	nextID := r.nextID
+	r.mu.RUnlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for id, wallet := range r.wallets {
+			if previous, ok := saved[id]; ok {
+				*wallet = previous
+			} else {
+				delete(r.wallets, id)
+			}
+		}
+		r.nextID = nextID
+	}
+}
+
 func TestWalletRepositoryInMemory_Create(t *testing.T) {
 	repo := NewWalletRepositoryInMemory()
 	ctx := context.Background()
 
 	wallet := &entities.Wallet{
 		OwnerID:   1,
-		Balance:   100.50,
+		Balance:   entities.MoneyFromCents(10050),
 		CreatedAt: time.Now(),
 	}
 
@@ -93,7 +207,7 @@ func TestWalletRepositoryInMemory_GetByID_Found(t *testing.T) {
 
 	expectedWallet := &entities.Wallet{
 		OwnerID:   2,
-		Balance:   50.00,
+		Balance:   entities.MoneyFromCents(5000),
 		CreatedAt: time.Now(),
 	}
 	err := repo.Create(ctx, expectedWallet)
@@ -120,7 +234,7 @@ func TestWalletRepositoryInMemory_GetByOwnerID_Found(t *testing.T) {
 
 	expectedWallet := &entities.Wallet{
 		OwnerID:   3,
-		Balance:   120.75,
+		Balance:   entities.MoneyFromCents(12075),
 		CreatedAt: time.Now(),
 	}
 	err := repo.Create(ctx, expectedWallet)
@@ -141,37 +255,126 @@ func TestWalletRepositoryInMemory_GetByOwnerID_NotFound(t *testing.T) {
 	assert.Nil(t, retrievedWallet)
 }
 
-func TestWalletRepositoryInMemory_UpdateBalance_Success(t *testing.T) {
+func TestWalletRepositoryInMemory_GetSystemWallet_Found(t *testing.T) {
 	repo := NewWalletRepositoryInMemory()
 	ctx := context.Background()
+	account := entities.SystemAccountFunding
 
-	initialWallet := &entities.Wallet{
-		OwnerID:   4,
-		Balance:   75.20,
-		CreatedAt: time.Now(),
-	}
-	err := repo.Create(ctx, initialWallet)
+	assert.NoError(t, repo.Create(ctx, &entities.Wallet{OwnerID: 4, Type: entities.CommonWallet}))
+	assert.NoError(t, repo.Create(ctx, &entities.Wallet{OwnerID: 1, Currency: "USD", Type: entities.SystemWallet, SystemAccount: &account}))
+	expectedWallet := &entities.Wallet{OwnerID: 1, Currency: "BRL", Type: entities.SystemWallet, SystemAccount: &account}
+	assert.NoError(t, repo.Create(ctx, expectedWallet))
+
+	retrievedWallet, err := repo.GetSystemWallet(ctx, entities.SystemAccountFunding, "BRL")
 	assert.NoError(t, err)
+	assert.Equal(t, expectedWallet, retrievedWallet)
+}
+
+func TestWalletRepositoryInMemory_GetSystemWallet_NotFound(t *testing.T) {
+	repo := NewWalletRepositoryInMemory()
+	ctx := context.Background()
+
+	retrievedWallet, err := repo.GetSystemWallet(ctx, entities.SystemAccountFunding, "BRL")
+	assert.Error(t, err)
+	assert.ErrorContains(t, err, "carteira do sistema não encontrada")
+	assert.Nil(t, retrievedWallet)
+}
+
+func TestWalletRepositoryInMemory_Debit_Success(t *testing.T) {
+	repo := NewWalletRepositoryInMemory()
+	ctx := context.Background()
 
-	newBalance := 150.90
-	err = repo.UpdateBalance(ctx, initialWallet.ID, newBalance)
+	wallet := &entities.Wallet{OwnerID: 5, Balance: entities.MoneyFromCents(10000)}
+	assert.NoError(t, repo.Create(ctx, wallet))
+
+	err := repo.Debit(ctx, wallet.ID, entities.MoneyFromCents(4000), 0)
 	assert.NoError(t, err)
 
-	retrievedWallet, err := repo.GetByOwnerID(ctx, initialWallet.OwnerID)
+	retrievedWallet, err := repo.GetByID(ctx, wallet.ID)
 	assert.NoError(t, err)
-	assert.Equal(t, newBalance, retrievedWallet.Balance)
+	assert.Equal(t, entities.MoneyFromCents(6000), retrievedWallet.Balance)
+	assert.Equal(t, int64(1), retrievedWallet.Version)
 }
 
-func TestWalletRepositoryInMemory_UpdateBalance_NotFound(t *testing.T) {
+func TestWalletRepositoryInMemory_Debit_StaleVersion(t *testing.T) {
 	repo := NewWalletRepositoryInMemory()
 	ctx := context.Background()
 
-	err := repo.UpdateBalance(ctx, 999, 200.00)
-	assert.Error(t, err)
-	assert.ErrorContains(t, err, "carteira não encontrada")
+	wallet := &entities.Wallet{OwnerID: 6, Balance: entities.MoneyFromCents(10000)}
+	assert.NoError(t, repo.Create(ctx, wallet))
+	assert.NoError(t, repo.Credit(ctx, wallet.ID, entities.MoneyFromCents(100), 0))
 
-	retrievedWallet, err := repo.GetByID(ctx, 999)
-	assert.Error(t, err)
-	assert.ErrorContains(t, err, "carteira não encontrada")
-	assert.Nil(t, retrievedWallet)
+	err := repo.Debit(ctx, wallet.ID, entities.MoneyFromCents(4000), 0)
+	assert.ErrorIs(t, err, port.ErrWalletConflict)
+
+	retrievedWallet, err := repo.GetByID(ctx, wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(10100), retrievedWallet.Balance)
+}
+
+func TestWalletRepositoryInMemory_Debit_InsufficientBalance(t *testing.T) {
+	repo := NewWalletRepositoryInMemory()
+	ctx := context.Background()
+
+	wallet := &entities.Wallet{OwnerID: 7, Balance: entities.MoneyFromCents(1000)}
+	assert.NoError(t, repo.Create(ctx, wallet))
+
+	err := repo.Debit(ctx, wallet.ID, entities.MoneyFromCents(1001), 0)
+	assert.ErrorIs(t, err, port.ErrWalletConflict)
+
+	retrievedWallet, err := repo.GetByID(ctx, wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(1000), retrievedWallet.Balance)
+	assert.Equal(t, int64(0), retrievedWallet.Version)
+}
+
+func TestWalletRepositoryInMemory_Debit_SystemWalletMayGoNegative(t *testing.T) {
+	repo := NewWalletRepositoryInMemory()
+	ctx := context.Background()
+	account := entities.SystemAccountFunding
+
+	wallet := &entities.Wallet{OwnerID: 1, Type: entities.SystemWallet, SystemAccount: &account}
+	assert.NoError(t, repo.Create(ctx, wallet))
+
+	err := repo.Debit(ctx, wallet.ID, entities.MoneyFromCents(2500), 0)
+	assert.NoError(t, err)
+
+	retrievedWallet, err := repo.GetByID(ctx, wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(-2500), retrievedWallet.Balance)
+}
+
+func TestWalletRepositoryInMemory_Hold_ReservesAvailableBalance(t *testing.T) {
+	repo := NewWalletRepositoryInMemory()
+	ctx := context.Background()
+
+	wallet := &entities.Wallet{OwnerID: 8, Balance: entities.MoneyFromCents(1000)}
+	assert.NoError(t, repo.Create(ctx, wallet))
+
+	assert.NoError(t, repo.Hold(ctx, wallet.ID, entities.MoneyFromCents(600), 0))
+	assert.ErrorIs(t, repo.Hold(ctx, wallet.ID, entities.MoneyFromCents(500), 1), port.ErrWalletConflict)
+	assert.ErrorIs(t, repo.Debit(ctx, wallet.ID, entities.MoneyFromCents(500), 1), port.ErrWalletConflict)
+
+	retrievedWallet, err := repo.GetByID(ctx, wallet.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entities.MoneyFromCents(1000), retrievedWallet.Balance)
+	assert.Equal(t, entities.MoneyFromCents(600), retrievedWallet.HeldBalance)
+	assert.Equal(t, entities.MoneyFromCents(400), retrievedWallet.AvailableBalance())
+}
+
+func TestWalletRepositoryInMemory_ReleaseHold(t *testing.T) {
+	repo := NewWalletRepositoryInMemory()
+	ctx := context.Background()
+
+	wallet := &entities.Wallet{OwnerID: 9, Balance: entities.MoneyFromCents(1000)}
+	assert.NoError(t, repo.Create(ctx, wallet))
+	assert.NoError(t, repo.Hold(ctx, wallet.ID, entities.MoneyFromCents(600), 0))
+
+	assert.ErrorIs(t, repo.ReleaseHold(ctx, wallet.ID, entities.MoneyFromCents(601), 1), port.ErrWalletConflict)
+	assert.NoError(t, repo.ReleaseHold(ctx, wallet.ID, entities.MoneyFromCents(600), 1))
+
+	retrievedWallet, err := repo.GetByID(ctx, wallet.ID)
+	assert.NoError(t, err)
+	assert.True(t, retrievedWallet.HeldBalance.IsZero())
+	assert.Equal(t, int64(2), retrievedWallet.Version)
 }